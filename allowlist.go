@@ -0,0 +1,64 @@
+package graphql
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+// ParseSignedManifest is like ParseManifest, but first verifies that
+// sig is a valid Ed25519 signature of data by the owner of publicKey.
+func ParseSignedManifest(data, sig []byte, publicKey ed25519.PublicKey) (*Manifest, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid manifest public key size %d", len(publicKey))
+	}
+	if !ed25519.Verify(publicKey, data, sig) {
+		return nil, errors.New("invalid manifest signature")
+	}
+	return ParseManifest(data)
+}
+
+// WithAllowList makes the client refuse to execute operations whose
+// document, or document ID, isn't in manifest m, returning a *PolicyError
+// instead. In high-security deployments, m should come from
+// ParseSignedManifest, to guarantee that no ad-hoc operations leave
+// the service.
+//
+// The allow-list applies to all requests made by the client,
+// including those made with RoundTrip.
+func WithAllowList(m *Manifest) ClientOption {
+	return func(c *Client) {
+		c.allowList = m
+	}
+}
+
+// PolicyError is returned when the client refuses to execute
+// an operation not allowed by its allow-list.
+type PolicyError struct {
+	Query      string // Query of the refused request, if sent by query.
+	DocumentID string // Document ID of the refused request, if sent by document ID.
+}
+
+func (e *PolicyError) Error() string {
+	if e.DocumentID != "" {
+		return fmt.Sprintf("graphql: document ID %q is not in the allow-list", e.DocumentID)
+	}
+	return fmt.Sprintf("graphql: operation is not in the allow-list: %q", e.Query)
+}
+
+// checkAllowed checks that request in is allowed by c's allow-list.
+func (c *Client) checkAllowed(in Request) error {
+	if c.allowList == nil {
+		return nil
+	}
+	var ok bool
+	if in.DocumentID != "" {
+		_, ok = c.allowList.Document(in.DocumentID)
+	} else {
+		_, ok = c.allowList.ID(in.Query)
+	}
+	if !ok {
+		return &PolicyError{Query: in.Query, DocumentID: in.DocumentID}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_allowList(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"viewer-1": "{viewer{login}}"}`)
+	sig := ed25519.Sign(privateKey, data)
+
+	if _, err := graphql.ParseSignedManifest(append(data, ' '), sig, publicKey); err == nil {
+		t.Error("got error: nil for tampered manifest, want: non-nil")
+	}
+	manifest, err := graphql.ParseSignedManifest(data, sig, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithAllowList(manifest),
+	)
+
+	if err := client.Query(context.Background(), new(viewerQuery), nil); err != nil {
+		t.Errorf("allowed query: %v", err)
+	}
+	if err := client.Run(context.Background(), &graphql.Persisted{Operation: graphql.NewQuery(new(viewerQuery), nil), ID: "viewer-1"}); err != nil {
+		t.Errorf("allowed persisted query: %v", err)
+	}
+
+	refused := []struct {
+		run  func() error
+		want string
+	}{
+		{
+			run: func() error {
+				return client.Query(context.Background(), new(repoQuery), map[string]interface{}{
+					"owner": graphql.String("o"),
+					"name":  graphql.String("n"),
+				})
+			},
+			want: `graphql: operation is not in the allow-list: "query($name:String!$owner:String!){repository(owner: $owner, name: $name){name}}"`,
+		},
+		{
+			run: func() error {
+				return client.Run(context.Background(), &graphql.Persisted{Operation: graphql.NewQuery(new(viewerQuery), nil), ID: "viewer-2"})
+			},
+			want: `graphql: document ID "viewer-2" is not in the allow-list`,
+		},
+		{
+			run: func() error {
+				_, err := client.RoundTrip(context.Background(), graphql.Request{Query: "{viewer{email}}"})
+				return err
+			},
+			want: `graphql: operation is not in the allow-list: "{viewer{email}}"`,
+		},
+	}
+	for _, tc := range refused {
+		err := tc.run()
+		if _, ok := err.(*graphql.PolicyError); !ok {
+			t.Errorf("got error: %v, want *graphql.PolicyError", err)
+			continue
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("got error: %v, want: %v", got, tc.want)
+		}
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
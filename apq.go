@@ -0,0 +1,118 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// WithPersistedQueryGET makes the client send queries as automatic
+// persisted queries (APQ), as supported by Apollo Server and others, with
+// HTTP GET requests: only the SHA-256 hash of the query document is sent,
+// with the variables and operation name, as parameters in the URL:
+//
+//	/graphql?extensions={"persistedQuery":{"version":1,"sha256Hash":"…"}}&variables={…}
+//
+// As such requests have no body, CDNs and other HTTP caches in front of
+// the server can cache and serve the responses to hot queries. If the
+// server doesn't know the hash, the query is sent again with a POST
+// request, with its document and hash, which the server then registers.
+//
+// Mutations and subscriptions, operations sent by document ID, and
+// operations with file uploads are always sent with POST requests, as
+// are all operations if the client's codec isn't JSONCodec.
+func WithPersistedQueryGET() ClientOption {
+	return func(c *Client) {
+		c.persistedGET = true
+	}
+}
+
+// roundTripPersisted sends query request in to endpoint as a persisted
+// query with a GET request, and sends it with a POST request with its
+// document if the server doesn't know it.
+func (c *Client) roundTripPersisted(ctx context.Context, endpoint string, in Request, modify func(*http.Request), into interface{}) (Response, error) {
+	if c.configErr != nil {
+		return Response{}, c.configErr
+	}
+	if err := c.checkAllowed(in); err != nil {
+		return Response{}, err
+	}
+	persisted := map[string]interface{}{"version": 1, "sha256Hash": documentHash(in.Query)}
+	extensions := make(map[string]interface{}, len(in.Extensions)+1)
+	for k, v := range in.Extensions {
+		extensions[k] = v
+	}
+	extensions["persistedQuery"] = persisted
+	in.Extensions = extensions
+
+	get := in
+	get.Query = ""
+	out, err := c.exchange(ctx, endpoint, get, modify, into)
+	if err != nil || !persistedQueryMissed(out.Errors) {
+		return out, err
+	}
+	return c.exchange(ctx, endpoint, in, modify, into)
+}
+
+// usePersistedGET reports whether request in should be sent as
+// a persisted query with a GET request.
+func (c *Client) usePersistedGET(in Request) bool {
+	if !c.persistedGET || in.DocumentID != "" || c.customTransport != nil {
+		return false
+	}
+	if _, ok := c.codec.(JSONCodec); !ok {
+		return false
+	}
+	return len(findUploads(in.Variables)) == 0 && c.queriesOnly(in.Query)
+}
+
+// isPersistedGET reports whether request in is a persisted query to send
+// with a GET request: one with a persisted query hash, but no document.
+func isPersistedGET(in Request) bool {
+	_, ok := in.Extensions["persistedQuery"]
+	return ok && in.Query == "" && in.DocumentID == ""
+}
+
+// newPersistedGET returns a GET request sending request in to endpoint,
+// with its fields as parameters in the URL.
+func newPersistedGET(endpoint string, in Request) (*http.Request, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	params := u.Query()
+	extensions, err := json.Marshal(in.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("extensions", string(extensions))
+	if len(in.Variables) > 0 {
+		variables, err := json.Marshal(in.Variables)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("variables", string(variables))
+	}
+	if in.OperationName != "" {
+		params.Set("operationName", in.OperationName)
+	}
+	u.RawQuery = params.Encode()
+	return http.NewRequest(http.MethodGet, u.String(), nil)
+}
+
+// persistedQueryMissed reports whether errs report the server doesn't
+// know a persisted query, or doesn't support persisted queries.
+func persistedQueryMissed(errs ErrorList) bool {
+	for _, e := range errs {
+		switch e.Message {
+		case "PersistedQueryNotFound", "PersistedQueryNotSupported":
+			return true
+		}
+		switch e.Extensions["code"] {
+		case "PERSISTED_QUERY_NOT_FOUND", "PERSISTED_QUERY_NOT_SUPPORTED":
+			return true
+		}
+	}
+	return false
+}
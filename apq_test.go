@@ -0,0 +1,97 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithPersistedQueryGET(t *testing.T) {
+	type request struct {
+		Query      string
+		Variables  map[string]interface{}
+		Extensions struct {
+			PersistedQuery struct {
+				Version    int
+				Sha256Hash string
+			}
+		}
+	}
+	known := make(map[string]bool)
+	var got []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in request
+		if req.Method == http.MethodGet {
+			if req.Header.Get("Content-Type") != "" {
+				t.Errorf("GET request has Content-Type %q", req.Header.Get("Content-Type"))
+			}
+			params := req.URL.Query()
+			if err := json.Unmarshal([]byte(params.Get("extensions")), &in.Extensions); err != nil {
+				t.Error(err)
+			}
+			if v := params.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &in.Variables); err != nil {
+					t.Error(err)
+				}
+			}
+		} else if err := json.Unmarshal([]byte(mustRead(req.Body)), &in); err != nil {
+			t.Error(err)
+		}
+		hash := in.Extensions.PersistedQuery.Sha256Hash
+		short := hash
+		if len(short) > 8 {
+			short = short[:8]
+		}
+		got = append(got, req.Method+" "+short+" "+in.Query)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case in.Query != "" && hash != "":
+			if sum := sha256.Sum256([]byte(in.Query)); hex.EncodeToString(sum[:]) != hash {
+				t.Errorf("got hash %s for query %q", hash, in.Query)
+			}
+			known[hash] = true
+		case hash != "" && !known[hash]:
+			mustWrite(w, `{"errors": [{"message": "PersistedQueryNotFound", "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`)
+			return
+		}
+		if in.Variables["login"] != "gopher" {
+			t.Errorf("got variables %v", in.Variables)
+		}
+		mustWrite(w, `{"data": {"user": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithPersistedQueryGET())
+
+	var q struct {
+		User struct {
+			Login graphql.String
+		} `graphql:"user(login: $login)"`
+	}
+	vars := map[string]interface{}{"login": graphql.String("gopher")}
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, vars); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Mutate(context.Background(), &q, vars); err != nil {
+		t.Fatal(err)
+	}
+	query := `query($login:String!){user(login: $login){login}}`
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])[:8]
+	want := []string{
+		"GET " + hash + " ",
+		"POST " + hash + " " + query,
+		"GET " + hash + " ",
+		"POST  mutation($login:String!){user(login: $login){login}}",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got requests:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Transport sends GraphQL requests over a protocol other than GraphQL over
+// HTTP, such as a gRPC bridge carrying the JSON of requests and responses
+// in messages, for WithTransport.
+type Transport interface {
+	// RoundTrip sends request in to endpoint, with the headers set for
+	// it by the operation and the client, and returns the response.
+	// GraphQL errors are returned in out.Errors, not as err.
+	RoundTrip(ctx context.Context, endpoint string, in Request, header http.Header) (out Response, err error)
+}
+
+// WithTransport makes the client send requests with t rather than HTTP.
+// The headers operations set, such as with a RequestHandler, are given
+// to t, which may send them as the metadata of its protocol. Options
+// configuring HTTP, such as WithCodec, WithProxy and WithRequestTransform,
+// don't apply, and operations with uploads can't be sent.
+func WithTransport(t Transport) ClientOption {
+	return func(c *Client) {
+		c.customTransport = t
+	}
+}
+
+// roundTripCustom implements roundTrip with c.customTransport.
+func (c *Client) roundTripCustom(ctx context.Context, endpoint string, in Request, modify func(*http.Request), into interface{}) (out Response, err error) {
+	if len(findUploads(in.Variables)) > 0 {
+		return out, errUploadsTransport
+	}
+	// Find the headers set for the request, on one that isn't sent.
+	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return out, err
+	}
+	c.setDeadlineHeader(ctx, req)
+	if modify != nil {
+		modify(req)
+	}
+	if err := ctx.Err(); err != nil {
+		return out, err
+	}
+	out, err = c.customTransport.RoundTrip(ctx, endpoint, in, req.Header)
+	if err != nil {
+		return out, err
+	}
+	if string(out.Data) == "null" {
+		out.Data = nil
+	}
+	if into != nil && out.Data != nil {
+		err = c.decodeData(out.Data, into)
+		out.Data = nil
+	}
+	return out, err
+}
+
+var errUploadsTransport = errors.New("graphql: cannot send uploads with a Transport")
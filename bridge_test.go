@@ -0,0 +1,68 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// transportFunc is a graphql.Transport that calls itself.
+type transportFunc func(ctx context.Context, endpoint string, in graphql.Request, header http.Header) (graphql.Response, error)
+
+func (f transportFunc) RoundTrip(ctx context.Context, endpoint string, in graphql.Request, header http.Header) (graphql.Response, error) {
+	return f(ctx, endpoint, in, header)
+}
+
+func TestWithTransport(t *testing.T) {
+	var gotHeader http.Header
+	transport := transportFunc(func(ctx context.Context, endpoint string, in graphql.Request, header http.Header) (graphql.Response, error) {
+		gotHeader = header
+		return graphql.Response{Data: []byte(`{"report": "aGVsbG8=", "viewer": {"login": "gopher"}}`)}, nil
+	})
+	client := graphql.NewClient("/graphql", nil, graphql.WithTransport(transport))
+
+	var buf bytes.Buffer
+	var q struct {
+		Report graphql.Stream
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	q.Report.W, q.Report.Base64 = &buf, true
+	err := client.Run(context.Background(), &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+		req.Header.Set("X-Tenant", "a")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello" || q.Viewer.Login != "gopher" {
+		t.Errorf("got report %q and login %q, want hello and gopher", buf.String(), q.Viewer.Login)
+	}
+	if gotHeader.Get("X-Tenant") != "a" {
+		t.Errorf("got headers %v, want X-Tenant a", gotHeader)
+	}
+}
+
+func TestWithTransportUploads(t *testing.T) {
+	transport := transportFunc(func(context.Context, string, graphql.Request, http.Header) (graphql.Response, error) {
+		t.Error("upload sent")
+		return graphql.Response{}, nil
+	})
+	client := graphql.NewClient("/graphql", nil, graphql.WithTransport(transport))
+
+	var m struct {
+		UploadFile struct {
+			ID graphql.ID
+		} `graphql:"uploadFile(file: $file)"`
+	}
+	err := client.Mutate(context.Background(), &m, map[string]interface{}{
+		"file": graphql.Upload{Name: "a.txt", Body: strings.NewReader("a")},
+	})
+	if err == nil {
+		t.Fatal("got nil error, want one")
+	}
+}
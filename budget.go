@@ -0,0 +1,57 @@
+package graphql
+
+import "context"
+
+// DocumentSizeWarning is a warning about an operation whose query document,
+// constructed from its data structure, is larger than the budget set with
+// WithDocumentBudget.
+type DocumentSizeWarning struct {
+	Op     Operation
+	Size   int // Size of the document in bytes.
+	Budget int
+}
+
+// WithDocumentBudget sets a budget of budget bytes for the query documents
+// of *Query and *Mutation operations, constructed from their data
+// structures. It catches data structures that select far more than
+// intended, such as by embedding large structs, before servers reject
+// them for exceeding their complexity limits. Documents of other
+// operations, such as Static, are written by hand, and aren't checked.
+//
+// warn is called whenever an operation's document exceeds the budget.
+// If it returns an error, the operation fails with it, without being
+// sent. If warn is nil, a warning is logged with the client's logger
+// once per document, for up to DefaultDocumentCacheSize documents.
+func WithDocumentBudget(budget int, warn func(ctx context.Context, w DocumentSizeWarning) error) ClientOption {
+	return func(c *Client) {
+		c.docBudget = budget
+		c.docBudgetWarn = warn
+	}
+}
+
+// checkDocumentSize warns about query, the document of op, if op's
+// document is constructed and exceeds the budget of c.
+func (c *Client) checkDocumentSize(ctx context.Context, op Operation, query string) error {
+	if c.docBudget <= 0 || len(query) <= c.docBudget || !constructed(op) {
+		return nil
+	}
+	w := DocumentSizeWarning{Op: op, Size: len(query), Budget: c.docBudget}
+	if c.docBudgetWarn != nil {
+		return c.docBudgetWarn(ctx, w)
+	}
+	// Warnings are remembered by hash, so oversized documents aren't kept.
+	if _, stored := c.budgetWarned.loadOrStore(documentHash(query), true); stored {
+		c.logf("graphql: warning: query document of %d bytes exceeds budget of %d bytes", w.Size, w.Budget)
+	}
+	return nil
+}
+
+// constructed reports whether the query document of op is constructed
+// from a data structure.
+func constructed(op Operation) bool {
+	if _, ok := asOperation[*Query](op); ok {
+		return true
+	}
+	_, ok := asOperation[*Mutation](op)
+	return ok
+}
@@ -0,0 +1,95 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithDocumentBudget(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body, "bio") {
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher", "name": "Gopher", "bio": ""}}}`)
+			return
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	errTooLarge := errors.New("too large")
+	var warnings []graphql.DocumentSizeWarning
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithDocumentBudget(20, func(ctx context.Context, w graphql.DocumentSizeWarning) error {
+			warnings = append(warnings, w)
+			return errTooLarge
+		}),
+	)
+
+	var small struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.Query{Data: &small}); err != nil {
+		t.Fatal(err)
+	}
+	var large struct {
+		Viewer struct {
+			Login graphql.String
+			Name  graphql.String
+			Bio   graphql.String
+		}
+	}
+	op := &graphql.Query{Data: &large}
+	if err := client.Run(context.Background(), op); err != errTooLarge {
+		t.Fatalf("got error %v, want %v", err, errTooLarge)
+	}
+	// Hand-written documents aren't checked.
+	if err := client.Run(context.Background(), &graphql.Static{QueryStr: `{viewer{login,name,bio}}`, Into: &large}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+	want := []graphql.DocumentSizeWarning{{Op: op, Size: len(`{viewer{login,name,bio}}`), Budget: 20}}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %+v, want %+v", warnings, want)
+	}
+}
+
+func TestWithDocumentBudget_log(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "name": "Gopher"}}}`)
+	})
+	var logger logRecorder
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithLogger(&logger),
+		graphql.WithDocumentBudget(10, nil),
+	)
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+			Name  graphql.String
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Run(context.Background(), &graphql.Query{Data: &q}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"graphql: warning: query document of 20 bytes exceeds budget of 10 bytes"}
+	if !reflect.DeepEqual([]string(logger), want) {
+		t.Errorf("got log %q, want %q", logger, want)
+	}
+}
@@ -0,0 +1,262 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Cache stores the data of query responses, for WithCache.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the data stored for key, if it has an entry
+	// that hasn't expired.
+	Get(key string) (data []byte, ok bool)
+
+	// Set stores data for key, for ttl, or without expiry if ttl is zero.
+	Set(key string, data []byte, ttl time.Duration)
+
+	// Delete removes the entry for key, if any.
+	Delete(key string)
+}
+
+// WithCache makes the client cache the data of successful query responses
+// in cache for ttl, or until evicted by cache if ttl is zero. Queries
+// with the same document, operation name and variables, sent to the same
+// URL, are served from the cache while their entries haven't expired.
+//
+// Mutations, subscriptions, operations with uploads, and responses with
+// GraphQL errors or streamed fields aren't cached, except for errors of
+// operations decorated with WithNegativeCache.
+//
+// Cache entries are versioned by the client's schema, set with WithSchema,
+// and the layout of the data structures of operations, so entries written
+// before a deploy changed either, such as in a DiskCache, are discarded
+// rather than decoded.
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.cache, c.cacheTTL = cache, ttl
+	}
+}
+
+// CacheRequest is a request whose response may be cached, for the
+// functions that compute cache keys.
+type CacheRequest struct {
+	URL     string
+	Request Request
+	Header  http.Header // Headers set by the operation, such as with a RequestHandler.
+
+	// ClientDirectives are the directives stripped from the operation,
+	// set with WithClientDirectives.
+	ClientDirectives []ClientDirective
+}
+
+// CacheKeyFunc returns the cache key of the response to r, run with ctx,
+// or "" if the response isn't to be cached. Requests with the same key
+// are served the same cached response.
+type CacheKeyFunc func(ctx context.Context, r *CacheRequest) string
+
+// WithCacheKey sets the function that computes the cache keys of queries,
+// for clients created WithCache. It can include data that responses
+// depend on besides the request, such as the tenant or user whose
+// credentials the request is sent with, so multi-tenant services don't
+// serve cached data across tenants. The default is DefaultCacheKey.
+func WithCacheKey(key CacheKeyFunc) ClientOption {
+	return func(c *Client) {
+		c.cacheKeyFunc = key
+	}
+}
+
+// WithCacheVary makes the default cache key of queries depend on the
+// values of headers set by the operation, such as "Authorization" or
+// "X-Tenant-ID", besides the request, as for the Vary header of HTTP.
+func WithCacheVary(headers ...string) ClientOption {
+	return func(c *Client) {
+		for _, h := range headers {
+			c.cacheVary = append(c.cacheVary, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// DefaultCacheKey returns a hash of the URL, query, operation name,
+// variables and client directives of r.
+func DefaultCacheKey(ctx context.Context, r *CacheRequest) string {
+	return cacheHash(r, nil)
+}
+
+// cacheHash returns a hash of the URL, query, operation name, variables
+// and client directives of r, and the values of headers vary.
+func cacheHash(r *CacheRequest, vary []string) string {
+	var header map[string][]string
+	for _, h := range vary {
+		if v, ok := r.Header[h]; ok {
+			if header == nil {
+				header = make(map[string][]string, len(vary))
+			}
+			header[h] = v
+		}
+	}
+	b, err := json.Marshal(struct {
+		URL           string                 `json:"url"`
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		Header        map[string][]string    `json:"header,omitempty"`
+		Directives    []ClientDirective      `json:"clientDirectives,omitempty"`
+	}{r.URL, r.Request.Query, r.Request.OperationName, omitAbsent(r.Request.Variables), header, r.ClientDirectives})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheKey returns the key of the response to request in for op, sent to
+// endpoint, in c's cache, or "" if it isn't cached.
+func (c *Client) cacheKey(ctx context.Context, op Operation, endpoint string, in Request) string {
+	if c.cache == nil || !c.selectsQuery(in.Query, in.OperationName) || len(findUploads(in.Variables)) > 0 {
+		return ""
+	}
+	r := &CacheRequest{URL: endpoint, Request: in, ClientDirectives: c.clientDirectivesOf(op.Query(), op.Variables())}
+	vary := c.cacheVary
+	if c.versionHeader != "" {
+		// Responses depend on the API version operations select.
+		vary = append(vary[:len(vary):len(vary)], c.versionHeader)
+	}
+	if c.cacheKeyFunc != nil || len(vary) > 0 {
+		// Find the headers set by op, on a request that isn't sent.
+		req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+		if err != nil {
+			return ""
+		}
+		c.modifier(op)(req)
+		r.Header = req.Header
+	}
+	if c.cacheKeyFunc != nil {
+		return c.cacheKeyFunc(ctx, r)
+	}
+	return cacheHash(r, vary)
+}
+
+// cached returns the cached data of op for key, if any. If the response
+// for key is being prefetched, it waits for the prefetch, or for ctx to
+// be done.
+func (c *Client) cached(ctx context.Context, op Operation, key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	if data, ok := c.getEntry(op, key); ok {
+		c.usePrefetch(key)
+		return data, true
+	}
+	p := c.pendingPrefetch(key)
+	if p == nil {
+		return nil, false
+	}
+	select {
+	case <-p.done:
+		data, ok := c.getEntry(op, key)
+		if ok {
+			c.usePrefetch(key)
+		}
+		return data, ok
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// store caches the data of response out to op for key, if it's cacheable.
+func (c *Client) store(op Operation, key string, out Response) {
+	if len(out.Errors) > 0 {
+		c.storeNegative(op, key, out)
+		return
+	}
+	if key == "" || out.Data == nil {
+		return
+	}
+	ttl, ok := c.entryTTL(op, out)
+	if !ok {
+		return
+	}
+	entry := c.wrapEntry(op, out.Data)
+	c.cache.Set(key, entry, ttl)
+	c.storeStale(key, entry, ttl)
+	c.indexEntities(key, out.Data, ttl)
+}
+
+// MemoryCache is a Cache that holds entries in memory. Expired entries
+// are removed when they're read, or when entries are added.
+type MemoryCache struct {
+	clock   Clock
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	sets    int
+}
+
+type memoryEntry struct {
+	data    []byte
+	expires time.Time // Or zero if the entry doesn't expire.
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{clock: systemClock{}, entries: make(map[string]memoryEntry)}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expires.IsZero() && !m.now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.data, true
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, data []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	// Sweep expired entries once in a while, so they don't accumulate.
+	if m.sets++; m.sets >= len(m.entries) {
+		m.sets = 0
+		for k, e := range m.entries {
+			if !e.expires.IsZero() && !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	e := memoryEntry{data: data}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	m.entries[key] = e
+}
+
+// Delete implements Cache.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+}
+
+func (m *MemoryCache) setClock(clock Clock) {
+	m.mu.Lock()
+	m.clock = clock
+	m.mu.Unlock()
+}
+
+// now returns the time of m's clock. m.mu must be held.
+func (m *MemoryCache) now() time.Time {
+	return m.clock.Now()
+}
@@ -0,0 +1,200 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithCache(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch body {
+		case `{"query":"query($id:ID!){user(id: $id){login}}","variables":{"id":"1"}}` + "\n":
+			mustWrite(w, `{"data": {"user": {"login": "gopher"}}}`)
+		case `{"query":"query($id:ID!){user(id: $id){login}}","variables":{"id":"2"}}` + "\n":
+			mustWrite(w, `{"data": {"user": null}, "errors": [{"message": "not found"}]}`)
+		case `{"query":"mutation{logout}"}` + "\n":
+			mustWrite(w, `{"data": {"logout": true}}`)
+		default:
+			t.Errorf("unexpected request body: %s", body)
+		}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+
+	var q struct {
+		User *struct {
+			Login graphql.String
+		} `graphql:"user(id: $id)"`
+	}
+	for i := 0; i < 3; i++ {
+		q.User = nil
+		if err := client.Query(context.Background(), &q, map[string]interface{}{"id": graphql.ID("1")}); err != nil {
+			t.Fatal(err)
+		}
+		if q.User == nil || q.User.Login != "gopher" {
+			t.Fatalf("got user: %+v, want gopher", q.User)
+		}
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(1); got != want {
+		t.Errorf("got %d requests for a cached query, want %d", got, want)
+	}
+
+	// Responses with errors aren't cached.
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, map[string]interface{}{"id": graphql.ID("2")}); err == nil {
+			t.Fatal("got no error, want not found")
+		}
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(3); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+
+	// Mutations aren't cached.
+	var m struct {
+		Logout graphql.Boolean
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Mutate(context.Background(), &m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(5); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
+
+func TestWithCache_operationName(t *testing.T) {
+	for _, tc := range []struct {
+		query, name  string
+		wantRequests int32
+	}{
+		{"query Q{viewer{login}} mutation M{logout}", "Q", 1},
+		{"query Q{viewer{login}} mutation M{logout}", "M", 2},
+		{"mutation M{logout} query Q{viewer{login}}", "Q", 1},
+		{"mutation M{logout} query Q{viewer{login}}", "M", 2},
+		{"# Viewer.\n{viewer{login}}", "", 1},
+		{"query Q{viewer{login}} query R{viewer{login}}", "", 2},
+	} {
+		var requests int32
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			mustRead(req.Body)
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}, "logout": true}}`)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+			graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+			graphql.WithOperationNameInBody(),
+		)
+		for i := 0; i < 2; i++ {
+			var data struct {
+				Viewer struct {
+					Login graphql.String
+				}
+				Logout graphql.Boolean
+			}
+			if err := client.Run(context.Background(), &graphql.Static{QueryStr: tc.query, Into: &data, Name: tc.name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := atomic.LoadInt32(&requests); got != tc.wantRequests {
+			t.Errorf("%q, operation %q: got %d requests, want %d", tc.query, tc.name, got, tc.wantRequests)
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := graphql.NewMemoryCache()
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if data, ok := c.Get("a"); !ok || string(data) != "1" {
+		t.Errorf("got a: %q, %v, want 1, true", data, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("got expired entry b")
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("got deleted entry a")
+	}
+}
+
+func TestWithCacheVary(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "`+req.Header.Get("X-Tenant-ID")+`"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithCacheVary("x-tenant-id"),
+	)
+
+	for _, tenant := range []string{"a", "b", "a", "b"} {
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		op := &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) { req.Header.Set("X-Tenant-ID", tenant) }}
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := q.Viewer.Login, graphql.String(tenant); got != want {
+			t.Errorf("got login: %v, want: %v", got, want)
+		}
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(2); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
+
+type tenantKey struct{}
+
+func TestWithCacheKey(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithCacheKey(func(ctx context.Context, r *graphql.CacheRequest) string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			if tenant == "" {
+				return "" // Not cached.
+			}
+			return tenant + ":" + graphql.DefaultCacheKey(ctx, r)
+		}),
+	)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for _, tenant := range []string{"a", "b", "a", "", ""} {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		if err := client.Query(ctx, &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(4); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheHint is a hint from a server of how long a response may be cached,
+// given by its Cache-Control header, or by the cacheControl extension of
+// Apollo Server.
+type CacheHint struct {
+	MaxAge  time.Duration // How long the response may be cached.
+	Private bool          // Whether the response may only be cached for its user.
+	NoStore bool          // Whether the response mustn't be cached.
+}
+
+// CacheHint returns the cache hint of r, combining the directives of its
+// Cache-Control header and the hints of its cacheControl extension into
+// the most restrictive: the least max age, private if any is, and no-store
+// if any is. It reports false if r has neither a max age nor no-store.
+func (r Response) CacheHint() (hint CacheHint, ok bool) {
+	var hasMaxAge bool
+	maxAge := func(age time.Duration) {
+		if !hasMaxAge || age < hint.MaxAge {
+			hint.MaxAge = age
+		}
+		hasMaxAge = true
+	}
+	for _, v := range r.HTTP.Header.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache":
+				hint.NoStore = true
+			case "private":
+				hint.Private = true
+			case "max-age":
+				if secs, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil && secs >= 0 {
+					maxAge(time.Duration(secs) * time.Second)
+				}
+			}
+		}
+	}
+	ext, _ := r.Extensions["cacheControl"].(map[string]interface{})
+	hints, _ := ext["hints"].([]interface{})
+	for _, h := range hints {
+		h, _ := h.(map[string]interface{})
+		if secs, ok := h["maxAge"].(float64); ok && secs >= 0 {
+			maxAge(time.Duration(secs * float64(time.Second)))
+		}
+		if h["scope"] == "PRIVATE" {
+			hint.Private = true
+		}
+	}
+	return hint, hasMaxAge || hint.NoStore
+}
+
+// CacheHintPolicy is how a client created WithCache uses the cache hints
+// of responses to set the TTLs of their cache entries.
+type CacheHintPolicy int
+
+const (
+	// IgnoreCacheHints caches responses for the TTL given to WithCache or
+	// WithCacheTTL, whatever their hints. It's the default.
+	IgnoreCacheHints CacheHintPolicy = iota
+
+	// CacheHintsAsDefault caches responses for the max age of their hints,
+	// instead of the TTL given to WithCache, unless operations are
+	// decorated with WithCacheTTL. Responses without hints are cached for
+	// the TTL given to WithCache.
+	CacheHintsAsDefault
+
+	// CacheHintsAsLimit caches responses for the TTL given to WithCache or
+	// WithCacheTTL, but no longer than the max age of their hints.
+	CacheHintsAsLimit
+)
+
+// WithCacheHints sets how the client uses the cache hints of responses,
+// as given by CacheHint, to set the TTLs of their cache entries, for
+// clients created WithCache. Whatever the policy, responses whose hints
+// are no-store, or have a max age of zero, aren't cached. Private
+// responses are cached, as the client's cache is its own, but clients
+// sending requests for several users should use WithCacheVary or
+// WithCacheKey so responses aren't served to other users.
+func WithCacheHints(policy CacheHintPolicy) ClientOption {
+	return func(c *Client) {
+		c.cacheHints = policy
+	}
+}
+
+// entryTTL returns the TTL of the cache entry of response out to op, and
+// whether it should be cached at all.
+func (c *Client) entryTTL(op Operation, out Response) (ttl time.Duration, ok bool) {
+	ttl = c.ttlOf(op)
+	if c.cacheHints == IgnoreCacheHints {
+		return ttl, true
+	}
+	hint, hinted := out.CacheHint()
+	if !hinted {
+		return ttl, true
+	}
+	if hint.NoStore || hint.MaxAge <= 0 {
+		return 0, false
+	}
+	switch c.cacheHints {
+	case CacheHintsAsDefault:
+		if _, ok := asOperation[*cachedFor](op); !ok {
+			ttl = hint.MaxAge
+		}
+	case CacheHintsAsLimit:
+		if ttl == 0 || hint.MaxAge < ttl {
+			ttl = hint.MaxAge
+		}
+	}
+	return ttl, true
+}
@@ -0,0 +1,133 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestResponse_CacheHint(t *testing.T) {
+	tests := []struct {
+		header     string
+		extensions map[string]interface{}
+		want       graphql.CacheHint
+		wantOK     bool
+	}{
+		{"", nil, graphql.CacheHint{}, false},
+		{"private", nil, graphql.CacheHint{Private: true}, false},
+		{"max-age=60, public", nil, graphql.CacheHint{MaxAge: time.Minute}, true},
+		{"private, max-age=60", nil, graphql.CacheHint{MaxAge: time.Minute, Private: true}, true},
+		{"no-store", nil, graphql.CacheHint{NoStore: true}, true},
+		{"max-age=invalid", nil, graphql.CacheHint{}, false},
+		{"max-age=60", map[string]interface{}{"cacheControl": map[string]interface{}{
+			"version": 1.0,
+			"hints": []interface{}{
+				map[string]interface{}{"path": []interface{}{"user"}, "maxAge": 30.0},
+				map[string]interface{}{"path": []interface{}{"user", "email"}, "maxAge": 10.0, "scope": "PRIVATE"},
+			},
+		}}, graphql.CacheHint{MaxAge: 10 * time.Second, Private: true}, true},
+		{"", map[string]interface{}{"cacheControl": map[string]interface{}{
+			"hints": []interface{}{map[string]interface{}{"path": []interface{}{"user"}, "maxAge": 0.0}},
+		}}, graphql.CacheHint{}, true},
+	}
+	for _, tt := range tests {
+		r := graphql.Response{Extensions: tt.extensions, HTTP: graphql.ResponseMeta{Header: http.Header{}}}
+		if tt.header != "" {
+			r.HTTP.Header.Set("Cache-Control", tt.header)
+		}
+		got, ok := r.CacheHint()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%q, %v: got %+v, %v, want %+v, %v", tt.header, tt.extensions, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// ttlCache is a Cache recording the TTLs of the entries set, by the data
+// they hold, following their version line.
+type ttlCache struct {
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+func (c *ttlCache) Get(key string) ([]byte, bool) { return nil, false }
+
+func (c *ttlCache) Set(key string, entry []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, data, _ := strings.Cut(string(entry), "\n")
+	c.ttls[data] = ttl
+}
+
+func (c *ttlCache) Delete(key string) {}
+
+func TestWithCacheHints(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(body, `"id":"hinted"`):
+			w.Header().Set("Cache-Control", "max-age=30")
+		case strings.Contains(body, `"id":"long"`):
+			w.Header().Set("Cache-Control", "max-age=3600")
+		case strings.Contains(body, `"id":"nostore"`):
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		mustWrite(w, `{"data": {"user": {"login": "`+strings.Split(strings.Split(body, `"id":"`)[1], `"`)[0]+`"}}}`)
+	})
+	var q struct {
+		User struct {
+			Login graphql.String
+		} `graphql:"user(id: $id)"`
+	}
+	run := func(policy graphql.CacheHintPolicy) map[string]time.Duration {
+		cache := &ttlCache{ttls: make(map[string]time.Duration)}
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+			graphql.WithCache(cache, time.Minute),
+			graphql.WithCacheHints(policy),
+		)
+		for _, id := range []string{"plain", "hinted", "long", "nostore"} {
+			if err := client.Query(context.Background(), &q, map[string]interface{}{"id": graphql.ID(id)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		op := graphql.WithCacheTTL(graphql.NewQuery(&q, map[string]interface{}{"id": graphql.ID("hinted"), "v": graphql.Int(1)}), 2*time.Hour)
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+		return cache.ttls
+	}
+	key := func(id string) string { return `{"user": {"login": "` + id + `"}}` }
+
+	tests := []struct {
+		policy graphql.CacheHintPolicy
+		want   map[string]time.Duration
+	}{
+		{graphql.IgnoreCacheHints, map[string]time.Duration{
+			key("plain"): time.Minute, key("hinted"): 2 * time.Hour, key("long"): time.Minute, key("nostore"): time.Minute,
+		}},
+		{graphql.CacheHintsAsDefault, map[string]time.Duration{
+			key("plain"): time.Minute, key("hinted"): 2 * time.Hour, key("long"): time.Hour,
+		}},
+		{graphql.CacheHintsAsLimit, map[string]time.Duration{
+			key("plain"): time.Minute, key("hinted"): 30 * time.Second, key("long"): time.Minute,
+		}},
+	}
+	for _, tt := range tests {
+		got := run(tt.policy)
+		if len(got) != len(tt.want) {
+			t.Errorf("policy %d: got TTLs %v, want %v", tt.policy, got, tt.want)
+			continue
+		}
+		for k, ttl := range tt.want {
+			if got[k] != ttl {
+				t.Errorf("policy %d: got TTL %v for %s, want %v", tt.policy, got[k], k, ttl)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// cacheFormat is the format of cache entries, which begin with a line of
+// it and of the version of the data they hold. It's changed whenever the
+// format changes incompatibly, so entries in the previous format, such as
+// in a DiskCache written before a deploy, are discarded.
+const cacheFormat = "graphql-cache/1"
+
+// entryVersion returns the version of the data of the cache entries of
+// op: a hash of the schema of c, if any, and of the layout of the data
+// structure of op. Entries of other versions, such as written before a
+// deploy changed either, are discarded when they're read, rather than
+// failing to be decoded, or being decoded wrongly.
+func (c *Client) entryVersion(op Operation) string {
+	var schema string
+	if c.schema != nil {
+		schema = c.schema.hash()
+	}
+	return cacheFormat + " " + schema + " " + layoutHash(reflect.TypeOf(op.ResponsePtr()))
+}
+
+// wrapEntry returns data as a cache entry of op.
+func (c *Client) wrapEntry(op Operation, data []byte) []byte {
+	version := c.entryVersion(op)
+	entry := make([]byte, 0, len(version)+1+len(data))
+	entry = append(entry, version...)
+	entry = append(entry, '\n')
+	return append(entry, data...)
+}
+
+// getEntry returns the data of the cache entry of op for key, if it has
+// one of the version of op. Entries of other versions are deleted.
+func (c *Client) getEntry(op Operation, key string) ([]byte, bool) {
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	version := c.entryVersion(op)
+	if len(entry) <= len(version) || entry[len(version)] != '\n' || string(entry[:len(version)]) != version {
+		c.evict(key)
+		return nil, false
+	}
+	return entry[len(version)+1:], true
+}
+
+// hash returns a hash of the SDL of s, computed once.
+func (s *Schema) hash() string {
+	s.hashOnce.Do(func() {
+		sum := sha256.Sum256([]byte(s.SDL()))
+		s.sdlHash = hex.EncodeToString(sum[:8])
+	})
+	return s.sdlHash
+}
+
+// layouts caches the layout hashes of types, by reflect.Type.
+var layouts sync.Map
+
+// layoutHash returns a hash of the layout of type t: its kind, and those
+// of its elements, and the names, tags and types of its fields, or ""
+// if t is nil.
+func layoutHash(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if h, ok := layouts.Load(t); ok {
+		return h.(string)
+	}
+	var b bytes.Buffer
+	writeLayout(&b, t, make(map[reflect.Type]int))
+	sum := sha256.Sum256(b.Bytes())
+	h := hex.EncodeToString(sum[:8])
+	layouts.Store(t, h)
+	return h
+}
+
+// writeLayout writes a description of the layout of t to b. seen numbers
+// the named types being described, so recursive types are described once.
+func writeLayout(b *bytes.Buffer, t reflect.Type, seen map[reflect.Type]int) {
+	if t.Name() != "" {
+		if n, ok := seen[t]; ok {
+			fmt.Fprintf(b, "#%d", n)
+			return
+		}
+		seen[t] = len(seen)
+		b.WriteString(t.PkgPath() + "." + t.Name() + "=")
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		b.WriteString("*")
+		writeLayout(b, t.Elem(), seen)
+	case reflect.Slice:
+		b.WriteString("[]")
+		writeLayout(b, t.Elem(), seen)
+	case reflect.Array:
+		fmt.Fprintf(b, "[%d]", t.Len())
+		writeLayout(b, t.Elem(), seen)
+	case reflect.Map:
+		b.WriteString("map[")
+		writeLayout(b, t.Key(), seen)
+		b.WriteString("]")
+		writeLayout(b, t.Elem(), seen)
+	case reflect.Struct:
+		b.WriteString("struct{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if i > 0 {
+				b.WriteString(";")
+			}
+			fmt.Fprintf(b, "%s %q ", f.Name, strings.TrimSpace(string(f.Tag)))
+			writeLayout(b, f.Type, seen)
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString(t.Kind().String())
+	}
+}
@@ -0,0 +1,78 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestCacheVersioning(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	cache := graphql.NewMemoryCache()
+	key := graphql.WithCacheKey(func(ctx context.Context, r *graphql.CacheRequest) string { return "viewer" })
+	newClient := func(opts ...graphql.ClientOption) *graphql.Client {
+		opts = append([]graphql.ClientOption{graphql.WithCache(cache, time.Minute), key}, opts...)
+		return graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, opts...)
+	}
+	schema, err := graphql.ParseSchema([]byte(`type Query { viewer: User! } type User { login: String! }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v1 struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var v2 struct {
+		Viewer struct {
+			Login *graphql.String
+		}
+	}
+	run := func(client *graphql.Client, into interface{}) {
+		t.Helper()
+		if err := client.Run(context.Background(), &graphql.Static{QueryStr: `{viewer{login}}`, Into: into}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An entry from before the format was versioned is discarded.
+	cache.Set("viewer", []byte(`{"viewer": {"login": "stale"}}`), 0)
+	run(newClient(), &v1)
+	if v1.Viewer.Login != "gopher" {
+		t.Errorf("got login %q, want gopher", v1.Viewer.Login)
+	}
+
+	tests := []struct {
+		name   string
+		client *graphql.Client
+		into   interface{}
+		want   int32 // Requests after the run.
+	}{
+		{"same layout", newClient(), &v1, 1},
+		{"changed layout", newClient(), &v2, 2},
+		{"changed layout cached", newClient(), &v2, 2},
+		{"schema", newClient(graphql.WithSchema(schema)), &v2, 3},
+		{"schema cached", newClient(graphql.WithSchema(schema)), &v2, 3},
+	}
+	for _, tt := range tests {
+		run(tt.client, tt.into)
+		if got := atomic.LoadInt32(&requests); got != tt.want {
+			t.Errorf("%s: got %d requests, want %d", tt.name, got, tt.want)
+		}
+	}
+	if v2.Viewer.Login == nil || *v2.Viewer.Login != "gopher" {
+		t.Errorf("got login %v, want gopher", v2.Viewer.Login)
+	}
+}
@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Route is a routing rule of WithRoutes, sending the operations it
+// matches to another endpoint than the client's GraphQL server URL,
+// such as a new backend individual operations are migrated to.
+//
+// A route matches operations by name, by the hash of their query
+// document, or by both if both are set.
+type Route struct {
+	Name string `json:"name,omitempty"` // Operation name matched, if not empty.
+
+	// Hash is the hex-encoded SHA-256 hash of the query document matched,
+	// as sent to the server, if not empty.
+	Hash string `json:"hash,omitempty"`
+
+	URL string `json:"url"` // Endpoint URL, absolute or relative to the client's URL.
+
+	// Rate is the fraction of matched operations routed, from 0 to 1,
+	// for canary releases, or 1 if zero.
+	Rate float64 `json:"rate,omitempty"`
+}
+
+// ParseRoutes parses routing rules in JSON, an array of routes:
+//
+//	[{"name": "Viewer", "url": "https://next.example.com/graphql", "rate": 0.1}]
+func ParseRoutes(data []byte) ([]Route, error) {
+	var routes []Route
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("invalid routes: %v", err)
+	}
+	return routes, nil
+}
+
+// WithRoutes sends operations matched by routes to their endpoints, so
+// individual operations can be moved to another backend without changes
+// where they're run. The first route matching an operation applies.
+// Operations wrapped in *Routed are sent to its URL instead.
+func WithRoutes(routes ...Route) ClientOption {
+	return func(c *Client) {
+		for _, r := range routes {
+			if err := r.validate(); err != nil && c.configErr == nil {
+				c.configErr = err
+			}
+		}
+		c.routes = append(c.routes, routes...)
+	}
+}
+
+func (r Route) validate() error {
+	switch {
+	case r.Name == "" && r.Hash == "":
+		return errors.New("graphql: route matches no operations; want name or hash")
+	case r.URL == "":
+		return fmt.Errorf("graphql: route of %s has no URL", r.match())
+	case r.Rate < 0 || r.Rate > 1:
+		return fmt.Errorf("graphql: route of %s has rate %v; want 0 to 1", r.match(), r.Rate)
+	}
+	return nil
+}
+
+func (r Route) match() string {
+	if r.Name != "" {
+		return fmt.Sprintf("operation %q", r.Name)
+	}
+	return fmt.Sprintf("document %s", r.Hash)
+}
+
+// route returns the URL of the first route of c matching op, whose
+// query document as sent is query, or "" if none does.
+func (c *Client) route(op Operation, query string) string {
+	var name, hash string
+	if named, ok := asOperation[NamedOperation](op); ok {
+		name = named.OperationName()
+	}
+	for _, r := range c.routes {
+		if r.Name != "" && r.Name != name {
+			continue
+		}
+		if r.Hash != "" {
+			if hash == "" {
+				hash = documentHash(query)
+			}
+			if r.Hash != hash {
+				continue
+			}
+		}
+		if r.Rate > 0 && r.Rate < 1 && rand.Float64() >= r.Rate {
+			continue
+		}
+		return r.URL
+	}
+	return ""
+}
+
+// documentHash returns the hex-encoded SHA-256 hash of query document doc.
+func documentHash(doc string) string {
+	sum := sha256.Sum256([]byte(doc))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithRoutes(t *testing.T) {
+	var urls []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		urls = append(urls, req.URL.String())
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	const doc = "{viewer{id}}"
+	sum := sha256.Sum256([]byte(doc))
+	routes, err := graphql.ParseRoutes([]byte(`[
+		{"name": "Viewer", "url": "/next/graphql"},
+		{"hash": "` + hex.EncodeToString(sum[:]) + `", "url": "https://example.com/other"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := graphql.NewClient("https://example.com/graphql", &http.Client{Transport: localRoundTripper{handler: handler}}, graphql.WithRoutes(routes...))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for _, op := range []graphql.Operation{
+		&graphql.Query{Data: &q, Name: "Viewer"},
+		&graphql.Query{Data: &q, Name: "Other"},
+		&graphql.Static{QueryStr: doc, Into: &q},
+		&graphql.Routed{Operation: &graphql.Query{Data: &q, Name: "Viewer"}, URL: "/explicit"},
+	} {
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"https://example.com/next/graphql",
+		"https://example.com/graphql",
+		"https://example.com/other",
+		"https://example.com/explicit",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("got URLs: %q, want: %q", urls, want)
+	}
+}
+
+func TestWithRoutes_invalid(t *testing.T) {
+	for _, r := range []graphql.Route{
+		{URL: "/next"},
+		{Name: "Viewer"},
+		{Name: "Viewer", URL: "/next", Rate: 1.5},
+	} {
+		client := graphql.NewClient("/graphql", nil, graphql.WithRoutes(r))
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(context.Background(), &q, nil); err == nil {
+			t.Errorf("route %+v: got nil error", r)
+		}
+	}
+	if _, err := graphql.ParseRoutes([]byte(`{}`)); err == nil {
+		t.Error("got nil error parsing invalid routes")
+	}
+}
@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// ClientDirective is a client-only directive in an operation, such as
+// @connection(key: "feed"), which is stripped from the document sent to
+// the server. Client directives are passed to the functions that compute
+// cache keys, in CacheRequest, and included in the default cache key.
+type ClientDirective struct {
+	Name      string                 // Name of the directive, without "@".
+	Field     string                 // Path of the field the directive is on, such as "viewer.feed", if any.
+	Arguments map[string]interface{} // Argument values, with variables replaced by their values.
+}
+
+// WithClientDirectives makes the client strip the directives with names,
+// such as "connection", from operations before sending them, so they can
+// be used in graphql struct tags to configure the client, rather than the
+// server:
+//
+//	Feed struct { ... } `graphql:"feed(first: 10) @connection(key: \"home\")"`
+//
+// The stripped directives are available to the cache in CacheRequest.
+func WithClientDirectives(names ...string) ClientOption {
+	return func(c *Client) {
+		if c.clientDirectives == nil {
+			c.clientDirectives = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.clientDirectives[strings.TrimPrefix(name, "@")] = true
+		}
+	}
+}
+
+// strippedQuery is a query without its client directives.
+type strippedQuery struct {
+	query      string
+	directives []*clientDirective
+}
+
+// clientDirective is a client directive in a query, with arguments that
+// may refer to variables.
+type clientDirective struct {
+	name      string
+	field     string
+	arguments []*parser.Argument
+}
+
+// stripClientDirectives returns query without the client directives of c,
+// and the directives. Results are cached per query, for up to
+// DefaultDocumentCacheSize queries. Queries that can't be parsed are
+// left as is.
+func (c *Client) stripClientDirectives(query string) strippedQuery {
+	if len(c.clientDirectives) == 0 {
+		return strippedQuery{query: query}
+	}
+	if sq, ok := c.stripped.load(query); ok {
+		return sq
+	}
+	sq, _ := c.stripped.loadOrStore(query, c.stripQuery(query))
+	return sq
+}
+
+func (c *Client) stripQuery(query string) strippedQuery {
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return strippedQuery{query: query}
+	}
+	s := &stripper{names: c.clientDirectives}
+	for _, op := range doc.Operations {
+		s.directives(op.Directives, "")
+		for _, d := range op.VarDefs {
+			s.directives(d.Directives, "")
+		}
+		s.selectionSet(op.SelectionSet, "")
+	}
+	for _, f := range doc.Fragments {
+		s.directives(f.Directives, "")
+		s.selectionSet(f.SelectionSet, "")
+	}
+	if len(s.found) == 0 {
+		return strippedQuery{query: query}
+	}
+	sort.Slice(s.ranges, func(i, j int) bool { return s.ranges[i][0] < s.ranges[j][0] })
+	var b strings.Builder
+	last := 0
+	for _, r := range s.ranges {
+		b.WriteString(query[last:r[0]])
+		last = r[1]
+	}
+	b.WriteString(query[last:])
+	return strippedQuery{query: b.String(), directives: s.found}
+}
+
+// stripper finds the client directives of a document.
+type stripper struct {
+	names  map[string]bool
+	found  []*clientDirective
+	ranges [][2]int // Byte offsets of the directives found.
+}
+
+func (s *stripper) selectionSet(sels []parser.Selection, path string) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *parser.Field:
+			p := sel.ResponseKey()
+			if path != "" {
+				p = path + "." + p
+			}
+			s.directives(sel.Directives, p)
+			s.selectionSet(sel.SelectionSet, p)
+		case *parser.InlineFragment:
+			s.directives(sel.Directives, path)
+			s.selectionSet(sel.SelectionSet, path)
+		case *parser.FragmentSpread:
+			s.directives(sel.Directives, path)
+		}
+	}
+}
+
+func (s *stripper) directives(ds []*parser.Directive, path string) {
+	for _, d := range ds {
+		if !s.names[d.Name] {
+			continue
+		}
+		s.found = append(s.found, &clientDirective{name: d.Name, field: path, arguments: d.Arguments})
+		s.ranges = append(s.ranges, [2]int{d.Pos.Offset, d.End})
+	}
+}
+
+// clientDirectivesOf returns the client directives of query, with the
+// values of variables vars.
+func (c *Client) clientDirectivesOf(query string, vars map[string]interface{}) []ClientDirective {
+	stripped := c.stripClientDirectives(query)
+	if len(stripped.directives) == 0 {
+		return nil
+	}
+	ds := make([]ClientDirective, len(stripped.directives))
+	for i, d := range stripped.directives {
+		ds[i] = ClientDirective{Name: d.name, Field: d.field}
+		if len(d.arguments) > 0 {
+			ds[i].Arguments = make(map[string]interface{}, len(d.arguments))
+			for _, a := range d.arguments {
+				ds[i].Arguments[a.Name] = literalValue(a.Value, vars)
+			}
+		}
+	}
+	return ds
+}
+
+// literalValue returns the value of input literal v, with the values
+// of variables vars.
+func literalValue(v *parser.Value, vars map[string]interface{}) interface{} {
+	switch v.Kind {
+	case parser.VariableValue:
+		return vars[v.Raw]
+	case parser.NullValue:
+		return nil
+	case parser.ListValue:
+		l := make([]interface{}, len(v.List))
+		for i, e := range v.List {
+			l[i] = literalValue(e, vars)
+		}
+		return l
+	case parser.ObjectValue:
+		m := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			m[f.Name] = literalValue(f.Value, vars)
+		}
+		return m
+	case parser.BooleanValue:
+		return v.Raw == "true"
+	case parser.IntValue, parser.FloatValue:
+		return json.Number(v.Raw)
+	default: // String and enum values.
+		return v.Raw
+	}
+}
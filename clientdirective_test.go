@@ -0,0 +1,83 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+type homeFeedQuery struct {
+	Feed struct {
+		TotalCount graphql.Int
+	} `graphql:"feed(first: $first) @include(if: true) @connection(key: \"home\", filter: [$kind, \"all\"])"`
+}
+
+type popularFeedQuery struct {
+	Feed struct {
+		TotalCount graphql.Int
+	} `graphql:"feed(first: $first) @include(if: true) @connection(key: \"popular\")"`
+}
+
+func TestWithClientDirectives(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in struct{ Query string }
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			t.Error(err)
+		}
+		queries = append(queries, in.Query)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"feed": {"totalCount": 3}}}`)
+	})
+	var directives [][]graphql.ClientDirective
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithClientDirectives("connection"),
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithCacheKey(func(ctx context.Context, r *graphql.CacheRequest) string {
+			directives = append(directives, r.ClientDirectives)
+			return graphql.DefaultCacheKey(ctx, r)
+		}))
+
+	vars := map[string]interface{}{"first": graphql.Int(10), "kind": graphql.String("news")}
+	for i := 0; i < 2; i++ {
+		var home homeFeedQuery
+		if err := client.Query(context.Background(), &home, vars); err != nil {
+			t.Fatal(err)
+		}
+		if home.Feed.TotalCount != 3 {
+			t.Errorf("got total count %v, want 3", home.Feed.TotalCount)
+		}
+	}
+	// Documents that differ only in client directives are cached separately.
+	var popular popularFeedQuery
+	if err := client.Query(context.Background(), &popular, vars); err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueries := []string{
+		"query($first:Int!$kind:String!){feed(first: $first) @include(if: true) {totalCount}}",
+		"query($first:Int!$kind:String!){feed(first: $first) @include(if: true) {totalCount}}",
+	}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Errorf("got queries:\n%q\nwant:\n%q", queries, wantQueries)
+	}
+	home := []graphql.ClientDirective{{
+		Name:      "connection",
+		Field:     "feed",
+		Arguments: map[string]interface{}{"key": "home", "filter": []interface{}{graphql.String("news"), "all"}},
+	}}
+	wantDirectives := [][]graphql.ClientDirective{home, home, {{
+		Name:      "connection",
+		Field:     "feed",
+		Arguments: map[string]interface{}{"key": "popular"},
+	}}}
+	if !reflect.DeepEqual(directives, wantDirectives) {
+		t.Errorf("got directives %+v, want %+v", directives, wantDirectives)
+	}
+}
@@ -0,0 +1,43 @@
+package graphql
+
+import "time"
+
+// Clock is the source of time of the time-dependent behavior of clients,
+// such as the expiry of cached responses and the batching window of
+// Loaders. It's replaced with WithClock, so that behavior can be tested
+// deterministically without sleeping, such as with graphqltest.Clock.
+type Clock interface {
+	Now() time.Time
+
+	// AfterFunc calls f in its own goroutine once d has elapsed,
+	// unless the returned timer is stopped first.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer started by a Clock.
+type Timer interface {
+	// Stop prevents the timer from firing. It reports whether it did,
+	// or false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+// WithClock sets the clock of the client, and of its cache, if it's
+// a MemoryCache, DiskCache, or EncryptedCache of one. The default is
+// the system clock.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// systemClock is the Clock of the system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// clocked is implemented by caches whose clock is set by clients.
+type clocked interface {
+	setClock(Clock)
+}
@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"reflect"
+)
+
+// Clone returns a copy of op with a deep copy of its variables and
+// a new zero value of the same type as its Data, so the copy can be run
+// concurrently with op.
+func (op *Query) Clone() *Query {
+	c := *op
+	c.Data = newData(op.Data)
+	c.Vars = copyVariables(op.Vars)
+	return &c
+}
+
+// Clone returns a copy of op with a deep copy of its variables and
+// a new zero value of the same type as its Data, so the copy can be run
+// concurrently with op.
+func (op *Mutation) Clone() *Mutation {
+	c := *op
+	c.Data = newData(op.Data)
+	c.Vars = copyVariables(op.Vars)
+	return &c
+}
+
+// Clone returns a copy of op with a deep copy of its variables and
+// a new zero value of the same type as its Into, so the copy can be run
+// concurrently with op.
+func (op *Static) Clone() *Static {
+	c := *op
+	c.Into = newData(op.Into)
+	c.Vars = copyVariables(op.Vars)
+	return &c
+}
+
+// newData returns a pointer to a new zero value of the type data points to.
+// If data isn't a non-nil pointer, it's returned as is.
+func newData(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return data
+	}
+	return reflect.New(v.Type().Elem()).Interface()
+}
+
+// copyVariables returns a deep copy of variables, so that later changes
+// made by the caller to variables or values they reference don't affect
+// the copy.
+func copyVariables(variables map[string]interface{}) map[string]interface{} {
+	if variables == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(variables))
+	copies := make(map[visit]reflect.Value)
+	for k, v := range variables {
+		if v == nil {
+			c[k] = nil
+			continue
+		}
+		c[k] = deepCopy(reflect.ValueOf(v), copies).Interface()
+	}
+	return c
+}
+
+// visit identifies a pointer, map or slice value copied by deepCopy.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// deepCopy returns a deep copy of v. Unexported struct fields
+// are copied shallowly. Pointers, maps and slices already copied, as
+// recorded in copies, are copied once, so values that refer to
+// themselves are copied with the same cycles rather than endlessly.
+func deepCopy(v reflect.Value, copies map[visit]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), v.Type(), 0}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		copies[key] = c
+		c.Elem().Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), copies))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), v.Type(), v.Len()}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copies[key] = c
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copies))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{v.Pointer(), v.Type(), 0}
+		if c, ok := copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copies[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), copies))
+		}
+		return c
+	case reflect.Struct:
+		if v.Type() == uploadType {
+			return v // Its Body is read once, so it must not be copied.
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i), copies))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
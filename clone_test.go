@@ -0,0 +1,100 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestQuery_Clone(t *testing.T) {
+	type input struct {
+		Labels []graphql.String
+		Limit  *graphql.Int
+	}
+	limit := graphql.Int(10)
+	op := graphql.NewQuery(new(viewerQuery), map[string]interface{}{
+		"input": input{Labels: []graphql.String{"bug"}, Limit: &limit},
+		"ids":   []graphql.ID{"1", "2"},
+	})
+	op.Name = "Viewer"
+
+	c := op.Clone()
+	op.Vars["input"].(input).Labels[0] = "feature"
+	*op.Vars["input"].(input).Limit = 20
+	op.Vars["ids"].([]graphql.ID)[1] = "3"
+
+	want := map[string]interface{}{
+		"input": input{Labels: []graphql.String{"bug"}, Limit: graphql.NewInt(10)},
+		"ids":   []graphql.ID{"1", "2"},
+	}
+	if !reflect.DeepEqual(c.Vars, want) {
+		t.Errorf("got cloned variables: %#v, want: %#v", c.Vars, want)
+	}
+	if c.Data == op.Data {
+		t.Error("cloned Data points to the original")
+	}
+	if _, ok := c.Data.(*viewerQuery); !ok {
+		t.Errorf("got cloned Data of type %T, want *viewerQuery", c.Data)
+	}
+	if c.Name != "Viewer" {
+		t.Errorf("got cloned Name: %q, want: %q", c.Name, "Viewer")
+	}
+}
+
+type listNode struct {
+	Name graphql.String
+	Next *listNode
+}
+
+func TestQuery_Clone_cycle(t *testing.T) {
+	n := &listNode{Name: "a"}
+	n.Next = n
+	op := graphql.NewQuery(new(viewerQuery), map[string]interface{}{"node": n})
+
+	c := op.Clone()
+	got := c.Vars["node"].(*listNode)
+	if got == n || got.Next != got || got.Name != "a" {
+		t.Errorf("got cloned cycle %p -> %p, want a copy of %p pointing to itself", got, got.Next, n)
+	}
+
+	// Sending it fails, rather than overflowing the stack.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		t.Error("got request for cyclic variable")
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	if err := client.Run(context.Background(), op); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("got error %v, want one about a cycle", err)
+	}
+}
+
+func TestClient_Run_concurrentClones(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	op := graphql.NewQuery(new(viewerQuery), map[string]interface{}{"n": graphql.Int(1)})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := op.Clone()
+			if err := client.Run(context.Background(), c); err != nil {
+				t.Error(err)
+				return
+			}
+			if got := c.Data.(*viewerQuery).Viewer.Login; got != "gopher" {
+				t.Errorf("got login: %q, want: %q", got, "gopher")
+			}
+		}()
+	}
+	wg.Wait()
+}
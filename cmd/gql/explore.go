@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// explore runs schema exploration command cmd, one of "types", "fields"
+// and "sdl", with command-line arguments args.
+func explore(ctx context.Context, cmd string, args []string, stdout, stderr io.Writer) error {
+	usage := map[string]string{
+		"types":  "usage: gql types [flags] [endpoint] [pattern]",
+		"fields": "usage: gql fields [flags] [endpoint] Type[.pattern]",
+		"sdl":    "usage: gql sdl [flags] [endpoint] [Type]",
+	}[cmd]
+	fs := flag.NewFlagSet("gql "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, usage)
+		fs.PrintDefaults()
+	}
+	var (
+		headers multiFlag
+		file    = fs.String("schema", "", "read the schema from `file`, with its SDL or introspection result, instead of from endpoint")
+		timeout = fs.Duration("timeout", 30*time.Second, "timeout of the introspection request, or 0 for none")
+	)
+	fs.Var(&headers, "H", "request header, as `\"Name: value\"`; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	maxArgs := 1
+	if *file == "" {
+		maxArgs++
+	}
+	if len(args) > maxArgs || (*file == "" && len(args) == 0) || (cmd == "fields" && len(args) < maxArgs) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	var schema *graphql.Schema
+	var err error
+	if *file != "" {
+		schema, err = readSchema(*file)
+	} else {
+		if *timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, *timeout)
+			defer cancel()
+		}
+		schema, err = introspect(ctx, args[0], headers)
+		args = args[1:]
+	}
+	if err != nil {
+		return err
+	}
+	var arg string
+	if len(args) > 0 {
+		arg = args[0]
+	}
+
+	switch cmd {
+	case "types":
+		for _, name := range schema.SearchTypes(arg) {
+			fmt.Fprintln(stdout, name)
+		}
+	case "fields":
+		typeName, pattern, _ := strings.Cut(arg, ".")
+		defs, err := schema.SearchFields(typeName, pattern)
+		if err != nil {
+			return err
+		}
+		for _, def := range defs {
+			fmt.Fprintln(stdout, def)
+		}
+	case "sdl":
+		if arg == "" {
+			fmt.Fprint(stdout, schema.SDL())
+			return nil
+		}
+		sdl, ok := schema.TypeSDL(arg)
+		if !ok {
+			return fmt.Errorf("schema has no type %q", arg)
+		}
+		fmt.Fprintln(stdout, sdl)
+	}
+	return nil
+}
+
+// introspect fetches the schema of the server at endpoint, sending
+// headers given as "Name: value".
+func introspect(ctx context.Context, endpoint string, headers []string) (*graphql.Schema, error) {
+	header, err := parseHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+	client := graphql.NewClient(endpoint, nil)
+	defer client.Close()
+	var data rawData
+	err = client.Run(ctx, &graphql.Static{
+		QueryStr:       graphql.IntrospectionQuery,
+		Into:           &data,
+		RequestHandler: setHeader(header),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("introspection failed: %v", err)
+	}
+	return graphql.SchemaFromIntrospection(data)
+}
+
+// readSchema reads the schema in file, which contains either its SDL,
+// or the JSON result of the introspection query.
+func readSchema(file string) (*graphql.Schema, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return graphql.SchemaFromIntrospection(data)
+	}
+	return graphql.ParseSchema(data)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testIntrospection = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "repository", "args": [
+				{"name": "owner", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+				{"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+			], "type": {"kind": "OBJECT", "name": "Repository"}},
+			{"name": "repositoryOwner", "args": [], "type": {"kind": "OBJECT", "name": "User"}},
+			{"name": "viewer", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}
+		]},
+		{"kind": "OBJECT", "name": "Repository", "fields": [
+			{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+		]},
+		{"kind": "OBJECT", "name": "User", "description": "A user.", "fields": [
+			{"name": "login", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+		]},
+		{"kind": "INPUT_OBJECT", "name": "UserFilter", "inputFields": [
+			{"name": "login", "type": {"kind": "SCALAR", "name": "String"}}
+		]}
+	],
+	"directives": []
+}}}`
+
+func TestExplore(t *testing.T) {
+	var auth string
+	s := server(t, testIntrospection, func(_ map[string]interface{}, a string) { auth = a })
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"types", s.URL, "user"}, "User\nUserFilter\n"},
+		{[]string{"fields", s.URL, "Query.repo"}, "repository(owner: String!, name: String!): Repository\nrepositoryOwner: User\n"},
+		{[]string{"fields", s.URL, "UserFilter"}, "login: String\n"},
+		{[]string{"sdl", s.URL, "User"}, "\"\"\"A user.\"\"\"\ntype User {\n  login: String\n}\n"},
+	}
+	for _, tc := range tests {
+		var stdout bytes.Buffer
+		if err := run(context.Background(), append([]string{tc.args[0], "-H", "Authorization: Bearer token"}, tc.args[1:]...), nil, &stdout, io.Discard); err != nil {
+			t.Errorf("run(%q): %v", tc.args, err)
+			continue
+		}
+		if got := stdout.String(); got != tc.want {
+			t.Errorf("run(%q): got output:\n%s\nwant:\n%s", tc.args, got, tc.want)
+		}
+	}
+	if auth != "Bearer token" {
+		t.Errorf("got Authorization: %q, want: Bearer token", auth)
+	}
+}
+
+func TestExplore_schemaFile(t *testing.T) {
+	dir := t.TempDir()
+	sdl := filepath.Join(dir, "schema.graphql")
+	if err := os.WriteFile(sdl, []byte("type Query {\n  viewer: User\n}\n\ntype User {\n  login: String\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	introspection := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(introspection, []byte(testIntrospection), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := run(context.Background(), []string{"sdl", "-schema", sdl}, nil, &stdout, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "type Query {\n  viewer: User\n}\n\ntype User {\n  login: String\n}\n"; got != want {
+		t.Errorf("got SDL:\n%s\nwant:\n%s", got, want)
+	}
+	stdout.Reset()
+	if err := run(context.Background(), []string{"types", "-schema", introspection, "repo"}, nil, &stdout, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "Repository\n"; got != want {
+		t.Errorf("got types: %q, want: %q", got, want)
+	}
+}
+
+func TestExplore_errors(t *testing.T) {
+	s := server(t, testIntrospection, func(map[string]interface{}, string) {})
+
+	var stderr bytes.Buffer
+	if err := run(context.Background(), []string{"fields", s.URL}, nil, io.Discard, &stderr); err == nil || !strings.HasPrefix(stderr.String(), "usage: gql fields") {
+		t.Errorf("got error %v, stderr %q; want usage", err, stderr.String())
+	}
+	if err := run(context.Background(), []string{"fields", s.URL, "Nope.x"}, nil, io.Discard, io.Discard); err == nil || !strings.Contains(err.Error(), `no type "Nope"`) {
+		t.Errorf("got error: %v, want no type", err)
+	}
+	if err := run(context.Background(), []string{"sdl", s.URL, "Nope"}, nil, io.Discard, io.Discard); err == nil {
+		t.Error("got nil error for SDL of unknown type")
+	}
+}
@@ -0,0 +1,278 @@
+// gql is a command-line GraphQL client, for running operations while
+// developing and debugging.
+//
+// Usage:
+//
+//	gql [flags] endpoint [file]
+//
+// It runs the operation in file, or read from standard input if file
+// is omitted or "-", and prints the response as pretty-printed JSON:
+//
+//	echo '{ viewer { login } }' | gql -H 'Authorization: Bearer …' https://api.github.com/graphql
+//
+// Variables are given as a JSON object with -vars, and individually
+// with -var, whose values are parsed as JSON if valid, and used as
+// strings otherwise:
+//
+//	gql -var login=gopher -var first=10 https://example.com/graphql user.graphql
+//
+// With -persisted, operations are sent by document ID, the hex-encoded
+// SHA-256 hash of their document, instead of by their query. With
+// -manifest, operations in a persisted operations manifest are sent by
+// their ID there. With -introspect, the schema of the server is dumped
+// as the result of the standard introspection query.
+//
+// The schema of a server can be explored with the types, fields and sdl
+// commands, which take the same -H and -timeout flags, and -schema to
+// read the schema from a file with its SDL or introspection result
+// instead of from the server:
+//
+//	gql types endpoint [pattern]        # names of types containing pattern
+//	gql fields endpoint Type[.pattern]  # fields of Type containing pattern
+//	gql sdl endpoint [Type]             # SDL of the schema, or of Type
+//
+// Patterns are matched ignoring case, so "gql types user" lists User and
+// UserConnection, and "gql fields Query.repo" lists the fields of Query
+// such as repository and repositoryOwner.
+//
+// gql exits with status 1 if the response has GraphQL errors,
+// and status 2 if the operation can't be run.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	var errs graphql.ErrorList
+	switch {
+	case err == flag.ErrHelp:
+		os.Exit(2)
+	case errors.As(err, &errs):
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "gql:", err)
+		os.Exit(2)
+	}
+}
+
+// run runs gql with command-line arguments args. It returns
+// a graphql.ErrorList if the response has GraphQL errors.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "types", "fields", "sdl":
+			return explore(ctx, args[0], args[1:], stdout, stderr)
+		}
+	}
+	fs := flag.NewFlagSet("gql", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gql [flags] endpoint [file]")
+		fmt.Fprintln(stderr, "       gql types|fields|sdl [flags] [endpoint] [args]")
+		fs.PrintDefaults()
+	}
+	var (
+		headers   multiFlag
+		vars      multiFlag
+		varsJSON  = fs.String("vars", "", "variables, as a JSON `object`")
+		name      = fs.String("op", "", "`name` of the operation to run, sent as the request's operationName")
+		raw       = fs.Bool("raw", false, "print the response as compact JSON")
+		persisted = fs.Bool("persisted", false, "send operations by the SHA-256 hash of their document")
+		manifest  = fs.String("manifest", "", "send operations in persisted operations manifest `file` by their ID")
+		introspec = fs.Bool("introspect", false, "dump the server's schema by running the introspection query")
+		timeout   = fs.Duration("timeout", 30*time.Second, "timeout of the request, or 0 for none")
+	)
+	fs.Var(&headers, "H", "request header, as `\"Name: value\"`; may be repeated")
+	fs.Var(&vars, "var", "variable, as `name=value`; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || (*introspec && fs.NArg() > 1) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	endpoint := fs.Arg(0)
+
+	var query string
+	if *introspec {
+		query = graphql.IntrospectionQuery
+	} else {
+		var err error
+		if query, err = readQuery(fs.Arg(1), stdin); err != nil {
+			return err
+		}
+	}
+	variables, err := parseVariables(*varsJSON, vars)
+	if err != nil {
+		return err
+	}
+	header, err := parseHeaders(headers)
+	if err != nil {
+		return err
+	}
+
+	opts := []graphql.ClientOption{graphql.WithOperationNameInBody()}
+	if *manifest != "" {
+		data, err := ioutil.ReadFile(*manifest)
+		if err != nil {
+			return err
+		}
+		m, err := graphql.ParseManifest(data)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, graphql.WithManifest(m))
+	}
+	client := graphql.NewClient(endpoint, nil, opts...)
+	defer client.Close()
+
+	var data rawData
+	var op graphql.Operation = &graphql.Static{
+		QueryStr:       query,
+		Into:           &data,
+		Vars:           variables,
+		Name:           *name,
+		RequestHandler: setHeader(header),
+	}
+	if *persisted {
+		sum := sha256.Sum256([]byte(query))
+		op = &graphql.Persisted{Operation: op, ID: hex.EncodeToString(sum[:])}
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	result, err := graphql.RunResult[rawData](ctx, client, op)
+	var errs graphql.ErrorList
+	if err != nil && !errors.As(err, &errs) {
+		return err
+	}
+	if err := printResponse(stdout, result, *raw); err != nil {
+		return err
+	}
+	return result.Err()
+}
+
+// readQuery reads the query in file, or in stdin if file is "" or "-".
+func readQuery(file string, stdin io.Reader) (string, error) {
+	var b []byte
+	var err error
+	if file == "" || file == "-" {
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return "", errors.New("empty query")
+	}
+	return string(b), nil
+}
+
+// parseHeaders parses headers given as "Name: value".
+func parseHeaders(headers []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q; want \"Name: value\"", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// setHeader returns a request handler setting the headers in header.
+func setHeader(header http.Header) graphql.RequestHandlerFunc {
+	return func(req *http.Request) {
+		for name, values := range header {
+			req.Header[name] = values
+		}
+	}
+}
+
+// parseVariables returns the variables of the JSON object obj,
+// if not empty, and of the name=value pairs in vars, which
+// override those in obj.
+func parseVariables(obj string, vars []string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	if obj != "" {
+		if err := json.Unmarshal([]byte(obj), &variables); err != nil {
+			return nil, fmt.Errorf("invalid -vars: %v", err)
+		}
+	}
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q; want name=value", v)
+		}
+		if json.Valid([]byte(value)) {
+			variables[name] = json.RawMessage(value)
+		} else {
+			variables[name] = value
+		}
+	}
+	return variables, nil
+}
+
+// printResponse prints the response of result to w, pretty-printed
+// unless raw is set.
+func printResponse(w io.Writer, result *graphql.Result[rawData], raw bool) error {
+	resp := struct {
+		Data       json.RawMessage        `json:"data,omitempty"`
+		Errors     graphql.ErrorList      `json:"errors,omitempty"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}{json.RawMessage(result.Data), result.Errors, result.Extensions}
+	var b []byte
+	var err error
+	if raw {
+		b, err = json.Marshal(resp)
+	} else {
+		b, err = json.MarshalIndent(resp, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// rawData is response data kept as JSON.
+type rawData []byte
+
+// DecodeGraphQL implements graphql.DataDecoder.
+func (d *rawData) DecodeGraphQL(data []byte) error {
+	*d = append((*d)[:0], data...)
+	return nil
+}
+
+// multiFlag is a flag that may be repeated.
+type multiFlag []string
+
+func (f *multiFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *multiFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// server returns a server responding with resp, reporting the bodies
+// and Authorization headers of its requests to got.
+func server(t *testing.T, resp string, got func(body map[string]interface{}, auth string)) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		got(body, req.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, resp)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRun(t *testing.T) {
+	var body map[string]interface{}
+	var auth string
+	s := server(t, `{"data": {"user": {"name": "Gopher"}}}`, func(b map[string]interface{}, a string) { body, auth = b, a })
+
+	var stdout bytes.Buffer
+	err := run(context.Background(), []string{
+		"-H", "Authorization: Bearer token",
+		"-vars", `{"login": "x", "first": 1}`,
+		"-var", "login=gopher",
+		"-var", "admin=true",
+		"-op", "User",
+		s.URL,
+	}, strings.NewReader(`query User($login: String!, $first: Int, $admin: Boolean) { user(login: $login) { name } }`), &stdout, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "{\n  \"data\": {\n    \"user\": {\n      \"name\": \"Gopher\"\n    }\n  }\n}\n"; got != want {
+		t.Errorf("got output:\n%s\nwant:\n%s", got, want)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("got Authorization: %q, want: Bearer token", auth)
+	}
+	if got, want := body["operationName"], "User"; got != want {
+		t.Errorf("got operationName: %v, want: %v", got, want)
+	}
+	vars, _ := body["variables"].(map[string]interface{})
+	if vars["login"] != "gopher" || vars["first"] != 1.0 || vars["admin"] != true {
+		t.Errorf("got variables: %v", vars)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	s := server(t, `{"data": {"user": null}, "errors": [{"message": "not found"}]}`, func(map[string]interface{}, string) {})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "user.graphql")
+	if err := os.WriteFile(file, []byte(`{ user { name } }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	err := run(context.Background(), []string{"-raw", s.URL, file}, nil, &stdout, io.Discard)
+	var errs graphql.ErrorList
+	if !errors.As(err, &errs) || errs[0].Message != "not found" {
+		t.Errorf("got error: %v, want not found", err)
+	}
+	if got, want := stdout.String(), `{"data":{"user":null},"errors":[{"message":"not found"}]}`+"\n"; got != want {
+		t.Errorf("got output: %q, want: %q", got, want)
+	}
+}
+
+func TestRun_persisted(t *testing.T) {
+	var body map[string]interface{}
+	s := server(t, `{"data": {"viewer": {"login": "gopher"}}}`, func(b map[string]interface{}, _ string) { body = b })
+
+	query := `{ viewer { login } }`
+	err := run(context.Background(), []string{"-persisted", s.URL, "-"}, strings.NewReader(query), io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(query))
+	if got, want := body["documentId"], hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("got documentId: %v, want: %v", got, want)
+	}
+	if _, ok := body["query"]; ok {
+		t.Errorf("got query in body: %v", body)
+	}
+}
+
+func TestRun_introspect(t *testing.T) {
+	var body map[string]interface{}
+	s := server(t, `{"data": {"__schema": {"queryType": {"name": "Query"}}}}`, func(b map[string]interface{}, _ string) { body = b })
+
+	var stdout bytes.Buffer
+	if err := run(context.Background(), []string{"-introspect", "-raw", s.URL}, nil, &stdout, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if body["query"] != graphql.IntrospectionQuery {
+		t.Errorf("got query: %v, want IntrospectionQuery", body["query"])
+	}
+	if got, want := stdout.String(), `{"data":{"__schema":{"queryType":{"name":"Query"}}}}`+"\n"; got != want {
+		t.Errorf("got output: %q, want: %q", got, want)
+	}
+}
+
+func TestRun_usage(t *testing.T) {
+	var stderr bytes.Buffer
+	for _, args := range [][]string{
+		{},
+		{"-introspect", "https://example.com", "query.graphql"},
+	} {
+		stderr.Reset()
+		if err := run(context.Background(), args, nil, io.Discard, &stderr); err == nil {
+			t.Errorf("run(%q): got nil error", args)
+		}
+		if !strings.HasPrefix(stderr.String(), "usage: gql") {
+			t.Errorf("run(%q): got stderr %q, want usage", args, stderr.String())
+		}
+	}
+	if err := run(context.Background(), []string{"-var", "x", "https://example.com"}, strings.NewReader("{a}"), io.Discard, io.Discard); err == nil || !strings.Contains(err.Error(), "name=value") {
+		t.Errorf("got error: %v, want invalid variable", err)
+	}
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec encodes GraphQL requests and decodes GraphQL responses in the wire
+// format of a server, such as JSON (the default), MessagePack or CBOR.
+type Codec interface {
+	// ContentType returns the media type of the wire format, sent as
+	// the Content-Type of requests.
+	ContentType() string
+
+	// EncodeRequest writes the encoding of req to w.
+	EncodeRequest(w io.Writer, req Request) error
+
+	// DecodeResponse decodes a response from r into resp. Since response
+	// data is decoded into query data structures from JSON, resp.Data
+	// must be set to the JSON encoding of the response data, if any.
+	DecodeResponse(r io.Reader, resp *Response) error
+}
+
+// WithCodec sets the codec the client uses to encode requests and decode
+// responses. The default is JSONCodec. WithStrictHTTP only applies
+// to clients using JSONCodec.
+func WithCodec(codec Codec) ClientOption {
+	return func(c *Client) {
+		c.codec = codec
+	}
+}
+
+// JSONCodec is the Codec for the JSON wire format of GraphQL over HTTP.
+type JSONCodec struct{}
+
+// ContentType returns "application/json".
+func (JSONCodec) ContentType() string { return mediaTypeJSON }
+
+// EncodeRequest writes the JSON encoding of req to w.
+func (JSONCodec) EncodeRequest(w io.Writer, req Request) error {
+	return json.NewEncoder(w).Encode(req)
+}
+
+// DecodeResponse decodes a JSON response from r into resp.
+func (JSONCodec) DecodeResponse(r io.Reader, resp *Response) error {
+	return json.NewDecoder(r).Decode(resp)
+}
@@ -0,0 +1,80 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// gobCodec is a graphql.Codec using a gob encoding of GraphQL
+// requests and responses.
+type gobCodec struct{}
+
+type gobRequest struct {
+	Query string
+}
+
+type gobResponse struct {
+	Data   []byte // JSON.
+	Errors []string
+}
+
+func (gobCodec) ContentType() string { return "application/x-gob" }
+
+func (gobCodec) EncodeRequest(w io.Writer, req graphql.Request) error {
+	return gob.NewEncoder(w).Encode(gobRequest{Query: req.Query})
+}
+
+func (gobCodec) DecodeResponse(r io.Reader, resp *graphql.Response) error {
+	var g gobResponse
+	if err := gob.NewDecoder(r).Decode(&g); err != nil {
+		return err
+	}
+	resp.Data = g.Data
+	for _, msg := range g.Errors {
+		resp.Errors = append(resp.Errors, graphql.Error{Message: msg})
+	}
+	return nil
+}
+
+func TestClient_codec(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.Header.Get("Content-Type"), "application/x-gob"; got != want {
+			t.Errorf("got Content-Type: %q, want: %q", got, want)
+		}
+		if got, want := req.Header.Get("Accept"), "application/x-gob"; got != want {
+			t.Errorf("got Accept: %q, want: %q", got, want)
+		}
+		var in gobRequest
+		if err := gob.NewDecoder(req.Body).Decode(&in); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := in.Query, "{viewer{login}}"; got != want {
+			t.Errorf("got query: %q, want: %q", got, want)
+		}
+		err := gob.NewEncoder(w).Encode(gobResponse{
+			Data:   []byte(`{"viewer": {"login": "gopher"}}`),
+			Errors: []string{"partial"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCodec(gobCodec{}),
+	)
+
+	var q viewerQuery
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err.Error(), "partial"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,180 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// CoercionError is returned by Client.Run for variables whose values
+// can't be coerced into the types the schema set with WithSchema expects.
+type CoercionError struct {
+	Variable string // Name of the variable, without "$".
+	Type     string // GraphQL type expected by the schema, such as "[IssueState!]".
+	Reason   string
+}
+
+func (e *CoercionError) Error() string {
+	return fmt.Sprintf("cannot coerce variable \"$%s\" to type %q: %s", e.Variable, e.Type, e.Reason)
+}
+
+// coercion is how the variables of a query are coerced into the types
+// the schema expects where they're used. It's computed once per query,
+// for up to DefaultDocumentCacheSize queries.
+type coercion struct {
+	query string                  // Query with variable definitions rewritten to the expected types.
+	types map[string]*parser.Type // Variable name -> expected type.
+}
+
+// coercion returns the coercion of the variables of query,
+// which must only be called if c has a schema.
+func (c *Client) coercion(query string) *coercion {
+	co, ok := c.coercions.load(query)
+	if !ok {
+		co, _ = c.coercions.loadOrStore(query, c.schema.coercion(query))
+	}
+	return co
+}
+
+// coercion computes the coercion of the variables of query. Variable
+// definitions that aren't compatible with the types expected where the
+// variables are used are rewritten to the expected types, if values of the
+// defined types can be coerced into them: Int into Float or ID, String
+// into an enum or ID, and a single value into a list. Non-null definitions
+// stay non-null. Other incompatible
+// definitions are left for validation to report.
+func (s *Schema) coercion(query string) *coercion {
+	co := &coercion{query: query, types: make(map[string]*parser.Type)}
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return co
+	}
+	var defs []*parser.VarDef
+	for d, t := range parser.VariableTypes(s.s, doc) {
+		co.types[d.Name] = t
+		if !parser.Compatible(d.Type, d.Default != nil, t) && s.coercible(d.Type, t) {
+			defs = append(defs, d)
+		}
+	}
+	if len(defs) == 0 {
+		return co
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].TypeStart < defs[j].TypeStart })
+	var b strings.Builder
+	last := 0
+	for _, d := range defs {
+		t := *co.types[d.Name]
+		t.NonNull = t.NonNull || d.Type.NonNull
+		b.WriteString(query[last:d.TypeStart])
+		b.WriteString(t.String())
+		last = d.TypeEnd
+	}
+	b.WriteString(query[last:])
+	co.query = b.String()
+	return co
+}
+
+// coercible reports whether values of type from can be coerced into type to.
+func (s *Schema) coercible(from, to *parser.Type) bool {
+	if from.Elem != nil {
+		return to.Elem != nil && s.coercible(from.Elem, to.Elem)
+	}
+	if to.Elem != nil {
+		return s.coercible(from, to.Elem)
+	}
+	if from.Name == to.Name {
+		return true
+	}
+	switch from.Name {
+	case "Int":
+		return to.Name == "Float" || to.Name == "ID"
+	case "String":
+		t := s.s.Types[to.Name]
+		return to.Name == "ID" || t != nil && t.Kind == parser.EnumKind
+	}
+	return false
+}
+
+// coerceVariables coerces variables into the types expected by co,
+// returning a copy of variables if any value is changed.
+func (s *Schema) coerceVariables(co *coercion, variables map[string]interface{}) (map[string]interface{}, error) {
+	out := variables
+	copied := false
+	for _, name := range sortedKeys(co.types) {
+		t := co.types[name]
+		v, changed, err := s.coerceValue(variables[name], t)
+		if err != nil {
+			return nil, &CoercionError{Variable: name, Type: t.String(), Reason: err.Error()}
+		}
+		if !changed {
+			continue
+		}
+		if !copied {
+			out = make(map[string]interface{}, len(variables))
+			for k, v := range variables {
+				out[k] = v
+			}
+			copied = true
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// coerceValue coerces v into type t. Values of types implementing
+// json.Marshaler, and null values, are left as they are.
+func (s *Schema) coerceValue(v interface{}, t *parser.Type) (_ interface{}, changed bool, _ error) {
+	if _, ok := v.(json.Marshaler); ok {
+		return v, false, nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Invalid, reflect.Ptr:
+		return v, false, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem == nil {
+			return v, false, nil // Left for the server to report.
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			e, c, err := s.coerceValue(rv.Index(i).Interface(), t.Elem)
+			if err != nil {
+				return nil, false, err
+			}
+			list[i], changed = e, changed || c
+		}
+		if !changed {
+			return v, false, nil
+		}
+		return list, true, nil
+	}
+	if t.Elem != nil {
+		e, _, err := s.coerceValue(v, t.Elem)
+		if err != nil {
+			return nil, false, err
+		}
+		return []interface{}{e}, true, nil
+	}
+	if def := s.s.Types[t.Name]; def != nil && def.Kind == parser.EnumKind && rv.Kind() == reflect.String {
+		if def.EnumValue(rv.String()) == nil {
+			return nil, false, fmt.Errorf("%q is not a value of enum %q", rv.String(), def.Name)
+		}
+	}
+	return v, false, nil
+}
+
+func sortedKeys(m map[string]*parser.Type) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
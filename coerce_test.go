@@ -0,0 +1,66 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+const coercionSchema = `
+	type Query {
+		products(minPrice: Float!, sort: Sort, tags: [String!]): [Product!]!
+	}
+	type Product {
+		name: String!
+	}
+	enum Sort { PRICE NAME }
+`
+
+func TestClient_Run_coerceVariables(t *testing.T) {
+	schema, err := graphql.ParseSchema([]byte(coercionSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body = mustRead(req.Body)
+		mustWrite(w, `{"data": {"products": [{"name": "Gopher"}]}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithSchema(schema))
+
+	var q struct {
+		Products []struct {
+			Name graphql.String
+		} `graphql:"products(minPrice: $minPrice, sort: $sort, tags: $tags)"`
+	}
+	err = client.Query(context.Background(), &q, map[string]interface{}{
+		"minPrice": graphql.Int(10),
+		"sort":     graphql.String("PRICE"),
+		"tags":     graphql.String("toys"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body, `{"query":"query($minPrice:Float!$sort:Sort!$tags:[String!]!){products(minPrice: $minPrice, sort: $sort, tags: $tags){name}}","variables":{"minPrice":10,"sort":"PRICE","tags":["toys"]}}`+"\n"; got != want {
+		t.Errorf("got body:\n%v\nwant:\n%v", got, want)
+	}
+
+	body = ""
+	err = client.Query(context.Background(), &q, map[string]interface{}{
+		"minPrice": graphql.Int(10),
+		"sort":     graphql.String("POPULARITY"),
+		"tags":     []graphql.String{"toys"},
+	})
+	if got, want := err.Error(), `cannot coerce variable "$sort" to type "Sort": "POPULARITY" is not a value of enum "Sort"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if _, ok := err.(*graphql.CoercionError); !ok {
+		t.Errorf("got error %T, want *graphql.CoercionError", err)
+	}
+	if body != "" {
+		t.Errorf("got request with body %v, want none", body)
+	}
+}
@@ -0,0 +1,176 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Columnar is a query data structure field type for large lists of objects,
+// such as the nodes of a connection read for analytics, that decodes them
+// into columns: a slice per field, rather than a slice of structs. That
+// makes far fewer allocations for long lists, and lays values out as
+// columnar formats such as Apache Arrow do, so they can be copied into
+// arrays of them directly.
+//
+// T is a struct with a slice field per field of the objects, and a struct
+// field with slice fields of its own per field of object type, named and
+// tagged as the fields of a struct of the objects would be:
+//
+//	var q struct {
+//		Repository struct {
+//			Issues struct {
+//				Nodes graphql.Columnar[struct {
+//					Number []graphql.Int
+//					Title  []graphql.String
+//					Author struct {
+//						Login []graphql.String
+//					}
+//				}]
+//			} `graphql:"issues(first: 100)"`
+//		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+//	}
+//
+// The query is constructed as for a slice of structs, here
+// "nodes{number,title,author{login}}". The columns have an element for
+// each object, or its zero value where an object, or one of its fields
+// of object type, is null. Values are decoded with encoding/json.
+type Columnar[T any] struct {
+	Columns T
+	Len     int // Number of objects decoded, the length of each column.
+}
+
+// columnar is implemented by pointers to Columnar types.
+type columnar interface {
+	columnsType() reflect.Type
+}
+
+func (c *Columnar[T]) columnsType() reflect.Type {
+	return reflect.TypeOf(&c.Columns).Elem()
+}
+
+var columnarType = reflect.TypeOf((*columnar)(nil)).Elem()
+
+// UnmarshalJSON implements json.Unmarshaler, decoding a JSON list of
+// objects into columns.
+func (c *Columnar[T]) UnmarshalJSON(data []byte) error {
+	var zero T
+	c.Columns, c.Len = zero, 0
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	cols, err := newColumnSet(reflect.ValueOf(&c.Columns).Elem())
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('[') {
+		return fmt.Errorf("graphql: cannot decode %v into columns; want list", tok)
+	}
+	for dec.More() {
+		if err := cols.decodeRow(dec, c.Len); err != nil {
+			return err
+		}
+		c.Len++
+		cols.pad(c.Len)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// columnSet is the set of columns of a Columnar struct, or of a struct
+// field of it, by response key.
+type columnSet map[string]column
+
+// column is either a slice of values, or a set of columns of an object.
+type column struct {
+	values reflect.Value
+	object columnSet
+}
+
+func newColumnSet(v reflect.Value) (columnSet, error) {
+	cols := make(columnSet)
+	for i := 0; i < v.NumField(); i++ {
+		key, inline, ok := responseKey(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Slice && !inline:
+			cols[key] = column{values: f}
+		case f.Kind() == reflect.Struct:
+			object, err := newColumnSet(f)
+			if err != nil {
+				return nil, err
+			}
+			if inline {
+				for k, col := range object {
+					cols[k] = col
+				}
+				continue
+			}
+			cols[key] = column{object: object}
+		default:
+			return nil, fmt.Errorf("graphql: column %s of type %v isn't a slice", v.Type().Field(i).Name, f.Type())
+		}
+	}
+	return cols, nil
+}
+
+// decodeRow decodes the next JSON object of dec, or null, into row i of
+// cols, all of which have length i.
+func (cols columnSet) decodeRow(dec *json.Decoder, i int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case nil:
+		return nil
+	case json.Delim('{'):
+	default:
+		return fmt.Errorf("graphql: cannot decode %v into columns; want object", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		col, ok := cols[tok.(string)]
+		switch {
+		case !ok:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		case col.object != nil:
+			err = col.object.decodeRow(dec, i)
+			col.object.pad(i + 1)
+		case col.values.Len() > i:
+			err = fmt.Errorf("graphql: duplicate field %q in object", tok)
+		default:
+			col.values.Set(reflect.Append(col.values, reflect.Zero(col.values.Type().Elem())))
+			err = dec.Decode(col.values.Index(i).Addr().Interface())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// pad appends zero values to the columns of cols shorter than n.
+func (cols columnSet) pad(n int) {
+	for _, col := range cols {
+		if col.object != nil {
+			col.object.pad(n)
+			continue
+		}
+		for col.values.Len() < n {
+			col.values.Set(reflect.Append(col.values, reflect.Zero(col.values.Type().Elem())))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestColumnar(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct{ Query string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		query = body.Query
+		mustWrite(w, `{"data": {"repository": {"issues": {"nodes": [
+			{"number": 1, "title": "First", "author": {"login": "gopher"}, "labels": ["bug"], "__typename": "Issue"},
+			{"number": 2, "title": null, "author": null, "labels": []},
+			null,
+			{"title": "Fourth", "author": {"login": "octocat"}, "number": 4}
+		]}}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type issueColumns struct {
+		Number []graphql.Int
+		Title  []graphql.String
+		Author struct {
+			Login []graphql.String
+		}
+		Labels [][]string `graphql:"labels: labelNames"`
+	}
+	var q struct {
+		Repository struct {
+			Issues struct {
+				Nodes graphql.Columnar[issueColumns]
+			} `graphql:"issues(first: 4)"`
+		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{repository(owner: "octocat", name: "Hello-World"){issues(first: 4){nodes{number,title,author{login},labels: labelNames}}}}`; query != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", query, want)
+	}
+	nodes := q.Repository.Issues.Nodes
+	if nodes.Len != 4 {
+		t.Fatalf("got %d nodes, want 4", nodes.Len)
+	}
+	var want issueColumns
+	want.Number = []graphql.Int{1, 2, 0, 4}
+	want.Title = []graphql.String{"First", "", "", "Fourth"}
+	want.Author.Login = []graphql.String{"gopher", "", "", "octocat"}
+	want.Labels = [][]string{{"bug"}, {}, nil, nil}
+	if !reflect.DeepEqual(nodes.Columns, want) {
+		t.Errorf("got columns:\n%+v\nwant:\n%+v", nodes.Columns, want)
+	}
+}
+
+func TestColumnar_errors(t *testing.T) {
+	for _, data := range []string{
+		`{"number": 1}`,
+		`[1]`,
+		`[{"number": 1, "number": 2}]`,
+		`[{"number": "one"}]`,
+	} {
+		var c graphql.Columnar[struct{ Number []graphql.Int }]
+		if err := json.Unmarshal([]byte(data), &c); err == nil {
+			t.Errorf("%s: got nil error", data)
+		}
+	}
+	var c graphql.Columnar[struct{ Number graphql.Int }]
+	if err := json.Unmarshal([]byte(`[{"number": 1}]`), &c); err == nil {
+		t.Error("got nil error decoding into non-slice column")
+	}
+}
@@ -0,0 +1,251 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/arvata-io/graphql/ident"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// IssueKind is the kind of a compatibility Issue.
+type IssueKind int
+
+const (
+	// InvalidSchema means the schema itself could not be parsed.
+	InvalidSchema IssueKind = iota
+	// InvalidOperation means the operation's document doesn't validate
+	// against the schema; for example, it selects a removed field.
+	InvalidOperation
+	// TypeMismatch means a Go type in the operation's data structure
+	// can't hold values of the corresponding GraphQL type.
+	TypeMismatch
+	// DeprecatedUsage means the operation uses a deprecated field,
+	// argument or enum value.
+	DeprecatedUsage
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case InvalidSchema:
+		return "invalid schema"
+	case InvalidOperation:
+		return "invalid operation"
+	case TypeMismatch:
+		return "type mismatch"
+	case DeprecatedUsage:
+		return "deprecated usage"
+	default:
+		return fmt.Sprintf("IssueKind(%d)", int(k))
+	}
+}
+
+// Issue is a compatibility issue between an operation and a schema.
+type Issue struct {
+	Kind IssueKind
+	Op   Operation // Operation with the issue, or nil for InvalidSchema.
+
+	// Path is the dot-separated path of the field with the issue,
+	// such as "repository.issue.title", for TypeMismatch issues.
+	Path string
+
+	// Line and Column locate the issue in the operation's
+	// query document, or in the schema for InvalidSchema issues.
+	Line, Column int
+
+	Message string
+}
+
+func (i Issue) String() string {
+	var loc string
+	switch {
+	case i.Path != "":
+		loc = i.Path + ": "
+	case i.Line != 0:
+		loc = fmt.Sprintf("%d:%d: ", i.Line, i.Column)
+	}
+	return fmt.Sprintf("%v: %s%s", i.Kind, loc, i.Message)
+}
+
+// CheckCompatibility checks operations ops against the GraphQL schema
+// described by SDL schemaSDL, and returns the issues found, in order.
+// It can be used in tests to detect drift between query data structures
+// and a server's schema before deploying.
+//
+// Every operation is validated against the schema. For *Query and
+// *Mutation operations, the Go types of their data structures are
+// additionally checked against the types of the fields they select.
+func CheckCompatibility(schemaSDL []byte, ops ...Operation) []Issue {
+	schema, err := parser.ParseSchema(string(schemaSDL))
+	if err != nil {
+		return []Issue{issue(InvalidSchema, nil, err.(*parser.Error))}
+	}
+	var issues []Issue
+	for _, op := range ops {
+		doc, err := parser.ParseDocument(op.Query())
+		if err != nil {
+			issues = append(issues, issue(InvalidOperation, op, err.(*parser.Error)))
+			continue
+		}
+		errs, warnings := parser.Check(schema, doc)
+		for _, err := range errs {
+			issues = append(issues, issue(InvalidOperation, op, err))
+		}
+		for _, w := range warnings {
+			issues = append(issues, issue(DeprecatedUsage, op, w))
+		}
+
+		var data interface{}
+		switch op := op.(type) {
+		case *Query:
+			data = op.Data
+		case *Mutation:
+			data = op.Data
+		default:
+			continue
+		}
+		if root := schema.RootType(doc.Operations[0].Type); root != nil && data != nil {
+			c := &compatChecker{schema: schema, op: op}
+			c.checkStruct(reflect.TypeOf(data), root, "")
+			issues = append(issues, c.issues...)
+		}
+	}
+	return issues
+}
+
+func issue(kind IssueKind, op Operation, err *parser.Error) Issue {
+	i := Issue{Kind: kind, Op: op, Message: err.Message}
+	if len(err.Locations) > 0 {
+		i.Line, i.Column = err.Locations[0].Line, err.Locations[0].Column
+	}
+	return i
+}
+
+// compatChecker checks Go types of a query data structure against a schema.
+type compatChecker struct {
+	schema *parser.Schema
+	op     Operation
+	issues []Issue
+}
+
+// checkStruct checks the fields of struct type t, which selects fields of type def.
+func (c *compatChecker) checkStruct(t reflect.Type, def *parser.TypeDef, path string) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		value, ok := f.Tag.Lookup("graphql")
+		value = strings.TrimSpace(value)
+		switch {
+		case ok && value == jsonutil.FallbackTag:
+			continue
+		case f.Anonymous && !ok:
+			c.checkStruct(f.Type, def, path)
+			continue
+		case strings.HasPrefix(value, "..."):
+			fragDef := def
+			cond := strings.TrimSpace(strings.TrimPrefix(value, "..."))
+			if strings.HasPrefix(cond, "on ") {
+				if t := c.schema.Types[strings.Fields(cond[len("on "):])[0]]; t != nil {
+					fragDef = t
+				}
+			}
+			c.checkStruct(f.Type, fragDef, path)
+			continue
+		}
+		name := ident.ParseMixedCaps(f.Name).ToLowerCamelCase()
+		if ok {
+			name = fieldName(value)
+		}
+		fd := def.Field(name)
+		if fd == nil {
+			// Unknown fields are reported by validation, and __typename is always a String.
+			continue
+		}
+		c.checkType(f.Type, fd.Type, joinPath(path, name))
+	}
+}
+
+// checkType checks that Go type t can hold values of GraphQL type gt.
+func (c *compatChecker) checkType(t reflect.Type, gt *parser.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshaler) || t.Kind() == reflect.Interface {
+		// Custom scalar types and interfaces may hold anything.
+		return
+	}
+	if gt.Elem != nil {
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			c.mismatch(t, gt, path)
+			return
+		}
+		c.checkType(t.Elem(), gt.Elem, path)
+		return
+	}
+	def := c.schema.Types[gt.Name]
+	if def == nil {
+		return
+	}
+	if !def.IsLeaf() {
+		if t.Kind() != reflect.Struct {
+			c.mismatch(t, gt, path)
+			return
+		}
+		c.checkStruct(t, def, path)
+		return
+	}
+	var ok bool
+	switch k := t.Kind(); {
+	case def.Kind == parser.EnumKind:
+		ok = k == reflect.String
+	case def.Name == "Int":
+		ok = k >= reflect.Int && k <= reflect.Uint64 || k == reflect.Float32 || k == reflect.Float64
+	case def.Name == "Float":
+		ok = k == reflect.Float32 || k == reflect.Float64
+	case def.Name == "String", def.Name == "ID":
+		ok = k == reflect.String
+	case def.Name == "Boolean":
+		ok = k == reflect.Bool
+	default:
+		// Custom scalars may be represented by any type.
+		ok = true
+	}
+	if !ok {
+		c.mismatch(t, gt, path)
+	}
+}
+
+func (c *compatChecker) mismatch(t reflect.Type, gt *parser.Type, path string) {
+	c.issues = append(c.issues, Issue{
+		Kind:    TypeMismatch,
+		Op:      c.op,
+		Path:    path,
+		Message: fmt.Sprintf("Go type %v cannot hold GraphQL type %v", t, gt),
+	})
+}
+
+// fieldName returns the GraphQL field name selected by graphql struct
+// field tag value, such as "human" for `luke: human(id: "1000")`.
+func fieldName(tag string) string {
+	if i := strings.IndexAny(tag, "(@{"); i != -1 {
+		tag = tag[:i]
+	}
+	if i := strings.Index(tag, ":"); i != -1 {
+		tag = tag[i+1:]
+	}
+	return strings.TrimSpace(tag)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,124 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	schema := []byte(`
+		type Query {
+			repository(owner: String!, name: String!): Repository
+		}
+		type Repository {
+			name: String!
+			stargazerCount: Int!
+			isFork: Boolean!
+			issues(first: Int, states: [IssueState!]): IssueConnection!
+			watchers: Int! @deprecated(reason: "Use watcherCount.")
+		}
+		type IssueConnection {
+			nodes: [Issue]
+		}
+		type Issue {
+			number: Int!
+			title: String!
+			state: IssueState!
+		}
+		enum IssueState { OPEN CLOSED }
+	`)
+
+	var ok struct {
+		Repository struct {
+			Name           graphql.String
+			StargazerCount int
+			Issues         struct {
+				Nodes []struct {
+					Number graphql.Int
+					State  string
+				}
+			} `graphql:"issues(first: 10, states: [OPEN])"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	var drifted struct {
+		Repository struct {
+			Name     graphql.String
+			IsFork   graphql.String
+			Watchers graphql.Int
+			Issues   struct {
+				Nodes struct {
+					Title graphql.String
+				}
+			}
+			Forks graphql.Int
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]interface{}{
+		"owner": graphql.String("shurcooL"),
+		"name":  graphql.String("graphql"),
+	}
+	issues := graphql.CheckCompatibility(schema,
+		graphql.NewQuery(&ok, vars),
+		graphql.NewQuery(&drifted, vars),
+	)
+
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	want := []string{
+		`invalid operation: 1:117: cannot query field "forks" on type "Repository"`,
+		`deprecated usage: 1:87: field "Repository.watchers" is deprecated: Use watcherCount.`,
+		`type mismatch: repository.isFork: Go type graphql.String cannot hold GraphQL type Boolean!`,
+		`type mismatch: repository.issues.nodes: Go type struct { Title graphql.String } cannot hold GraphQL type [Issue]`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d issues:\n%q\nwant %d:\n%q", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("issue %d:\ngot:  %s\nwant: %s", i, got[i], want[i])
+		}
+	}
+	for _, i := range issues {
+		if i.Op == nil {
+			t.Errorf("got nil Op for issue: %v", i)
+		}
+	}
+}
+
+func TestCheckCompatibility_fieldAfterFragment(t *testing.T) {
+	schema := []byte(`
+		type Query {
+			viewer: User!
+		}
+		interface Node {
+			id: ID!
+		}
+		type User implements Node {
+			id: ID!
+			login: String!
+		}
+	`)
+	var q struct {
+		Viewer struct {
+			Node struct {
+				ID graphql.ID
+			} `graphql:"... on Node"`
+			Login graphql.Int
+		}
+	}
+	issues := graphql.CheckCompatibility(schema, graphql.NewQuery(&q, nil))
+	want := `type mismatch: viewer.login: Go type graphql.Int cannot hold GraphQL type String!`
+	if len(issues) != 1 || issues[0].String() != want {
+		t.Errorf("got issues: %v, want: %s", issues, want)
+	}
+}
+
+func TestCheckCompatibility_invalidSchema(t *testing.T) {
+	issues := graphql.CheckCompatibility([]byte(`type Query { a: Foo }`))
+	if len(issues) != 1 || issues[0].Kind != graphql.InvalidSchema {
+		t.Fatalf("got issues: %v, want 1 invalid schema issue", issues)
+	}
+}
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnStats are statistics of the connections used by the HTTP requests of
+// an operation, reported to observers in OperationEvent. Across operations,
+// Reused divided by Requests is the rate at which keep-alive connections
+// are reused, as tuned with WithKeepAlive.
+type ConnStats struct {
+	Requests int           // HTTP requests sent, including retries and redirects.
+	Reused   int           // Requests sent on connections kept alive from earlier requests.
+	DNS      time.Duration // Time spent resolving host names.
+	Connect  time.Duration // Time spent establishing TCP connections.
+	TLS      time.Duration // Time spent in TLS handshakes.
+}
+
+// connTracer collects the ConnStats of requests sent with the context
+// it's traced in.
+type connTracer struct {
+	clock Clock
+
+	mu       sync.Mutex
+	stats    ConnStats
+	dns, tls time.Time
+	connect  map[string]time.Time // Address -> start of connecting to it.
+}
+
+// trace returns ctx with HTTP requests sent with it traced by t.
+func (t *connTracer) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.stats.Requests++
+			if info.Reused {
+				t.stats.Reused++
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = t.clock.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.stats.DNS += t.clock.Now().Sub(t.dns)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connect == nil {
+				t.connect = make(map[string]time.Time)
+			}
+			t.connect[network+" "+addr] = t.clock.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if start, ok := t.connect[network+" "+addr]; ok {
+				t.stats.Connect += t.clock.Now().Sub(start)
+				delete(t.connect, network+" "+addr)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = t.clock.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.stats.TLS += t.clock.Now().Sub(t.tls)
+		},
+	})
+}
+
+// collected returns the statistics collected so far.
+func (t *connTracer) collected() ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// RedactPolicy reports whether the value of the header with canonical
+// name header is redacted from debugging output, such as AsCurl's
+// and HAR recordings.
+type RedactPolicy func(header string) bool
+
+// DefaultRedactPolicy is the redaction policy clients use unless
+// WithRedactPolicy is given. It redacts the Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie headers, and those with names
+// containing "Token", "Secret", "Key", "Password" or "Session",
+// such as X-Api-Key.
+func DefaultRedactPolicy(header string) bool {
+	switch header {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+		return true
+	}
+	for _, s := range []string{"Token", "Secret", "Key", "Password", "Session"} {
+		if strings.Contains(header, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRedactPolicy sets the policy of the headers redacted from debugging
+// output, such as AsCurl's. The default is DefaultRedactPolicy.
+func WithRedactPolicy(p RedactPolicy) ClientOption {
+	return func(c *Client) {
+		c.redact = p
+	}
+}
+
+// redacted is the value of redacted headers.
+const redacted = "[REDACTED]"
+
+// AsCurl returns a curl command sending the request of op, with its body
+// pretty-printed, so the request can be reproduced outside the program.
+// It includes the headers set by op and the client, with the values of
+// those of the client's redaction policy redacted, but not those set by
+// the HTTP client, such as credentials added by its transport. Operations
+// with uploads, and clients not using JSONCodec, aren't supported.
+func (c *Client) AsCurl(ctx context.Context, op Operation) (string, error) {
+	if _, ok := c.codec.(JSONCodec); !ok {
+		return "", errors.New("graphql: cannot make curl command with codec other than JSONCodec")
+	}
+	if c.autoName {
+		op = nameOperation(op)
+	}
+	in, endpoint, err := c.request(ctx, op)
+	if err != nil {
+		return "", err
+	}
+	if len(findUploads(in.Variables)) > 0 {
+		return "", errors.New("graphql: cannot make curl command of operation with uploads")
+	}
+	if id := c.documentID(op, in.Query); id != "" {
+		in.Query, in.DocumentID = "", id
+	}
+	in.Variables = c.stringifyInt64s(omitAbsent(in.Variables))
+	body, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mediaTypeJSON)
+	if c.strict {
+		setStrictHeaders(req)
+	}
+	c.setDeadlineHeader(ctx, req)
+	c.modifier(op)(req)
+	redact := c.redact
+	if redact == nil {
+		redact = DefaultRedactPolicy
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("curl -X POST " + shellQuote(endpoint))
+	for _, name := range names {
+		for _, v := range req.Header[name] {
+			if redact(http.CanonicalHeaderKey(name)) {
+				v = redacted
+			}
+			b.WriteString(" \\\n  -H " + shellQuote(name+": "+v))
+		}
+	}
+	b.WriteString(" \\\n  --data-raw " + shellQuote(string(body)))
+	return b.String(), nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// AsCurl returns a curl command sending the request of op with c,
+// as Client.AsCurl does.
+func (op *Query) AsCurl(c *Client) (string, error) {
+	return c.AsCurl(context.Background(), op)
+}
+
+// AsCurl returns a curl command sending the request of op with c,
+// as Client.AsCurl does.
+func (op *Mutation) AsCurl(c *Client) (string, error) {
+	return c.AsCurl(context.Background(), op)
+}
+
+// AsCurl returns a curl command sending the request of op with c,
+// as Client.AsCurl does.
+func (op *Static) AsCurl(c *Client) (string, error) {
+	return c.AsCurl(context.Background(), op)
+}
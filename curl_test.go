@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_AsCurl(t *testing.T) {
+	client := graphql.NewClient("https://example.com/graphql", nil)
+
+	var q struct {
+		User struct {
+			Name graphql.String
+		} `graphql:"user(login: $login)"`
+	}
+	op := &graphql.Query{
+		Data: &q,
+		Vars: map[string]interface{}{"login": graphql.String("o'brien")},
+		Name: "User",
+		RequestHandler: func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Api-Key", "secret")
+			req.Header.Set("X-Trace", "abc")
+		},
+	}
+	got, err := op.AsCurl(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `curl -X POST 'https://example.com/graphql' \
+  -H 'Authorization: [REDACTED]' \
+  -H 'Content-Type: application/json' \
+  -H 'X-Api-Key: [REDACTED]' \
+  -H 'X-Trace: abc' \
+  --data-raw '{
+  "query": "query User($login:String!){user(login: $login){name}}",
+  "variables": {
+    "login": "o'\''brien"
+  }
+}'`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if strings.Contains(got, "secret") {
+		t.Error("got secret in curl command")
+	}
+}
+
+func TestWithRedactPolicy(t *testing.T) {
+	client := graphql.NewClient("https://example.com/graphql", nil, graphql.WithRedactPolicy(func(header string) bool {
+		return header == "X-Trace"
+	}))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	got, err := client.AsCurl(context.Background(), &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Trace", "abc")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `-H 'Authorization: Bearer token'`) || !strings.Contains(got, `-H 'X-Trace: [REDACTED]'`) {
+		t.Errorf("got:\n%s\nwant Authorization kept and X-Trace redacted", got)
+	}
+}
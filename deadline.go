@@ -0,0 +1,37 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithDeadlineHeader makes the client send the time remaining until the
+// deadline of an operation's context, in whole milliseconds, in header,
+// such as "X-Request-Timeout-Ms", so gateways and servers that honor it
+// can stop working on requests the client has given up on. The header
+// isn't sent for contexts without a deadline. A RequestHandler can
+// override it.
+func WithDeadlineHeader(header string) ClientOption {
+	return func(c *Client) {
+		c.deadlineHeader = http.CanonicalHeaderKey(header)
+	}
+}
+
+// setDeadlineHeader sets the deadline header of c on req, if c has one
+// and ctx has a deadline.
+func (c *Client) setDeadlineHeader(ctx context.Context, req *http.Request) {
+	if c.deadlineHeader == "" {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	remaining := deadline.Sub(c.clock.Now())
+	if remaining < 0 {
+		remaining = 0
+	}
+	req.Header.Set(c.deadlineHeader, strconv.FormatInt(int64(remaining/time.Millisecond), 10))
+}
@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestWithDeadlineHeader(t *testing.T) {
+	var got []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, req.Header.Get("X-Request-Timeout-Ms"))
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	now := time.Now()
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithDeadlineHeader("x-request-timeout-ms"), graphql.WithClock(graphqltest.NewClock(now)))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(1500*time.Millisecond+500*time.Microsecond))
+	defer cancel()
+	if err := client.Query(ctx, &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Run(ctx, &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+		req.Header.Set("X-Request-Timeout-Ms", "100")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1500", "", "100"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got headers %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// parallelDecodeSize is the size of response data from which its
+// top-level fields are decoded concurrently.
+const parallelDecodeSize = 64 << 10
+
+// decodeParallel decodes response data into query data structure v with
+// unmarshal, decoding the values of its top-level fields, such as the
+// aliases of a batched query, concurrently, with up to GOMAXPROCS
+// goroutines. It reports false if the data can't be decoded that way,
+// because it's small, or v's top-level fields don't map one to one to
+// the data's.
+func decodeParallel(data []byte, v interface{}, unmarshal func([]byte, interface{}) error) (bool, error) {
+	if len(data) < parallelDecodeSize {
+		return false, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return false, nil
+	}
+	fields, ok := topLevelFields(rv.Elem())
+	if !ok {
+		return false, nil
+	}
+	keys, values, ok := splitObject(data)
+	if !ok || len(keys) < 2 {
+		return false, nil
+	}
+	targets := make([]reflect.Value, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		f, ok := fields[key]
+		if !ok || seen[key] {
+			// Let jsonutil report unknown fields, and merge duplicate ones.
+			return false, nil
+		}
+		seen[key] = true
+		targets[i] = f
+	}
+
+	errs := make([]error, len(keys))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = decodeField(unmarshal, values[i], targets[i])
+			}
+		}()
+	}
+	for i := range keys {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// decodeField decodes data into field f with unmarshal, converting a panic,
+// which would crash the program in a goroutine of its own, to an error.
+func decodeField(unmarshal func([]byte, interface{}) error, data []byte, f reflect.Value) (err error) {
+	defer recoverPanic(&err)
+	return unmarshal(data, f.Addr().Interface())
+}
+
+// topLevelFields returns the fields of struct v by their response keys.
+// It reports false if v has fields that are inlined, such as fragments,
+// or hold unknown data, which need decoding together.
+func topLevelFields(v reflect.Value) (map[string]reflect.Value, bool) {
+	t := v.Type()
+	fields := make(map[string]reflect.Value, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("graphql") == jsonutil.FallbackTag {
+			return nil, false
+		}
+		key, inline, ok := responseKey(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			return nil, false
+		}
+		if _, dup := fields[key]; dup {
+			return nil, false
+		}
+		fields[key] = v.Field(i)
+	}
+	return fields, true
+}
+
+// splitObject returns the keys and raw values of JSON object data,
+// in order. It reports false if data isn't an object.
+func splitObject(data []byte) (keys []string, values []json.RawMessage, ok bool) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := d.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil, false
+	}
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return nil, nil, false
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	if _, err := d.Token(); err != nil {
+		return nil, nil, false
+	}
+	if _, err := d.Token(); err == nil {
+		// Trailing data, which jsonutil reports.
+		return nil, nil, false
+	}
+	return keys, values, true
+}
+
+// maxPooledBuffer is the capacity of the largest response buffer that's
+// kept for reuse, so buffers of occasional huge responses don't pin memory.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// WithPooledDecoding makes the client reuse the buffers it reads responses
+// into, and the state it decodes their data into query data structures
+// with, across operations. It reduces allocations in services decoding
+// thousands of responses per second, at the cost of keeping pooled memory
+// around between them.
+//
+// Responses decoded with JSONCodec are parsed with json.Unmarshal, which
+// rejects responses with data after the JSON object that the default mode
+// ignores. Values in query data structures never refer to pooled memory,
+// provided the UnmarshalJSON methods of their scalar types copy the data
+// they're passed if they retain it, as json.Unmarshaler requires. Other
+// codecs can't retain the buffers either, as they're passed an io.Reader.
+func WithPooledDecoding() ClientOption {
+	return func(c *Client) {
+		c.pooled = true
+	}
+}
+
+// decodePooled decodes a response from r into resp with codec, reading
+// it into a pooled buffer.
+func decodePooled(codec Codec, r io.Reader, resp *Response) error {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+	if _, ok := codec.(JSONCodec); ok {
+		// It copies raw members, such as resp.Data, out of buf.
+		return json.Unmarshal(buf.Bytes(), resp)
+	}
+	return codec.DecodeResponse(bytes.NewReader(buf.Bytes()), resp)
+}
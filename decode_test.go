@@ -0,0 +1,132 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+type repo struct {
+	Name  string
+	Stars int
+	Tags  []string
+}
+
+// batchedQuery has many independent top-level aliases.
+type batchedQuery struct {
+	R0 *repo `graphql:"r0: repository(id: 0)"`
+	R1 repo  `graphql:"r1: repository(id: 1)"`
+	R2 repo  `graphql:"r2: repository(id: 2)"`
+	R3 repo  `graphql:"r3: repository(id: 3)"`
+	R4 *repo `graphql:"r4: repository(id: 4)"`
+}
+
+// batchedData returns data for batchedQuery, large enough to be decoded
+// in parallel.
+func batchedData(r3 string) string {
+	tags := `"` + strings.Repeat("tag", 1000) + `"`
+	for i := 0; i < 5; i++ {
+		tags += `, "` + strings.Repeat("t", 5000) + `"`
+	}
+	var fields []string
+	for i := 0; i < 3; i++ {
+		fields = append(fields, fmt.Sprintf(`"r%d": {"name": "repo%d", "stars": %d, "tags": [%s]}`, i, i, i*10, tags))
+	}
+	fields = append(fields, `"r3": `+r3, `"r4": null`)
+	return `{` + strings.Repeat(" ", 64<<10) + strings.Join(fields, ", ") + `}`
+}
+
+func TestClient_Query_parallelDecode(t *testing.T) {
+	data := batchedData(`{"name": "repo3", "stars": 30, "tags": []}`)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": `+data+`}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var got batchedQuery
+	if err := client.Query(context.Background(), &got, nil); err != nil {
+		t.Fatal(err)
+	}
+	var want batchedQuery
+	if err := jsonutil.UnmarshalGraphQL([]byte(data), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.R0 == nil || got.R0.Name != "repo0" || got.R3.Stars != 30 || got.R4 != nil {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestClient_Query_parallelDecodeError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": `+batchedData(`{"name": "repo3", "unknown": 1}`)+`}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q batchedQuery
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil || !strings.Contains(err.Error(), `"unknown"`) {
+		t.Errorf("got error: %v", err)
+	}
+}
+
+func TestWithPooledDecoding(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(body, "r0"):
+			mustWrite(w, `{"data": `+batchedData(`{"name": "repo3", "stars": 30}`)+`}`)
+		case strings.Contains(body, "bad"):
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}} {}`)
+		default:
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}, "errors": [{"message": "partial"}]}`)
+		}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithPooledDecoding())
+
+	for i := 0; i < 3; i++ {
+		var q struct {
+			Viewer struct {
+				Login string
+			}
+		}
+		err := client.Query(context.Background(), &q, nil)
+		if got, want := fmt.Sprint(err), "partial"; got != want {
+			t.Errorf("got error %q, want %q", got, want)
+		}
+		if got, want := q.Viewer.Login, "gopher"; got != want {
+			t.Errorf("got login %q, want %q", got, want)
+		}
+
+		var batched batchedQuery
+		if err := client.Query(context.Background(), &batched, nil); err != nil {
+			t.Fatal(err)
+		}
+		if batched.R0 == nil || batched.R0.Name != "repo0" || batched.R3.Stars != 30 {
+			t.Errorf("got %+v", batched)
+		}
+	}
+
+	var bad struct {
+		Viewer struct {
+			Login string
+		} `graphql:"viewer(bad: true)"`
+	}
+	if err := client.Query(context.Background(), &bad, nil); err == nil {
+		t.Error("got no error for data after the response")
+	}
+}
@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy is how an operation decorated by WithRetry is retried.
+// Operations with uploads are only retried if the bodies of all their
+// uploads are io.Seekers, as the bodies are read by each attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the operation is run at most,
+	// including the first. If less than 1, DefaultRetryAttempts is used.
+	MaxAttempts int
+
+	// Backoff is how long to wait before each retry, measured with the
+	// client's Clock. If nil, DefaultRetryBackoff is used.
+	Backoff Backoff
+
+	// Retryable reports whether a run that failed with err is retried.
+	// If nil, IsRetryable is used. Responses with GraphQL errors are
+	// not failures, and aren't retried.
+	Retryable func(err error) bool
+}
+
+// Defaults of RetryPolicy.
+var (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+)
+
+// IsRetryable reports whether err is an error that may not recur if the
+// operation is run again: a *StatusError of a retryable status code, or
+// an error sending the request other than its context being done.
+func IsRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, ErrFaultDropped)
+}
+
+// WithRetry returns op decorated to be run again by clients, as policy
+// says, when it fails, rather than only where client-wide retries apply.
+// Retrying mutations that aren't idempotent may apply them repeatedly.
+func WithRetry(op Operation, policy RetryPolicy) Operation {
+	return &retried{Operation: op, policy: policy}
+}
+
+type retried struct {
+	Operation
+	policy RetryPolicy
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *retried) Unwrap() Operation {
+	return op.Operation
+}
+
+// retry runs run, until it succeeds, as policy p says.
+func (c *Client) retry(ctx context.Context, p RetryPolicy, run func() (Response, error)) (out Response, err error) {
+	attempts, backoff, retryable := p.MaxAttempts, p.Backoff, p.Retryable
+	if attempts < 1 {
+		attempts = DefaultRetryAttempts
+	}
+	if backoff == nil {
+		backoff = DefaultRetryBackoff
+	}
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	for attempt := 1; ; attempt++ {
+		out, err = run()
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return out, err
+		}
+		if err := c.sleep(ctx, backoff(attempt)); err != nil {
+			return out, err
+		}
+	}
+}
+
+// WithCacheTTL returns op decorated so that its response is cached for
+// ttl, rather than the TTL given to WithCache, by clients created with
+// WithCache. It has no effect on other clients, or operations that
+// aren't cached.
+func WithCacheTTL(op Operation, ttl time.Duration) Operation {
+	return &cachedFor{Operation: op, ttl: ttl}
+}
+
+type cachedFor struct {
+	Operation
+	ttl time.Duration
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *cachedFor) Unwrap() Operation {
+	return op.Operation
+}
+
+// ttlOf returns the TTL of the cached response of op.
+func (c *Client) ttlOf(op Operation) time.Duration {
+	if op, ok := asOperation[*cachedFor](op); ok {
+		return op.ttl
+	}
+	return c.cacheTTL
+}
+
+// WithHeadersOp returns op decorated to send its requests with headers h,
+// replacing the values of headers with the same names set by op.
+func WithHeadersOp(op Operation, h http.Header) Operation {
+	return &withHeaders{Operation: op, header: h}
+}
+
+type withHeaders struct {
+	Operation
+	header http.Header
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *withHeaders) Unwrap() Operation {
+	return op.Operation
+}
+
+// ModifyRequest modifies req as the wrapped operation does, then sets
+// the headers of op.
+func (op *withHeaders) ModifyRequest(req *http.Request) {
+	op.Operation.ModifyRequest(req)
+	for k, v := range op.header {
+		req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+}
@@ -0,0 +1,175 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestWithRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		want     int // Requests.
+	}{
+		{"succeeds", []int{503, 502, 200}, false, 3},
+		{"exhausted", []int{503, 503, 503, 200}, true, 3},
+		{"not retryable", []int{500, 200}, true, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+				status := tc.statuses[requests]
+				requests++
+				if req.Header.Get("X-Trace") != "abc" {
+					t.Errorf("got X-Trace header %q, want %q", req.Header.Get("X-Trace"), "abc")
+				}
+				if status != http.StatusOK {
+					http.Error(w, "unavailable", status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+			})
+			client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+			var q struct {
+				Viewer struct {
+					Login graphql.String
+				}
+			}
+			op := graphql.WithHeadersOp(graphql.NewQuery(&q, nil), http.Header{"X-Trace": {"abc"}})
+			op = graphql.WithRetry(op, graphql.RetryPolicy{MaxAttempts: 3, Backoff: graphql.ConstantBackoff(0)})
+			err := client.Run(context.Background(), op)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tc.wantErr)
+			}
+			var statusErr *graphql.StatusError
+			if tc.wantErr && !errors.As(err, &statusErr) {
+				t.Errorf("got error %v, want a *graphql.StatusError", err)
+			}
+			if !tc.wantErr && q.Viewer.Login != "gopher" {
+				t.Errorf("got login %q, want %q", q.Viewer.Login, "gopher")
+			}
+			if requests != tc.want {
+				t.Errorf("got %d requests, want %d", requests, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry_upload(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     io.Reader
+		wantErr  bool
+		wantSent []bool // Whether each request has the file contents.
+	}{
+		{"seeker", strings.NewReader("hello world"), false, []bool{true, true}},
+		{"reader", struct{ io.Reader }{strings.NewReader("hello world")}, true, []bool{true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent []bool
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+				sent = append(sent, strings.Contains(mustRead(req.Body), "hello world"))
+				if len(sent) == 1 {
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				mustWrite(w, `{"data": {"upload": {"ok": true}}}`)
+			})
+			client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+			var m struct {
+				Upload struct {
+					OK graphql.Boolean
+				} `graphql:"upload(file: $file)"`
+			}
+			op := graphql.NewMutation(&m, map[string]interface{}{
+				"file": graphql.Upload{Name: "hello.txt", Body: tc.body},
+			})
+			err := client.Run(context.Background(), graphql.WithRetry(op, graphql.RetryPolicy{Backoff: graphql.ConstantBackoff(0)}))
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(sent, tc.wantSent) {
+				t.Errorf("got file sent in requests %v, want %v", sent, tc.wantSent)
+			}
+		})
+	}
+}
+
+func TestWithCacheTTL(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	clock := graphqltest.NewClock(time.Now())
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithClock(clock), graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	run := func() {
+		t.Helper()
+		if err := client.Run(context.Background(), graphql.WithCacheTTL(graphql.NewQuery(&q, nil), time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run()
+	clock.Advance(30 * time.Minute)
+	run()
+	if requests != 1 {
+		t.Errorf("got %d requests before the TTL, want 1", requests)
+	}
+	clock.Advance(time.Hour)
+	run()
+	if requests != 2 {
+		t.Errorf("got %d requests after the TTL, want 2", requests)
+	}
+}
+
+func TestWithHeadersOp(t *testing.T) {
+	var got http.Header
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	op := &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+		req.Header.Set("X-Tenant", "a")
+		req.Header.Set("X-Kept", "yes")
+	}}
+	if err := client.Run(context.Background(), graphql.WithHeadersOp(op, http.Header{"x-tenant": {"b"}})); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Tenant") != "b" || got.Get("X-Kept") != "yes" {
+		t.Errorf("got headers %v, want X-Tenant b and X-Kept yes", got)
+	}
+}
@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Difference is a difference between two GraphQL responses.
+type Difference struct {
+	// Path is the path of the differing value, such as
+	// "data.repository.issues[2].title" or "errors[0].message".
+	Path string
+
+	// A and B are the values in each response, or nil if absent.
+	A, B json.RawMessage
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Path, diffValue(d.A), diffValue(d.B))
+}
+
+func diffValue(v json.RawMessage) string {
+	if v == nil {
+		return "(absent)"
+	}
+	return string(v)
+}
+
+// Compare runs op against the servers targeted by clients a and b, such
+// as a GraphQL server and the gateway replacing it, or two versions of a
+// schema, and returns the differences between their responses, in order
+// of path. The data of the responses are compared, and so are the
+// messages and paths of their GraphQL errors; extensions aren't.
+//
+// The data of a's response is decoded into op.ResponsePtr(), as by
+// Client.Run; b's isn't decoded. An error is returned if op can't be
+// run against either server.
+func Compare(ctx context.Context, a, b *Client, op Operation) ([]Difference, error) {
+	var outs [2]Response
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i, run := range []func() (Response, error){
+		func() (Response, error) { return a.do(ctx, op) },
+		func() (Response, error) { return b.do(ctx, &undecoded{op}) },
+	} {
+		wg.Add(1)
+		go func(i int, run func() (Response, error)) {
+			defer wg.Done()
+			outs[i], errs[i] = run()
+		}(i, run)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("running against %c: %w", 'a'+i, err)
+		}
+	}
+	return diffCompared(outs[0], outs[1])
+}
+
+// diffCompared returns the differences between the parts of responses
+// a and b Compare compares.
+func diffCompared(a, b Response) ([]Difference, error) {
+	va, err := compared(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := compared(b)
+	if err != nil {
+		return nil, err
+	}
+	return DiffResponses(va, vb)
+}
+
+// compared returns the JSON of the parts of out Compare compares.
+func compared(out Response) ([]byte, error) {
+	type comparedError struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path,omitempty"`
+	}
+	v := struct {
+		Data   json.RawMessage `json:"data,omitempty"`
+		Errors []comparedError `json:"errors,omitempty"`
+	}{Data: out.Data}
+	for _, e := range out.Errors {
+		v.Errors = append(v.Errors, comparedError{e.Message, e.Path})
+	}
+	return json.Marshal(v)
+}
+
+// DiffResponses returns the differences between the JSON of GraphQL
+// responses a and b, such as ones recorded from two servers, in order
+// of path. Objects are compared by key, and lists by index.
+func DiffResponses(a, b []byte) ([]Difference, error) {
+	va, err := decodeDiffJSON(a)
+	if err != nil {
+		return nil, fmt.Errorf("decoding a: %v", err)
+	}
+	vb, err := decodeDiffJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("decoding b: %v", err)
+	}
+	var diffs []Difference
+	diffJSON(&diffs, "", va, vb, true, true)
+	return diffs, nil
+}
+
+func decodeDiffJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// diffJSON appends the differences between decoded JSON values a and b
+// at path to diffs. okA and okB report whether a and b are present.
+func diffJSON(diffs *[]Difference, path string, a, b interface{}, okA, okB bool) {
+	if okA && okB {
+		oa, objA := a.(map[string]interface{})
+		ob, objB := b.(map[string]interface{})
+		la, listA := a.([]interface{})
+		lb, listB := b.([]interface{})
+		switch {
+		case objA && objB:
+			keys := make([]string, 0, len(oa)+len(ob))
+			for k := range oa {
+				keys = append(keys, k)
+			}
+			for k := range ob {
+				if _, ok := oa[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				va, okA := oa[k]
+				vb, okB := ob[k]
+				diffJSON(diffs, joinPath(path, k), va, vb, okA, okB)
+			}
+			return
+		case listA && listB:
+			for i := 0; i < len(la) || i < len(lb); i++ {
+				var va, vb interface{}
+				okA, okB := i < len(la), i < len(lb)
+				if okA {
+					va = la[i]
+				}
+				if okB {
+					vb = lb[i]
+				}
+				diffJSON(diffs, path+"["+strconv.Itoa(i)+"]", va, vb, okA, okB)
+			}
+			return
+		case !objA && !listA && a == b:
+			return
+		}
+	}
+	d := Difference{Path: path}
+	if okA {
+		d.A, _ = json.Marshal(a)
+	}
+	if okB {
+		d.B, _ = json.Marshal(b)
+	}
+	*diffs = append(*diffs, d)
+}
+
+// undecoded is an operation whose response data isn't decoded.
+type undecoded struct {
+	Operation
+}
+
+func (op *undecoded) ResponsePtr() interface{} {
+	return discardData{}
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *undecoded) Unwrap() Operation {
+	return op.Operation
+}
+
+// discardData is query data discarding response data.
+type discardData struct{}
+
+func (discardData) DecodeGraphQL([]byte) error { return nil }
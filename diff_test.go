@@ -0,0 +1,95 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestCompare(t *testing.T) {
+	newClient := func(resp string) *graphql.Client {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			mustRead(req.Body)
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(w, resp)
+		})
+		return graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	}
+	a := newClient(`{"data": {"user": {"name": "Gopher", "tags": ["a", "b"], "age": 13}}, "extensions": {"cost": 1}}`)
+	b := newClient(`{"data": {"user": {"name": "Gopher", "tags": ["a"], "age": 14}}, "errors": [{"message": "partial", "path": ["user", "avatar"], "extensions": {"trace": "x"}}], "extensions": {"cost": 2}}`)
+
+	var q struct {
+		User struct {
+			Name graphql.String
+			Tags []graphql.String
+			Age  graphql.Int
+		}
+	}
+	diffs, err := graphql.Compare(context.Background(), a, b, &graphql.Query{Data: &q})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.String())
+	}
+	want := []string{
+		`data.user.age: 13 != 14`,
+		`data.user.tags[1]: "b" != (absent)`,
+		`errors: (absent) != [{"message":"partial","path":["user","avatar"]}]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got differences:\n%q\nwant:\n%q", got, want)
+	}
+	if q.User.Age != 13 {
+		t.Errorf("got age: %v, want a's response decoded", q.User.Age)
+	}
+
+	diffs, err = graphql.Compare(context.Background(), a, a, &graphql.Query{Data: &q})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("got differences between same responses: %v", diffs)
+	}
+}
+
+func TestCompare_error(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	a := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	b := graphql.NewClient("/nope", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	_, err := graphql.Compare(context.Background(), a, b, &graphql.Static{QueryStr: "{viewer{login}}", Into: &struct{}{}})
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+}
+
+func TestDiffResponses(t *testing.T) {
+	diffs, err := graphql.DiffResponses(
+		[]byte(`{"data": {"a": 1, "b": {"c": null}, "d": [1, 2], "e": 1.5}}`),
+		[]byte(`{"data": {"a": 1, "b": {"c": {"x": true}}, "d": [1, 3], "f": "new", "e": 1.5}}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []graphql.Difference{
+		{Path: "data.b.c", A: []byte(`null`), B: []byte(`{"x":true}`)},
+		{Path: "data.d[1]", A: []byte(`2`), B: []byte(`3`)},
+		{Path: "data.f", B: []byte(`"new"`)},
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("got differences:\n%v\nwant:\n%v", diffs, want)
+	}
+
+	if _, err := graphql.DiffResponses([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("got nil error for invalid JSON")
+	}
+}
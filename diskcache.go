@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskCache is a Cache that stores entries as plain files in a directory,
+// with an index file that records their sizes, expiry and last use, so
+// cached responses survive across runs of a program, such as a CLI tool.
+// When the entries exceed the cache's maximum size, the least recently
+// used ones are evicted.
+//
+// Entries and the index are written atomically, and entries are checksummed.
+// Corrupt or unreadable entries are removed and treated as misses, and an index
+// that's corrupt or missing is rebuilt from the entry files. A directory
+// should be used by one DiskCache at a time.
+type DiskCache struct {
+	dir     string
+	maxSize int64
+	clock   Clock
+
+	mu    sync.Mutex
+	index map[string]*diskEntry // File name -> entry.
+	size  int64                 // Total size of the entry files.
+	dirty bool                  // Whether index has changes not written to the index file.
+}
+
+type diskEntry struct {
+	Size    int64     `json:"size"`
+	Expires time.Time `json:"expires,omitempty"`
+	Used    time.Time `json:"used"`
+}
+
+const (
+	diskIndexFile = "index.json"
+	diskEntryExt  = ".entry"
+	diskMagic     = "gqc1"
+	diskHeaderLen = len(diskMagic) + 8 + 4 // Magic, expiry in Unix nanoseconds, CRC-32 of the data.
+)
+
+// NewDiskCache returns a DiskCache that stores entries in dir, creating
+// it if needed, evicting entries once their total size exceeds maxSize
+// bytes. There's no maximum size if maxSize is zero.
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	d := &DiskCache{dir: dir, maxSize: maxSize, clock: systemClock{}}
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// load reads the index file of d, reconciling it with the entry files
+// in the directory, and rebuilding it if it's corrupt.
+func (d *DiskCache) load() error {
+	d.index = make(map[string]*diskEntry)
+	if b, err := ioutil.ReadFile(filepath.Join(d.dir, diskIndexFile)); err == nil {
+		if err := json.Unmarshal(b, &d.index); err != nil || d.index == nil {
+			d.index = make(map[string]*diskEntry)
+		}
+	}
+	files, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(files))
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(name, ".tmp-") {
+			// Left behind by a write that was interrupted.
+			os.Remove(filepath.Join(d.dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, diskEntryExt) {
+			continue
+		}
+		found[name] = true
+		e, ok := d.index[name]
+		if !ok || e.Size != fi.Size() {
+			// Not in the index, which is rebuilt from the file. Its expiry is read
+			// from its header when it's next read.
+			d.index[name] = &diskEntry{Size: fi.Size(), Used: fi.ModTime()}
+			d.dirty = true
+		}
+	}
+	d.size = 0
+	for name, e := range d.index {
+		if !found[name] {
+			delete(d.index, name)
+			d.dirty = true
+			continue
+		}
+		d.size += e.Size
+	}
+	d.evict()
+	return d.flush()
+}
+
+// diskFileName returns the name of the entry file for key.
+func diskFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + diskEntryExt
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	name := diskFileName(key)
+	e, ok := d.index[name]
+	if !ok {
+		return nil, false
+	}
+	now := d.clock.Now()
+	if !e.Expires.IsZero() && !now.Before(e.Expires) {
+		d.remove(name)
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(filepath.Join(d.dir, name))
+	if err != nil {
+		d.remove(name)
+		return nil, false
+	}
+	data, expires, err := decodeDiskEntry(b)
+	if err != nil || !expires.IsZero() && !now.Before(expires) {
+		d.remove(name)
+		return nil, false
+	}
+	e.Expires, e.Used = expires, now
+	d.dirty = true
+	return data, true
+}
+
+// Set implements Cache.
+func (d *DiskCache) Set(key string, data []byte, ttl time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	b := encodeDiskEntry(data, expires)
+	name := diskFileName(key)
+	if err := d.writeFile(name, b); err != nil {
+		d.remove(name)
+		return
+	}
+	if e, ok := d.index[name]; ok {
+		d.size -= e.Size
+	}
+	d.index[name] = &diskEntry{Size: int64(len(b)), Expires: expires, Used: now}
+	d.size += int64(len(b))
+	d.dirty = true
+	d.evict()
+	d.flush()
+}
+
+// Delete implements Cache.
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.remove(diskFileName(key))
+	d.flush()
+}
+
+// Close writes the index of d, recording when its entries were last used.
+func (d *DiskCache) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.flush()
+}
+
+func (d *DiskCache) setClock(clock Clock) {
+	d.mu.Lock()
+	d.clock = clock
+	d.mu.Unlock()
+}
+
+// remove removes the entry with file name.
+func (d *DiskCache) remove(name string) {
+	os.Remove(filepath.Join(d.dir, name))
+	if e, ok := d.index[name]; ok {
+		d.size -= e.Size
+		delete(d.index, name)
+		d.dirty = true
+	}
+}
+
+// evict removes expired entries, and the least recently used entries
+// while the size of d exceeds its maximum.
+func (d *DiskCache) evict() {
+	if d.maxSize <= 0 || d.size <= d.maxSize {
+		return
+	}
+	now := d.clock.Now()
+	names := make([]string, 0, len(d.index))
+	for name, e := range d.index {
+		if !e.Expires.IsZero() && !now.Before(e.Expires) {
+			d.remove(name)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return d.index[names[i]].Used.Before(d.index[names[j]].Used) })
+	for _, name := range names {
+		if d.size <= d.maxSize {
+			break
+		}
+		d.remove(name)
+	}
+}
+
+// flush writes the index file, if it has changed.
+func (d *DiskCache) flush() error {
+	if !d.dirty {
+		return nil
+	}
+	b, err := json.Marshal(d.index)
+	if err != nil {
+		return err
+	}
+	if err := d.writeFile(diskIndexFile, b); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
+
+// writeFile atomically writes file name in the directory of d.
+func (d *DiskCache) writeFile(name string, b []byte) error {
+	f, err := ioutil.TempFile(d.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(d.dir, name))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// encodeDiskEntry returns the contents of the entry file for data.
+func encodeDiskEntry(data []byte, expires time.Time) []byte {
+	b := make([]byte, diskHeaderLen, diskHeaderLen+len(data))
+	copy(b, diskMagic)
+	if !expires.IsZero() {
+		binary.BigEndian.PutUint64(b[len(diskMagic):], uint64(expires.UnixNano()))
+	}
+	binary.BigEndian.PutUint32(b[len(diskMagic)+8:], crc32.ChecksumIEEE(data))
+	return append(b, data...)
+}
+
+// decodeDiskEntry returns the data and expiry of entry file contents b.
+func decodeDiskEntry(b []byte) (data []byte, expires time.Time, err error) {
+	if len(b) < diskHeaderLen || !bytes.HasPrefix(b, []byte(diskMagic)) {
+		return nil, time.Time{}, errors.New("invalid cache entry header")
+	}
+	data = b[diskHeaderLen:]
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(b[len(diskMagic)+8:]) {
+		return nil, time.Time{}, errors.New("cache entry checksum mismatch")
+	}
+	if ns := binary.BigEndian.Uint64(b[len(diskMagic):]); ns != 0 {
+		expires = time.Unix(0, int64(ns))
+	}
+	return data, expires, nil
+}
@@ -0,0 +1,109 @@
+package graphql_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := graphql.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", []byte(`{"a":1}`), 0)
+	c.Set("b", []byte(`{"b":2}`), time.Hour)
+	c.Set("c", []byte(`{"c":3}`), time.Nanosecond)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries survive across caches using the same directory.
+	c, err = graphql.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := c.Get("a"); !ok || string(data) != `{"a":1}` {
+		t.Errorf("got a: %s, %v", data, ok)
+	}
+	if data, ok := c.Get("b"); !ok || string(data) != `{"b":2}` {
+		t.Errorf("got b: %s, %v", data, ok)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Error("got expired entry c")
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("got deleted entry a")
+	}
+}
+
+func TestDiskCache_evict(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(strings.Repeat("x", 100))
+	c, err := graphql.NewDiskCache(dir, 350) // Room for 3 entries with their headers.
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", data, 0)
+	c.Set("b", data, 0)
+	c.Set("c", data, 0)
+	c.Get("a") // b is now the least recently used.
+	c.Set("d", data, 0)
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("got %s cached: %v, want: %v", key, ok, want)
+		}
+	}
+}
+
+func TestDiskCache_corruption(t *testing.T) {
+	dir := t.TempDir()
+	c, err := graphql.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", []byte(`{"a":1}`), 0)
+	c.Set("b", []byte(`{"b":2}`), 0)
+	c.Close()
+
+	// Corrupt the index and an entry.
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.json"), []byte("{garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(dir, "*.entry"))
+	if len(entries) != 2 {
+		t.Fatalf("got %d entry files, want 2", len(entries))
+	}
+	for _, name := range entries {
+		b, _ := ioutil.ReadFile(name)
+		if strings.HasSuffix(string(b), `{"b":2}`) {
+			b[len(b)-2] = '3'
+			ioutil.WriteFile(name, b, 0o600)
+		}
+	}
+
+	c, err = graphql.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := c.Get("a"); !ok || string(data) != `{"a":1}` {
+		t.Errorf("got a: %s, %v, want entry recovered from rebuilt index", data, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("got corrupt entry b")
+	}
+	entries, _ = filepath.Glob(filepath.Join(dir, "*.entry"))
+	if len(entries) != 1 {
+		t.Errorf("got %d entry files, want corrupt entry removed", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.json")); err != nil {
+		t.Error(err)
+	}
+}
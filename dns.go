@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// WithDNSRefresh makes the client close its idle connections at least every
+// interval, so later requests connect anew to the addresses the server's
+// host name resolves to then, rather than stay on the addresses it resolved
+// to when connections were first made. That spreads requests across the
+// servers of hosts behind weighted or failover DNS records as they change.
+// Connections are closed lazily, before requests, so no background work is
+// started. It has the same transport requirement as WithProxy.
+//
+// Only idle connections are closed: a connection that's always busy, such
+// as an HTTP/2 connection multiplexing a steady stream of requests, or one
+// kept busy by long-running operations, stays open past the interval, on
+// the addresses it was made to. Servers whose clients must move with their
+// DNS records should also limit the age of connections themselves, such as
+// by sending GOAWAY frames or "Connection: close" headers.
+func WithDNSRefresh(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.dnsRefresh = &dnsRefresh{interval: interval}
+	}
+}
+
+// dnsRefresh tracks when a client's connections were last closed by
+// WithDNSRefresh.
+type dnsRefresh struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// refreshDNS closes the idle connections of c if it's been at least the
+// interval of WithDNSRefresh since they were last closed.
+func (c *Client) refreshDNS() {
+	r := c.dnsRefresh
+	if r == nil || c.ownTransport == nil {
+		return
+	}
+	now := c.clock.Now()
+	r.mu.Lock()
+	if r.last.IsZero() {
+		r.last = now
+	}
+	due := now.Sub(r.last) >= r.interval
+	if due {
+		r.last = now
+	}
+	r.mu.Unlock()
+	if due {
+		c.ownTransport.CloseIdleConnections()
+	}
+}
+
+// IPPreference selects the IP versions of the addresses a client connects
+// to, for hosts that resolve to both IPv4 and IPv6 addresses.
+type IPPreference int
+
+const (
+	// HappyEyeballs connects to IPv6 and IPv4 addresses concurrently,
+	// preferring whichever answers first, per RFC 6555. It's the default.
+	HappyEyeballs IPPreference = iota
+
+	PreferIPv4 // Connect to IPv4 addresses, and to IPv6 ones if that fails.
+	PreferIPv6 // Connect to IPv6 addresses, and to IPv4 ones if that fails.
+	IPv4Only   // Connect to IPv4 addresses only.
+	IPv6Only   // Connect to IPv6 addresses only.
+)
+
+// WithIPPreference sets the IP versions of the addresses the client
+// connects to, such as to avoid IPv6 on networks where it's broken.
+// It has the same transport requirement as WithProxy.
+func WithIPPreference(pref IPPreference) ClientOption {
+	return func(c *Client) {
+		c.ipPreference = pref
+	}
+}
+
+// dial returns a function dialing connections with dialer, to addresses
+// selected by pref.
+func dial(dialer *net.Dialer, pref IPPreference) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var first, second string
+	switch pref {
+	case PreferIPv4:
+		first, second = "tcp4", "tcp6"
+	case PreferIPv6:
+		first, second = "tcp6", "tcp4"
+	case IPv4Only:
+		first = "tcp4"
+	case IPv6Only:
+		first = "tcp6"
+	default:
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network != "tcp" {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		conn, err := dialer.DialContext(ctx, first, addr)
+		if err != nil && second != "" && ctx.Err() == nil {
+			if conn, err2 := dialer.DialContext(ctx, second, addr); err2 == nil {
+				return conn, nil
+			}
+		}
+		return conn, err
+	}
+}
@@ -0,0 +1,64 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestWithDNSRefresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer ts.Close()
+
+	clock := graphqltest.NewClock(time.Now())
+	var reused []bool
+	client := graphql.NewClient(ts.URL, nil,
+		graphql.WithClock(clock),
+		graphql.WithDNSRefresh(time.Minute),
+		graphql.WithObserver(func(ctx context.Context, e graphql.OperationEvent) {
+			reused = append(reused, e.Conns.Reused > 0)
+		}),
+	)
+	var q viewerQuery
+	for _, d := range []time.Duration{0, 30 * time.Second, 40 * time.Second, 0} {
+		clock.Advance(d)
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []bool{false, true, false, true}; !reflect.DeepEqual(reused, want) {
+		t.Errorf("got connections reused: %v, want: %v", reused, want)
+	}
+}
+
+func TestWithIPPreference(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		pref    graphql.IPPreference
+		wantErr bool
+	}{
+		{graphql.HappyEyeballs, false},
+		{graphql.PreferIPv4, false},
+		{graphql.PreferIPv6, false},
+		{graphql.IPv4Only, false},
+		{graphql.IPv6Only, true},
+	} {
+		client := graphql.NewClient(ts.URL, nil, graphql.WithIPPreference(tc.pref))
+		var q viewerQuery
+		if err := client.Query(context.Background(), &q, nil); (err != nil) != tc.wantErr {
+			t.Errorf("preference %d: got error: %v, want error: %v", tc.pref, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package graphql
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+)
+
+// DefaultDocumentCacheSize is the default maximum number of query
+// documents kept by the document cache.
+const DefaultDocumentCacheSize = 4096
+
+// documents caches the query documents constructed from query data
+// structures, so they're only constructed once per structure.
+var documents = newDocumentCache(DefaultDocumentCacheSize)
+
+// DocumentCacheStats are statistics of the cache of query documents
+// constructed from query data structures by NewQuery and NewMutation.
+type DocumentCacheStats struct {
+	Entries   int    // Documents in the cache.
+	Unique    int    // Distinct documents in the cache, each held in memory once.
+	Bytes     int    // Total size of the distinct documents.
+	Limit     int    // Maximum number of documents, or 0 if unlimited.
+	Hits      uint64 // Documents found in the cache.
+	Misses    uint64 // Documents constructed because they weren't in the cache.
+	Evictions uint64 // Documents evicted as the least recently used.
+}
+
+// HitRate returns the fraction of documents found in the cache,
+// or 0 if none were looked up.
+func (s DocumentCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// DocumentCacheStatistics returns the statistics of the document cache.
+func DocumentCacheStatistics() DocumentCacheStats {
+	return documents.stats()
+}
+
+// SetDocumentCacheSize sets the maximum number of query documents kept by
+// the document cache, evicting the least recently used ones beyond it, or
+// removes the maximum if size is 0. Programs constructing many distinct
+// operation types, such as ones built at run time, may lower it to bound
+// memory use, or raise it to avoid constructing documents repeatedly.
+// The default is DefaultDocumentCacheSize.
+func SetDocumentCacheSize(size int) {
+	documents.setLimit(size)
+}
+
+// docKey identifies a document constructed from a query data structure.
+type docKey struct {
+	t    reflect.Type
+	op   string // "query" or "mutation".
+	name string // Operation name.
+	args string // Variable definitions.
+	dirs string // Directives of the operation.
+}
+
+type docEntry struct {
+	key docKey
+	doc string
+}
+
+// internedDoc is a document held in memory once, for all the entries with it.
+type internedDoc struct {
+	doc  string
+	refs int
+}
+
+// documentCache is an LRU cache of query documents. Identical documents
+// constructed from distinct types, such as anonymous structs of the same
+// shape in different functions, are interned, so they share memory.
+type documentCache struct {
+	mu       sync.Mutex
+	limit    int
+	lru      *list.List // Of *docEntry, the most recently used first.
+	entries  map[docKey]*list.Element
+	interned map[string]*internedDoc
+	bytes    int
+
+	hits, misses, evictions uint64
+}
+
+func newDocumentCache(limit int) *documentCache {
+	return &documentCache{
+		limit:    limit,
+		lru:      list.New(),
+		entries:  make(map[docKey]*list.Element),
+		interned: make(map[string]*internedDoc),
+	}
+}
+
+// get returns the document for key, constructing it with construct
+// if it isn't cached.
+func (c *documentCache) get(key docKey, construct func() string) string {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(e)
+		doc := e.Value.(*docEntry).doc
+		c.mu.Unlock()
+		return doc
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Construct the document without holding the lock, as it may be slow.
+	doc := construct()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		// Constructed concurrently.
+		c.lru.MoveToFront(e)
+		return e.Value.(*docEntry).doc
+	}
+	doc = c.intern(doc)
+	c.entries[key] = c.lru.PushFront(&docEntry{key: key, doc: doc})
+	c.evict()
+	return doc
+}
+
+// intern returns the interned copy of doc, adding a reference to it.
+func (c *documentCache) intern(doc string) string {
+	if in, ok := c.interned[doc]; ok {
+		in.refs++
+		return in.doc
+	}
+	c.interned[doc] = &internedDoc{doc: doc, refs: 1}
+	c.bytes += len(doc)
+	return doc
+}
+
+// release removes a reference to interned document doc.
+func (c *documentCache) release(doc string) {
+	in := c.interned[doc]
+	if in.refs--; in.refs == 0 {
+		delete(c.interned, doc)
+		c.bytes -= len(doc)
+	}
+}
+
+// evict evicts the least recently used documents beyond the limit.
+func (c *documentCache) evict() {
+	for c.limit > 0 && c.lru.Len() > c.limit {
+		e := c.lru.Back()
+		de := c.lru.Remove(e).(*docEntry)
+		delete(c.entries, de.key)
+		c.release(de.doc)
+		c.evictions++
+	}
+}
+
+func (c *documentCache) setLimit(limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limit = limit
+	c.evict()
+}
+
+func (c *documentCache) stats() DocumentCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return DocumentCacheStats{
+		Entries:   c.lru.Len(),
+		Unique:    len(c.interned),
+		Bytes:     c.bytes,
+		Limit:     c.limit,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
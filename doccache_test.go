@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type loginQueryA struct {
+	Viewer struct{ Login string }
+}
+
+type loginQueryB struct {
+	Viewer struct{ Login string }
+}
+
+func TestDocumentCache(t *testing.T) {
+	c := newDocumentCache(2)
+	get := func(v interface{}, op string) string {
+		return c.get(docKey{t: reflect.TypeOf(v), op: op}, func() string { return op + query(v) })
+	}
+
+	// Distinct types of the same shape, with identical documents.
+	for i := 0; i < 3; i++ {
+		for _, v := range []interface{}{loginQueryA{}, loginQueryB{}} {
+			if got, want := get(v, "query"), "query{viewer{login}}"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		}
+	}
+	want := DocumentCacheStats{Entries: 2, Unique: 1, Bytes: len("query{viewer{login}}"), Limit: 2, Hits: 4, Misses: 2}
+	if got := c.stats(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+	if got, want := c.stats().HitRate(), 4.0/6; got != want {
+		t.Errorf("got hit rate %v, want %v", got, want)
+	}
+
+	// Another document evicts the least recently used one, loginQueryA's.
+	get(loginQueryA{}, "mutation")
+	want = DocumentCacheStats{Entries: 2, Unique: 2, Bytes: len("query{viewer{login}}mutation{viewer{login}}"), Limit: 2, Hits: 4, Misses: 3, Evictions: 1}
+	if got := c.stats(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+	get(loginQueryA{}, "query")
+	if got, want := c.stats().Misses, uint64(4); got != want {
+		t.Errorf("got %v misses, want %v", got, want)
+	}
+
+	// Lowering the limit evicts documents, and releases interned ones.
+	c.setLimit(1)
+	want = DocumentCacheStats{Entries: 1, Unique: 1, Bytes: len("query{viewer{login}}"), Limit: 1, Hits: 4, Misses: 4, Evictions: 3}
+	if got := c.stats(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+}
+
+func TestConstructQuery_cached(t *testing.T) {
+	SetDocumentCacheSize(0)
+	defer SetDocumentCacheSize(DefaultDocumentCacheSize)
+
+	var q struct {
+		Node struct{ ID string } `graphql:"node(id: $id)"`
+	}
+	for i := 0; i < 2; i++ {
+		for _, name := range []string{"", "A"} {
+			got := constructQuery(&q, map[string]interface{}{"id": ID("1")}, name)
+			want := "query($id:ID!){node(id: $id){id}}"
+			if name != "" {
+				want = "query A($id:ID!){node(id: $id){id}}"
+			}
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		}
+	}
+	if s := DocumentCacheStatistics(); s.Limit != 0 || s.Entries < 2 || s.Hits < 2 {
+		t.Errorf("got stats %+v", s)
+	}
+}
+
+func TestVarCheckCacheLimit(t *testing.T) {
+	c := NewClient("/graphql", nil)
+	for i := 0; i < DefaultDocumentCacheSize+10; i++ {
+		query := fmt.Sprintf("query($id: ID!){node(id: $id){id}} # %d", i)
+		if err := c.checkVariables(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := c.varChecks.len(), DefaultDocumentCacheSize; got != want {
+		t.Errorf("got %d cached checks, want %d", got, want)
+	}
+	if err := c.checkVariables("query{node(id: $id){id}}"); err == nil {
+		t.Error("got no error for undefined variable past the limit")
+	}
+}
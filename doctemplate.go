@@ -0,0 +1,330 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// DocumentTemplate is a text/template of a GraphQL document, for the rare
+// documents that must be assembled from strings, such as with fields or
+// enum values chosen at run time. Variables should be used for values
+// wherever GraphQL permits them.
+//
+// Like html/template, it escapes the output of actions for their context
+// in the document, so it can't change the document's structure:
+//
+//   - In string literals, values are escaped as string characters.
+//   - In block strings, values can't contain " or \, as they could combine
+//     with the template's quotes to end the block string.
+//   - In comments, line terminators are replaced by spaces.
+//   - Elsewhere, booleans, numbers and nil are written as GraphQL literals,
+//     and strings as names, such as of fields, types or enum values.
+//     Strings that aren't valid names are an error, as are other values.
+//
+// For example:
+//
+//	tmpl := graphql.MustParseDocumentTemplate(`query($login: String!) {
+//		user(login: $login) { {{.Field}} }
+//		search(query: "{{.Search}}", type: {{.Type}}, first: {{.First}}) { issueCount }
+//	}`)
+type DocumentTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseDocumentTemplate parses text as a DocumentTemplate.
+func ParseDocumentTemplate(text string) (*DocumentTemplate, error) {
+	if strings.ContainsRune(text, templateMarker) {
+		return nil, errors.New("graphql: template contains NUL character")
+	}
+	t, err := template.New("document").Funcs(template.FuncMap{templateEscaper: markValue}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: %v", err)
+	}
+	for _, t := range t.Templates() {
+		if t.Tree != nil {
+			escapeActions(t.Tree.Root)
+		}
+	}
+	return &DocumentTemplate{tmpl: t}, nil
+}
+
+// MustParseDocumentTemplate is like ParseDocumentTemplate, but panics
+// if text can't be parsed. It simplifies initializing global variables.
+func MustParseDocumentTemplate(text string) *DocumentTemplate {
+	t, err := ParseDocumentTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute renders the document of t with data.
+func (t *DocumentTemplate) Execute(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("graphql: %v", err)
+	}
+	return escapeMarkedValues(buf.String())
+}
+
+// StaticTemplate is like Static, but its query document is rendered from
+// Template with Data, the first time it's run. Errors rendering it are
+// returned by the client's methods running it.
+type StaticTemplate struct {
+	Template *DocumentTemplate
+	Data     interface{}
+	Into     interface{}
+	Vars     map[string]interface{}
+
+	// Name is the name of the operation in the document to execute.
+	// It's only sent to the server as the request's operationName
+	// if the client was created with WithOperationNameInBody.
+	Name string
+
+	RequestHandler RequestHandlerFunc
+
+	once  sync.Once
+	query string
+	err   error
+}
+
+// render renders the document of op, once.
+func (op *StaticTemplate) render() error {
+	op.once.Do(func() {
+		op.query, op.err = op.Template.Execute(op.Data)
+	})
+	return op.err
+}
+
+func (op *StaticTemplate) Variables() map[string]interface{} {
+	return op.Vars
+}
+
+// Query returns the document of op, or "" if it can't be rendered.
+func (op *StaticTemplate) Query() string {
+	op.render()
+	return op.query
+}
+
+func (op *StaticTemplate) ResponsePtr() interface{} {
+	return op.Into
+}
+
+func (op *StaticTemplate) OperationName() string {
+	return op.Name
+}
+
+func (op *StaticTemplate) ModifyRequest(req *http.Request) {
+	if op.RequestHandler != nil {
+		op.RequestHandler(req)
+	}
+}
+
+// templateEscaper is the name of the function the output of every
+// template action is passed to.
+const templateEscaper = "_graphqlEscape"
+
+// templateMarker delimits marked values in rendered documents.
+const templateMarker = '\x00'
+
+// escapeActions appends the escaper to the pipelines of the actions in n
+// that write output.
+func escapeActions(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, n := range n.Nodes {
+			escapeActions(n)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(templateEscaper).SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.WithNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	}
+}
+
+// markValue returns v marked for escaping once the document is rendered,
+// and its context known: its kind and text, delimited by templateMarker.
+func markValue(v interface{}) string {
+	kind, text := 'e', ""
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Invalid, reflect.Ptr:
+		kind = 'z'
+	case reflect.Bool:
+		kind, text = 'n', strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		kind, text = 'n', strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		kind, text = 'n', strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			kind, text = 'n', strconv.FormatFloat(f, 'g', -1, rv.Type().Bits())
+		} else {
+			text = fmt.Sprintf("cannot write %v in document", f)
+		}
+	case reflect.String:
+		kind, text = 's', rv.String()
+	default:
+		if s, ok := v.(fmt.Stringer); ok {
+			kind, text = 's', s.String()
+		} else {
+			text = fmt.Sprintf("cannot write value of type %T in document", v)
+		}
+	}
+	return string(templateMarker) + string(kind) + base64.RawStdEncoding.EncodeToString([]byte(text)) + string(templateMarker)
+}
+
+// escapeMarkedValues replaces the values marked by markValue in document
+// doc with their text, escaped for their context.
+func escapeMarkedValues(doc string) (string, error) {
+	if !strings.ContainsRune(doc, templateMarker) {
+		return doc, nil
+	}
+	const (
+		code = iota
+		str
+		blockStr
+		comment
+	)
+	var b strings.Builder
+	state := code
+	for i := 0; i < len(doc); {
+		if doc[i] == templateMarker {
+			end := strings.IndexByte(doc[i+1:], templateMarker)
+			if end < 1 {
+				return "", errors.New("graphql: invalid value marker in document")
+			}
+			kind, encoded := doc[i+1], doc[i+2:i+1+end]
+			i += end + 2
+			text, err := base64.RawStdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", errors.New("graphql: invalid value marker in document")
+			}
+			if kind == 'e' {
+				return "", fmt.Errorf("graphql: %s", text)
+			}
+			switch state {
+			case str:
+				var buf bytes.Buffer
+				enc := json.NewEncoder(&buf)
+				enc.SetEscapeHTML(false)
+				enc.Encode(string(text))
+				b.WriteString(strings.TrimSuffix(buf.String(), "\n")[1 : buf.Len()-2])
+			case blockStr:
+				if bytes.ContainsAny(text, `"\\`) {
+					return "", fmt.Errorf("graphql: %q can't be written in block string", text)
+				}
+				b.Write(text)
+			case comment:
+				b.WriteString(strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(string(text)))
+			default:
+				switch kind {
+				case 'z':
+					b.WriteString("null")
+				case 'n':
+					b.Write(text)
+				case 's':
+					if !isName(string(text)) {
+						return "", fmt.Errorf("graphql: %q isn't a valid name in document", text)
+					}
+					b.Write(text)
+				}
+			}
+			continue
+		}
+		c := doc[i]
+		switch state {
+		case code:
+			switch {
+			case strings.HasPrefix(doc[i:], `"""`):
+				state = blockStr
+				b.WriteString(`"""`)
+				i += 3
+				continue
+			case c == '"':
+				state = str
+			case c == '#':
+				state = comment
+			}
+		case str:
+			switch c {
+			case '\\':
+				if i+1 < len(doc) && doc[i+1] == templateMarker {
+					return "", errors.New("graphql: escape sequence in template before value")
+				}
+				if i+1 < len(doc) {
+					b.WriteString(doc[i : i+2])
+					i += 2
+					continue
+				}
+			case '"', '\n', '\r':
+				state = code
+			}
+		case blockStr:
+			switch {
+			case strings.HasPrefix(doc[i:], `\"""`):
+				b.WriteString(`\"""`)
+				i += 4
+				continue
+			case strings.HasPrefix(doc[i:], `"""`):
+				state = code
+				b.WriteString(`"""`)
+				i += 3
+				continue
+			}
+		case comment:
+			if c == '\n' || c == '\r' {
+				state = code
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), nil
+}
+
+// isName reports whether s is a GraphQL name.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
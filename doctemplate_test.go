@@ -0,0 +1,94 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestDocumentTemplate(t *testing.T) {
+	tmpl := graphql.MustParseDocumentTemplate(`query($login: String!) { # {{.Comment}}
+	user(login: $login) { {{range .Fields}}{{.}} {{end}}}
+	search(query: "{{.Search}}", type: {{.Type}}, first: {{.First}}, exact: {{.Exact}}, after: {{.After}}) { issueCount }
+	note(text: """{{.Note}}""")
+}`)
+	got, err := tmpl.Execute(map[string]interface{}{
+		"Comment": "fields\nchosen at run time",
+		"Fields":  []string{"login", "name"},
+		"Search":  `repo:"octocat/Hello-World" \ is:open`,
+		"Type":    "ISSUE",
+		"First":   10,
+		"Exact":   true,
+		"After":   nil,
+		"Note":    "multi\nline",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query($login: String!) { # fields chosen at run time
+	user(login: $login) { login name }
+	search(query: "repo:\"octocat/Hello-World\" \\ is:open", type: ISSUE, first: 10, exact: true, after: null) { issueCount }
+	note(text: """multi
+line""")
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentTemplate_injection(t *testing.T) {
+	for _, tc := range []struct {
+		tmpl string
+		data interface{}
+	}{
+		{`{ user { {{.}} } }`, "login } viewer { email"},
+		{`{ search(type: {{.}}) { issueCount } }`, "ISSUE) { issueCount } viewer(x: 1"},
+		{`{ search(query: "\{{.}}") { issueCount } }`, `"`},
+		{`{ note(text: """{{.}}""") }`, `"`},
+		{`{ note(text: """{{.}}""") }`, `\`},
+		{`{ search(first: {{.}}) { issueCount } }`, []int{1}},
+	} {
+		tmpl, err := graphql.ParseDocumentTemplate(tc.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := tmpl.Execute(tc.data); err == nil {
+			t.Errorf("%s with %q: got document %q, want error", tc.tmpl, tc.data, got)
+		}
+	}
+}
+
+func TestStaticTemplate(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct{ Query string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		query = body.Query
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	tmpl := graphql.MustParseDocumentTemplate(`{viewer{ {{.}} }}`)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.StaticTemplate{Template: tmpl, Data: "login", Into: &q}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{viewer{ login }}`; query != want {
+		t.Errorf("got query: %q, want: %q", query, want)
+	}
+	err := client.Run(context.Background(), &graphql.StaticTemplate{Template: tmpl, Data: "login }", Into: &q})
+	if err == nil || !strings.Contains(err.Error(), "isn't a valid name") {
+		t.Errorf("got error: %v, want invalid name", err)
+	}
+}
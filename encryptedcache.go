@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"time"
+)
+
+// EncryptedCache is a Cache that encrypts the data of entries stored in
+// another cache, such as a DiskCache, so cached responses, which may
+// contain personal data, aren't stored in the clear.
+//
+// Data is sealed with an AEAD, such as AES-GCM, with a random nonce and
+// the entry's key as additional data, so entries can't be read without
+// the AEAD's key nor moved to other keys. Entries that fail to decrypt,
+// such as ones stored with another key, are deleted and treated as misses.
+type EncryptedCache struct {
+	cache Cache
+	aead  cipher.AEAD
+}
+
+// NewEncryptedCache returns an EncryptedCache storing entries in cache,
+// encrypted with aead.
+func NewEncryptedCache(cache Cache, aead cipher.AEAD) *EncryptedCache {
+	return &EncryptedCache{cache: cache, aead: aead}
+}
+
+// Get implements Cache.
+func (e *EncryptedCache) Get(key string) ([]byte, bool) {
+	b, ok := e.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	n := e.aead.NonceSize()
+	if len(b) < n {
+		e.cache.Delete(key)
+		return nil, false
+	}
+	data, err := e.aead.Open(nil, b[:n], b[n:], []byte(key))
+	if err != nil {
+		e.cache.Delete(key)
+		return nil, false
+	}
+	return data, true
+}
+
+// Set implements Cache.
+func (e *EncryptedCache) Set(key string, data []byte, ttl time.Duration) {
+	n := e.aead.NonceSize()
+	b := make([]byte, n, n+len(data)+e.aead.Overhead())
+	if _, err := rand.Read(b); err != nil {
+		return
+	}
+	e.cache.Set(key, e.aead.Seal(b, b, data, []byte(key)), ttl)
+}
+
+// Delete implements Cache.
+func (e *EncryptedCache) Delete(key string) {
+	e.cache.Delete(key)
+}
+
+func (e *EncryptedCache) setClock(clock Clock) {
+	if cache, ok := e.cache.(clocked); ok {
+		cache.setClock(clock)
+	}
+}
@@ -0,0 +1,55 @@
+package graphql_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func newAEAD(t *testing.T, key string) cipher.AEAD {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestEncryptedCache(t *testing.T) {
+	dir := t.TempDir()
+	disk, err := graphql.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := graphql.NewEncryptedCache(disk, newAEAD(t, "0123456789abcdef0123456789abcdef"))
+	secret := []byte(`{"user":{"email":"gopher@example.com"}}`)
+	c.Set("a", secret, 0)
+	if data, ok := c.Get("a"); !ok || !bytes.Equal(data, secret) {
+		t.Errorf("got %s, %v, want %s, true", data, ok, secret)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(dir, "*.entry"))
+	for _, name := range entries {
+		b, _ := ioutil.ReadFile(name)
+		if bytes.Contains(b, []byte("gopher@example.com")) {
+			t.Errorf("entry %s is stored in the clear", name)
+		}
+	}
+
+	// Entries encrypted with another key are misses, and are deleted.
+	other := graphql.NewEncryptedCache(disk, newAEAD(t, "fedcba9876543210fedcba9876543210"))
+	if _, ok := other.Get("a"); ok {
+		t.Error("got entry decrypted with another key")
+	}
+	if _, ok := disk.Get("a"); ok {
+		t.Error("entry that failed to decrypt wasn't deleted")
+	}
+}
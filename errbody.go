@@ -0,0 +1,94 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"unicode/utf8"
+)
+
+// DefaultMaxErrorBody is the default maximum number of bytes of a response
+// body included in errors for responses with unexpected status codes.
+const DefaultMaxErrorBody = 1024
+
+// maxDiscardErrorBody is the maximum number of bytes of a response body
+// read past the part included in an error, to determine its length.
+const maxDiscardErrorBody = 1 << 20
+
+// WithMaxErrorBody sets the maximum number of bytes of a response body
+// included in errors for responses with unexpected status codes.
+// Longer bodies are truncated. Bodies that are HTML or binary are
+// summarized by their content type, length and a short prefix instead.
+func WithMaxErrorBody(n int) ClientOption {
+	return func(c *Client) {
+		c.maxErrorBody = n
+	}
+}
+
+// statusError returns an error for resp, whose status code is unexpected.
+// It consumes the body of resp.
+func (c *Client) statusError(resp *http.Response) error {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       summarizeBody(resp, c.maxErrorBody),
+		Retryable:  c.statusPolicy(resp.StatusCode) == StatusRetryable,
+	}
+}
+
+// summarizeBody returns a description of the body of resp, fit for
+// inclusion in an error message: the body itself, quoted and truncated
+// to max bytes if it's text, or a summary otherwise.
+func summarizeBody(resp *http.Response, max int) string {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, int64(max)))
+	n, _ := io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDiscardErrorBody))
+	length := fmt.Sprintf("%d bytes", len(body)+int(n))
+	if n == maxDiscardErrorBody {
+		length = "more than " + length
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/html" || isHTML(body) || isBinary(body) {
+		if contentType == "" {
+			contentType = http.DetectContentType(body)
+		}
+		const prefix = 64
+		if len(body) > prefix {
+			body = body[:prefix]
+		}
+		return fmt.Sprintf("<%s, %s: %q...>", contentType, length, body)
+	}
+	if n > 0 {
+		return fmt.Sprintf("%q... (truncated, %s)", body, length)
+	}
+	return fmt.Sprintf("%q", body)
+}
+
+// isHTML reports whether body looks like an HTML document.
+func isHTML(body []byte) bool {
+	b := bytes.ToLower(bytes.TrimSpace(body))
+	return bytes.HasPrefix(b, []byte("<!doctype html")) || bytes.HasPrefix(b, []byte("<html"))
+}
+
+// isBinary reports whether body looks like binary data rather than text.
+// body may end in a truncated UTF-8 sequence.
+func isBinary(body []byte) bool {
+	for len(body) > 0 {
+		r, size := utf8.DecodeRune(body)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			if !utf8.FullRune(body) {
+				return false // Truncated.
+			}
+			return true
+		case r < ' ' && r != '\t' && r != '\n' && r != '\r':
+			return true
+		}
+		body = body[size:]
+	}
+	return false
+}
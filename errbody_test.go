@@ -0,0 +1,59 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_errorBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        []graphql.ClientOption
+		want        string
+	}{
+		{
+			name: "text",
+			body: "upstream unavailable",
+			want: `non-200 OK status code: 502 Bad Gateway body: "upstream unavailable"`,
+		},
+		{
+			name: "truncated",
+			body: strings.Repeat("x", 20),
+			opts: []graphql.ClientOption{graphql.WithMaxErrorBody(8)},
+			want: `non-200 OK status code: 502 Bad Gateway body: "xxxxxxxx"... (truncated, 20 bytes)`,
+		},
+		{
+			name:        "html",
+			contentType: "text/html; charset=utf-8",
+			body:        "<!DOCTYPE html><html><head><title>502 Bad Gateway</title></head><body>" + strings.Repeat("<p>oops</p>", 1000) + "</body></html>",
+			want:        `non-200 OK status code: 502 Bad Gateway body: <text/html; charset=utf-8, 11084 bytes: "<!DOCTYPE html><html><head><title>502 Bad Gateway</title></head>"...>`,
+		},
+		{
+			name: "binary",
+			body: "\x00\x01\x02\xff",
+			want: `non-200 OK status code: 502 Bad Gateway body: <application/octet-stream, 4 bytes: "\x00\x01\x02\xff"...>`,
+		},
+	}
+	for _, tc := range tests {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			if tc.contentType != "" {
+				w.Header().Set("Content-Type", tc.contentType)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+			mustWrite(w, tc.body)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, tc.opts...)
+
+		err := client.Query(context.Background(), new(viewerQuery), nil)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s:\ngot error:  %v\nwant error: %v", tc.name, err, tc.want)
+		}
+	}
+}
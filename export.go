@@ -0,0 +1,430 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/arvata-io/graphql/ident"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// ExportFormat is a format Client.Export writes nodes in.
+type ExportFormat int
+
+const (
+	// NDJSON writes each node as a JSON object on its own line,
+	// with the response keys of its fields.
+	NDJSON ExportFormat = iota
+
+	// CSV writes a header row of field paths, such as "author.login",
+	// followed by a row for each node. Lists are written as JSON.
+	CSV
+)
+
+// Export runs op for each page of the connection in its query data
+// structure, and writes the nodes of the connection to w in format.
+// Only one page of nodes is held in memory at a time.
+//
+// The connection is the first struct in the data structure with a pageInfo
+// field, having endCursor and hasNextPage fields, and a nodes field or an
+// edges field whose elements have a node field. op must declare a nullable
+// cursor variable named "after" for the connection's after argument:
+//
+//	var q struct {
+//		Repository struct {
+//			Issues struct {
+//				Nodes    []struct{ Number graphql.Int; Title graphql.String }
+//				PageInfo struct {
+//					EndCursor   graphql.String
+//					HasNextPage graphql.Boolean
+//				}
+//			} `graphql:"issues(first: 100, after: $after)"`
+//		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+//	}
+//	op := graphql.NewQuery(&q, map[string]interface{}{"after": (*graphql.String)(nil)})
+//	err := client.Export(ctx, op, w, graphql.NDJSON)
+//
+// The variables of op aren't modified, and the data structure of op
+// is left holding the last page.
+func (c *Client) Export(ctx context.Context, op Operation, w io.Writer, format ExportFormat) error {
+	if format != NDJSON && format != CSV {
+		return fmt.Errorf("graphql: unknown export format %d", format)
+	}
+	vars := op.Variables()
+	if _, ok := vars["after"]; !ok {
+		return errors.New(`graphql: exported operation has no "after" variable`)
+	}
+	data := reflect.ValueOf(op.ResponsePtr())
+	if data.Kind() != reflect.Ptr || data.IsNil() {
+		return errors.New("graphql: exported operation has no query data structure")
+	}
+	if _, ok := findConnection(data.Elem()); !ok {
+		return errors.New("graphql: exported operation has no connection with pageInfo and nodes or edges")
+	}
+	page := &pageOperation{Operation: op, vars: make(map[string]interface{}, len(vars))}
+	for k, v := range vars {
+		page.vars[k] = v
+	}
+	bw := bufio.NewWriter(w)
+	var cw *csv.Writer
+	var columns []exportColumn
+	for {
+		data.Elem().Set(reflect.Zero(data.Elem().Type()))
+		if err := c.Run(ctx, page); err != nil {
+			return err
+		}
+		conn, ok := findConnection(data.Elem())
+		if !ok {
+			return errors.New("graphql: exported connection is null")
+		}
+		for i := 0; i < conn.Len(); i++ {
+			node := conn.node(i)
+			switch format {
+			case NDJSON:
+				if err := writeNodeJSON(bw, node); err != nil {
+					return err
+				}
+				bw.WriteByte('\n')
+			case CSV:
+				if cw == nil {
+					cw = csv.NewWriter(bw)
+					columns = exportColumns(nil, node.Type())
+					header := make([]string, len(columns))
+					for i, col := range columns {
+						header[i] = strings.Join(col.keys, ".")
+					}
+					cw.Write(header)
+				}
+				record, err := csvRecord(columns, node)
+				if err != nil {
+					return err
+				}
+				cw.Write(record)
+			}
+		}
+		if cw != nil {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		cursor, more := conn.next()
+		if !more {
+			return nil
+		}
+		if cursor == "" {
+			return errors.New("graphql: exported connection has a next page but no endCursor")
+		}
+		page.vars["after"] = String(cursor)
+	}
+}
+
+// pageOperation is an operation run for a page of a connection,
+// with the variables of the page.
+type pageOperation struct {
+	Operation
+	vars map[string]interface{}
+}
+
+func (op *pageOperation) Variables() map[string]interface{} {
+	return op.vars
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *pageOperation) Unwrap() Operation {
+	return op.Operation
+}
+
+// connection is a page of a connection in a query data structure.
+type connection struct {
+	nodes    reflect.Value // Slice of nodes, or of edges if edges is set.
+	edges    bool
+	pageInfo reflect.Value
+}
+
+func (c connection) Len() int { return c.nodes.Len() }
+
+// node returns the i'th node of c.
+func (c connection) node(i int) reflect.Value {
+	v := c.nodes.Index(i)
+	if c.edges {
+		v, _ = fieldByGraphQLName(indirect(v), "node")
+	}
+	return v
+}
+
+// next returns the end cursor of c, and whether there's a next page.
+func (c connection) next() (cursor string, more bool) {
+	info := indirect(c.pageInfo)
+	if !info.IsValid() {
+		return "", false
+	}
+	if v, ok := fieldByGraphQLName(info, "hasNextPage"); ok {
+		if v = indirect(v); v.IsValid() && v.Kind() == reflect.Bool {
+			more = v.Bool()
+		}
+	}
+	if v, ok := fieldByGraphQLName(info, "endCursor"); ok {
+		if v = indirect(v); v.IsValid() && v.Kind() == reflect.String {
+			cursor = v.String()
+		}
+	}
+	return cursor, more
+}
+
+// findConnection returns the first connection in query data structure v,
+// searched depth-first. Connections in lists aren't found.
+func findConnection(v reflect.Value) (connection, bool) {
+	v = indirect(v)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return connection{}, false
+	}
+	if info, ok := fieldByGraphQLName(v, "pageInfo"); ok {
+		if nodes, ok := fieldByGraphQLName(v, "nodes"); ok && nodes.Kind() == reflect.Slice {
+			return connection{nodes: nodes, pageInfo: info}, true
+		}
+		if edges, ok := fieldByGraphQLName(v, "edges"); ok && edges.Kind() == reflect.Slice {
+			return connection{nodes: edges, edges: true, pageInfo: info}, true
+		}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if conn, ok := findConnection(v.Field(i)); ok {
+			return conn, true
+		}
+	}
+	return connection{}, false
+}
+
+// fieldByGraphQLName returns the field of struct v with response key
+// name, searching inlined fields too.
+func fieldByGraphQLName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, inline, ok := responseKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		if inline {
+			if f := indirect(v.Field(i)); f.IsValid() && f.Kind() == reflect.Struct {
+				if f, ok := fieldByGraphQLName(f, name); ok {
+					return f, true
+				}
+			}
+			continue
+		}
+		if key == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// responseKey returns the key of struct field f in responses, or whether
+// it's inlined into its parent, as fragments and embedded structs are.
+// It reports false for fields that aren't part of the query.
+func responseKey(f reflect.StructField) (key string, inline, ok bool) {
+	value, tagged := f.Tag.Lookup("graphql")
+	value = strings.TrimSpace(value)
+	switch {
+	case value == jsonutil.FallbackTag || f.PkgPath != "":
+		return "", false, false
+	case !tagged:
+		if f.Anonymous {
+			return "", true, true
+		}
+		return ident.ParseMixedCaps(f.Name).ToLowerCamelCase(), false, true
+	case strings.HasPrefix(value, "..."):
+		return "", true, true
+	}
+	if i := strings.IndexAny(value, "(@"); i != -1 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, ":"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), false, true
+}
+
+// indirect dereferences pointers and interfaces in v, returning
+// the zero Value if one is nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isLeafType reports whether values of t are written as they're encoded
+// by encoding/json, rather than field by field.
+func isLeafType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(jsonUnmarshaler)
+}
+
+// writeNodeJSON writes v to w as JSON, with the response keys of its fields.
+func writeNodeJSON(w *bufio.Writer, v reflect.Value) error {
+	v = indirect(v)
+	switch {
+	case !v.IsValid():
+		_, err := w.WriteString("null")
+		return err
+	case v.Kind() == reflect.Struct && !isLeafType(v.Type()):
+		w.WriteByte('{')
+		if _, err := writeFieldsJSON(w, v, true); err != nil {
+			return err
+		}
+		return w.WriteByte('}')
+	case v.Kind() == reflect.Slice && !isLeafType(v.Type().Elem()):
+		if v.IsNil() {
+			_, err := w.WriteString("null")
+			return err
+		}
+		w.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeNodeJSON(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte(']')
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// writeFieldsJSON writes the fields of struct v to w as JSON object members.
+// first is whether no members have been written yet; it returns whether
+// that's still the case.
+func writeFieldsJSON(w *bufio.Writer, v reflect.Value, first bool) (bool, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, inline, ok := responseKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		if inline {
+			f := indirect(v.Field(i))
+			if !f.IsValid() || f.Kind() != reflect.Struct {
+				continue
+			}
+			var err error
+			if first, err = writeFieldsJSON(w, f, first); err != nil {
+				return first, err
+			}
+			continue
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		w.Write(k)
+		w.WriteByte(':')
+		if err := writeNodeJSON(w, v.Field(i)); err != nil {
+			return first, err
+		}
+	}
+	return first, nil
+}
+
+// exportColumn is a CSV column of exported nodes.
+type exportColumn struct {
+	keys  []string // Response keys of the path to the value.
+	index [][]int  // Field indices of the path; an empty index is an inlined field.
+}
+
+// exportColumns returns the columns of values of type t, whose values are at path.
+func exportColumns(path *exportColumn, t reflect.Type) []exportColumn {
+	if path == nil {
+		path = &exportColumn{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isLeafType(t) {
+		return []exportColumn{*path}
+	}
+	var columns []exportColumn
+	for i := 0; i < t.NumField(); i++ {
+		key, inline, ok := responseKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		col := exportColumn{
+			keys:  path.keys,
+			index: append(path.index[:len(path.index):len(path.index)], []int{i}),
+		}
+		if !inline {
+			col.keys = append(path.keys[:len(path.keys):len(path.keys)], key)
+		}
+		columns = append(columns, exportColumns(&col, t.Field(i).Type)...)
+	}
+	return columns
+}
+
+// csvRecord returns the values of columns of node.
+func csvRecord(columns []exportColumn, node reflect.Value) ([]string, error) {
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		v := node
+		for _, index := range col.index {
+			if v = indirect(v); !v.IsValid() {
+				break
+			}
+			v = v.FieldByIndex(index)
+		}
+		s, err := csvValue(v)
+		if err != nil {
+			return nil, err
+		}
+		record[i] = s
+	}
+	return record, nil
+}
+
+// csvValue returns v formatted as a CSV field. Strings are written
+// unquoted, null as an empty field, and other values as JSON.
+func csvValue(v reflect.Value) (string, error) {
+	if v = indirect(v); !v.IsValid() {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := writeNodeJSON(w, v); err != nil {
+		return "", err
+	}
+	w.Flush()
+	b := buf.Bytes()
+	switch {
+	case string(b) == "null":
+		return "", nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err == nil {
+			return s, nil
+		}
+	}
+	return string(b), nil
+}
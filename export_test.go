@@ -0,0 +1,105 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// pagesHandler serves a connection of issues in pages of two,
+// recording the cursors it's sent.
+func pagesHandler(cursors *[]interface{}) http.Handler {
+	pages := map[interface{}]string{
+		nil:  `{"issues": {"edges": [{"node": {"number": 1, "title": "First", "author": {"login": "a"}}}, {"node": {"number": 2, "title": "Quote \"this\", please", "author": null}}], "pageInfo": {"endCursor": "c2", "hasNextPage": true}}}`,
+		"c2": `{"issues": {"edges": [{"node": {"number": 3, "title": "Last", "author": {"login": "b"}, "labels": ["bug", "p1"]}}], "pageInfo": {"endCursor": "c3", "hasNextPage": false}}}`,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var in struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			panic(err)
+		}
+		if got, want := in.Query, "query($after:String){issues(first: 2, after: $after){edges{node{number,title,author{login},labels}},pageInfo{endCursor,hasNextPage}}}"; got != want {
+			panic("got query: " + got)
+		}
+		*cursors = append(*cursors, in.Variables["after"])
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": `+pages[in.Variables["after"]]+`}`)
+	})
+}
+
+type issuesQuery struct {
+	Issues struct {
+		Edges []struct {
+			Node struct {
+				Number graphql.Int
+				Title  graphql.String
+				Author *struct {
+					Login graphql.String
+				}
+				Labels []graphql.String
+			}
+		}
+		PageInfo struct {
+			EndCursor   graphql.String
+			HasNextPage graphql.Boolean
+		}
+	} `graphql:"issues(first: 2, after: $after)"`
+}
+
+func TestClient_Export(t *testing.T) {
+	tests := []struct {
+		format graphql.ExportFormat
+		want   string
+	}{
+		{
+			format: graphql.NDJSON,
+			want: `{"number":1,"title":"First","author":{"login":"a"},"labels":null}
+{"number":2,"title":"Quote \"this\", please","author":null,"labels":null}
+{"number":3,"title":"Last","author":{"login":"b"},"labels":["bug","p1"]}
+`,
+		},
+		{
+			format: graphql.CSV,
+			want: `number,title,author.login,labels
+1,First,a,
+2,"Quote ""this"", please",,
+3,Last,b,"[""bug"",""p1""]"
+`,
+		},
+	}
+	for _, tc := range tests {
+		var cursors []interface{}
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: pagesHandler(&cursors)}})
+		var q issuesQuery
+		vars := map[string]interface{}{"after": (*graphql.String)(nil)}
+		var buf bytes.Buffer
+		if err := client.Export(context.Background(), graphql.NewQuery(&q, vars), &buf, tc.format); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("format %d: got:\n%s\nwant:\n%s", tc.format, got, tc.want)
+		}
+		if len(cursors) != 2 || cursors[0] != nil || cursors[1] != "c2" {
+			t.Errorf("format %d: got cursors: %v, want: [<nil> c2]", tc.format, cursors)
+		}
+		if vars["after"] != (*graphql.String)(nil) {
+			t.Errorf("format %d: variables were modified: %v", tc.format, vars)
+		}
+	}
+}
+
+func TestClient_Export_noCursorVariable(t *testing.T) {
+	client := graphql.NewClient("/graphql", nil)
+	var q issuesQuery
+	err := client.Export(context.Background(), graphql.NewQuery(&q, nil), &bytes.Buffer{}, graphql.NDJSON)
+	if got, want := err.Error(), `graphql: exported operation has no "after" variable`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
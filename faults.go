@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// Faults configures artificial faults that a client injects into its
+// requests, for testing how code that uses the client handles slow and
+// failing servers, without a fault-injecting proxy. Rates are the
+// probabilities of faults for each request, from 0 to 1.
+type Faults struct {
+	Latency     time.Duration // Delay before requests are sent, measured with the client's Clock.
+	LatencyRate float64       // Rate of requests delayed by Latency.
+
+	// DropRate is the rate of requests whose response is dropped after
+	// they're sent, failing with ErrFaultDropped.
+	DropRate float64
+
+	// MalformedRate is the rate of responses whose body is truncated
+	// to half its length, so it's malformed JSON.
+	MalformedRate float64
+
+	// PartialErrorRate is the rate of JSON responses with an error added
+	// to their errors, keeping their data, as for partial failures.
+	PartialErrorRate float64
+
+	// Seed seeds the choice of requests with faults, so it's reproducible.
+	// If zero, it's seeded with the time the client is created.
+	Seed int64
+}
+
+// ErrFaultDropped is the error of requests whose response is dropped by
+// faults injected with WithFaults.
+var ErrFaultDropped = errors.New("graphql: injected fault: response dropped")
+
+// FaultError is the GraphQL error added to responses with a partial error
+// injected with WithFaults.
+var FaultError = Error{
+	Message:    "graphql: injected fault: partial error",
+	Extensions: map[string]interface{}{"code": "INJECTED_FAULT"},
+}
+
+// WithFaults makes the client inject faults into its requests. It's
+// intended for tests, and shouldn't be used in production.
+func WithFaults(f Faults) ClientOption {
+	return func(c *Client) {
+		seed := f.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		c.faults = &faultInjector{Faults: f, rand: rand.New(rand.NewSource(seed))}
+	}
+}
+
+// faultInjector injects Faults into requests.
+type faultInjector struct {
+	Faults
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// roll reports whether a fault with rate happens.
+func (f *faultInjector) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rand.Float64() < rate
+}
+
+// send sends req with ctx, injecting c's faults, if any.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		// Fail fast, rather than rely on the transport to check ctx.
+		return nil, err
+	}
+	f := c.faults
+	if f == nil {
+		return ctxhttp.Do(ctx, c.httpClient, req)
+	}
+	if f.Latency > 0 && f.roll(f.LatencyRate) {
+		elapsed := make(chan struct{})
+		t := c.clock.AfterFunc(f.Latency, func() { close(elapsed) })
+		select {
+		case <-elapsed:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	resp, err := ctxhttp.Do(ctx, c.httpClient, req)
+	if err != nil {
+		return resp, err
+	}
+	malformed, partial := f.roll(f.MalformedRate), f.roll(f.PartialErrorRate)
+	if f.roll(f.DropRate) {
+		resp.Body.Close()
+		return nil, ErrFaultDropped
+	}
+	if !malformed && !partial {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if partial {
+		body = addFaultError(body)
+	}
+	if malformed {
+		body = body[:len(body)/2]
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// addFaultError returns JSON response body with FaultError added to its
+// errors, or body if it isn't a JSON object.
+func addFaultError(body []byte) []byte {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return body
+	}
+	var errs []json.RawMessage
+	json.Unmarshal(resp["errors"], &errs)
+	e, _ := json.Marshal(FaultError)
+	resp["errors"], _ = json.Marshal(append(errs, e))
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return body
+	}
+	return b
+}
@@ -0,0 +1,111 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func faultsClient(f graphql.Faults, opts ...graphql.ClientOption) *graphql.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	opts = append(opts, graphql.WithFaults(f))
+	return graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, opts...)
+}
+
+func TestWithFaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults graphql.Faults
+		check  func(t *testing.T, q viewerQuery, err error)
+	}{
+		{
+			name:   "dropped",
+			faults: graphql.Faults{DropRate: 1},
+			check: func(t *testing.T, q viewerQuery, err error) {
+				if !errors.Is(err, graphql.ErrFaultDropped) {
+					t.Errorf("got error: %v, want: %v", err, graphql.ErrFaultDropped)
+				}
+			},
+		},
+		{
+			name:   "malformed",
+			faults: graphql.Faults{MalformedRate: 1},
+			check: func(t *testing.T, q viewerQuery, err error) {
+				if err == nil || q.Viewer.Login != "" {
+					t.Errorf("got %+v, %v, want decoding error", q, err)
+				}
+			},
+		},
+		{
+			name:   "partial error",
+			faults: graphql.Faults{PartialErrorRate: 1},
+			check: func(t *testing.T, q viewerQuery, err error) {
+				var errs graphql.ErrorList
+				if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Message != graphql.FaultError.Message {
+					t.Errorf("got error: %v, want: %v", err, graphql.FaultError.Message)
+				}
+				if q.Viewer.Login != "gopher" {
+					t.Errorf("got login: %q, want data kept", q.Viewer.Login)
+				}
+			},
+		},
+		{
+			name:   "none",
+			faults: graphql.Faults{DropRate: 0, MalformedRate: 0},
+			check: func(t *testing.T, q viewerQuery, err error) {
+				if err != nil || q.Viewer.Login != "gopher" {
+					t.Errorf("got %+v, %v", q, err)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var q viewerQuery
+			err := faultsClient(tc.faults).Query(context.Background(), &q, nil)
+			tc.check(t, q, err)
+		})
+	}
+}
+
+func TestWithFaults_rate(t *testing.T) {
+	client := faultsClient(graphql.Faults{DropRate: 0.5, Seed: 1})
+	dropped := 0
+	for i := 0; i < 200; i++ {
+		var q viewerQuery
+		if err := client.Query(context.Background(), &q, nil); errors.Is(err, graphql.ErrFaultDropped) {
+			dropped++
+		}
+	}
+	if dropped < 70 || dropped > 130 {
+		t.Errorf("got %d of 200 requests dropped, want about 100", dropped)
+	}
+}
+
+func TestWithFaults_latency(t *testing.T) {
+	clock := graphqltest.NewClock(time.Unix(0, 0))
+	client := faultsClient(graphql.Faults{Latency: time.Second, LatencyRate: 1}, graphql.WithClock(clock))
+	done := make(chan error)
+	go func() {
+		var q viewerQuery
+		done <- client.Query(context.Background(), &q, nil)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("request finished before its latency: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
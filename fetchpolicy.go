@@ -0,0 +1,59 @@
+package graphql
+
+import "context"
+
+// FetchPolicy is whether runs of a query are served from the cache of
+// a client created WithCache, or sent to the server.
+type FetchPolicy int
+
+const (
+	// CacheFirst serves runs from the cache if it has their response,
+	// and sends them to the server otherwise. It's the default.
+	CacheFirst FetchPolicy = iota
+
+	// ForceNetwork sends runs to the server, without reading the cache,
+	// nor caching their responses.
+	ForceNetwork
+
+	// RefreshCache sends runs to the server, without reading the cache,
+	// and caches their responses, replacing any cached ones.
+	RefreshCache
+)
+
+// WithFetchPolicy returns op decorated to be run with fetch policy policy,
+// rather than CacheFirst or the policy of the context it's run with.
+func WithFetchPolicy(op Operation, policy FetchPolicy) Operation {
+	return &fetchPolicied{Operation: op, policy: policy}
+}
+
+type fetchPolicied struct {
+	Operation
+	policy FetchPolicy
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *fetchPolicied) Unwrap() Operation {
+	return op.Operation
+}
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass returns a copy of ctx with which queries are run with
+// the RefreshCache fetch policy, unless they're decorated WithFetchPolicy.
+// Request handlers can use it to honor user-initiated refreshes, such as
+// with a "Cache-Control: no-cache" request header, so users get fresh
+// data, which later runs are then served.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// fetchPolicy returns the fetch policy of op, run with ctx.
+func fetchPolicy(ctx context.Context, op Operation) FetchPolicy {
+	if op, ok := asOperation[*fetchPolicied](op); ok {
+		return op.policy
+	}
+	if bypass, _ := ctx.Value(cacheBypassKey{}).(bool); bypass {
+		return RefreshCache
+	}
+	return CacheFirst
+}
@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithFetchPolicy(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "databaseId": `+strconv.Itoa(int(n))+`}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+	)
+
+	run := func(ctx context.Context, policy *graphql.FetchPolicy) int {
+		t.Helper()
+		var q viewerIDQuery
+		var op graphql.Operation = graphql.NewQuery(&q, nil)
+		if policy != nil {
+			op = graphql.WithFetchPolicy(op, *policy)
+		}
+		if err := client.Run(ctx, op); err != nil {
+			t.Fatal(err)
+		}
+		return int(q.Viewer.DatabaseID)
+	}
+	policy := func(p graphql.FetchPolicy) *graphql.FetchPolicy { return &p }
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		policy *graphql.FetchPolicy
+		want   int
+	}{
+		{"first run", ctx, nil, 1},
+		{"cached", ctx, nil, 1},
+		{"ForceNetwork", ctx, policy(graphql.ForceNetwork), 2},
+		{"not cached by ForceNetwork", ctx, nil, 1},
+		{"RefreshCache", ctx, policy(graphql.RefreshCache), 3},
+		{"cached by RefreshCache", ctx, nil, 3},
+		{"WithCacheBypass", graphql.WithCacheBypass(ctx), nil, 4},
+		{"cached by WithCacheBypass", ctx, nil, 4},
+		{"CacheFirst overrides WithCacheBypass", graphql.WithCacheBypass(ctx), policy(graphql.CacheFirst), 4},
+	}
+	for _, tt := range tests {
+		if got := run(tt.ctx, tt.policy); got != tt.want {
+			t.Errorf("%s: got response %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
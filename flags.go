@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// featureFlag is the name of the directive gating fields by feature flag.
+const featureFlag = "featureFlag"
+
+// WithFeatureFlags makes the client include fields gated by feature flags
+// in the operations it sends only while their flags are enabled, as
+// reported by enabled at request time, so fields servers are rolling out
+// can be selected before all servers have them. Fields are gated with
+// a flag struct tag:
+//
+//	CheckoutV2 *Checkout `graphql:"checkoutV2" flag:"new-checkout"`
+//	Checkout   *Checkout `flag:"!new-checkout"`
+//
+// A field tagged "!name" is included only while flag name is disabled.
+// The tag adds a @featureFlag(if: "name") or @featureFlag(unless: "name")
+// directive to the field, which can also be written in queries directly,
+// and is always removed before operations are sent. Without
+// WithFeatureFlags, all flags are disabled.
+//
+// Variables only used by excluded fields can be removed with
+// WithPruneUnusedVariables. A selection set must not consist only
+// of excluded fields.
+func WithFeatureFlags(enabled func(ctx context.Context, flag string) bool) ClientOption {
+	return func(c *Client) {
+		c.flagEnabled = enabled
+	}
+}
+
+// featureFlagDirective returns the directive gating a field by flag, as
+// given in a flag struct tag, preceded by a space.
+func featureFlagDirective(flag string) string {
+	flag = strings.TrimSpace(flag)
+	if strings.HasPrefix(flag, "!") {
+		return " @" + featureFlag + "(unless: " + strconv.Quote(strings.TrimSpace(flag[1:])) + ")"
+	}
+	return " @" + featureFlag + "(if: " + strconv.Quote(flag) + ")"
+}
+
+// gatedField is a field gated by a feature flag in a query.
+type gatedField struct {
+	flag   string
+	unless bool     // Whether the field is included while flag is disabled.
+	field  [2]int   // Byte offsets of the field.
+	dirs   [][2]int // Byte offsets of its @featureFlag directives.
+}
+
+// applyFeatureFlags returns query with the fields gated by disabled feature
+// flags removed, and without @featureFlag directives. The gated fields are
+// found once per query, for up to DefaultDocumentCacheSize queries. Queries
+// that can't be parsed are left as is.
+func (c *Client) applyFeatureFlags(ctx context.Context, query string) string {
+	if !strings.Contains(query, "@"+featureFlag) {
+		return query
+	}
+	fields, ok := c.gated.load(query)
+	if !ok {
+		fields, _ = c.gated.loadOrStore(query, gatedFields(query))
+	}
+	if len(fields) == 0 {
+		return query
+	}
+	enabled := make(map[string]bool)
+	var ranges [][2]int
+	for _, f := range fields {
+		on, ok := enabled[f.flag]
+		if !ok {
+			on = c.flagEnabled != nil && c.flagEnabled(ctx, f.flag)
+			enabled[f.flag] = on
+		}
+		if on == f.unless {
+			ranges = append(ranges, f.field)
+		} else {
+			ranges = append(ranges, f.dirs...)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		if r[0] < last {
+			continue // Within a removed field.
+		}
+		b.WriteString(query[last:r[0]])
+		last = r[1]
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// gatedFields returns the fields of query gated by feature flags.
+func gatedFields(query string) []gatedField {
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return nil
+	}
+	var fields []gatedField
+	var walk func(sels []parser.Selection)
+	walk = func(sels []parser.Selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *parser.Field:
+				for _, d := range sel.Directives {
+					if d.Name != featureFlag {
+						continue
+					}
+					g := gatedField{field: [2]int{trimSpaceBefore(query, sel.Pos.Offset), sel.End}}
+					for _, a := range d.Arguments {
+						if a.Value.Kind == parser.StringValue && (a.Name == "if" || a.Name == "unless") {
+							g.flag, g.unless = a.Value.Raw, a.Name == "unless"
+						}
+					}
+					for _, d := range sel.Directives {
+						if d.Name == featureFlag {
+							g.dirs = append(g.dirs, [2]int{trimSpaceBefore(query, d.Pos.Offset), d.End})
+						}
+					}
+					fields = append(fields, g)
+					break
+				}
+				walk(sel.SelectionSet)
+			case *parser.InlineFragment:
+				walk(sel.SelectionSet)
+			}
+		}
+	}
+	for _, op := range doc.Operations {
+		walk(op.SelectionSet)
+	}
+	for _, f := range doc.Fragments {
+		walk(f.SelectionSet)
+	}
+	return fields
+}
+
+// trimSpaceBefore returns offset i in s moved back past spaces.
+func trimSpaceBefore(s string, i int) int {
+	for i > 0 && s[i-1] == ' ' {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,98 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithFeatureFlags(t *testing.T) {
+	var body struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	flags := map[string]bool{}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithFeatureFlags(func(ctx context.Context, flag string) bool { return flags[flag] }),
+		graphql.WithPruneUnusedVariables(),
+	)
+
+	var q struct {
+		Viewer struct {
+			Login    graphql.String
+			Avatar   graphql.String `graphql:"avatar(size: $size)" flag:"avatars"`
+			Pronouns struct {
+				Subject graphql.String
+				Object  graphql.String `flag:"objects"`
+			} `flag:"pronouns"`
+			Legacy graphql.String `flag:"!pronouns"`
+		}
+	}
+	vars := map[string]interface{}{"size": graphql.Int(32)}
+	for _, tc := range []struct {
+		flags map[string]bool
+		want  string
+	}{
+		{
+			flags: map[string]bool{},
+			want:  "query{viewer{login,,,legacy}}",
+		},
+		{
+			flags: map[string]bool{"avatars": true, "pronouns": true},
+			want:  "query($size:Int!){viewer{login,avatar(size: $size),pronouns{subject,},}}",
+		},
+		{
+			flags: map[string]bool{"pronouns": true, "objects": true},
+			want:  "query{viewer{login,,pronouns{subject,object},}}",
+		},
+	} {
+		flags = tc.flags
+		if err := client.Query(context.Background(), &q, vars); err != nil {
+			t.Fatal(err)
+		}
+		if body.Query != tc.want {
+			t.Errorf("flags %v: got query:\n%s\nwant:\n%s", tc.flags, body.Query, tc.want)
+		}
+	}
+}
+
+func TestFeatureFlags_default(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct{ Query string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		query = body.Query
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	err := client.Run(context.Background(), &graphql.Static{
+		QueryStr: `{ viewer { login name @featureFlag(if: "names") handle @featureFlag(unless: "names") } }`,
+		Into:     &q,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{ viewer { login handle } }`; query != want {
+		t.Errorf("got query: %q, want: %q", query, want)
+	}
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// Fragment is a fragment definition that the documents of operations can
+// spread by name, and have composed into them with Compose. It lets the
+// package owning a domain type own the fragment selecting its fields,
+// and operations elsewhere select them without repeating the fragment:
+//
+//	// Package users.
+//	var UserFields = graphql.MustParseFragment(`fragment UserFields on User {
+//		login
+//		avatar { ...AvatarFields }
+//	}`, media.AvatarFields)
+//
+//	// Package main.
+//	query := graphql.MustCompose(`query($login: String!) {
+//		user(login: $login) { ...UserFields }
+//	}`, users.UserFields)
+//
+// A Fragment is immutable, and safe for concurrent use.
+type Fragment struct {
+	name       string
+	definition string
+	spreads    []string    // Names of the fragments spread by the definition, in order.
+	uses       []*Fragment // Fragments the definition may spread.
+}
+
+// ParseFragment parses definition, a single fragment definition. uses are
+// the fragments it spreads, which are resolved by name, along with the
+// fragments they use in turn. Every fragment spread by definition must be
+// among them.
+func ParseFragment(definition string, uses ...*Fragment) (*Fragment, error) {
+	doc, err := parser.ParseDocument(definition)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: %v", err)
+	}
+	if len(doc.Fragments) != 1 || len(doc.Operations) != 0 {
+		return nil, errors.New("graphql: fragment must be a single fragment definition")
+	}
+	def := doc.Fragments[0]
+	f := &Fragment{
+		name:       def.Name,
+		definition: strings.TrimSpace(definition),
+		uses:       uses,
+	}
+	seen := make(map[string]bool)
+	fragmentSpreads(def.SelectionSet, func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			f.spreads = append(f.spreads, name)
+		}
+	})
+	known, err := collectFragments(uses)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range f.spreads {
+		if name != f.name && known[name] == nil {
+			return nil, fmt.Errorf("graphql: fragment %q spreads fragment %q, which it doesn't use", f.name, name)
+		}
+	}
+	return f, nil
+}
+
+// MustParseFragment is like ParseFragment, but panics if definition
+// can't be parsed. It simplifies initializing global variables.
+func MustParseFragment(definition string, uses ...*Fragment) *Fragment {
+	f, err := ParseFragment(definition, uses...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Name returns the name of f, as spread in documents, as in "...Name".
+func (f *Fragment) Name() string {
+	return f.name
+}
+
+// String returns the definition of f.
+func (f *Fragment) String() string {
+	return f.definition
+}
+
+// Compose returns document with the definitions of the fragments it
+// spreads appended, resolved by name from fragments and the fragments
+// they use, transitively. Each definition is appended once, in the order
+// the fragments are first spread, and only if it's spread, so fragments
+// can be passed whether or not the document needs them. Fragments that
+// document defines itself are left as they are.
+//
+// It's an error if a spread fragment can't be resolved, or if fragments
+// resolve a name to different definitions.
+func Compose(document string, fragments ...*Fragment) (string, error) {
+	doc, err := parser.ParseDocument(document)
+	if err != nil {
+		return "", fmt.Errorf("graphql: %v", err)
+	}
+	known, err := collectFragments(fragments)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(document)
+	added := make(map[string]bool)
+	var add func(name string) error
+	add = func(name string) error {
+		if added[name] || doc.Fragment(name) != nil {
+			return nil
+		}
+		f := known[name]
+		if f == nil {
+			return fmt.Errorf("graphql: document spreads unknown fragment %q", name)
+		}
+		added[name] = true
+		b.WriteString("\n")
+		b.WriteString(f.definition)
+		for _, name := range f.spreads {
+			if err := add(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	spread := func(name string) {
+		if err == nil {
+			err = add(name)
+		}
+	}
+	for _, op := range doc.Operations {
+		fragmentSpreads(op.SelectionSet, spread)
+	}
+	for _, f := range doc.Fragments {
+		fragmentSpreads(f.SelectionSet, spread)
+	}
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// MustCompose is like Compose, but panics if the document can't be
+// composed. It simplifies initializing global variables.
+func MustCompose(document string, fragments ...*Fragment) string {
+	s, err := Compose(document, fragments...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// collectFragments returns fragments and the fragments they use,
+// transitively, by name.
+func collectFragments(fragments []*Fragment) (map[string]*Fragment, error) {
+	known := make(map[string]*Fragment)
+	var collect func(fragments []*Fragment) error
+	collect = func(fragments []*Fragment) error {
+		for _, f := range fragments {
+			if other := known[f.name]; other != nil {
+				if other.definition != f.definition {
+					return fmt.Errorf("graphql: conflicting definitions of fragment %q", f.name)
+				}
+				continue
+			}
+			known[f.name] = f
+			if err := collect(f.uses); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return known, collect(fragments)
+}
+
+// fragmentSpreads calls spread with the name of every fragment spread in
+// sels, in order.
+func fragmentSpreads(sels []parser.Selection, spread func(name string)) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *parser.Field:
+			fragmentSpreads(sel.SelectionSet, spread)
+		case *parser.InlineFragment:
+			fragmentSpreads(sel.SelectionSet, spread)
+		case *parser.FragmentSpread:
+			spread(sel.Name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+var (
+	avatarFields = graphql.MustParseFragment(`fragment AvatarFields on Avatar { url(size: 64) }`)
+	userFields   = graphql.MustParseFragment(`fragment UserFields on User {
+	login
+	avatar { ...AvatarFields }
+}`, avatarFields)
+	repoFields = graphql.MustParseFragment(`fragment RepoFields on Repository {
+	name
+	owner { ...UserFields }
+}`, userFields)
+)
+
+func TestCompose(t *testing.T) {
+	got, err := graphql.Compose(`query {
+	viewer { ...UserFields }
+	repository(owner: "octocat", name: "Hello-World") { ...RepoFields ...Local }
+}
+fragment Local on Repository { id }`, repoFields, userFields, avatarFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query {
+	viewer { ...UserFields }
+	repository(owner: "octocat", name: "Hello-World") { ...RepoFields ...Local }
+}
+fragment Local on Repository { id }
+fragment UserFields on User {
+	login
+	avatar { ...AvatarFields }
+}
+fragment AvatarFields on Avatar { url(size: 64) }
+fragment RepoFields on Repository {
+	name
+	owner { ...UserFields }
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Unspread fragments aren't appended.
+	got, err = graphql.Compose(`{ viewer { login } }`, repoFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{ viewer { login } }`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCompose_errors(t *testing.T) {
+	conflicting := graphql.MustParseFragment(`fragment UserFields on User { login }`)
+	tests := []struct {
+		document  string
+		fragments []*graphql.Fragment
+		want      string
+	}{
+		{`{ viewer { ...UserFields } }`, nil, `graphql: document spreads unknown fragment "UserFields"`},
+		{`{ viewer { ...UserFields } }`, []*graphql.Fragment{repoFields, conflicting}, `graphql: conflicting definitions of fragment "UserFields"`},
+		{`{ viewer {`, nil, `graphql: 1:11: `},
+	}
+	for _, tt := range tests {
+		_, err := graphql.Compose(tt.document, tt.fragments...)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Compose(%q): got error %v, want %q", tt.document, err, tt.want)
+		}
+	}
+}
+
+func TestParseFragment_errors(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       string
+	}{
+		{`fragment UserFields on User { ...AvatarFields }`, `graphql: fragment "UserFields" spreads fragment "AvatarFields", which it doesn't use`},
+		{`query { viewer { login } }`, `graphql: fragment must be a single fragment definition`},
+		{`fragment A on User { login } fragment B on User { login }`, `graphql: fragment must be a single fragment definition`},
+	}
+	for _, tt := range tests {
+		if _, err := graphql.ParseFragment(tt.definition); err == nil || err.Error() != tt.want {
+			t.Errorf("ParseFragment(%q): got error %v, want %q", tt.definition, err, tt.want)
+		}
+	}
+}
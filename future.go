@@ -0,0 +1,43 @@
+package graphql
+
+import "context"
+
+// Future is an operation run in the background by Client.Go.
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+// Go starts running op in the background, as Run does, returning
+// a Future to wait for it with. The response data of op mustn't be
+// used until it's done. Like other background work of the client,
+// op is canceled by Close.
+//
+// Wait has the signature errgroup.Group.Go takes, so futures can be
+// joined with an errgroup:
+//
+//	user, repos := client.Go(ctx, userQuery), client.Go(ctx, reposQuery)
+//	var g errgroup.Group
+//	g.Go(user.Wait)
+//	g.Go(repos.Wait)
+//	err := g.Wait()
+func (c *Client) Go(ctx context.Context, op Operation) *Future {
+	f := &Future{done: make(chan struct{})}
+	c.goBackground(ctx, func(ctx context.Context) {
+		defer close(f.done)
+		f.err = c.Run(ctx, op)
+	})
+	return f
+}
+
+// Done returns a channel that's closed once the operation of f is done.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the operation of f to be done, and returns its error,
+// as Run does.
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
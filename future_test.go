@@ -0,0 +1,62 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestClient_Go(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	t.Cleanup(func() { client.Close() })
+
+	var q1, q2 struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	f1, f2 := client.Go(context.Background(), graphql.NewQuery(&q1, nil)), client.Go(context.Background(), graphql.NewQuery(&q2, nil))
+	<-f1.Done()
+	if err := f1.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f2.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if q1.Viewer.Login != "gopher" || q2.Viewer.Login != "gopher" {
+		t.Errorf("got logins %q and %q, want gopher", q1.Viewer.Login, q2.Viewer.Login)
+	}
+}
+
+func TestClient_Go_close(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	started := make(chan struct{}, 1)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: cancelableRoundTripper{localRoundTripper{handler: blockingHandler(started)}}})
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	f := client.Go(context.Background(), graphql.NewQuery(&q, nil))
+	<-started
+	select {
+	case <-f.Done():
+		t.Fatal("future done before the client is closed")
+	default:
+	}
+	client.Close()
+	if err := f.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
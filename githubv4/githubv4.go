@@ -0,0 +1,115 @@
+// Package githubv4 is a compatibility layer for code written against
+// package github.com/shurcooL/githubv4, running it on top of package
+// graphql. It has the same NewClient signature, Client methods and scalar
+// types, so migrating is a matter of changing import paths; query structs
+// need no changes.
+//
+// The GitHub enum and input object types of githubv4 aren't provided.
+// Define the ones used, with the same names as in the GitHub schema,
+// as their names are the types of the variables they're passed in:
+//
+//	type AddReactionInput struct {
+//		SubjectID githubv4.ID     `json:"subjectId"`
+//		Content   ReactionContent `json:"content"`
+//	}
+//	type ReactionContent string
+//
+// Variables of Go's string type are declared as ID!, as they are by
+// package github.com/shurcooL/githubv4, rather than as String!, as package
+// graphql declares them. Use String for variables of type String!.
+//
+// Client.Run and Client.GraphQL give access to the features of package
+// graphql, such as its Operation interface and client options.
+package githubv4
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Endpoint is the URL of the GitHub GraphQL API v4.
+const Endpoint = "https://api.github.com/graphql"
+
+// Client is a GitHub GraphQL API v4 client.
+type Client struct {
+	client *graphql.Client
+}
+
+// NewClient creates a new GitHub GraphQL API v4 client with the provided
+// HTTP client, which is expected to authenticate requests, and options
+// of package graphql. If httpClient is nil, http.DefaultClient is used.
+func NewClient(httpClient *http.Client, opts ...graphql.ClientOption) *Client {
+	return NewEnterpriseClient(Endpoint, httpClient, opts...)
+}
+
+// NewEnterpriseClient creates a new GitHub GraphQL API v4 client
+// targeting the GitHub Enterprise server at url, such as
+// "https://github.example.com/api/graphql", otherwise like NewClient.
+func NewEnterpriseClient(url string, httpClient *http.Client, opts ...graphql.ClientOption) *Client {
+	return &Client{client: graphql.NewClient(url, httpClient, opts...)}
+}
+
+// Query executes a single GraphQL query request, with a query derived
+// from q, populating the response into it. q should be a pointer to struct
+// that corresponds to the GitHub GraphQL schema.
+func (c *Client) Query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	return c.client.Query(ctx, q, stringIDs(variables))
+}
+
+// Mutate executes a single GraphQL mutation request, with a mutation
+// derived from m, populating the response into it. m should be a pointer
+// to struct that corresponds to the GitHub GraphQL schema. input is passed
+// in the "input" variable, alongside variables, which may be nil.
+func (c *Client) Mutate(ctx context.Context, m interface{}, input Input, variables map[string]interface{}) error {
+	variables = stringIDs(variables)
+	if variables == nil {
+		variables = map[string]interface{}{"input": input}
+	} else {
+		variables["input"] = input
+	}
+	return c.client.Mutate(ctx, m, variables)
+}
+
+// stringIDs returns a copy of variables with the values of Go's string
+// type, and of pointers to and slices of it, converted to ID, so they're
+// declared as ID! as package github.com/shurcooL/githubv4 declared them.
+func stringIDs(variables map[string]interface{}) map[string]interface{} {
+	if variables == nil {
+		return nil
+	}
+	ids := make(map[string]interface{}, len(variables))
+	for name, v := range variables {
+		switch v := v.(type) {
+		case string:
+			ids[name] = ID(v)
+		case *string:
+			ids[name] = (*ID)(v)
+		case []string:
+			l := make([]ID, len(v))
+			for i, s := range v {
+				l[i] = ID(s)
+			}
+			ids[name] = l
+		default:
+			ids[name] = v
+		}
+	}
+	return ids
+}
+
+// Run runs operation op, as graphql.Client.Run does.
+func (c *Client) Run(ctx context.Context, op graphql.Operation) error {
+	return c.client.Run(ctx, op)
+}
+
+// GraphQL returns the underlying client of package graphql.
+func (c *Client) GraphQL() *graphql.Client {
+	return c.client
+}
+
+// Input represents one of the Input structs:
+//
+// AddReactionInput, CreateIssueInput, and others, as defined by the caller.
+type Input interface{}
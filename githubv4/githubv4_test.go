@@ -0,0 +1,157 @@
+package githubv4_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/githubv4"
+)
+
+type AddReactionInput struct {
+	SubjectID githubv4.ID     `json:"subjectId"`
+	Content   ReactionContent `json:"content"`
+}
+
+type ReactionContent string
+
+// newServer returns a server that records the requests it gets in got,
+// and responds with data.
+func newServer(t *testing.T, got *graphql.Request, data string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(got); err != nil {
+			t.Error(err)
+		}
+		io.WriteString(w, `{"data": `+data+`}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientQuery(t *testing.T) {
+	var got graphql.Request
+	srv := newServer(t, &got, `{"repository": {"databaseId": 42, "url": "https://github.com/octocat/hello", "createdAt": "2011-01-26T19:01:12Z", "pushedAt": null}}`)
+	client := githubv4.NewEnterpriseClient(srv.URL, nil)
+
+	var q struct {
+		Repository struct {
+			DatabaseID githubv4.Int
+			URL        githubv4.URI
+			CreatedAt  githubv4.DateTime
+			PushedAt   *githubv4.DateTime
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{
+		"owner": githubv4.String("octocat"),
+		"name":  githubv4.String("hello"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($name:String!$owner:String!){repository(owner: $owner, name: $name){databaseId,url,createdAt,pushedAt}}`; got.Query != want {
+		t.Errorf("got query %q, want %q", got.Query, want)
+	}
+	if q.Repository.DatabaseID != 42 {
+		t.Errorf("got database ID %v, want 42", q.Repository.DatabaseID)
+	}
+	if u := q.Repository.URL.String(); u != "https://github.com/octocat/hello" {
+		t.Errorf("got URL %q, want %q", u, "https://github.com/octocat/hello")
+	}
+	if want := time.Date(2011, 1, 26, 19, 1, 12, 0, time.UTC); !q.Repository.CreatedAt.Equal(want) {
+		t.Errorf("got createdAt %v, want %v", q.Repository.CreatedAt, want)
+	}
+	if q.Repository.PushedAt != nil {
+		t.Errorf("got pushedAt %v, want nil", q.Repository.PushedAt)
+	}
+}
+
+func TestClientQuery_stringID(t *testing.T) {
+	var got graphql.Request
+	srv := newServer(t, &got, `{"node": {"id": "MDQ6VXNlcjU4MzIzMQ=="}, "nodes": []}`)
+	client := githubv4.NewEnterpriseClient(srv.URL, nil)
+
+	var q struct {
+		Node struct {
+			ID githubv4.ID
+		} `graphql:"node(id: $id)"`
+		Nodes []struct {
+			ID githubv4.ID
+		} `graphql:"nodes(ids: $ids)"`
+	}
+	id := "MDQ6VXNlcjU4MzIzMQ=="
+	variables := map[string]interface{}{
+		"id":  id,
+		"ids": []string{id},
+	}
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($id:ID!$ids:[ID!]!){node(id: $id){id},nodes(ids: $ids){id}}`; got.Query != want {
+		t.Errorf("got query %q, want %q", got.Query, want)
+	}
+	if q.Node.ID != githubv4.ID(id) {
+		t.Errorf("got ID %q, want %q", q.Node.ID, id)
+	}
+	if _, ok := variables["id"].(string); !ok {
+		t.Errorf("got variable id of type %T, want the caller's string", variables["id"])
+	}
+}
+
+func TestClientMutate(t *testing.T) {
+	var got graphql.Request
+	srv := newServer(t, &got, `{"addReaction": {"reaction": {"content": "HOORAY"}}}`)
+	client := githubv4.NewEnterpriseClient(srv.URL, nil)
+
+	var m struct {
+		AddReaction struct {
+			Reaction struct {
+				Content ReactionContent
+			}
+		} `graphql:"addReaction(input: $input)"`
+	}
+	input := AddReactionInput{SubjectID: "MDU6SXNzdWUyMTc5NTQ0OTc=", Content: "HOORAY"}
+	if err := client.Mutate(context.Background(), &m, input, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := `mutation($input:AddReactionInput!){addReaction(input: $input){reaction{content}}}`; got.Query != want {
+		t.Errorf("got query %q, want %q", got.Query, want)
+	}
+	want := map[string]interface{}{"input": map[string]interface{}{"subjectId": "MDU6SXNzdWUyMTc5NTQ0OTc=", "content": "HOORAY"}}
+	if !reflect.DeepEqual(got.Variables, want) {
+		t.Errorf("got variables %v, want %v", got.Variables, want)
+	}
+	if m.AddReaction.Reaction.Content != "HOORAY" {
+		t.Errorf("got content %q, want %q", m.AddReaction.Reaction.Content, "HOORAY")
+	}
+}
+
+func TestClientRun(t *testing.T) {
+	var got graphql.Request
+	srv := newServer(t, &got, `{"viewer": {"login": "octocat"}}`)
+	client := githubv4.NewEnterpriseClient(srv.URL, nil)
+
+	var q struct {
+		Viewer struct {
+			Login githubv4.String
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.Query{Data: &q, Name: "Viewer"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `query Viewer{viewer{login}}`; got.Query != want {
+		t.Errorf("got query %q, want %q", got.Query, want)
+	}
+	if q.Viewer.Login != "octocat" {
+		t.Errorf("got login %q, want %q", q.Viewer.Login, "octocat")
+	}
+	if client.GraphQL() == nil {
+		t.Error("got nil graphql client")
+	}
+}
@@ -0,0 +1,179 @@
+package githubv4
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Note: These custom types are meant to be used in queries, as well as
+// in variables, with the same names as in the GitHub GraphQL schema.
+
+type (
+	// Boolean represents true or false values.
+	Boolean = graphql.Boolean
+
+	// Float represents signed double-precision fractional values as
+	// specified by IEEE 754.
+	Float = graphql.Float
+
+	// ID represents a unique identifier that is Base64 obfuscated.
+	ID = graphql.ID
+
+	// Int represents non-fractional signed whole numeric values.
+	Int = graphql.Int
+
+	// String represents textual data as UTF-8 character sequences.
+	String = graphql.String
+)
+
+type (
+	// Base64String is a (potentially binary) string encoded using base64.
+	Base64String string
+
+	// Date is an ISO-8601 encoded date.
+	Date struct{ time.Time }
+
+	// DateTime is an ISO-8601 encoded UTC date.
+	DateTime struct{ time.Time }
+
+	// GitObjectID is a Git object ID. For example,
+	// "912ec1990bd09f8fc128c3fa6b59105085aabc03".
+	GitObjectID string
+
+	// GitRefname is a fully qualified reference name (e.g.,
+	// "refs/heads/master").
+	GitRefname string
+
+	// GitSSHRemote is a Git SSH string.
+	GitSSHRemote string
+
+	// GitTimestamp is an ISO-8601 encoded date. Unlike the DateTime type,
+	// GitTimestamp is not converted in UTC.
+	GitTimestamp struct{ time.Time }
+
+	// HTML is a string containing HTML code.
+	HTML string
+
+	// PreciseDateTime is an ISO-8601 encoded UTC date with full precision.
+	PreciseDateTime struct{ time.Time }
+
+	// URI is an RFC 3986, RFC 3987, and RFC 6570 (level 4) compliant URI.
+	URI struct{ *url.URL }
+
+	// X509Certificate is a valid x509 certificate string.
+	X509Certificate struct{ *x509.Certificate }
+)
+
+// MarshalJSON implements the json.Marshaler interface. Dates are encoded
+// as "YYYY-MM-DD".
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format("2006-01-02"))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// dates encoded as "YYYY-MM-DD", and as RFC 3339 times.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, s)
+	}
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The URI is a quoted string.
+func (u URI) MarshalJSON() ([]byte, error) {
+	if u.URL == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The URI is expected to be a quoted string.
+func (u *URI) UnmarshalJSON(data []byte) error {
+	// Ignore null, like in the main JSON package.
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	var err error
+	u.URL, err = url.Parse(s)
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (X509Certificate) MarshalJSON() ([]byte, error) {
+	// TODO: Implement.
+	return nil, errors.New("githubv4.X509Certificate.MarshalJSON: not implemented")
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (*X509Certificate) UnmarshalJSON(data []byte) error {
+	// TODO: Implement.
+	return errors.New("githubv4.X509Certificate.UnmarshalJSON: not implemented")
+}
+
+// NewBase64String is a helper to make a new *Base64String.
+func NewBase64String(v Base64String) *Base64String { return &v }
+
+// NewBoolean is a helper to make a new *Boolean.
+func NewBoolean(v Boolean) *Boolean { return &v }
+
+// NewDate is a helper to make a new *Date.
+func NewDate(v Date) *Date { return &v }
+
+// NewDateTime is a helper to make a new *DateTime.
+func NewDateTime(v DateTime) *DateTime { return &v }
+
+// NewFloat is a helper to make a new *Float.
+func NewFloat(v Float) *Float { return &v }
+
+// NewGitObjectID is a helper to make a new *GitObjectID.
+func NewGitObjectID(v GitObjectID) *GitObjectID { return &v }
+
+// NewGitRefname is a helper to make a new *GitRefname.
+func NewGitRefname(v GitRefname) *GitRefname { return &v }
+
+// NewGitSSHRemote is a helper to make a new *GitSSHRemote.
+func NewGitSSHRemote(v GitSSHRemote) *GitSSHRemote { return &v }
+
+// NewGitTimestamp is a helper to make a new *GitTimestamp.
+func NewGitTimestamp(v GitTimestamp) *GitTimestamp { return &v }
+
+// NewHTML is a helper to make a new *HTML.
+func NewHTML(v HTML) *HTML { return &v }
+
+// NewID is a helper to make a new *ID.
+func NewID(v ID) *ID { return &v }
+
+// NewInt is a helper to make a new *Int.
+func NewInt(v Int) *Int { return &v }
+
+// NewPreciseDateTime is a helper to make a new *PreciseDateTime.
+func NewPreciseDateTime(v PreciseDateTime) *PreciseDateTime { return &v }
+
+// NewString is a helper to make a new *String.
+func NewString(v String) *String { return &v }
+
+// NewURI is a helper to make a new *URI.
+func NewURI(v URI) *URI { return &v }
+
+// NewX509Certificate is a helper to make a new *X509Certificate.
+func NewX509Certificate(v X509Certificate) *X509Certificate { return &v }
@@ -0,0 +1,65 @@
+package githubv4_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql/githubv4"
+)
+
+func TestDate(t *testing.T) {
+	for _, in := range []string{`"2017-05-04"`, `"2017-05-04T00:00:00Z"`} {
+		var d githubv4.Date
+		if err := json.Unmarshal([]byte(in), &d); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if want := time.Date(2017, 5, 4, 0, 0, 0, 0, time.UTC); !d.Equal(want) {
+			t.Errorf("%s: got %v, want %v", in, d.Time, want)
+		}
+		got, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"2017-05-04"`; string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	var u githubv4.URI
+	if err := json.Unmarshal([]byte(`"https://example.org/foo/bar"`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.String(), "https://example.org/foo/bar"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got, err := json.Marshal(githubv4.URI{URL: &url.URL{Scheme: "https", Host: "example.org", Path: "/foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"https://example.org/foo"`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	got, err = json.Marshal(githubv4.URI{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "null" {
+		t.Errorf("got %s, want null", got)
+	}
+}
+
+func TestNewScalars(t *testing.T) {
+	if got := *githubv4.NewString("foo"); got != "foo" {
+		t.Errorf("got %q, want %q", got, "foo")
+	}
+	if got := *githubv4.NewID("MDQ6VXNlcjE="); got != "MDQ6VXNlcjE=" {
+		t.Errorf("got %q, want %q", got, "MDQ6VXNlcjE=")
+	}
+	if got := *githubv4.NewGitObjectID("912ec19"); got != "912ec19" {
+		t.Errorf("got %q, want %q", got, "912ec19")
+	}
+}
@@ -5,29 +5,137 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/arvata-io/graphql/internal/jsonutil"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 // Client is a GraphQL client.
 type Client struct {
 	url        string // GraphQL server URL.
 	httpClient *http.Client
+
+	logger    Logger
+	schema    *Schema
+	autoName  bool
+	bodyName  bool // Whether to send operation names as operationName.
+	strict    bool // Whether to follow the GraphQL over HTTP specification strictly.
+	manifest  *Manifest
+	allowList *Manifest
+
+	codec             Codec
+	requestTransform  func(io.Reader) io.Reader
+	responseTransform func(io.Reader) io.Reader
+	statusPolicy      StatusPolicy
+	maxErrorBody      int
+	int64Strings      map[string]bool // Scalars of Int64 and BigInt variables to send as strings; empty means all.
+	redirectHosts     []string        // Hosts redirects may be followed to; "" is the server's host.
+	proxy             string
+	tls               tlsOptions
+	timeouts          *Timeouts  // Timeouts applied to the transport, if any.
+	keepAlive         *KeepAlive // Keep-alive configuration of the transport, if any.
+	dnsRefresh        *dnsRefresh
+	ipPreference      IPPreference
+	session           session
+	ownTransport      *http.Transport // Copy of the HTTP client's transport configured by options, if any.
+	configErr         error           // Error configuring the client, returned by all requests.
+	pooled            bool            // Whether to reuse memory across responses, set by WithPooledDecoding.
+	deadlineHeader    string          // Header to send the time remaining until the deadline in, if any.
+	strictVars        bool            // Whether operations must use the variables they define.
+	pruneVars         bool            // Whether to remove variables operations don't use.
+	persistedGET      bool            // Whether to send queries as persisted queries with GET requests.
+	clientDirectives  map[string]bool // Names of directives to strip from operations.
+	inFlight          chan struct{}   // Slots of requests in flight, if limited by WithMaxConcurrency.
+	customTransport   Transport       // Transport sending requests instead of HTTP, if any.
+	observers         []func(context.Context, OperationEvent)
+	redact            RedactPolicy // Policy of headers redacted from debugging output, if not the default.
+	har               *HARRecorder // Recorder of HTTP exchanges, if any.
+	shadow            *Shadow      // Mirroring of queries to a secondary server, if any.
+	routes            []Route      // Rules routing operations to other endpoints.
+	flagEnabled       func(ctx context.Context, flag string) bool
+	versionHeader     string            // Header selecting the API version, if any.
+	version           string            // API version selected by default.
+	deprecations      map[string]string // Deprecated API version -> message.
+	versionWarn       func(ctx context.Context, w VersionWarning)
+	docBudget         int // Size of constructed documents warned about, if non-zero.
+	docBudgetWarn     func(ctx context.Context, w DocumentSizeWarning) error
+
+	validated queryCache[[]Issue]           // Query string -> []Issue, for queries validated against schema.
+	coercions queryCache[*coercion]         // Query string -> *coercion, for queries run with a schema.
+	varChecks queryCache[varCheck]          // Query string -> varCheck, for queries run without a schema.
+	stripped  queryCache[strippedQuery]     // Query string -> strippedQuery, for queries with client directives.
+	queryDocs queryCache[map[string]string] // Query string -> operation name -> type, for shadowing, caching and persisted queries.
+	gated     queryCache[[]gatedField]      // Query string -> []gatedField, for queries with fields gated by feature flags.
+
+	versionWarned sync.Map         // Version and message -> true, for deprecation warnings logged.
+	budgetWarned  queryCache[bool] // Query hash -> true, for document size warnings logged.
+
+	clock        Clock
+	faults       *faultInjector // Faults injected into requests, if any.
+	cache        Cache
+	cacheTTL     time.Duration
+	cacheHints   CacheHintPolicy
+	cacheKeyFunc CacheKeyFunc
+	cacheVary    []string // Canonical names of headers the default cache key depends on.
+	prefetchMu   sync.Mutex
+	prefetches   map[string]*prefetch // Cache key -> prefetch, for prefetches not used yet.
+	prefetchSets int                  // Prefetches added since prefetches was last swept.
+	stampede     *StampedePolicy      // Protection from stampedes of queries missing the cache, if any.
+	flightMu     sync.Mutex
+	flights      map[string]*flight // Cache key -> fetch of its response, with stampede protection.
+
+	invalidations map[string]func(json.RawMessage) []string // Subscription field -> entities invalidated by its events.
+	entityMu      sync.Mutex
+	entityKeys    map[string]map[string]struct{} // "Typename:id" -> keys of cached responses containing it.
+	keyEntities   map[string]indexedEntry        // Key of cached response -> entities it contains.
+	entitySets    int                            // Responses indexed since keyEntities was last swept.
+
+	mirrors chan struct{} // Queries mirrored by shadow in flight, up to its MaxInFlight.
+
+	lifecycle *lifecycle
 }
 
 // NewClient creates a GraphQL client targeting the specified GraphQL server URL.
-// If httpClient is nil, then http.DefaultClient is used.
-func NewClient(url string, httpClient *http.Client) *Client {
+// If httpClient is nil, then http.DefaultClient is used. The client doesn't
+// follow redirects, unless WithRedirects is given.
+func NewClient(url string, httpClient *http.Client, opts ...ClientOption) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
-	return &Client{
+	c := &Client{
 		url:        url,
 		httpClient: httpClient,
+		codec:      JSONCodec{},
+		clock:      systemClock{},
+		lifecycle:  newLifecycle(),
+
+		statusPolicy: DefaultStatusPolicy,
+		maxErrorBody: DefaultMaxErrorBody,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if cache, ok := c.cache.(clocked); ok {
+		cache.setClock(c.clock)
+	}
+	// Copy the HTTP client, so its redirect policy can be set
+	// without affecting other users of it.
+	hc := *c.httpClient
+	hc.CheckRedirect = c.checkRedirect(hc.CheckRedirect)
+	c.httpClient = &hc
+	c.configErr = c.configureTransport()
+	if err := c.configureSession(); err != nil && c.configErr == nil {
+		c.configErr = err
 	}
+	if c.har != nil {
+		c.recordHAR()
+	}
+	return c
 }
 
 // Query executes a single GraphQL query request,
@@ -52,74 +160,378 @@ func (c *Client) Mutate(ctx context.Context, m interface{}, variables map[string
 	})
 }
 
-type request struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
+// Request is a GraphQL request, in the wire format of
+// GraphQL over HTTP.
+type Request struct {
+	Query         string                 `json:"query,omitempty"`
+	DocumentID    string                 `json:"documentId,omitempty"` // Sent instead of Query for trusted documents.
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
 }
 
-// do executes a single GraphQL operation.
+// Response is a GraphQL response, in the wire format of
+// GraphQL over HTTP.
+type Response struct {
+	Data       json.RawMessage        `json:"data,omitempty"` // Undecoded; nil if absent or null.
+	Errors     ErrorList              `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	HTTP ResponseMeta `json:"-"` // Metadata of the HTTP response carrying the GraphQL response.
+
+	cached bool // Whether the response was served from the cache.
+}
+
+// ResponseMeta is metadata of the HTTP response carrying a GraphQL response.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// Run executes a single GraphQL operation op.
 func (c *Client) Run(ctx context.Context, op Operation) error {
-	in := request{
-		Query:     op.Query(),
-		Variables: op.Variables(),
-	}
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(in)
+	out, err := c.do(ctx, op)
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest(http.MethodPost, c.url, &buf)
+	if len(out.Errors) > 0 {
+		return out.Errors
+	}
+	return nil
+}
+
+// do executes a single GraphQL operation, decoding the data of the
+// response into op.ResponsePtr(). The errors in the response are
+// returned in out, not as err.
+func (c *Client) do(ctx context.Context, op Operation) (out Response, err error) {
+	if c.autoName {
+		op = nameOperation(op)
+	}
+	if len(c.observers) > 0 {
+		start, t := c.clock.Now(), &connTracer{clock: c.clock}
+		defer func(ctx context.Context) { c.observe(ctx, op, start, t, out, err) }(ctx)
+		ctx = t.trace(ctx)
+	}
+	if c.versionHeader != "" {
+		version := c.versionOf(op)
+		c.checkVersion(ctx, op, version)
+		defer func() { c.checkServerDeprecation(ctx, op, version, out.HTTP.Header) }()
+	}
+	if c.shadow != nil {
+		defer func() {
+			if err == nil {
+				c.mirror(ctx, op, out)
+			}
+		}()
+	}
+	if r, ok := asOperation[*retried](op); ok {
+		uploads := findUploads(op.Variables())
+		offsets, ok := uploadOffsets(uploads)
+		if !ok {
+			// The bodies of the uploads can't be sent again.
+			return c.doOnce(ctx, op)
+		}
+		return c.retry(ctx, r.policy, func() (Response, error) {
+			if err := rewindUploads(uploads, offsets); err != nil {
+				return Response{}, err
+			}
+			return c.doOnce(ctx, op)
+		})
+	}
+	return c.doOnce(ctx, op)
+}
+
+// doOnce executes op once, as do does.
+func (c *Client) doOnce(ctx context.Context, op Operation) (out Response, err error) {
+	defer recoverPanic(&err)
+	in, endpoint, err := c.request(ctx, op)
+	if err != nil {
+		return out, err
+	}
+	key := c.cacheKey(ctx, op, endpoint, in)
+	policy := fetchPolicy(ctx, op)
+	if policy == ForceNetwork {
+		key = ""
+	}
+	if policy == CacheFirst {
+		if data, ok := c.cached(ctx, op, key); ok {
+			return Response{Data: data, cached: true}, c.decodeData(data, op.ResponsePtr())
+		}
+		if out, ok := c.cachedNegative(op, key); ok {
+			if out.Data != nil {
+				err = c.decodeData(out.Data, op.ResponsePtr())
+			}
+			return out, err
+		}
+		if key != "" && c.stampede != nil {
+			data, done := c.joinFlight(ctx, op, key)
+			if data != nil {
+				return Response{Data: data, cached: true}, c.decodeData(data, op.ResponsePtr())
+			}
+			if done != nil {
+				defer done()
+			}
+		}
+	}
+	if id := c.documentID(op, in.Query); id != "" {
+		in.Query, in.DocumentID = "", id
+	}
+	var into interface{}
+	if _, ok := c.codec.(JSONCodec); ok && !c.strict {
+		if _, isTarget := op.ResponsePtr().(responseDecoder); isTarget || hasStream(reflect.TypeOf(op.ResponsePtr())) {
+			into = op.ResponsePtr()
+		}
+	}
+	if c.usePersistedGET(in) {
+		out, err = c.roundTripPersisted(ctx, endpoint, in, c.modifier(op), into)
+	} else {
+		out, err = c.roundTrip(ctx, endpoint, in, c.modifier(op), into)
+	}
 	if err != nil {
+		return out, err
+	}
+	c.store(op, key, out)
+	if out.Data != nil {
+		err := c.decodeData(out.Data, op.ResponsePtr())
+		if err != nil {
+			// TODO: Consider including response body in returned error, if deemed helpful.
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// DataDecoder is implemented by query data structures that decode response
+// data themselves, rather than with reflection, such as those with methods
+// generated by package querygen.
+type DataDecoder interface {
+	DecodeGraphQL(data []byte) error
+}
+
+// decodeData decodes response data into query data structure v.
+func (c *Client) decodeData(data []byte, v interface{}) error {
+	if d, ok := v.(DataDecoder); ok {
+		return d.DecodeGraphQL(data)
+	}
+	unmarshal := jsonutil.UnmarshalGraphQL
+	if c.pooled {
+		unmarshal = jsonutil.UnmarshalGraphQLPooled
+	}
+	if ok, err := decodeParallel(data, v, unmarshal); ok {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	op.ModifyRequest(req)
+	return unmarshal(data, v)
+}
 
-	resp, err := ctxhttp.Do(ctx, c.httpClient, req)
+// request returns the GraphQL request for op, without fields gated by
+// disabled feature flags, without unused variables if c prunes them,
+// validated and with its variables coerced if c has a schema, or checked
+// for undefined variables otherwise, and the endpoint to send it to.
+func (c *Client) request(ctx context.Context, op Operation) (in Request, endpoint string, err error) {
+	if op, ok := asOperation[*StaticTemplate](op); ok {
+		if err := op.render(); err != nil {
+			return in, "", err
+		}
+	}
+	// Snapshot variables, so the request isn't affected by changes
+	// made to them after Run is called, such as by another goroutine.
+	in = Request{
+		Query:     op.Query(),
+		Variables: copyVariables(op.Variables()),
+	}
+	if op, ok := asOperation[NamedOperation](op); ok && c.bodyName {
+		in.OperationName = op.OperationName()
+	}
+	in.Query = c.applyFeatureFlags(ctx, in.Query)
+	in.Query = c.stripClientDirectives(in.Query).query
+	if c.pruneVars {
+		c.pruneVariables(&in)
+	}
+	if c.schema != nil {
+		co := c.coercion(in.Query)
+		in.Query = co.query
+		if err := c.validate(op, in.Query); err != nil {
+			return in, "", err
+		}
+		if in.Variables, err = c.schema.coerceVariables(co, in.Variables); err != nil {
+			return in, "", err
+		}
+	} else if err := c.checkVariables(in.Query); err != nil {
+		return in, "", err
+	}
+	if err := c.checkDocumentSize(ctx, op, in.Query); err != nil {
+		return in, "", err
+	}
+	endpoint, err = c.endpoint(ctx, op, in.Query)
+	return in, endpoint, err
+}
+
+// RoundTrip sends GraphQL request in to the server and returns its response,
+// without decoding the response data. It's intended for tools, such as proxies,
+// caches and replayers, that work at the protocol layer.
+//
+// GraphQL errors in the response are returned in out.Errors, not as err.
+// If the server responds with a status code not classified as StatusGraphQL,
+// err is a *StatusError and out.HTTP is set.
+func (c *Client) RoundTrip(ctx context.Context, in Request) (out Response, err error) {
+	endpoint, err := expandURL(ctx, c.url, in.Variables)
 	if err != nil {
-		return err
+		return out, err
+	}
+	return c.roundTrip(ctx, endpoint, in, nil, nil)
+}
+
+// roundTrip implements RoundTrip, sending in to endpoint. If modify is
+// non-nil, it's called with the HTTP request before it's sent. If into
+// is non-nil, the response data is decoded into it while the response
+// is read, and out.Data isn't set.
+func (c *Client) roundTrip(ctx context.Context, endpoint string, in Request, modify func(*http.Request), into interface{}) (out Response, err error) {
+	defer recoverPanic(&err)
+	if c.configErr != nil {
+		return out, c.configErr
+	}
+	if err := c.checkAllowed(in); err != nil {
+		return out, err
+	}
+	return c.exchange(ctx, endpoint, in, modify, into)
+}
+
+// exchange sends in to endpoint as roundTrip does, once it's allowed.
+func (c *Client) exchange(ctx context.Context, endpoint string, in Request, modify func(*http.Request), into interface{}) (out Response, err error) {
+	defer recoverPanic(&err)
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return out, err
+	}
+	defer release()
+	in.Variables = c.stringifyInt64s(omitAbsent(in.Variables))
+	if c.customTransport != nil {
+		return c.roundTripCustom(ctx, endpoint, in, modify, into)
+	}
+	var req *http.Request
+	if c.persistedGET && isPersistedGET(in) {
+		req, err = newPersistedGET(endpoint, in)
+	} else {
+		req, err = c.newPost(endpoint, in)
+	}
+	if err != nil {
+		return out, err
+	}
+	if _, ok := c.codec.(JSONCodec); !ok {
+		req.Header.Set("Accept", c.codec.ContentType())
+	} else if c.strict {
+		setStrictHeaders(req)
+	}
+	if req.Method == http.MethodGet {
+		req.Header.Del("Content-Type")
+	}
+	c.setDeadlineHeader(ctx, req)
+	if modify != nil {
+		modify(req)
+	}
+	c.loadCookies(req.URL)
+	if err := c.setCSRFHeader(ctx, req); err != nil {
+		return out, err
+	}
+
+	c.refreshDNS()
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return out, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("non-200 OK status code: %v body: %q", resp.Status, body)
+	if len(resp.Header["Set-Cookie"]) > 0 {
+		c.saveCookies(req.URL)
+	}
+	if err := decodeContentEncoding(resp); err != nil {
+		return Response{HTTP: ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header}}, err
+	}
+	if c.responseTransform != nil {
+		resp.Body = ioutil.NopCloser(c.responseTransform(resp.Body))
 	}
-	var out struct {
-		Data   *json.RawMessage
-		Errors errors
-		//Extensions interface{} // Unused.
+	if _, ok := c.codec.(JSONCodec); ok && c.strict {
+		return c.readStrict(resp)
+	}
+	meta := ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header}
+	if c.statusPolicy(resp.StatusCode) != StatusGraphQL {
+		return Response{HTTP: meta}, c.statusError(resp)
+	}
+	if into != nil {
+		err = decodeStreaming(resp.Body, &out, into)
+	} else if c.pooled {
+		err = decodePooled(c.codec, resp.Body, &out)
+	} else {
+		err = c.codec.DecodeResponse(resp.Body, &out)
 	}
-	err = json.NewDecoder(resp.Body).Decode(&out)
 	if err != nil {
 		// TODO: Consider including response body in returned error, if deemed helpful.
-		return err
+		return Response{HTTP: meta}, err
 	}
-	if out.Data != nil {
-		err := jsonutil.UnmarshalGraphQL(*out.Data, op.ResponsePtr())
+	if string(out.Data) == "null" {
+		out.Data = nil
+	}
+	out.HTTP = meta
+	return out, nil
+}
+
+// newPost returns a POST request sending in to endpoint, encoded with
+// the codec of c, or as a multipart request if it has uploads.
+func (c *Client) newPost(endpoint string, in Request) (*http.Request, error) {
+	var buf bytes.Buffer
+	if err := c.codec.EncodeRequest(&buf, in); err != nil {
+		return nil, err
+	}
+	var body io.Reader = &buf
+	contentType, contentLength := c.codec.ContentType(), int64(-1)
+	if uploads := findUploads(in.Variables); len(uploads) > 0 {
+		if _, ok := c.codec.(JSONCodec); !ok {
+			return nil, fmt.Errorf("cannot send uploads with codec %T; want JSONCodec", c.codec)
+		}
+		mb := newMultipartBody(buf.Bytes(), uploads)
+		body, contentType, contentLength = mb, mb.contentType, mb.length
+	} else if c.requestTransform != nil {
+		b, err := ioutil.ReadAll(c.requestTransform(&buf))
 		if err != nil {
-			// TODO: Consider including response body in returned error, if deemed helpful.
-			return err
+			return nil, fmt.Errorf("transforming request body: %v", err)
 		}
+		body = bytes.NewReader(b)
 	}
-	if len(out.Errors) > 0 {
-		return out.Errors
+	req, err := http.NewRequest(http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
 }
 
-// errors represents the "errors" array in a response from a GraphQL server.
-// If returned via error interface, the slice is expected to contain at least 1 element.
+// Error is an error in the "errors" array of a response from a GraphQL server.
 //
-// Specification: https://facebook.github.io/graphql/#sec-Errors.
-type errors []struct {
-	Message   string
+// Specification: https://spec.graphql.org/October2021/#sec-Errors.
+type Error struct {
+	Message   string `json:"message"`
 	Locations []struct {
-		Line   int
-		Column int
-	}
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`       // Field names and list indices, if the error is associated with a field.
+	Extensions map[string]interface{} `json:"extensions,omitempty"` // Additional information, such as an error code.
 }
 
 // Error implements error interface.
-func (e errors) Error() string {
+func (e Error) Error() string {
+	return e.Message
+}
+
+// ErrorList represents the "errors" array in a response from a GraphQL server.
+// If returned via error interface, the slice is expected to contain at least 1 element.
+//
+// Specification: https://facebook.github.io/graphql/#sec-Errors.
+type ErrorList []Error
+
+// Error implements error interface.
+func (e ErrorList) Error() string {
 	return e[0].Message
 }
@@ -0,0 +1,137 @@
+package graphqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// BenchmarkData is the query data structure of the documents returned by
+// BenchmarkDocument, a repository with its issues, which covers nested
+// objects, lists, aliases, arguments and custom scalars.
+type BenchmarkData struct {
+	Repository struct {
+		ID          graphql.ID
+		Name        graphql.String
+		Description *graphql.String
+		Stars       graphql.Int `graphql:"stars: stargazerCount"`
+		Issues      struct {
+			TotalCount graphql.Int
+			Nodes      []struct {
+				Number    graphql.Int
+				Title     graphql.String
+				Body      graphql.String
+				Closed    graphql.Boolean
+				CreatedAt time.Time
+				Author    *struct {
+					Login graphql.String
+				}
+				Labels struct {
+					Nodes []struct {
+						Name  graphql.String
+						Color graphql.String
+					}
+				} `graphql:"labels(first: 10)"`
+			}
+		} `graphql:"issues(first: $first, after: $after)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// BenchmarkVariables returns the variables of a query for BenchmarkData.
+func BenchmarkVariables() map[string]interface{} {
+	return map[string]interface{}{
+		"owner": graphql.String("arvata-io"),
+		"name":  graphql.String("graphql"),
+		"first": graphql.Int(100),
+		"after": (*graphql.String)(nil),
+	}
+}
+
+// BenchmarkSizes are the numbers of issues of small, medium and huge
+// benchmark documents.
+var BenchmarkSizes = []struct {
+	Name   string
+	Issues int
+}{
+	{"small", 1},
+	{"medium", 100},
+	{"huge", 10000},
+}
+
+// BenchmarkDocument returns GraphQL response data JSON for BenchmarkData,
+// with n issues.
+func BenchmarkDocument(n int) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `{"repository":{"id":"R_1","name":"graphql","description":"A GraphQL client.","stars":1234,"issues":{"totalCount":%d,"nodes":[`, n)
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		author := `{"login":"gopher"}`
+		if i%10 == 9 {
+			author = "null"
+		}
+		fmt.Fprintf(&buf, `{"number":%d,"title":"Issue %d","body":"Steps to reproduce:\n1. Run the \"example\".\n2. See the error.","closed":%t,"createdAt":%q,"author":%s,"labels":{"nodes":[{"name":"bug","color":"d73a4a"},{"name":"help wanted","color":"008672"}]}}`,
+			i+1, i+1, i%2 == 0, created.Add(time.Duration(i)*time.Hour).Format(time.RFC3339), author)
+	}
+	buf.WriteString(`]}}}`)
+	return buf.Bytes()
+}
+
+// BenchmarkDecode benchmarks decoding GraphQL response data JSON data into
+// new values of the type of query data structure v, as the client does.
+func BenchmarkDecode(b *testing.B, v interface{}, data []byte) {
+	t := reflect.TypeOf(v)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := jsonutil.UnmarshalGraphQL(data, reflect.New(t).Interface()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONDecode is like BenchmarkDecode, but decodes with
+// encoding/json, as a baseline. It requires the response keys of v
+// to match its field names, as encoding/json does.
+func BenchmarkJSONDecode(b *testing.B, v interface{}, data []byte) {
+	t := reflect.TypeOf(v)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkQuery benchmarks generating the query of query data structure v
+// with variables.
+func BenchmarkQuery(b *testing.B, v interface{}, variables map[string]interface{}) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if graphql.NewQuery(v, variables).Query() == "" {
+			b.Fatal("empty query")
+		}
+	}
+}
+
+// BenchmarkEncodeRequest benchmarks encoding a request for the query of
+// query data structure v with variables, with codec.
+func BenchmarkEncodeRequest(b *testing.B, codec graphql.Codec, v interface{}, variables map[string]interface{}) {
+	req := graphql.Request{Query: graphql.NewQuery(v, variables).Query(), Variables: variables}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := codec.EncodeRequest(ioutil.Discard, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
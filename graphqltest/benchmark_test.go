@@ -0,0 +1,42 @@
+package graphqltest_test
+
+import (
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+func TestBenchmarkDocument(t *testing.T) {
+	var got graphqltest.BenchmarkData
+	if err := jsonutil.UnmarshalGraphQL(graphqltest.BenchmarkDocument(10), &got); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(got.Repository.Issues.Nodes); n != 10 {
+		t.Fatalf("got %d issues, want 10", n)
+	}
+	if got.Repository.Stars != 1234 || got.Repository.Issues.Nodes[9].Author != nil {
+		t.Errorf("got unexpected repository: %+v", got.Repository)
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	graphqltest.BenchmarkQuery(b, &graphqltest.BenchmarkData{}, graphqltest.BenchmarkVariables())
+}
+
+func BenchmarkEncodeRequest(b *testing.B) {
+	graphqltest.BenchmarkEncodeRequest(b, graphql.JSONCodec{}, &graphqltest.BenchmarkData{}, graphqltest.BenchmarkVariables())
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, size := range graphqltest.BenchmarkSizes {
+		data := graphqltest.BenchmarkDocument(size.Issues)
+		b.Run(size.Name, func(b *testing.B) {
+			graphqltest.BenchmarkDecode(b, graphqltest.BenchmarkData{}, data)
+		})
+		b.Run(size.Name+"/encoding-json", func(b *testing.B) {
+			graphqltest.BenchmarkJSONDecode(b, graphqltest.BenchmarkData{}, data)
+		})
+	}
+}
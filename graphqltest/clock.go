@@ -0,0 +1,82 @@
+package graphqltest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Clock is a graphql.Clock whose time only changes when it's advanced,
+// for testing time-dependent behavior, such as the expiry of cached
+// responses, deterministically and without sleeping:
+//
+//	clock := graphqltest.NewClock(time.Now())
+//	client := graphql.NewClient(url, nil, graphql.WithClock(clock), graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+//	...
+//	clock.Advance(time.Minute) // Cached responses expire.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*clockTimer
+}
+
+type clockTimer struct {
+	clock *Clock
+	when  time.Time
+	f     func()
+}
+
+// NewClock returns a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time of c.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc calls f in its own goroutine once c is advanced by d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) graphql.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &clockTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance advances the time of c by d, firing the timers that are due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due, pending []*clockTimer
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			due = append(due, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+	for _, t := range due {
+		go t.f()
+	}
+}
+
+// Stop implements graphql.Timer.
+func (t *clockTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
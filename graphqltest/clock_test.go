@@ -0,0 +1,71 @@
+package graphqltest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestClock(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"viewer": {"login": "gopher"}}}`))
+	}))
+	defer ts.Close()
+
+	clock := graphqltest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := graphql.NewClient(ts.URL, ts.Client(), graphql.WithClock(clock), graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	query := func(wantRequests int32) {
+		t.Helper()
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := atomic.LoadInt32(&requests); got != wantRequests {
+			t.Errorf("got %d requests, want %d", got, wantRequests)
+		}
+	}
+	query(1)
+	clock.Advance(59 * time.Second)
+	query(1)
+	clock.Advance(time.Second) // The cached response expires.
+	query(2)
+}
+
+func TestClock_AfterFunc(t *testing.T) {
+	clock := graphqltest.NewClock(time.Unix(0, 0))
+	fired := make(chan int, 2)
+	clock.AfterFunc(time.Second, func() { fired <- 1 })
+	stopped := clock.AfterFunc(time.Second, func() { fired <- 2 })
+	if !stopped.Stop() {
+		t.Error("Stop of a pending timer returned false")
+	}
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case n := <-fired:
+		t.Fatalf("timer %d fired early", n)
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	if n := <-fired; n != 1 {
+		t.Errorf("got timer %d fired, want 1", n)
+	}
+	if stopped.Stop() {
+		t.Error("Stop of a stopped timer returned true")
+	}
+	if got, want := clock.Now(), time.Unix(1, 0); !got.Equal(want) {
+		t.Errorf("got now: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+// Package graphqltest provides utilities for testing code that uses
+// package graphql.
+package graphqltest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// RequireDecoded decodes wantJSON, a GraphQL response data JSON object,
+// into a new value of the same type as got, and fails the test immediately
+// if it isn't deeply equal to got. got should be a pointer to a decoded
+// GraphQL query data structure.
+//
+// On mismatch, the differences are reported in struct field order,
+// one per line, identified by their Go path from the root of got.
+func RequireDecoded(t testing.TB, got interface{}, wantJSON string) {
+	t.Helper()
+	rv := reflect.ValueOf(got)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		t.Fatalf("graphqltest: got must be a non-nil pointer, not %T", got)
+		return
+	}
+	want := reflect.New(rv.Type().Elem())
+	err := jsonutil.UnmarshalGraphQL([]byte(wantJSON), want.Interface())
+	if err != nil {
+		t.Fatalf("graphqltest: decoding wantJSON: %v", err)
+		return
+	}
+	if diff := Diff(rv.Elem().Interface(), want.Elem().Interface()); len(diff) > 0 {
+		t.Fatalf("decoded value mismatch (-got +want):\n%s", strings.Join(diff, "\n"))
+	}
+}
+
+// Diff returns the differences between got and want, in struct field
+// and slice index order, one per entry. It returns nil if they're deeply equal.
+func Diff(got, want interface{}) []string {
+	var d differ
+	d.diff("", reflect.ValueOf(got), reflect.ValueOf(want))
+	return d.lines
+}
+
+type differ struct {
+	lines []string
+}
+
+func (d *differ) report(path string, got, want reflect.Value) {
+	if path == "" {
+		path = "."
+	}
+	d.lines = append(d.lines, fmt.Sprintf("%s:\n\t-%s\n\t+%s", path, format(got), format(want)))
+}
+
+func (d *differ) diff(path string, got, want reflect.Value) {
+	if !got.IsValid() || !want.IsValid() {
+		if got.IsValid() != want.IsValid() {
+			d.report(path, got, want)
+		}
+		return
+	}
+	if got.Type() != want.Type() {
+		d.report(path, got, want)
+		return
+	}
+	switch got.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.report(path, got, want)
+			}
+			return
+		}
+		d.diff(path, got.Elem(), want.Elem())
+	case reflect.Struct:
+		if got.NumField() == 0 || !exportedFields(got.Type()) {
+			// Compare opaque structs (e.g., time.Time) as a whole.
+			if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+				d.report(path, got, want)
+			}
+			return
+		}
+		for i := 0; i < got.NumField(); i++ {
+			if got.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			d.diff(path+"."+got.Type().Field(i).Name, got.Field(i), want.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if got.Kind() == reflect.Slice && got.IsNil() != want.IsNil() {
+			d.report(path, got, want)
+			return
+		}
+		n := got.Len()
+		if want.Len() > n {
+			n = want.Len()
+		}
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= got.Len():
+				d.report(p, reflect.Value{}, want.Index(i))
+			case i >= want.Len():
+				d.report(p, got.Index(i), reflect.Value{})
+			default:
+				d.diff(p, got.Index(i), want.Index(i))
+			}
+		}
+	default:
+		if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+			d.report(path, got, want)
+		}
+	}
+}
+
+// exportedFields reports whether struct type t has any exported fields.
+func exportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// format formats v for diff output.
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<missing>"
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || v.Kind() == reflect.Slice) && v.IsNil() {
+		return "nil"
+	}
+	if v.Kind() == reflect.Ptr {
+		return "&" + format(v.Elem())
+	}
+	return fmt.Sprintf("%#v", v.Interface())
+}
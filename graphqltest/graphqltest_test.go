@@ -0,0 +1,64 @@
+package graphqltest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestRequireDecoded(t *testing.T) {
+	type query struct {
+		Hero struct {
+			Name    graphql.String
+			Friends []struct {
+				Name graphql.String
+			}
+			Height *graphql.Float
+		}
+	}
+	var q query
+	q.Hero.Name = "R2-D2"
+	q.Hero.Friends = []struct{ Name graphql.String }{{"Luke Skywalker"}, {"Han Solo"}}
+
+	graphqltest.RequireDecoded(t, &q, `{"hero": {"name": "R2-D2", "friends": [{"name": "Luke Skywalker"}, {"name": "Han Solo"}], "height": null}}`)
+
+	ft := &fakeT{TB: t}
+	graphqltest.RequireDecoded(ft, &q, `{"hero": {"name": "R2-D2", "friends": [{"name": "Luke"}], "height": 0.96}}`)
+	want := `decoded value mismatch (-got +want):
+.Hero.Friends[0].Name:
+	-"Luke Skywalker"
+	+"Luke"
+.Hero.Friends[1]:
+	-struct { Name graphql.String }{Name:"Han Solo"}
+	+<missing>
+.Hero.Height:
+	-nil
+	+&0.96`
+	if ft.failure != want {
+		t.Errorf("got failure:\n%s\nwant:\n%s", ft.failure, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if diff := graphqltest.Diff([]int{1, 2}, []int{1, 2}); diff != nil {
+		t.Errorf("got diff: %q, want: nil", diff)
+	}
+	if got, want := len(graphqltest.Diff([]int{1, 2}, []int{1, 3})), 1; got != want {
+		t.Errorf("got %d differences, want %d", got, want)
+	}
+}
+
+// fakeT is a testing.TB that records the failure message
+// instead of stopping the test.
+type fakeT struct {
+	testing.TB
+	failure string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failure = fmt.Sprintf(format, args...)
+}
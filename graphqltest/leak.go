@@ -0,0 +1,95 @@
+package graphqltest
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// LeakTimeout is how long VerifyNoLeaks waits for goroutines to exit.
+var LeakTimeout = 5 * time.Second
+
+// VerifyNoLeaks makes test t fail if goroutines it starts after the call,
+// such as background work of clients, are still running once it ends,
+// waiting up to LeakTimeout for them to exit. It's checked after the
+// cleanup functions registered after the call have run, so it should be
+// called at the start of the test, before the cleanup of what it uses,
+// such as closing clients and test servers, is registered:
+//
+//	func TestPrefetch(t *testing.T) {
+//		graphqltest.VerifyNoLeaks(t)
+//		srv := httptest.NewServer(handler)
+//		t.Cleanup(srv.Close)
+//		client := graphql.NewClient(srv.URL, srv.Client())
+//		t.Cleanup(func() { client.Close() })
+//		...
+//	}
+//
+// Goroutines are told apart by their IDs, so tests run in parallel with t
+// may be reported as leaking goroutines of t.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := make(map[string]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+	t.Cleanup(func() {
+		t.Helper()
+		deadline := time.Now().Add(LeakTimeout)
+		for wait := time.Millisecond; ; wait *= 2 {
+			var leaked []string
+			for _, g := range goroutines() {
+				if !before[g.id] {
+					leaked = append(leaked, g.stack)
+				}
+			}
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("found %d leaked goroutines:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+				return
+			}
+			if wait > 100*time.Millisecond {
+				wait = 100 * time.Millisecond
+			}
+			time.Sleep(wait)
+		}
+	})
+}
+
+type goroutine struct {
+	id    string
+	stack string
+}
+
+// goroutines returns the goroutines running, other than the calling one.
+func goroutines() []goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := bytes.Split(buf, []byte("\n\n"))
+	gs := make([]goroutine, 0, len(stacks)-1)
+	for _, stack := range stacks[1:] { // The first one is the calling goroutine.
+		s := strings.TrimSpace(string(stack))
+		// The stack starts with a header such as "goroutine 7 [running]:".
+		fields := strings.Fields(s)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		gs = append(gs, goroutine{id: fields[1], stack: s})
+	}
+	return gs
+}
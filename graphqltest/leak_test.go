@@ -0,0 +1,60 @@
+package graphqltest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+// recordingT is a testing.TB that records errors and cleanup functions.
+type recordingT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingT) Helper()          {}
+func (t *recordingT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// finish runs the cleanup functions of t, the last registered first.
+func (t *recordingT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	rt := &recordingT{TB: t}
+	graphqltest.VerifyNoLeaks(rt)
+	stop := make(chan struct{})
+	go func() { <-stop }()
+	// The goroutine exits while VerifyNoLeaks waits.
+	time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+	rt.finish()
+	if len(rt.errors) > 0 {
+		t.Errorf("got errors: %v", rt.errors)
+	}
+}
+
+func TestVerifyNoLeaks_leak(t *testing.T) {
+	defer func(d time.Duration) { graphqltest.LeakTimeout = d }(graphqltest.LeakTimeout)
+	graphqltest.LeakTimeout = 10 * time.Millisecond
+
+	rt := &recordingT{TB: t}
+	graphqltest.VerifyNoLeaks(rt)
+	stop := make(chan struct{})
+	defer close(stop)
+	go leakingGoroutine(stop)
+	rt.finish()
+	if len(rt.errors) != 1 || !strings.Contains(rt.errors[0], "found 1 leaked goroutines") || !strings.Contains(rt.errors[0], "leakingGoroutine") {
+		t.Errorf("got errors: %v", rt.errors)
+	}
+}
+
+func leakingGoroutine(stop chan struct{}) { <-stop }
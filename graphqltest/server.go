@@ -0,0 +1,517 @@
+package graphqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// Server is a fake GraphQL server backed by a schema, for use in tests.
+// It validates incoming documents against the schema, so malformed
+// queries are reported as GraphQL errors just like a real server would,
+// and resolves them using user-supplied resolvers and canned values.
+//
+// Server implements http.Handler. Use it with httptest.NewServer, or with
+// an http.RoundTripper that invokes it directly.
+type Server struct {
+	schema    *parser.Schema
+	resolvers Resolvers
+}
+
+// Resolvers maps "Type.field" coordinates to either a Resolver func,
+// or a canned value to return for every selection of that field.
+//
+// Fields without a resolver are resolved from their parent value:
+// a map[string]interface{} is indexed by field name, and other values
+// are first converted to a map via encoding/json.
+//
+// Values for fields of abstract (interface or union) types
+// must provide their concrete type name via a "__typename" key.
+type Resolvers map[string]interface{}
+
+// Resolver resolves a field, given its parent value and
+// argument values coerced according to the schema.
+type Resolver func(parent interface{}, args map[string]interface{}) (interface{}, error)
+
+// NewServer returns a Server for the given GraphQL SDL schema.
+// The "Query.field" resolvers are given a nil parent.
+func NewServer(schemaSDL string, resolvers Resolvers) (*Server, error) {
+	schema, err := parser.ParseSchema(schemaSDL)
+	if err != nil {
+		return nil, fmt.Errorf("graphqltest: parsing schema: %v", err)
+	}
+	return &Server{schema: schema, resolvers: resolvers}, nil
+}
+
+// ServeHTTP handles a GraphQL POST request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var in struct {
+		Query         string
+		Variables     map[string]interface{}
+		OperationName string
+	}
+	dec := json.NewDecoder(req.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&in); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Execute(in.Query, in.OperationName, in.Variables))
+}
+
+// Response is a GraphQL response produced by Server.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error produced by Server.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// Location is a location in a GraphQL document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Execute validates and executes the GraphQL document query.
+func (s *Server) Execute(query, operationName string, variables map[string]interface{}) Response {
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return Response{Errors: []Error{toError(err)}}
+	}
+	if errs := parser.Validate(s.schema, doc); len(errs) > 0 {
+		var resp Response
+		for _, err := range errs {
+			resp.Errors = append(resp.Errors, toError(err))
+		}
+		return resp
+	}
+	op := doc.Operation(operationName)
+	if op == nil {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("unknown operation %q", operationName)}}}
+	}
+	e := &executor{schema: s.schema, doc: doc, resolvers: s.resolvers}
+	vars, err := e.coerceVariables(op, variables)
+	if err != nil {
+		return Response{Errors: []Error{toError(err)}}
+	}
+	e.vars = vars
+	resp := Response{}
+	if data, ok := e.selectionSet(s.schema.RootType(op.Type), nil, op.SelectionSet, nil); ok {
+		resp.Data = data
+	}
+	resp.Errors = e.errs
+	return resp
+}
+
+func toError(err error) Error {
+	e, ok := err.(*parser.Error)
+	if !ok {
+		return Error{Message: err.Error()}
+	}
+	out := Error{Message: e.Message}
+	for _, l := range e.Locations {
+		out.Locations = append(out.Locations, Location{Line: l.Line, Column: l.Column})
+	}
+	return out
+}
+
+// executor executes a single operation.
+type executor struct {
+	schema    *parser.Schema
+	doc       *parser.Document
+	resolvers Resolvers
+	vars      map[string]interface{}
+	errs      []Error
+}
+
+func (e *executor) errorf(pos parser.Pos, path []interface{}, format string, args ...interface{}) {
+	e.errs = append(e.errs, Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []Location{{Line: pos.Line, Column: pos.Column}},
+		Path:      append([]interface{}(nil), path...),
+	})
+}
+
+// coerceVariables checks provided variable values against op's
+// variable definitions, applying default values.
+func (e *executor) coerceVariables(op *parser.Operation, provided map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, d := range op.VarDefs {
+		v, ok := provided[d.Name]
+		switch {
+		case !ok && d.Default != nil:
+			vars[d.Name] = e.literal(d.Default, d.Type)
+		case (!ok || v == nil) && d.Type.NonNull:
+			return nil, &parser.Error{Message: fmt.Sprintf("variable \"$%s\" of required type %q was not provided", d.Name, d.Type), Locations: []parser.Pos{d.Pos}}
+		case ok:
+			vars[d.Name] = coerce(v, d.Type, e.schema)
+		}
+	}
+	return vars, nil
+}
+
+// literal returns the value of input literal v of type t.
+func (e *executor) literal(v *parser.Value, t *parser.Type) interface{} {
+	switch v.Kind {
+	case parser.VariableValue:
+		return e.vars[v.Raw]
+	case parser.NullValue:
+		return nil
+	case parser.ListValue:
+		var elem *parser.Type
+		if t != nil {
+			elem = t.Elem
+		}
+		l := make([]interface{}, 0, len(v.List))
+		for _, item := range v.List {
+			l = append(l, e.literal(item, elem))
+		}
+		return l
+	case parser.ObjectValue:
+		m := make(map[string]interface{}, len(v.Fields))
+		var def *parser.TypeDef
+		if t != nil {
+			def = e.schema.Types[t.NamedType()]
+		}
+		for _, f := range v.Fields {
+			var ft *parser.Type
+			if def != nil {
+				if fd := def.InputField(f.Name); fd != nil {
+					ft = fd.Type
+				}
+			}
+			m[f.Name] = e.literal(f.Value, ft)
+		}
+		return m
+	case parser.BooleanValue:
+		return v.Raw == "true"
+	case parser.IntValue, parser.FloatValue:
+		return coerce(json.Number(v.Raw), t, e.schema)
+	default: // String and enum values.
+		return v.Raw
+	}
+}
+
+// coerce converts JSON-decoded value v to the Go representation used for
+// argument values of type t: int for Int, float64 for Float, string for
+// ID, and so on. Values that can't be converted are returned as is.
+func coerce(v interface{}, t *parser.Type, schema *parser.Schema) interface{} {
+	if t == nil || v == nil {
+		return v
+	}
+	if t.Elem != nil {
+		l, ok := v.([]interface{})
+		if !ok {
+			return []interface{}{coerce(v, t.Elem, schema)}
+		}
+		out := make([]interface{}, len(l))
+		for i := range l {
+			out[i] = coerce(l[i], t.Elem, schema)
+		}
+		return out
+	}
+	switch t.Name {
+	case "Int":
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return int(i)
+			}
+		}
+	case "Float":
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return f
+			}
+		}
+	case "ID":
+		if n, ok := v.(json.Number); ok {
+			return n.String()
+		}
+	}
+	if def := schema.Types[t.Name]; def != nil && def.Kind == parser.InputObjectKind {
+		if m, ok := v.(map[string]interface{}); ok {
+			out := make(map[string]interface{}, len(m))
+			for k, fv := range m {
+				var ft *parser.Type
+				if fd := def.InputField(k); fd != nil {
+					ft = fd.Type
+				}
+				out[k] = coerce(fv, ft, schema)
+			}
+			return out
+		}
+	}
+	return v
+}
+
+// selectionSet executes sels against value of object type t.
+// It reports false if a non-null field resolved to null,
+// in which case the whole object is null.
+func (e *executor) selectionSet(t *parser.TypeDef, value interface{}, sels []parser.Selection, path []interface{}) (*object, bool) {
+	obj := new(object)
+	for _, g := range e.collectFields(t, sels, nil, make(map[string]bool)) {
+		f := g.fields[0]
+		fieldPath := append(path, g.key)
+		var (
+			v  interface{}
+			ok = true
+		)
+		if f.Name == "__typename" {
+			v = t.Name
+		} else {
+			def := t.Field(f.Name)
+			resolved, err := e.resolve(t, def, f, value)
+			if err != nil {
+				e.errorf(f.Pos, fieldPath, "%v", err)
+				ok = !def.Type.NonNull
+			} else {
+				v, ok = e.complete(def.Type, g.fields, resolved, fieldPath)
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+		obj.set(g.key, v)
+	}
+	return obj, true
+}
+
+// fieldGroup is a group of field selections sharing a response key.
+type fieldGroup struct {
+	key    string
+	fields []*parser.Field
+}
+
+// collectFields collects the fields selected by sels for object type t,
+// grouped by response key, in order of appearance.
+func (e *executor) collectFields(t *parser.TypeDef, sels []parser.Selection, groups []fieldGroup, visited map[string]bool) []fieldGroup {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *parser.Field:
+			if !e.included(sel.Directives) {
+				continue
+			}
+			i := 0
+			for i < len(groups) && groups[i].key != sel.ResponseKey() {
+				i++
+			}
+			if i == len(groups) {
+				groups = append(groups, fieldGroup{key: sel.ResponseKey()})
+			}
+			groups[i].fields = append(groups[i].fields, sel)
+		case *parser.InlineFragment:
+			if !e.included(sel.Directives) || !e.applies(sel.TypeCond, t) {
+				continue
+			}
+			groups = e.collectFields(t, sel.SelectionSet, groups, visited)
+		case *parser.FragmentSpread:
+			if !e.included(sel.Directives) || visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			f := e.doc.Fragment(sel.Name)
+			if !e.applies(f.TypeCond, t) {
+				continue
+			}
+			groups = e.collectFields(t, f.SelectionSet, groups, visited)
+		}
+	}
+	return groups
+}
+
+// included reports whether @skip and @include directives allow a selection.
+func (e *executor) included(ds []*parser.Directive) bool {
+	for _, d := range ds {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		for _, a := range d.Arguments {
+			if a.Name == "if" && e.literal(a.Value, nil) == (d.Name == "skip") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applies reports whether type condition cond applies to object type t.
+func (e *executor) applies(cond string, t *parser.TypeDef) bool {
+	if cond == "" {
+		return true
+	}
+	ct := e.schema.Types[cond]
+	return ct != nil && e.schema.IsPossibleType(ct, t.Name)
+}
+
+// resolve resolves the value of field f of object type t.
+func (e *executor) resolve(t *parser.TypeDef, def *parser.FieldDef, f *parser.Field, parent interface{}) (interface{}, error) {
+	if r, ok := e.resolvers[t.Name+"."+f.Name]; ok {
+		fn, ok := r.(Resolver)
+		if !ok {
+			fn, ok = r.(func(interface{}, map[string]interface{}) (interface{}, error))
+		}
+		if !ok {
+			return r, nil
+		}
+		args := make(map[string]interface{})
+		for _, ad := range def.Arguments {
+			if ad.Default != nil {
+				args[ad.Name] = e.literal(ad.Default, ad.Type)
+			}
+		}
+		for _, a := range f.Arguments {
+			if a.Value.Kind == parser.VariableValue {
+				if v, ok := e.vars[a.Value.Raw]; ok {
+					args[a.Name] = v
+				}
+				continue
+			}
+			args[a.Name] = e.literal(a.Value, def.Argument(a.Name).Type)
+		}
+		return fn(parent, args)
+	}
+	if parent == nil {
+		return nil, nil
+	}
+	m, ok := parent.(map[string]interface{})
+	if !ok {
+		b, err := json.Marshal(parent)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("cannot resolve field %q of %T", f.Name, parent)
+		}
+	}
+	if v, ok := m[f.Name]; ok {
+		return v, nil
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, f.Name) {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// complete completes resolved value v of type t, reporting false
+// if v is null (or became null due to an error) while t is non-null.
+func (e *executor) complete(t *parser.Type, fields []*parser.Field, v interface{}, path []interface{}) (interface{}, bool) {
+	if v == nil || isNil(v) {
+		if t.NonNull {
+			e.errorf(fields[0].Pos, path, "cannot return null for non-nullable field")
+			return nil, false
+		}
+		return nil, true
+	}
+	if t.Elem != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.errorf(fields[0].Pos, path, "expected list value, got %T", v)
+			return nil, !t.NonNull
+		}
+		l := make([]interface{}, rv.Len())
+		for i := range l {
+			item, ok := e.complete(t.Elem, fields, rv.Index(i).Interface(), append(path, i))
+			if !ok {
+				return nil, !t.NonNull
+			}
+			l[i] = item
+		}
+		return l, true
+	}
+	def := e.schema.Types[t.Name]
+	switch def.Kind {
+	case parser.ScalarKind, parser.EnumKind:
+		return v, true
+	}
+	ot := def
+	if def.Kind != parser.ObjectKind {
+		name := typename(v)
+		ot = e.schema.Types[name]
+		if ot == nil || !e.schema.IsPossibleType(def, name) {
+			e.errorf(fields[0].Pos, path, "cannot determine concrete type of %q value %v", def.Name, v)
+			return nil, !t.NonNull
+		}
+	}
+	var sels []parser.Selection
+	for _, f := range fields {
+		sels = append(sels, f.SelectionSet...)
+	}
+	obj, ok := e.selectionSet(ot, v, sels, path)
+	if !ok {
+		return nil, !t.NonNull
+	}
+	return obj, true
+}
+
+// typename returns the value of the "__typename" key of map v, if any.
+func typename(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok {
+		s, _ := m["__typename"].(string)
+		return s
+	}
+	return ""
+}
+
+func isNil(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// object is a JSON object that preserves key order.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) set(key string, v interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, v)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte(':')
+		b, err = json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
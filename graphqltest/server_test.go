@@ -0,0 +1,158 @@
+package graphqltest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+const schema = `
+type Query {
+	hero(episode: Episode = JEDI): Character
+	human(id: ID!): Human
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+interface Character {
+	id: ID!
+	name: String!
+}
+
+type Human implements Character {
+	id: ID!
+	name: String!
+	height(unit: LengthUnit = METER): Float
+}
+
+type Droid implements Character {
+	id: ID!
+	name: String!
+	primaryFunction: String
+}
+
+enum LengthUnit { METER FOOT }
+`
+
+func newClient(t *testing.T, resolvers graphqltest.Resolvers) *graphql.Client {
+	t.Helper()
+	s, err := graphqltest.NewServer(schema, resolvers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return graphql.NewClient(ts.URL, ts.Client())
+}
+
+func TestServer(t *testing.T) {
+	client := newClient(t, graphqltest.Resolvers{
+		"Query.hero": graphqltest.Resolver(func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+			if args["episode"] != "JEDI" {
+				return nil, errors.New("unexpected episode")
+			}
+			return map[string]interface{}{"__typename": "Droid", "id": "2001", "name": "R2-D2", "primaryFunction": "Astromech"}, nil
+		}),
+		"Query.human": graphqltest.Resolver(func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+			return struct {
+				ID     string
+				Name   string
+				Height float64
+			}{ID: args["id"].(string), Name: "Luke Skywalker", Height: 1.72}, nil
+		}),
+	})
+
+	var q struct {
+		Hero struct {
+			Typename string `graphql:"__typename"`
+			Name     graphql.String
+			Droid    struct {
+				PrimaryFunction graphql.String
+			} `graphql:"... on Droid"`
+		}
+		Human struct {
+			Name   graphql.String
+			Height graphql.Float
+		} `graphql:"human(id: $id)"`
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{
+		"id": graphql.ID("1000"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphqltest.RequireDecoded(t, &q, `{
+		"hero": {"__typename": "Droid", "name": "R2-D2", "primaryFunction": "Astromech"},
+		"human": {"name": "Luke Skywalker", "height": 1.72}
+	}`)
+}
+
+func TestServer_invalidQuery(t *testing.T) {
+	client := newClient(t, nil)
+
+	var q struct {
+		Hero struct {
+			Name     graphql.String
+			Nickname graphql.String
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+	if got, want := err.Error(), `cannot query field "nickname" on type "Character"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestServer_resolverError(t *testing.T) {
+	client := newClient(t, graphqltest.Resolvers{
+		"Query.hero": graphqltest.Resolver(func(interface{}, map[string]interface{}) (interface{}, error) {
+			return nil, errors.New("hero unavailable")
+		}),
+		"Query.human": map[string]interface{}{"name": nil},
+	})
+
+	var q struct {
+		Hero *struct {
+			Name graphql.String
+		}
+		Human *struct {
+			Name graphql.String
+		} `graphql:"human(id: 1000)"`
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+	if got, want := err.Error(), "hero unavailable"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if q.Hero != nil || q.Human != nil {
+		t.Errorf("got non-nil q.Hero or q.Human: %+v", q)
+	}
+}
+
+func TestServer_Execute(t *testing.T) {
+	s, err := graphqltest.NewServer(schema, graphqltest.Resolvers{
+		"Query.hero": map[string]interface{}{"__typename": "Human", "name": "Luke Skywalker", "height": 1.72},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := s.Execute(`{ hero { name ... on Human { height } } }`, "", nil)
+	if len(resp.Errors) > 0 {
+		t.Fatal(resp.Errors)
+	}
+	resp = s.Execute(`query($id: ID!) { human(id: $id) { name } }`, "", nil)
+	if got, want := len(resp.Errors), 1; got != want {
+		t.Fatalf("got %d errors, want %d", got, want)
+	}
+	if got, want := resp.Errors[0].Message, `variable "$id" of required type "ID!" was not provided`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
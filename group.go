@@ -0,0 +1,112 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Group is a sequence of operations, typically mutations, run in order by
+// Client.RunGroup, that's meant to succeed or fail as a whole, for servers
+// without transactions spanning several mutations. Operations added with
+// a compensation are undone by it if a later one fails. The zero value is
+// an empty group.
+type Group struct {
+	steps []groupStep
+}
+
+type groupStep struct {
+	op         Operation
+	compensate func(ctx context.Context) error
+}
+
+// Add adds op to g, with compensate, if not nil, to undo it, such as by
+// running a mutation deleting what op created. It returns g.
+func (g *Group) Add(op Operation, compensate func(ctx context.Context) error) *Group {
+	g.steps = append(g.steps, groupStep{op: op, compensate: compensate})
+	return g
+}
+
+// Len returns the number of operations in g.
+func (g *Group) Len() int {
+	return len(g.steps)
+}
+
+// GroupError is returned by Client.RunGroup when an operation of a group
+// fails.
+type GroupError struct {
+	Index int       // Index of the operation that failed.
+	Op    Operation // Operation that failed.
+	Err   error     // Error of the operation that failed.
+
+	// Completed are the operations that succeeded before, in order.
+	Completed []Operation
+
+	// CompensationErrs are the errors of the compensations of Completed,
+	// at the same indexes, or nil for those that succeeded or have none.
+	// It's nil if all compensations succeeded.
+	CompensationErrs []error
+}
+
+func (e *GroupError) Error() string {
+	msg := fmt.Sprintf("graphql: operation %d of group failed after %d completed: %v", e.Index, len(e.Completed), e.Err)
+	switch n := e.compensationFailures(); n {
+	case 0:
+	case 1:
+		msg += " (1 compensation failed)"
+	default:
+		msg += fmt.Sprintf(" (%d compensations failed)", n)
+	}
+	return msg
+}
+
+func (e *GroupError) Unwrap() error {
+	return e.Err
+}
+
+func (e *GroupError) compensationFailures() int {
+	var n int
+	for _, err := range e.CompensationErrs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// RunGroup runs the operations of g in order, stopping at the first one
+// that fails, including with GraphQL errors. Then it runs the
+// compensations of the operations that succeeded, in reverse order,
+// and returns a *GroupError. Compensations are run with ctx, so they
+// fail too if it's done.
+func (c *Client) RunGroup(ctx context.Context, g *Group) error {
+	for i, s := range g.steps {
+		err := c.Run(ctx, s.op)
+		if err == nil {
+			continue
+		}
+		gerr := &GroupError{Index: i, Op: s.op, Err: err, Completed: make([]Operation, i)}
+		var compensationErrs []error
+		for j := i - 1; j >= 0; j-- {
+			done := g.steps[j]
+			gerr.Completed[j] = done.op
+			if done.compensate == nil {
+				continue
+			}
+			if err := c.compensate(ctx, done.compensate); err != nil {
+				if compensationErrs == nil {
+					compensationErrs = make([]error, i)
+				}
+				compensationErrs[j] = err
+			}
+		}
+		gerr.CompensationErrs = compensationErrs
+		return gerr
+	}
+	return nil
+}
+
+// compensate runs compensation f, returning its panic as an error.
+func (c *Client) compensate(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	defer recoverPanic(&err)
+	return f(ctx)
+}
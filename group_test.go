@@ -0,0 +1,99 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// newGroupClient returns a client of a server that fails mutations
+// of items named "bad", and records the items created.
+func newGroupClient(created *[]string) *graphql.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body, `"bad"`) {
+			mustWrite(w, `{"data": null, "errors": [{"message": "bad item"}]}`)
+			return
+		}
+		*created = append(*created, body)
+		mustWrite(w, `{"data": {"createItem": {"id": "1"}}}`)
+	})
+	return graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+}
+
+type createItem struct {
+	CreateItem struct {
+		ID graphql.ID
+	} `graphql:"createItem(name: $name)"`
+}
+
+func TestRunGroup(t *testing.T) {
+	var created []string
+	client := newGroupClient(&created)
+
+	var g graphql.Group
+	for _, name := range []string{"a", "b", "c"} {
+		g.Add(graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String(name)}), nil)
+	}
+	if err := client.RunGroup(context.Background(), &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 3 {
+		t.Errorf("got %d items created, want 3", len(created))
+	}
+}
+
+func TestRunGroupFailure(t *testing.T) {
+	var created []string
+	client := newGroupClient(&created)
+
+	var compensated []string
+	compensate := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			compensated = append(compensated, name)
+			return err
+		}
+	}
+	errUndo := errors.New("cannot undo")
+	var g graphql.Group
+	a := graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String("a")})
+	b := graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String("b")})
+	c := graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String("c")})
+	bad := graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String("bad")})
+	g.Add(a, compensate("a", nil)).Add(b, nil).Add(c, compensate("c", errUndo)).Add(bad, compensate("bad", nil))
+	g.Add(graphql.NewMutation(&createItem{}, map[string]interface{}{"name": graphql.String("d")}), nil)
+
+	err := client.RunGroup(context.Background(), &g)
+	var gerr *graphql.GroupError
+	if !errors.As(err, &gerr) {
+		t.Fatalf("got error %v, want a *graphql.GroupError", err)
+	}
+	if gerr.Index != 3 || gerr.Op != bad {
+		t.Errorf("got failed operation %d, want 3", gerr.Index)
+	}
+	var errs graphql.ErrorList
+	if !errors.As(err, &errs) || errs[0].Message != "bad item" {
+		t.Errorf("got error %v, want the GraphQL errors", gerr.Err)
+	}
+	if len(gerr.Completed) != 3 || gerr.Completed[0] != a || gerr.Completed[1] != b || gerr.Completed[2] != c {
+		t.Errorf("got completed %v, want a, b and c", gerr.Completed)
+	}
+	if want := "c a"; strings.Join(compensated, " ") != want {
+		t.Errorf("got compensated %q, want %q", compensated, want)
+	}
+	if len(gerr.CompensationErrs) != 3 || gerr.CompensationErrs[0] != nil || gerr.CompensationErrs[2] != errUndo {
+		t.Errorf("got compensation errors %v, want %v for c", gerr.CompensationErrs, errUndo)
+	}
+	if want := "graphql: operation 3 of group failed after 3 completed: bad item (1 compensation failed)"; err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+	if len(created) != 3 {
+		t.Errorf("got %d items created, want 3", len(created))
+	}
+}
@@ -0,0 +1,104 @@
+// Package grpcbridge provides a graphql.Transport for the "GraphQL over
+// gRPC" bridge pattern, where a unary RPC carries the JSON of GraphQL
+// requests and responses in messages such as:
+//
+//	message GraphQLRequest {
+//		string query = 1;
+//		string variables_json = 2;
+//		string operation_name = 3;
+//	}
+//
+//	message GraphQLResponse {
+//		string response_json = 1;
+//	}
+//
+// The package doesn't depend on gRPC, nor on the messages of a particular
+// bridge. Instead, an Invoker calls the RPC of the bridge with its
+// generated client, converting the messages:
+//
+//	transport := grpcbridge.New(func(ctx context.Context, r *grpcbridge.Request) (*grpcbridge.Response, error) {
+//		ctx = metadata.NewOutgoingContext(ctx, r.Metadata)
+//		resp, err := bridge.Execute(ctx, &pb.GraphQLRequest{
+//			Query:         r.Query,
+//			VariablesJson: string(r.VariablesJSON),
+//			OperationName: r.OperationName,
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &grpcbridge.Response{ResponseJSON: []byte(resp.ResponseJson)}, nil
+//	})
+//	client := graphql.NewClient("", nil, graphql.WithTransport(transport))
+package grpcbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Request is a GraphQL request to send with a bridge RPC.
+type Request struct {
+	Endpoint      string // URL of the client, or of a routed operation, if any.
+	Query         string // Empty if the document is sent by its DocumentID.
+	VariablesJSON []byte // JSON object of the variables, or nil if there are none.
+	OperationName string
+	DocumentID    string // ID of a persisted document, if the client sends them.
+
+	// Metadata are the headers set for the request, such as by
+	// a RequestHandler, with lowercase names, as gRPC metadata.
+	Metadata map[string][]string
+}
+
+// Response is the response of a bridge RPC.
+type Response struct {
+	ResponseJSON []byte // JSON of the GraphQL response.
+}
+
+// Invoker calls the RPC of a bridge with req.
+type Invoker func(ctx context.Context, req *Request) (*Response, error)
+
+// New returns a graphql.Transport sending requests with invoke.
+func New(invoke Invoker) graphql.Transport {
+	return transport{invoke: invoke}
+}
+
+type transport struct {
+	invoke Invoker
+}
+
+func (t transport) RoundTrip(ctx context.Context, endpoint string, in graphql.Request, header http.Header) (out graphql.Response, err error) {
+	req := &Request{
+		Endpoint:      endpoint,
+		Query:         in.Query,
+		OperationName: in.OperationName,
+		DocumentID:    in.DocumentID,
+	}
+	if len(in.Variables) > 0 {
+		req.VariablesJSON, err = json.Marshal(in.Variables)
+		if err != nil {
+			return out, err
+		}
+	}
+	if len(header) > 0 {
+		req.Metadata = make(map[string][]string, len(header))
+		for k, v := range header {
+			req.Metadata[strings.ToLower(k)] = v
+		}
+	}
+	resp, err := t.invoke(ctx, req)
+	if err != nil {
+		return out, err
+	}
+	if resp == nil || len(resp.ResponseJSON) == 0 {
+		return out, fmt.Errorf("grpcbridge: empty response")
+	}
+	if err := json.Unmarshal(resp.ResponseJSON, &out); err != nil {
+		return out, fmt.Errorf("grpcbridge: decoding response: %v", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package grpcbridge_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/grpcbridge"
+)
+
+func TestTransport(t *testing.T) {
+	var got *grpcbridge.Request
+	transport := grpcbridge.New(func(ctx context.Context, req *grpcbridge.Request) (*grpcbridge.Response, error) {
+		got = req
+		return &grpcbridge.Response{ResponseJSON: []byte(`{"data": {"user": {"name": "Gopher"}}}`)}, nil
+	})
+	client := graphql.NewClient("grpc://bridge", nil, graphql.WithTransport(transport))
+
+	var q struct {
+		User struct {
+			Name graphql.String
+		} `graphql:"user(login: $login)"`
+	}
+	err := client.Run(context.Background(), &graphql.Query{
+		Data: &q,
+		Vars: map[string]interface{}{"login": graphql.String("gopher")},
+		Name: "User",
+		RequestHandler: func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer token")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.User.Name != "Gopher" {
+		t.Errorf("got name %q, want %q", q.User.Name, "Gopher")
+	}
+	if want := `query User($login:String!){user(login: $login){name}}`; got.Query != want {
+		t.Errorf("got query %q, want %q", got.Query, want)
+	}
+	if want := `{"login":"gopher"}`; string(got.VariablesJSON) != want {
+		t.Errorf("got variables %s, want %s", got.VariablesJSON, want)
+	}
+	if v := got.Metadata["authorization"]; len(v) != 1 || v[0] != "Bearer token" {
+		t.Errorf("got authorization metadata %q, want %q", v, "Bearer token")
+	}
+	if got.Endpoint != "grpc://bridge" {
+		t.Errorf("got endpoint %q, want %q", got.Endpoint, "grpc://bridge")
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	errUnavailable := errors.New("rpc error: code = Unavailable")
+	for _, tc := range []struct {
+		name   string
+		resp   *grpcbridge.Response
+		err    error
+		wantIs error
+		want   string
+	}{
+		{name: "rpc", err: errUnavailable, wantIs: errUnavailable},
+		{name: "graphql", resp: &grpcbridge.Response{ResponseJSON: []byte(`{"data": null, "errors": [{"message": "not found"}]}`)}, want: "not found"},
+		{name: "empty", resp: &grpcbridge.Response{}, want: "grpcbridge: empty response"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := grpcbridge.New(func(context.Context, *grpcbridge.Request) (*grpcbridge.Response, error) {
+				return tc.resp, tc.err
+			})
+			client := graphql.NewClient("", nil, graphql.WithTransport(transport))
+			var q struct {
+				Viewer struct {
+					Login graphql.String
+				}
+			}
+			err := client.Query(context.Background(), &q, nil)
+			if tc.wantIs != nil && !errors.Is(err, tc.wantIs) {
+				t.Errorf("got error %v, want %v", err, tc.wantIs)
+			}
+			if tc.want != "" && (err == nil || err.Error() != tc.want) {
+				t.Errorf("got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
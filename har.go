@@ -0,0 +1,435 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default bounds of HAR recorders with zero MaxEntries or MaxBodySize.
+const (
+	DefaultHAREntries  = 100
+	DefaultHARBodySize = 64 << 10
+)
+
+// HARRecorder records the HTTP requests clients send, and the responses
+// they receive, for export in the HTTP Archive (HAR) format understood by
+// browser developer tools and API debugging tools. It's safe for
+// concurrent use by multiple clients.
+//
+// Recording is bounded: only the most recent MaxEntries exchanges are
+// kept, and request and response bodies are truncated to MaxBodySize
+// bytes. Header values redacted by the client's redaction policy aren't
+// recorded, but bodies are recorded as they're sent and received,
+// including the values of variables and response data, unless redacted
+// with RedactVariables or RedactBody.
+type HARRecorder struct {
+	MaxEntries  int // Maximum number of entries kept, or DefaultHAREntries if zero.
+	MaxBodySize int // Maximum number of bytes kept of each body, or DefaultHARBodySize if zero.
+
+	// RedactVariables are the names of variables whose values are
+	// recorded as "[REDACTED]", in JSON request bodies and the variables
+	// parameters of URLs. Request bodies that can't be parsed, such as
+	// truncated ones, are recorded as "[REDACTED]" if it isn't empty.
+	RedactVariables []string
+
+	// RedactBody, if not nil, returns the text recorded for the body of a
+	// request, if request is true, or of a response, with content type
+	// mimeType and text, as truncated and with RedactVariables redacted,
+	// such as with the values of sensitive fields replaced.
+	RedactBody func(request bool, mimeType, text string) string
+
+	mu      sync.Mutex
+	entries []harEntry // Oldest first.
+}
+
+// WithHAR records the HTTP requests the client sends, and the responses
+// it receives, into r. Entries are added once response bodies are closed.
+// Requests sent with WithTransport aren't recorded.
+func WithHAR(r *HARRecorder) ClientOption {
+	return func(c *Client) {
+		c.har = r
+	}
+}
+
+// Len returns the number of entries recorded.
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset discards all entries recorded.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// WriteTo writes the entries recorded to w as a HAR 1.2 document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	entries := append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+	var doc struct {
+		Log struct {
+			Version string     `json:"version"`
+			Creator harCreator `json:"creator"`
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = harCreator{Name: "github.com/arvata-io/graphql", Version: "1.0"}
+	doc.Log.Entries = entries
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+func (r *HARRecorder) add(e harEntry) {
+	max := r.MaxEntries
+	if max <= 0 {
+		max = DefaultHAREntries
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) >= max {
+		r.entries = append(r.entries[:0], r.entries[len(r.entries)-max+1:]...)
+	}
+	r.entries = append(r.entries, e)
+}
+
+func (r *HARRecorder) maxBodySize() int {
+	if r.MaxBodySize <= 0 {
+		return DefaultHARBodySize
+	}
+	return r.MaxBodySize
+}
+
+type (
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	harEntry struct {
+		StartedDateTime string      `json:"startedDateTime"`
+		Time            float64     `json:"time"`
+		Request         harRequest  `json:"request"`
+		Response        harResponse `json:"response"`
+		Cache           struct{}    `json:"cache"`
+		Timings         harTimings  `json:"timings"`
+		Comment         string      `json:"comment,omitempty"`
+	}
+	harRequest struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		PostData    *harPostData   `json:"postData,omitempty"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int64          `json:"bodySize"`
+	}
+	harResponse struct {
+		Status      int            `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Cookies     []harNameValue `json:"cookies"`
+		Headers     []harNameValue `json:"headers"`
+		Content     harContent     `json:"content"`
+		RedirectURL string         `json:"redirectURL"`
+		HeadersSize int            `json:"headersSize"`
+		BodySize    int64          `json:"bodySize"`
+	}
+	harNameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	harPostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+	harContent struct {
+		Size     int64  `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+		Comment  string `json:"comment,omitempty"`
+	}
+	harTimings struct {
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	}
+)
+
+// harTransport is an HTTP transport recording exchanges into a HARRecorder.
+type harTransport struct {
+	base   http.RoundTripper
+	rec    *HARRecorder
+	redact RedactPolicy
+	clock  Clock
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.clock.Now()
+	reqBody := &harCapture{max: t.rec.maxBodySize()}
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		reqBody.rc = req.Body
+		req.Body = reqBody
+	}
+	u := t.redactURL(req.URL)
+	e := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         u.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNameValue{},
+			Headers:     t.headers(req.Header),
+			QueryString: queryString(u),
+			HeadersSize: -1,
+		},
+	}
+	resp, err := t.base.RoundTrip(req)
+	sent := t.clock.Now()
+	// The transport may still be sending the request body
+	// once it returns, so it's recorded as of the entry's end.
+	recordBody := func() {
+		e.Request.BodySize = reqBody.size()
+		if reqBody.rc != nil {
+			mimeType := req.Header.Get("Content-Type")
+			e.Request.PostData = &harPostData{MimeType: mimeType, Text: t.redactBody(true, mimeType, reqBody.text())}
+		}
+	}
+	if err != nil {
+		recordBody()
+		e.Time = millis(sent.Sub(start))
+		e.Timings.Wait = e.Time
+		e.Response = harResponse{Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1}
+		e.Comment = err.Error()
+		t.rec.add(e)
+		return nil, err
+	}
+	e.Request.HTTPVersion = resp.Proto
+	e.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     t.headers(resp.Header),
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+	}
+	e.Timings.Wait = millis(sent.Sub(start))
+	body := &harCapture{rc: resp.Body, max: t.rec.maxBodySize()}
+	body.done = func() {
+		end := t.clock.Now()
+		recordBody()
+		e.Time = millis(end.Sub(start))
+		e.Timings.Receive = millis(end.Sub(sent))
+		e.Response.BodySize = body.size()
+		mimeType := resp.Header.Get("Content-Type")
+		e.Response.Content = harContent{Size: body.size(), MimeType: mimeType, Text: t.redactBody(false, mimeType, body.text())}
+		if body.truncated() {
+			e.Response.Content.Comment = "truncated"
+		}
+		t.rec.add(e)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// headers returns h as HAR name-value pairs, sorted by name,
+// with the values of the headers t.redact reports redacted.
+func (t *harTransport) headers(h http.Header) []harNameValue {
+	nvs := []harNameValue{}
+	for _, name := range sortedNames(h) {
+		for _, v := range h[name] {
+			if t.redact(name) {
+				v = redacted
+			}
+			nvs = append(nvs, harNameValue{Name: name, Value: v})
+		}
+	}
+	return nvs
+}
+
+// redactURL returns u with the values of the variables of t.rec's
+// RedactVariables redacted from its variables parameter.
+func (t *harTransport) redactURL(u *url.URL) *url.URL {
+	q := u.Query()
+	if len(t.rec.RedactVariables) == 0 || q.Get("variables") == "" {
+		return u
+	}
+	vars, err := decodeJSON(q.Get("variables"))
+	if err != nil {
+		q.Set("variables", redacted)
+	} else if t.redactVariables(vars) {
+		b, _ := json.Marshal(vars)
+		q.Set("variables", string(b))
+	} else {
+		return u
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = q.Encode()
+	return &redactedURL
+}
+
+// redactBody returns text, the body of a request if request is true or
+// of a response, with the redactions of t.rec applied.
+func (t *harTransport) redactBody(request bool, mimeType, text string) string {
+	if request && len(t.rec.RedactVariables) > 0 && text != "" {
+		if body, err := decodeJSON(text); err != nil {
+			text = redacted
+		} else if t.redactRequests(body) {
+			b, _ := json.Marshal(body)
+			text = string(b)
+		}
+	}
+	if t.rec.RedactBody != nil {
+		text = t.rec.RedactBody(request, mimeType, text)
+	}
+	return text
+}
+
+// redactRequests redacts the variables of the request, or batch of
+// requests, body, reporting whether any were redacted.
+func (t *harTransport) redactRequests(body interface{}) bool {
+	if batch, ok := body.([]interface{}); ok {
+		found := false
+		for _, r := range batch {
+			found = t.redactRequests(r) || found
+		}
+		return found
+	}
+	r, ok := body.(map[string]interface{})
+	return ok && t.redactVariables(r["variables"])
+}
+
+// redactVariables replaces the values of the variables of t.rec's
+// RedactVariables in vars, reporting whether any were replaced.
+func (t *harTransport) redactVariables(vars interface{}) bool {
+	m, ok := vars.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	found := false
+	for _, name := range t.rec.RedactVariables {
+		if _, ok := m[name]; ok {
+			m[name] = redacted
+			found = true
+		}
+	}
+	return found
+}
+
+// decodeJSON decodes s, keeping numbers as they're written.
+func decodeJSON(s string) (interface{}, error) {
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	return v, err
+}
+
+func queryString(u *url.URL) []harNameValue {
+	nvs := []harNameValue{}
+	q := u.Query()
+	for _, name := range sortedNames(q) {
+		for _, v := range q[name] {
+			nvs = append(nvs, harNameValue{Name: name, Value: v})
+		}
+	}
+	return nvs
+}
+
+func sortedNames(m map[string][]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harCapture is a body keeping the first max bytes read from rc,
+// calling done once when closed.
+type harCapture struct {
+	rc   io.ReadCloser
+	max  int
+	once sync.Once
+	done func()
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+	n   int64 // Number of bytes read.
+}
+
+func (c *harCapture) Read(p []byte) (int, error) {
+	n, err := c.rc.Read(p)
+	c.mu.Lock()
+	if room := c.max - c.buf.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		c.buf.Write(p[:room])
+	}
+	c.n += int64(n)
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *harCapture) Close() error {
+	err := c.rc.Close()
+	if c.done != nil {
+		c.once.Do(c.done)
+	}
+	return err
+}
+
+func (c *harCapture) size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func (c *harCapture) truncated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n > int64(c.buf.Len())
+}
+
+func (c *harCapture) text() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// recordHAR wraps the transport of c's HTTP client to record exchanges
+// into c.har.
+func (c *Client) recordHAR() {
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	redact := c.redact
+	if redact == nil {
+		redact = DefaultRedactPolicy
+	}
+	c.httpClient.Transport = &harTransport{base: base, rec: c.har, redact: redact, clock: c.clock}
+}
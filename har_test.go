@@ -0,0 +1,191 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+type harDoc struct {
+	Log struct {
+		Version string
+		Entries []struct {
+			Request struct {
+				Method   string
+				URL      string
+				Headers  []struct{ Name, Value string }
+				BodySize int64
+				PostData struct{ MimeType, Text string }
+			}
+			Response struct {
+				Status  int
+				Headers []struct{ Name, Value string }
+				Content struct {
+					Size     int64
+					MimeType string
+					Text     string
+					Comment  string
+				}
+			}
+		}
+	}
+}
+
+func readHAR(t *testing.T, rec *graphql.HARRecorder) harDoc {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc harDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestWithHAR(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	rec := &graphql.HARRecorder{}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithHAR(rec))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	err := client.Run(context.Background(), &graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer secret")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+
+	doc := readHAR(t, rec)
+	if doc.Log.Version != "1.2" {
+		t.Errorf("got version: %q, want: 1.2", doc.Log.Version)
+	}
+	if len(doc.Log.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(doc.Log.Entries))
+	}
+	e := doc.Log.Entries[0]
+	if e.Request.Method != "POST" || e.Request.URL != "/graphql" {
+		t.Errorf("got request: %s %s, want: POST /graphql", e.Request.Method, e.Request.URL)
+	}
+	if got, want := e.Request.PostData.Text, `{"query":"{viewer{login}}"}`+"\n"; got != want {
+		t.Errorf("got request body: %q, want: %q", got, want)
+	}
+	if e.Response.Status != http.StatusOK || !strings.Contains(e.Response.Content.Text, "gopher") {
+		t.Errorf("got response: %d %q", e.Response.Status, e.Response.Content.Text)
+	}
+	for _, h := range append(e.Request.Headers, e.Response.Headers...) {
+		if strings.Contains(h.Value, "secret") {
+			t.Errorf("got unredacted header %s: %s", h.Name, h.Value)
+		}
+	}
+}
+
+func TestHARRecorder_bounds(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		mustWrite(w, `{"data": {"viewer": {"login": "`+strings.Repeat("a", 100)+`"}}}`)
+	})
+	rec := &graphql.HARRecorder{MaxEntries: 2, MaxBodySize: 10}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithHAR(rec))
+
+	for i := 0; i < 3; i++ {
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := rec.Len(), 2; got != want {
+		t.Fatalf("got %d entries, want %d", got, want)
+	}
+	for _, e := range readHAR(t, rec).Log.Entries {
+		if got, want := e.Response.Content.Text, `{"data": {`; got != want {
+			t.Errorf("got body: %q, want: %q", got, want)
+		}
+		if e.Response.Content.Size <= 10 || e.Response.Content.Comment != "truncated" {
+			t.Errorf("got size %d, comment %q; want full size and truncated", e.Response.Content.Size, e.Response.Content.Comment)
+		}
+		if len(e.Request.PostData.Text) != 10 || e.Request.BodySize <= 10 {
+			t.Errorf("got request body %q of size %d, want truncated", e.Request.PostData.Text, e.Request.BodySize)
+		}
+	}
+
+	rec.Reset()
+	if got := rec.Len(); got != 0 {
+		t.Errorf("got %d entries after Reset, want 0", got)
+	}
+}
+
+func TestHARRecorder_redact(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"login": {"session": "secret"}}}`)
+	})
+	for _, tc := range []struct {
+		maxBodySize int
+		wantRequest string
+	}{
+		{0, `{"query":"mutation($password:String!$user:String!){login(user: $user, password: $password){session}}","variables":{"password":"[REDACTED]","user":"gopher"}}`},
+		{10, `[REDACTED]`}, // Truncated.
+	} {
+		rec := &graphql.HARRecorder{
+			MaxBodySize:     tc.maxBodySize,
+			RedactVariables: []string{"password"},
+			RedactBody: func(request bool, mimeType, text string) string {
+				if request {
+					return text
+				}
+				return strings.ReplaceAll(text, "secret", "[REDACTED]")
+			},
+		}
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithHAR(rec))
+
+		var m struct {
+			Login struct {
+				Session graphql.String
+			} `graphql:"login(user: $user, password: $password)"`
+		}
+		err := client.Mutate(context.Background(), &m, map[string]interface{}{
+			"user":     graphql.String("gopher"),
+			"password": graphql.String("secret"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Login.Session != "secret" {
+			t.Errorf("got session %q, want the server's", m.Login.Session)
+		}
+		e := readHAR(t, rec).Log.Entries[0]
+		if got := e.Request.PostData.Text; got != tc.wantRequest {
+			t.Errorf("got request body:\n%s\nwant:\n%s", got, tc.wantRequest)
+		}
+		if got := e.Response.Content.Text; strings.Contains(got, "secret") {
+			t.Errorf("got unredacted response body: %s", got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultPingTimeout is the timeout Ping uses unless WithPingTimeout is given.
+const DefaultPingTimeout = 2 * time.Second
+
+// HealthStatus is the status of a GraphQL server, as determined by Ping.
+type HealthStatus int
+
+const (
+	// Healthy means the server answered the probe successfully.
+	Healthy HealthStatus = iota
+	// Degraded means the server answered the probe,
+	// but its response contained GraphQL errors.
+	Degraded
+	// Unhealthy means the server couldn't be reached, didn't answer in time,
+	// or answered with an unexpected HTTP status code or body.
+	Unhealthy
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case Degraded:
+		return "degraded"
+	case Unhealthy:
+		return "unhealthy"
+	default:
+		return fmt.Sprintf("HealthStatus(%d)", int(s))
+	}
+}
+
+// Health is the result of Ping.
+type Health struct {
+	Status  HealthStatus
+	Latency time.Duration // Time taken by the probe.
+	Err     error         // Error returned by the probe, if not Healthy.
+}
+
+// OK reports whether h.Status is Healthy.
+func (h Health) OK() bool {
+	return h.Status == Healthy
+}
+
+// PingOption configures Ping.
+type PingOption func(*pingConfig)
+
+type pingConfig struct {
+	probe   Operation
+	timeout time.Duration
+}
+
+// WithProbe makes Ping run op instead of the default `{__typename}` query.
+func WithProbe(op Operation) PingOption {
+	return func(c *pingConfig) {
+		c.probe = op
+	}
+}
+
+// WithPingTimeout sets the timeout of the probe. A timeout of zero or less
+// means the probe is only bounded by the context passed to Ping.
+func WithPingTimeout(d time.Duration) PingOption {
+	return func(c *pingConfig) {
+		c.timeout = d
+	}
+}
+
+// Ping checks the health of the GraphQL server by running a minimal
+// `{__typename}` query with a short timeout (DefaultPingTimeout).
+// It's intended for use in readiness checks of services that depend
+// on a GraphQL upstream.
+func (c *Client) Ping(ctx context.Context, opts ...PingOption) Health {
+	cfg := pingConfig{timeout: DefaultPingTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.probe == nil {
+		var q struct {
+			Typename string `graphql:"__typename"`
+		}
+		cfg.probe = NewQuery(&q, nil)
+	}
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	start := c.clock.Now()
+	err := c.Run(ctx, cfg.probe)
+	h := Health{Latency: c.clock.Now().Sub(start), Err: err}
+	switch err.(type) {
+	case nil:
+		h.Status = Healthy
+	case ErrorList:
+		h.Status = Degraded
+	default:
+		h.Status = Unhealthy
+	}
+	return h
+}
@@ -0,0 +1,76 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		opts    []graphql.PingOption
+		want    graphql.HealthStatus
+	}{
+		{
+			name: "healthy",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				if got, want := mustRead(req.Body), `{"query":"{__typename}"}`+"\n"; got != want {
+					t.Errorf("got body: %v, want %v", got, want)
+				}
+				mustWrite(w, `{"data": {"__typename": "Query"}}`)
+			},
+			want: graphql.Healthy,
+		},
+		{
+			name: "custom probe",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				if got, want := mustRead(req.Body), `{"query":"{viewer{login}}"}`+"\n"; got != want {
+					t.Errorf("got body: %v, want %v", got, want)
+				}
+				mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+			},
+			opts: []graphql.PingOption{graphql.WithProbe(&graphql.Static{
+				QueryStr: "{viewer{login}}",
+				Into:     new(struct{ Viewer struct{ Login string } }),
+			})},
+			want: graphql.Healthy,
+		},
+		{
+			name: "graphql errors",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				mustWrite(w, `{"errors": [{"message": "not ready"}]}`)
+			},
+			want: graphql.Degraded,
+		},
+		{
+			name: "status code",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: graphql.Unhealthy,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				<-req.Context().Done()
+			},
+			opts: []graphql.PingOption{graphql.WithPingTimeout(10 * time.Millisecond)},
+			want: graphql.Unhealthy,
+		},
+	}
+	for _, tc := range tests {
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: tc.handler}})
+		h := client.Ping(context.Background(), tc.opts...)
+		if h.Status != tc.want {
+			t.Errorf("%s: got status: %v (err: %v), want: %v", tc.name, h.Status, h.Err, tc.want)
+		}
+		if h.OK() != (h.Err == nil) {
+			t.Errorf("%s: got OK: %v, with err: %v", tc.name, h.OK(), h.Err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+type (
+	// Int64 represents signed 64-bit integer values, of custom scalars
+	// named Int64. Values are sent as JSON numbers, unless the client
+	// is created with WithInt64AsString.
+	Int64 int64
+
+	// BigInt represents signed 64-bit integer values, of custom scalars
+	// named BigInt. Values are sent as JSON numbers, unless the client
+	// is created with WithInt64AsString.
+	BigInt int64
+)
+
+// NewInt64 is a helper to make a new *Int64.
+func NewInt64(v Int64) *Int64 { return &v }
+
+// NewBigInt is a helper to make a new *BigInt.
+func NewBigInt(v BigInt) *BigInt { return &v }
+
+// UnmarshalJSON decodes i from a JSON number or string.
+func (i *Int64) UnmarshalJSON(b []byte) error {
+	return unmarshalInt64(b, (*int64)(i))
+}
+
+// UnmarshalJSON decodes i from a JSON number or string.
+func (i *BigInt) UnmarshalJSON(b []byte) error {
+	return unmarshalInt64(b, (*int64)(i))
+}
+
+func unmarshalInt64(b []byte, i *int64) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		b = []byte(s)
+	}
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = n
+	return nil
+}
+
+// WithInt64AsString makes the client send variables of types Int64 and
+// BigInt as JSON strings rather than numbers, which is what schemas with
+// String-serialized 64-bit scalars require, and avoids the loss of precision
+// of servers that decode JSON numbers as float64. If scalars are given,
+// only variables of the named types, such as "BigInt", are sent as strings.
+//
+// Only variables, and lists, pointers and Optionals of them, are converted. Int64 fields
+// of input objects can be sent as strings with the `json:",string"` tag option.
+func WithInt64AsString(scalars ...string) ClientOption {
+	return func(c *Client) {
+		c.int64Strings = map[string]bool{}
+		for _, s := range scalars {
+			c.int64Strings[s] = true
+		}
+	}
+}
+
+// stringifyInt64s returns variables with values of types Int64 and
+// BigInt replaced by strings, if c is configured to send them as strings.
+func (c *Client) stringifyInt64s(variables map[string]interface{}) map[string]interface{} {
+	if c.int64Strings == nil {
+		return variables
+	}
+	var out map[string]interface{}
+	for k, v := range variables {
+		s, ok := c.stringifyInt64(reflect.ValueOf(v))
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(variables))
+			for k, v := range variables {
+				out[k] = v
+			}
+		}
+		out[k] = s
+	}
+	if out == nil {
+		return variables
+	}
+	return out
+}
+
+var (
+	int64Type  = reflect.TypeOf(Int64(0))
+	bigIntType = reflect.TypeOf(BigInt(0))
+)
+
+// stringifyInt64 returns v with values of types Int64 and BigInt
+// replaced by strings, and whether any were replaced.
+func (c *Client) stringifyInt64(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if o, ok := v.Interface().(optional); ok {
+		return c.stringifyInt64(reflect.ValueOf(o.optionalValue()))
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, false
+		}
+		return c.stringifyInt64(v.Elem())
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		changed := false
+		for i := range list {
+			e, ok := c.stringifyInt64(v.Index(i))
+			if !ok {
+				e = v.Index(i).Interface()
+			}
+			list[i], changed = e, changed || ok
+		}
+		return list, changed
+	}
+	if t := v.Type(); t == int64Type || t == bigIntType {
+		if len(c.int64Strings) == 0 || c.int64Strings[t.Name()] {
+			return strconv.FormatInt(v.Int(), 10), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestInt64AsString(t *testing.T) {
+	tests := []struct {
+		opts []graphql.ClientOption
+		want string
+	}{
+		{
+			want: `{"after":7,"before":null,"big":9007199254740993,"ids":[1,2],"long":-5}`,
+		},
+		{
+			opts: []graphql.ClientOption{graphql.WithInt64AsString()},
+			want: `{"after":"7","before":null,"big":"9007199254740993","ids":["1","2"],"long":"-5"}`,
+		},
+		{
+			opts: []graphql.ClientOption{graphql.WithInt64AsString("BigInt")},
+			want: `{"after":"7","before":null,"big":"9007199254740993","ids":[1,2],"long":-5}`,
+		},
+	}
+	for _, tc := range tests {
+		var body string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			body = mustRead(req.Body)
+			mustWrite(w, `{"data": {"account": {"balance": "9007199254740993", "limit": 9007199254740995}}}`)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, tc.opts...)
+
+		var q struct {
+			Account struct {
+				Balance graphql.BigInt
+				Limit   graphql.Int64
+			} `graphql:"account(big: $big, long: $long, ids: $ids, after: $after, before: $before)"`
+		}
+		err := client.Query(context.Background(), &q, map[string]interface{}{
+			"big":    graphql.BigInt(9007199254740993),
+			"long":   graphql.NewInt64(-5),
+			"ids":    []graphql.Int64{1, 2},
+			"after":  graphql.Some(graphql.BigInt(7)),
+			"before": graphql.Null[graphql.Int64](),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"query":"query($after:BigInt$before:Int64$big:BigInt!$ids:[Int64!]!$long:Int64){account(big: $big, long: $long, ids: $ids, after: $after, before: $before){balance,limit}}","variables":` + tc.want + "}\n"
+		if body != want {
+			t.Errorf("got body:\n%v\nwant:\n%v", body, want)
+		}
+		if q.Account.Balance != 9007199254740993 || q.Account.Limit != 9007199254740995 {
+			t.Errorf("got account: %+v", q.Account)
+		}
+	}
+}
@@ -73,6 +73,34 @@ func BenchmarkUnmarshalGraphQL(b *testing.B) {
 	}
 }
 
+func BenchmarkUnmarshalGraphQLPooled(b *testing.B) {
+	type query struct {
+		Viewer struct {
+			Login     graphql.String
+			CreatedAt time.Time
+		}
+	}
+	for i := 0; i < b.N; i++ {
+		now := time.Now().UTC()
+		var got query
+		err := jsonutil.UnmarshalGraphQLPooled([]byte(`{
+			"viewer": {
+				"login": "shurcooL-test",
+				"createdAt": "`+now.Format(time.RFC3339Nano)+`"
+			}
+		}`), &got)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var want query
+		want.Viewer.Login = "shurcooL-test"
+		want.Viewer.CreatedAt = now
+		if !reflect.DeepEqual(got, want) {
+			b.Error("not equal")
+		}
+	}
+}
+
 func BenchmarkJSONUnmarshal(b *testing.B) {
 	type query struct {
 		Viewer struct {
@@ -10,6 +10,7 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // UnmarshalGraphQL parses the JSON-encoded GraphQL response data and stores
@@ -18,9 +19,31 @@ import (
 // The implementation is created on top of the JSON tokenizer available
 // in "encoding/json".Decoder.
 func UnmarshalGraphQL(data []byte, v interface{}) error {
+	return unmarshalGraphQL(&decoder{}, data, v)
+}
+
+// decoderPool holds decoders reused by UnmarshalGraphQLPooled.
+var decoderPool = sync.Pool{New: func() interface{} { return new(decoder) }}
+
+// UnmarshalGraphQLPooled is like UnmarshalGraphQL, but reuses the stacks
+// it keeps while decoding across calls, to reduce allocations when
+// decoding many responses. As with UnmarshalGraphQL, v doesn't refer
+// to data once it returns, unless json.Unmarshaler implementations in
+// v retain the data they're passed, which they mustn't.
+func UnmarshalGraphQLPooled(data []byte, v interface{}) error {
+	d := decoderPool.Get().(*decoder)
+	err := unmarshalGraphQL(d, data, v)
+	d.reset()
+	decoderPool.Put(d)
+	return err
+}
+
+// unmarshalGraphQL decodes JSON-encoded data into v with decoder d.
+func unmarshalGraphQL(d *decoder, data []byte, v interface{}) error {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
-	err := (&decoder{tokenizer: dec}).Decode(v)
+	d.tokenizer = dec
+	err := d.Decode(v)
 	if err != nil {
 		return err
 	}
@@ -54,6 +77,46 @@ type decoder struct {
 	// a single JSON value into multiple GraphQL fragments or embedded structs, so
 	// we keep track of them all.
 	vs [][]reflect.Value
+
+	// Raw JSON recorders for objects being decoded into structs
+	// that have an unknown type fallback field.
+	recorders []*rawRecorder
+
+	// Scratch space for the fields and fragments found while decoding.
+	scratch []reflect.Value
+}
+
+// reset clears d, keeping the capacity of its stacks, so it can be reused
+// without holding on to the values it decoded into.
+func (d *decoder) reset() {
+	d.tokenizer = nil
+	d.parseState = d.parseState[:0]
+	vs := d.vs[:cap(d.vs)]
+	for i := range vs {
+		s := vs[i][:cap(vs[i])]
+		for j := range s {
+			s[j] = reflect.Value{}
+		}
+		vs[i] = s[:0]
+	}
+	d.vs = vs[:0]
+	d.recorders = nil
+	scratch := d.scratch[:cap(d.scratch)]
+	for i := range scratch {
+		scratch[i] = reflect.Value{}
+	}
+	d.scratch = scratch[:0]
+}
+
+// pushVs pushes a new stack holding v onto d.vs, reusing the
+// memory of a stack popped earlier, if any.
+func (d *decoder) pushVs(v reflect.Value) {
+	if len(d.vs) < cap(d.vs) {
+		d.vs = d.vs[:len(d.vs)+1]
+		d.vs[len(d.vs)-1] = append(d.vs[len(d.vs)-1][:0], v)
+		return
+	}
+	d.vs = append(d.vs, []reflect.Value{v})
 }
 
 // Decode decodes a single JSON value from d.tokenizer into v.
@@ -62,7 +125,8 @@ func (d *decoder) Decode(v interface{}) error {
 	if rv.Kind() != reflect.Ptr {
 		return fmt.Errorf("cannot decode into non-pointer %T", v)
 	}
-	d.vs = [][]reflect.Value{{rv.Elem()}}
+	d.vs = d.vs[:0]
+	d.pushVs(rv.Elem())
 	return d.decode()
 }
 
@@ -71,7 +135,7 @@ func (d *decoder) decode() error {
 	// The loop invariant is that the top of each d.vs stack
 	// is where we try to unmarshal the next JSON value we see.
 	for len(d.vs) > 0 {
-		tok, err := d.tokenizer.Token()
+		tok, err := d.token()
 		if err == io.EOF {
 			return errors.New("unexpected end of JSON input")
 		} else if err != nil {
@@ -87,27 +151,43 @@ func (d *decoder) decode() error {
 				return errors.New("unexpected non-key in JSON input")
 			}
 			someFieldExist := false
+			fs := d.scratchValues(len(d.vs))
 			for i := range d.vs {
 				v := d.vs[i][len(d.vs[i])-1]
 				if v.Kind() == reflect.Ptr {
 					v = v.Elem()
 				}
-				var f reflect.Value
 				if v.Kind() == reflect.Struct {
-					f = fieldByGraphQLName(v, key)
-					if f.IsValid() {
+					fs[i] = fieldByGraphQLName(v, key)
+					if fs[i].IsValid() {
 						someFieldExist = true
 					}
 				}
-				d.vs[i] = append(d.vs[i], f)
 			}
 			if !someFieldExist {
+				if d.captured() {
+					// The value is captured by an unknown type fallback; skip it.
+					if err := d.skipValue(); err != nil {
+						return err
+					}
+					continue
+				}
 				return fmt.Errorf("struct field for %q doesn't exist in any of %v places to unmarshal", key, len(d.vs))
 			}
+			for i := range d.vs {
+				d.vs[i] = append(d.vs[i], fs[i])
+			}
+
+			if ok, err := d.streamValue(); err != nil {
+				return err
+			} else if ok {
+				d.popAllVs()
+				continue
+			}
 
 			// We've just consumed the current token, which was the key.
 			// Read the next token, which should be the value, and let the rest of code process it.
-			tok, err = d.tokenizer.Token()
+			tok, err = d.token()
 			if err == io.EOF {
 				return errors.New("unexpected end of JSON input")
 			} else if err != nil {
@@ -144,6 +224,9 @@ func (d *decoder) decode() error {
 				if !v.IsValid() {
 					continue
 				}
+				if tok == nil && d.state() == '[' && !isNullable(v) {
+					return fmt.Errorf("cannot unmarshal null list element into non-nullable %v; use a pointer element type such as *%v", v.Type(), v.Type())
+				}
 				err := unmarshalValue(tok, v)
 				if err != nil {
 					return err
@@ -152,13 +235,31 @@ func (d *decoder) decode() error {
 			d.popAllVs()
 
 		case json.Delim:
+			if (tok == '{' || tok == '[') && d.topsAreUnmarshalers() {
+				// Object or array value of a scalar, such as a GeoJSON geometry.
+				raw, err := d.rawValue(tok)
+				if err != nil {
+					return err
+				}
+				for i := range d.vs {
+					v := d.vs[i][len(d.vs[i])-1]
+					if !v.IsValid() {
+						continue
+					}
+					if err := json.Unmarshal(raw, v.Addr().Interface()); err != nil {
+						return err
+					}
+				}
+				d.popAllVs()
+				continue
+			}
 			switch tok {
 			case '{':
 				// Start of object.
 
 				d.pushState(tok)
 
-				frontier := make([]reflect.Value, len(d.vs)) // Places to look for GraphQL fragments/embedded structs.
+				frontier := d.scratchValues(len(d.vs)) // Places to look for GraphQL fragments/embedded structs.
 				for i := range d.vs {
 					v := d.vs[i][len(d.vs[i])-1]
 					frontier[i] = v
@@ -166,12 +267,19 @@ func (d *decoder) decode() error {
 					if v.Kind() == reflect.Ptr && v.IsNil() {
 						v.Set(reflect.New(v.Type().Elem())) // v = new(T).
 					}
+					if v.Kind() == reflect.Ptr {
+						v = v.Elem()
+					}
+					if v.Kind() == reflect.Struct {
+						if f, ok := fallbackField(v); ok {
+							d.recorders = append(d.recorders, newRawRecorder(f, fragmentTypes(v)))
+						}
+					}
 				}
 				// Find GraphQL fragments/embedded structs recursively, adding to frontier
 				// as new ones are discovered and exploring them further.
-				for len(frontier) > 0 {
-					v := frontier[0]
-					frontier = frontier[1:]
+				for next := 0; next < len(frontier); next++ {
+					v := frontier[next]
 					if v.Kind() == reflect.Ptr {
 						v = v.Elem()
 					}
@@ -181,11 +289,12 @@ func (d *decoder) decode() error {
 					for i := 0; i < v.NumField(); i++ {
 						if isGraphQLFragment(v.Type().Field(i)) || v.Type().Field(i).Anonymous {
 							// Add GraphQL fragment or embedded struct.
-							d.vs = append(d.vs, []reflect.Value{v.Field(i)})
+							d.pushVs(v.Field(i))
 							frontier = append(frontier, v.Field(i))
 						}
 					}
 				}
+				d.scratch = frontier
 			case '[':
 				// Start of array.
 
@@ -221,6 +330,98 @@ func (d *decoder) decode() error {
 	return nil
 }
 
+// token reads the next JSON token from d.tokenizer,
+// feeding it to any active raw JSON recorders.
+func (d *decoder) token() (json.Token, error) {
+	tok, err := d.tokenizer.Token()
+	if err != nil || len(d.recorders) == 0 {
+		return tok, err
+	}
+	active := d.recorders[:0]
+	for _, r := range d.recorders {
+		r.write(tok)
+		if r.done() {
+			r.finish()
+			continue
+		}
+		active = append(active, r)
+	}
+	d.recorders = active
+	return tok, nil
+}
+
+// captured reports whether the key just read is of an object of unknown
+// type being recorded by an unknown type fallback.
+func (d *decoder) captured() bool {
+	for _, r := range d.recorders {
+		if r.captures() {
+			return true
+		}
+	}
+	return false
+}
+
+// rawValue reads the rest of a JSON object or array whose opening
+// delimiter open has already been read, and returns its raw JSON text.
+func (d *decoder) rawValue(open json.Delim) (json.RawMessage, error) {
+	r := &rawRecorder{frames: []rawFrame{{delim: open}}}
+	r.buf.WriteByte(byte(open))
+	for !r.done() {
+		tok, err := d.token()
+		if err == io.EOF {
+			return nil, errors.New("unexpected end of JSON input")
+		} else if err != nil {
+			return nil, err
+		}
+		r.write(tok)
+	}
+	return r.buf.Bytes(), nil
+}
+
+// topsAreUnmarshalers reports whether the values on top of d.vs, where
+// the next JSON value is to be unmarshaled, all implement json.Unmarshaler
+// themselves, directly or through pointers.
+func (d *decoder) topsAreUnmarshalers() bool {
+	found := false
+	for i := range d.vs {
+		v := d.vs[i][len(d.vs[i])-1]
+		if !v.IsValid() {
+			continue
+		}
+		t := v.Type()
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Interface || !reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// skipValue reads and discards a single JSON value from d.tokenizer.
+func (d *decoder) skipValue() error {
+	depth := 0
+	for {
+		tok, err := d.token()
+		if err == io.EOF {
+			return errors.New("unexpected end of JSON input")
+		} else if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
 // pushState pushes a new parse state s onto the stack.
 func (d *decoder) pushState(s json.Delim) {
 	d.parseState = append(d.parseState, s)
@@ -241,15 +442,28 @@ func (d *decoder) state() json.Delim {
 }
 
 // popAllVs pops from all d.vs stacks, keeping only non-empty ones.
+// The empty ones are kept past the end of d.vs, for pushVs to reuse.
 func (d *decoder) popAllVs() {
-	var nonEmpty [][]reflect.Value
+	n := 0
 	for i := range d.vs {
 		d.vs[i] = d.vs[i][:len(d.vs[i])-1]
 		if len(d.vs[i]) > 0 {
-			nonEmpty = append(nonEmpty, d.vs[i])
+			d.vs[n], d.vs[i] = d.vs[i], d.vs[n]
+			n++
 		}
 	}
-	d.vs = nonEmpty
+	d.vs = d.vs[:n]
+}
+
+// scratchValues returns d's scratch space, with n zero values.
+// It's valid until the next call.
+func (d *decoder) scratchValues(n int) []reflect.Value {
+	s := d.scratch[:0]
+	for i := 0; i < n; i++ {
+		s = append(s, reflect.Value{})
+	}
+	d.scratch = s
+	return s
 }
 
 // fieldByGraphQLName returns an exported struct field of struct v
@@ -296,16 +510,117 @@ func isGraphQLFragment(f reflect.StructField) bool {
 		return false
 	}
 	value = strings.TrimSpace(value) // TODO: Parse better.
-	return strings.HasPrefix(value, "...")
+	return strings.HasPrefix(value, "...") && value != FallbackTag
+}
+
+// FallbackTag is the graphql struct field tag that marks a json.RawMessage
+// field as the unknown type fallback of its parent struct. When the
+// __typename of a decoded object doesn't match the type condition of any
+// of the struct's inline fragments, the raw JSON object is stored in it.
+// Fallback fields are not included in queries.
+const FallbackTag = "... on *"
+
+// fallbackField returns the unknown type fallback field of struct v, if any.
+func fallbackField(v reflect.Value) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" || strings.TrimSpace(f.Tag.Get("graphql")) != FallbackTag {
+			continue
+		}
+		if f.Type != rawMessageType {
+			continue
+		}
+		return v.Field(i), true
+	}
+	return reflect.Value{}, false
 }
 
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// fragmentTypes returns the type conditions of inline fragments in struct v.
+func fragmentTypes(v reflect.Value) []string {
+	var types []string
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !isGraphQLFragment(f) {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(f.Tag.Get("graphql")), "..."))
+		if !strings.HasPrefix(value, "on ") {
+			continue
+		}
+		value = strings.TrimSpace(value[len("on "):])
+		if i := strings.IndexAny(value, " @{"); i != -1 {
+			value = value[:i]
+		}
+		types = append(types, value)
+	}
+	return types
+}
+
+// isNullable reports whether v can represent a JSON null value
+// distinctly from its zero value.
+func isNullable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return v.Addr().Type().Implements(jsonUnmarshaler)
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// enum is implemented by enum types that want forward-compatible decoding.
+// It matches the graphql.Enum interface.
+type enum interface {
+	EnumValues() []string
+	SetUnknown(raw string)
+}
+
+var enumType = reflect.TypeOf((*enum)(nil)).Elem()
+
 // unmarshalValue unmarshals JSON value into v.
 // v must be addressable and not obtained by the use of unexported
 // struct fields, otherwise unmarshalValue will panic.
 func unmarshalValue(value json.Token, v reflect.Value) error {
+	if s, ok := value.(string); ok {
+		if e, ok := enumValue(v); ok && !isKnownEnumValue(e, s) {
+			e.SetUnknown(s)
+			return nil
+		}
+	}
 	b, err := json.Marshal(value) // TODO: Short-circuit (if profiling says it's worth it).
 	if err != nil {
 		return err
 	}
 	return json.Unmarshal(b, v.Addr().Interface())
 }
+
+// enumValue returns v as an enum, if it's one, or a pointer to one,
+// which is allocated if nil.
+func enumValue(v reflect.Value) (enum, bool) {
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !reflect.PtrTo(t).Implements(enumType) {
+		return nil, false
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v.Addr().Interface().(enum), true
+}
+
+// isKnownEnumValue reports whether s is one of the values of enum e.
+func isKnownEnumValue(e enum, s string) bool {
+	for _, known := range e.EnumValues() {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package jsonutil_test
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 	"time"
@@ -122,6 +123,64 @@ func TestUnmarshalGraphQL_arrayReset(t *testing.T) {
 	}
 }
 
+func TestUnmarshalGraphQL_arrayNullElements(t *testing.T) {
+	type query struct {
+		Foo []*graphql.String
+		Bar [][]*graphql.String
+		Baz [][]graphql.String
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"foo": ["bar", null, "baz"],
+		"bar": [["a", null], null, []],
+		"baz": [["a"], null]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Foo: []*graphql.String{graphql.NewString("bar"), nil, graphql.NewString("baz")},
+		Bar: [][]*graphql.String{{graphql.NewString("a"), nil}, nil, {}},
+		Baz: [][]graphql.String{{"a"}, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+func TestUnmarshalGraphQL_arrayNullElementNonNullable(t *testing.T) {
+	tests := []struct {
+		in   string
+		v    interface{}
+		want string
+	}{
+		{
+			in:   `{"foo": ["bar", null]}`,
+			v:    new(struct{ Foo []graphql.String }),
+			want: "cannot unmarshal null list element into non-nullable graphql.String; use a pointer element type such as *graphql.String",
+		},
+		{
+			in:   `{"foo": [["bar", null]]}`,
+			v:    new(struct{ Foo [][]string }),
+			want: "cannot unmarshal null list element into non-nullable string; use a pointer element type such as *string",
+		},
+		{
+			in:   `{"foo": [{"name": "bar"}, null]}`,
+			v:    new(struct{ Foo []struct{ Name string } }),
+			want: "cannot unmarshal null list element into non-nullable struct { Name string }; use a pointer element type such as *struct { Name string }",
+		},
+	}
+	for _, tc := range tests {
+		err := jsonutil.UnmarshalGraphQL([]byte(tc.in), tc.v)
+		if err == nil {
+			t.Fatalf("%s: got error: nil, want: non-nil", tc.in)
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("%s: got error: %v, want: %v", tc.in, got, tc.want)
+		}
+	}
+}
+
 func TestUnmarshalGraphQL_objectArray(t *testing.T) {
 	type query struct {
 		Foo []struct {
@@ -326,6 +385,168 @@ func TestUnmarshalGraphQL_union(t *testing.T) {
 	}
 }
 
+func TestUnmarshalGraphQL_unknownTypeFallback(t *testing.T) {
+	type issueTimelineItem struct {
+		Typename    string          `graphql:"__typename"`
+		Other       json.RawMessage `graphql:"... on *"`
+		ClosedEvent struct {
+			CreatedAt time.Time
+		} `graphql:"... on ClosedEvent"`
+	}
+	var got []issueTimelineItem
+	err := jsonutil.UnmarshalGraphQL([]byte(`[
+		{
+			"__typename": "ClosedEvent",
+			"createdAt": "2017-06-29T04:12:01Z"
+		},
+		{
+			"__typename": "LockedEvent",
+			"lockReason": null,
+			"actor": {"login": "shurcooL-test", "ids": [1, 2.5]}
+		}
+	]`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d items, want 2", len(got))
+	}
+	if got[0].Other != nil {
+		t.Errorf("got non-nil got[0].Other: %s", got[0].Other)
+	}
+	if want := time.Unix(1498709521, 0).UTC(); !got[0].ClosedEvent.CreatedAt.Equal(want) {
+		t.Errorf("got got[0].ClosedEvent.CreatedAt: %v, want: %v", got[0].ClosedEvent.CreatedAt, want)
+	}
+	if got, want := got[1].Typename, "LockedEvent"; got != want {
+		t.Errorf("got got[1].Typename: %q, want: %q", got, want)
+	}
+	if got, want := string(got[1].Other), `{"__typename":"LockedEvent","lockReason":null,"actor":{"login":"shurcooL-test","ids":[1,2.5]}}`; got != want {
+		t.Errorf("got got[1].Other:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+type issueState string
+
+const (
+	issueStateUnknown issueState = ""
+	issueStateOpen    issueState = "OPEN"
+	issueStateClosed  issueState = "CLOSED"
+)
+
+func (issueState) EnumValues() []string { return []string{"OPEN", "CLOSED"} }
+
+func (s *issueState) SetUnknown(string) { *s = issueStateUnknown }
+
+// lockReason is an enum that retains unknown raw values.
+type lockReason struct {
+	Value string
+	Raw   string
+}
+
+func (lockReason) EnumValues() []string { return []string{"OFF_TOPIC", "SPAM"} }
+
+func (r *lockReason) SetUnknown(raw string) { *r = lockReason{Raw: raw} }
+
+func (r *lockReason) UnmarshalJSON(b []byte) error { return json.Unmarshal(b, &r.Value) }
+
+func TestUnmarshalGraphQL_enum(t *testing.T) {
+	type query struct {
+		States  []issueState
+		Reasons []lockReason
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"states": ["OPEN", "MERGED", "CLOSED"],
+		"reasons": ["SPAM", "RESOLVED"]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		States:  []issueState{issueStateOpen, issueStateUnknown, issueStateClosed},
+		Reasons: []lockReason{{Value: "SPAM"}, {Raw: "RESOLVED"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\ngot:  %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQL_unknownTypeFallbackTypo(t *testing.T) {
+	type issueTimelineItem struct {
+		Typename    string          `graphql:"__typename"`
+		Other       json.RawMessage `graphql:"... on *"`
+		ClosedEvent struct {
+			Author struct {
+				Login string
+			}
+		} `graphql:"... on ClosedEvent"`
+	}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "typo",
+			data: `{"__typename": "ClosedEvent", "typo": 1}`,
+			want: `struct field for "typo" doesn't exist in any of 2 places to unmarshal`,
+		},
+		{
+			name: "author.logn",
+			data: `{"__typename": "ClosedEvent", "author": {"logn": "gopher"}}`,
+			want: `struct field for "logn" doesn't exist in any of 2 places to unmarshal`,
+		},
+		{
+			name: "unknown type actor.logn",
+			data: `{"__typename": "LockedEvent", "actor": {"logn": "gopher"}}`,
+		},
+	}
+	for _, tc := range tests {
+		var got issueTimelineItem
+		err := jsonutil.UnmarshalGraphQL([]byte(tc.data), &got)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%s: got error %v, want none", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: got error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestUnmarshalGraphQL_enumPointer(t *testing.T) {
+	type query struct {
+		State  *issueState
+		Reason *lockReason
+		Known  *issueState
+		Null   *issueState
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"state": "MERGED",
+		"reason": "RESOLVED",
+		"known": "CLOSED",
+		"null": null
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State == nil || *got.State != issueStateUnknown {
+		t.Errorf("got state %v, want pointer to unknown state", got.State)
+	}
+	if got.Reason == nil || *got.Reason != (lockReason{Raw: "RESOLVED"}) {
+		t.Errorf("got reason %+v, want pointer to unknown reason", got.Reason)
+	}
+	if got.Known == nil || *got.Known != issueStateClosed {
+		t.Errorf("got known state %v, want pointer to CLOSED", got.Known)
+	}
+	if got.Null != nil {
+		t.Errorf("got null state %v, want nil", *got.Null)
+	}
+}
+
 // Issue https://github.com/shurcooL/githubv4/issues/18.
 func TestUnmarshalGraphQL_arrayInsideInlineFragment(t *testing.T) {
 	/*
@@ -393,3 +614,86 @@ func TestUnmarshalGraphQL_arrayInsideInlineFragment(t *testing.T) {
 		t.Error("not equal")
 	}
 }
+
+// jsonScalar is a custom scalar whose values are JSON objects or arrays.
+type jsonScalar struct {
+	Raw string
+}
+
+func (s *jsonScalar) UnmarshalJSON(b []byte) error {
+	s.Raw = string(b)
+	return nil
+}
+
+func TestUnmarshalGraphQL_objectScalar(t *testing.T) {
+	type query struct {
+		Place struct {
+			Name     string
+			Geometry jsonScalar
+			Bounds   *jsonScalar
+			Tags     json.RawMessage
+		}
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"place": {
+			"name": "Berlin",
+			"geometry": {"type": "Point", "coordinates": [13.4, 52.52]},
+			"bounds": [[13.08, 52.33], [13.76, 52.67]],
+			"tags": {"capital": true}
+		}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Place.Name = "Berlin"
+	want.Place.Geometry.Raw = `{"type":"Point","coordinates":[13.4,52.52]}`
+	want.Place.Bounds = &jsonScalar{Raw: `[[13.08,52.33],[13.76,52.67]]`}
+	want.Place.Tags = json.RawMessage(`{"capital":true}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\ngot:  %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestUnmarshalGraphQLPooled(t *testing.T) {
+	type actor struct {
+		Login graphql.String
+	}
+	type query struct {
+		Search struct {
+			Nodes []struct {
+				Typename graphql.String `graphql:"__typename"`
+				actor
+				Issue struct {
+					Title graphql.String
+				} `graphql:"... on Issue"`
+				User struct {
+					Bio graphql.String
+				} `graphql:"... on User"`
+			}
+		}
+	}
+	data := []byte(`{"search": {"nodes": [
+		{"__typename": "Issue", "login": "a", "title": "First"},
+		{"__typename": "User", "login": "b", "bio": "Hi"},
+		{"__typename": "Issue", "login": "c", "title": "Second"}
+	]}}`)
+	var want query
+	if err := jsonutil.UnmarshalGraphQL(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	// Decode repeatedly, so decoders are reused, including after errors.
+	for i := 0; i < 3; i++ {
+		var got query
+		if err := jsonutil.UnmarshalGraphQLPooled(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+		if err := jsonutil.UnmarshalGraphQLPooled([]byte(`{"search": {"unknown": 1}}`), &got); err == nil {
+			t.Error("got no error for unknown field")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// rawRecorder reconstructs the raw JSON text of a single JSON object
+// from the tokens it is fed, and stores it in an unknown type fallback
+// field when the object's __typename doesn't match any known type.
+type rawRecorder struct {
+	field reflect.Value // Fallback field of type json.RawMessage.
+	known []string      // Type conditions of known inline fragments.
+
+	buf      bytes.Buffer
+	frames   []rawFrame // Stack of JSON objects and arrays being recorded.
+	typename *string    // Value of top-level __typename key, if seen.
+	wantName bool       // Whether next top-level value is __typename.
+}
+
+// rawFrame is a JSON object or array being recorded.
+type rawFrame struct {
+	delim json.Delim
+	n     int // Number of keys and values written so far.
+}
+
+// newRawRecorder returns a recorder for an object whose opening '{'
+// token has already been consumed.
+func newRawRecorder(field reflect.Value, known []string) *rawRecorder {
+	r := &rawRecorder{field: field, known: known}
+	r.buf.WriteByte('{')
+	r.frames = []rawFrame{{delim: '{'}}
+	return r
+}
+
+// write appends tok to the recorded JSON text.
+func (r *rawRecorder) write(tok json.Token) {
+	if tok == json.Delim('}') || tok == json.Delim(']') {
+		r.frames = r.frames[:len(r.frames)-1]
+		r.buf.WriteByte(byte(tok.(json.Delim)))
+		return
+	}
+
+	top := &r.frames[len(r.frames)-1]
+	key := top.delim == '{' && top.n%2 == 0
+	switch {
+	case key && top.n > 0, top.delim == '[' && top.n > 0:
+		r.buf.WriteByte(',')
+	case top.delim == '{' && !key:
+		r.buf.WriteByte(':')
+	}
+	top.n++
+
+	if len(r.frames) == 1 {
+		switch {
+		case key:
+			r.wantName = tok == "__typename"
+		case r.wantName:
+			if s, ok := tok.(string); ok {
+				r.typename = &s
+			}
+			r.wantName = false
+		}
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		r.buf.WriteByte(byte(tok))
+		r.frames = append(r.frames, rawFrame{delim: tok})
+	case json.Number:
+		r.buf.WriteString(tok.String())
+	default:
+		b, _ := json.Marshal(tok) // Strings, bools and null can always be marshaled.
+		r.buf.Write(b)
+	}
+}
+
+// done reports whether the recorded object is complete.
+func (r *rawRecorder) done() bool {
+	return len(r.frames) == 0
+}
+
+// finish stores the recorded object in the fallback field,
+// unless its __typename matches one of the known types.
+func (r *rawRecorder) finish() {
+	if r.knownType() {
+		return
+	}
+	r.field.Set(reflect.ValueOf(json.RawMessage(r.buf.Bytes())))
+}
+
+// knownType reports whether the __typename of the recorded object,
+// if seen, matches one of the known types.
+func (r *rawRecorder) knownType() bool {
+	if r.typename == nil {
+		return false
+	}
+	for _, t := range r.known {
+		if t == *r.typename {
+			return true
+		}
+	}
+	return false
+}
+
+// captures reports whether the key just written is a key of the recorded
+// object itself, rather than of an object nested in it, whose __typename
+// has been seen and matches none of the known types, so its value needn't
+// be decoded other than into the fallback field.
+func (r *rawRecorder) captures() bool {
+	return len(r.frames) == 1 && r.typename != nil && !r.knownType()
+}
@@ -0,0 +1,297 @@
+package jsonutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DecodeResponse decodes a GraphQL response from r, decoding its "data"
+// member into the GraphQL query data structure pointed to by v while it's
+// read. String values decoded into fields implementing StreamWriter are
+// written to them as they're read, rather than held in memory.
+//
+// It returns the raw JSON of the "errors" and "extensions" members
+// of the response, if any.
+func DecodeResponse(r io.Reader, v interface{}) (errs, extensions json.RawMessage, err error) {
+	t := &tokenizer{r: bufio.NewReader(r)}
+	d := &decoder{tokenizer: t}
+	tok, err := t.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, nil, fmt.Errorf("invalid GraphQL response: got %v, want object", tok)
+	}
+	for {
+		tok, err := t.Token()
+		if err == io.EOF {
+			return nil, nil, errors.New("unexpected end of JSON input")
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if tok == json.Delim('}') {
+			return errs, extensions, nil
+		}
+		switch tok {
+		case "data":
+			err = (&decoder{tokenizer: t}).Decode(v)
+		case "errors":
+			errs, err = d.rawNext()
+		case "extensions":
+			extensions, err = d.rawNext()
+		default:
+			err = d.skipValue()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// StreamWriter is implemented by query data structure fields that string
+// values are streamed to, by DecodeResponse.
+type StreamWriter interface {
+	// StreamWriter returns the writer the value is to be written to,
+	// which is closed once it's written.
+	StreamWriter() (io.WriteCloser, error)
+}
+
+// rawNext reads the next JSON value from d.tokenizer, and returns its raw JSON text.
+func (d *decoder) rawNext() (json.RawMessage, error) {
+	tok, err := d.token()
+	if err == io.EOF {
+		return nil, errors.New("unexpected end of JSON input")
+	} else if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); ok {
+		return d.rawValue(delim)
+	}
+	if n, ok := tok.(json.Number); ok {
+		return json.RawMessage(n), nil
+	}
+	return json.Marshal(tok)
+}
+
+// streamValue streams the next JSON value from d.tokenizer to the values
+// on top of d.vs, if they all implement StreamWriter, the tokenizer
+// supports streaming, and the value is a string. It reports whether it did.
+func (d *decoder) streamValue() (bool, error) {
+	t, ok := d.tokenizer.(*tokenizer)
+	if !ok || len(d.recorders) > 0 {
+		return false, nil
+	}
+	var sws []StreamWriter
+	for i := range d.vs {
+		v := d.vs[i][len(d.vs[i])-1]
+		if !v.IsValid() {
+			continue
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return false, nil
+			}
+			v = v.Elem()
+		}
+		sw, ok := v.Addr().Interface().(StreamWriter)
+		if !ok {
+			return false, nil
+		}
+		sws = append(sws, sw)
+	}
+	if len(sws) == 0 {
+		return false, nil
+	}
+	if c, err := t.peek(); err != nil || c != '"' {
+		return false, nil
+	}
+	ws := make([]io.WriteCloser, len(sws))
+	mws := make([]io.Writer, len(sws))
+	for i, sw := range sws {
+		w, err := sw.StreamWriter()
+		if err != nil {
+			return false, err
+		}
+		ws[i], mws[i] = w, w
+	}
+	if _, err := t.r.ReadByte(); err != nil { // Opening quote.
+		return false, err
+	}
+	bw := bufio.NewWriter(io.MultiWriter(mws...))
+	err := t.readString(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	for _, w := range ws {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return true, err
+}
+
+// tokenizer is a JSON tokenizer with the same tokens as the Token method
+// of a json.Decoder that uses numbers, and support for streaming strings.
+type tokenizer struct {
+	r *bufio.Reader
+}
+
+// peek skips whitespace and separators, and returns the next byte
+// without consuming it.
+func (t *tokenizer) peek() (byte, error) {
+	for {
+		b, err := t.r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\n', '\r', ',', ':':
+			t.r.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// Token returns the next JSON token.
+func (t *tokenizer) Token() (json.Token, error) {
+	c, err := t.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == '{' || c == '}' || c == '[' || c == ']':
+		t.r.ReadByte()
+		return json.Delim(c), nil
+	case c == '"':
+		t.r.ReadByte()
+		var buf bytes.Buffer
+		if err := t.readString(&buf); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	case c == 't':
+		return true, t.literal("true")
+	case c == 'f':
+		return false, t.literal("false")
+	case c == 'n':
+		return nil, t.literal("null")
+	case c == '-' || c >= '0' && c <= '9':
+		var buf bytes.Buffer
+		for {
+			b, err := t.r.Peek(1)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			if c := b[0]; c != '-' && c != '+' && c != '.' && c != 'e' && c != 'E' && (c < '0' || c > '9') {
+				break
+			}
+			c, _ := t.r.ReadByte()
+			buf.WriteByte(c)
+		}
+		n := json.Number(buf.String())
+		if _, err := n.Float64(); err != nil {
+			return nil, fmt.Errorf("invalid number %q in JSON input", n)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("invalid character %q in JSON input", c)
+}
+
+// literal consumes literal s.
+func (t *tokenizer) literal(s string) error {
+	b := make([]byte, len(s))
+	if _, err := io.ReadFull(t.r, b); err != nil || string(b) != s {
+		return fmt.Errorf("invalid literal in JSON input, want %s", s)
+	}
+	return nil
+}
+
+// readString writes the unescaped contents of a JSON string, whose
+// opening quote has already been read, to w.
+func (t *tokenizer) readString(w io.ByteWriter) error {
+	var rb [utf8.UTFMax]byte
+	for {
+		c, err := t.r.ReadByte()
+		if err != nil {
+			return errors.New("unexpected end of JSON string")
+		}
+		switch {
+		case c == '"':
+			return nil
+		case c < 0x20:
+			return fmt.Errorf("invalid control character %q in JSON string", c)
+		case c != '\\':
+			if err := w.WriteByte(c); err != nil {
+				return err
+			}
+			continue
+		}
+		c, err = t.r.ReadByte()
+		if err != nil {
+			return errors.New("unexpected end of JSON string")
+		}
+		var r rune
+		switch c {
+		case '"', '\\', '/':
+			r = rune(c)
+		case 'b':
+			r = '\b'
+		case 'f':
+			r = '\f'
+		case 'n':
+			r = '\n'
+		case 'r':
+			r = '\r'
+		case 't':
+			r = '\t'
+		case 'u':
+			if r, err = t.hex4(); err != nil {
+				return err
+			}
+			if utf16.IsSurrogate(r) {
+				r1 := r
+				r = utf8.RuneError
+				if b, err := t.r.Peek(2); err == nil && string(b) == `\u` {
+					t.r.Discard(2)
+					r2, err := t.hex4()
+					if err != nil {
+						return err
+					}
+					r = utf16.DecodeRune(r1, r2)
+				}
+			}
+		default:
+			return fmt.Errorf("invalid escape %q in JSON string", c)
+		}
+		n := utf8.EncodeRune(rb[:], r)
+		for _, b := range rb[:n] {
+			if err := w.WriteByte(b); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// hex4 reads the 4 hexadecimal digits of a \u escape.
+func (t *tokenizer) hex4() (rune, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(t.r, b[:]); err != nil {
+		return 0, errors.New("unexpected end of JSON string")
+	}
+	n, err := strconv.ParseUint(string(b[:]), 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escape \\u%s in JSON string", b[:])
+	}
+	return rune(n), nil
+}
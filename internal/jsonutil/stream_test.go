@@ -0,0 +1,90 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// streamField is a query data structure field that string values are streamed to.
+type streamField struct {
+	bytes.Buffer
+}
+
+func (f *streamField) StreamWriter() (io.WriteCloser, error) {
+	return nopCloser{&f.Buffer}, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestDecodeResponse(t *testing.T) {
+	type query struct {
+		Viewer struct {
+			Login  string
+			Age    int
+			Admin  *bool
+			Tags   []string
+			Bio    streamField
+			Avatar *streamField
+		}
+	}
+	var got query
+	errs, extensions, err := jsonutil.DecodeResponse(strings.NewReader(`{
+		"data": {"viewer": {
+			"login": "go\"pheré😀",
+			"age": 13,
+			"admin": null,
+			"tags": ["a", "b"],
+			"bio": "line 1\nline 2\t☃\/",
+			"avatar": null
+		}},
+		"errors": [{"message": "partial", "path": ["viewer", 0]}],
+		"unknown": {"nested": [1, {"x": true}]},
+		"extensions": {"cost": 1.5}
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want query
+	want.Viewer.Login = "go\"pheré😀"
+	want.Viewer.Age = 13
+	want.Viewer.Tags = []string{"a", "b"}
+	want.Viewer.Bio.WriteString("line 1\nline 2\t☃/")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\ngot:  %+v\nwant: %+v", got, want)
+	}
+	if got, want := string(errs), `[{"message":"partial","path":["viewer",0]}]`; got != want {
+		t.Errorf("got errors: %v, want: %v", got, want)
+	}
+	if got, want := string(extensions), `{"cost":1.5}`; got != want {
+		t.Errorf("got extensions: %v, want: %v", got, want)
+	}
+}
+
+func TestDecodeResponse_error(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`[]`,
+		`{"data": {"viewer": {"login": "unterminated`,
+		`{"data": {"viewer": {"login": "\x"}}}`,
+		`{"data": {"viewer": {"age": 1.2.3}}}`,
+		`{"data": {"viewer": {"admin": nul}}}`,
+	} {
+		var q struct {
+			Viewer struct {
+				Login string
+				Age   float64
+				Admin *bool
+			}
+		}
+		if _, _, err := jsonutil.DecodeResponse(strings.NewReader(s), &q); err == nil {
+			t.Errorf("decoding %q: got error: nil, want: non-nil", s)
+		}
+	}
+}
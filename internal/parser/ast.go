@@ -0,0 +1,443 @@
+// Package parser provides a parser for GraphQL executable documents and
+// schema definition language (SDL) documents, and validation of the former
+// against the latter.
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a location in a GraphQL document.
+type Pos struct {
+	Offset int // Byte offset, starting at 0.
+	Line   int // Line number, starting at 1.
+	Column int // Column number in bytes, starting at 1.
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Error is a syntax or validation error in a GraphQL document.
+type Error struct {
+	Message   string
+	Locations []Pos
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	return e.Locations[0].String() + ": " + e.Message
+}
+
+// Document is a GraphQL executable document.
+type Document struct {
+	Operations []*Operation
+	Fragments  []*Fragment
+}
+
+// Fragment returns the fragment definition named name, or nil if none.
+func (d *Document) Fragment(name string) *Fragment {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Operation returns the operation named name, or nil if none.
+// If name is empty and the document has a single operation, it is returned.
+func (d *Document) Operation(name string) *Operation {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0]
+		}
+		return nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// Operation is an operation definition.
+type Operation struct {
+	Type         string // "query", "mutation" or "subscription".
+	Name         string
+	VarDefs      []*VarDef
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Pos
+
+	VarDefsStart, VarDefsEnd int // Byte offsets of the parenthesized variable definitions in the document, if any.
+}
+
+// VarDef is a variable definition.
+type VarDef struct {
+	Name       string
+	Type       *Type
+	Default    *Value
+	Directives []*Directive
+	Pos        Pos
+
+	Start, End         int // Byte offsets of the definition in the document.
+	TypeStart, TypeEnd int // Byte offsets of Type in the document.
+}
+
+// Fragment is a fragment definition.
+type Fragment struct {
+	Name         string
+	TypeCond     string
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Pos
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	selection()
+}
+
+// Field is a field selection.
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Pos
+	End          int // Byte offset just past the field in the document.
+}
+
+// ResponseKey returns the alias of f if set, otherwise its name.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread is a named fragment spread.
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Pos        Pos
+}
+
+// InlineFragment is an inline fragment, with an optional type condition.
+type InlineFragment struct {
+	TypeCond     string
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Pos
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// Argument is an argument of a field or directive.
+type Argument struct {
+	Name  string
+	Value *Value
+	Pos   Pos
+}
+
+// Directive is a directive applied to a definition or selection.
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Pos       Pos
+	End       int // Byte offset just past the directive in the document.
+}
+
+// ValueKind is the kind of a Value.
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is an input value literal.
+type Value struct {
+	Kind   ValueKind
+	Raw    string         // Variable name, number, unquoted string, boolean or enum value.
+	List   []*Value       // For ListValue.
+	Fields []*ObjectField // For ObjectValue.
+	Pos    Pos
+}
+
+// ObjectField is a field of an input object value.
+type ObjectField struct {
+	Name  string
+	Value *Value
+	Pos   Pos
+}
+
+// Type is a type reference, such as "[String!]!".
+type Type struct {
+	Name    string // Named type, or empty for a list type.
+	Elem    *Type  // Element type of a list type.
+	NonNull bool
+}
+
+func (t *Type) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// NamedType returns the name of the innermost named type of t.
+func (t *Type) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+// Schema is a GraphQL schema, built from SDL documents.
+type Schema struct {
+	Types        map[string]*TypeDef
+	TypeNames    []string // In order of definition.
+	Directives   map[string]*DirectiveDef
+	Query        string // Root operation type names.
+	Mutation     string
+	Subscription string
+}
+
+// RootType returns the root type for operation type op, or nil if none.
+func (s *Schema) RootType(op string) *TypeDef {
+	switch op {
+	case "query":
+		return s.Types[s.Query]
+	case "mutation":
+		return s.Types[s.Mutation]
+	case "subscription":
+		return s.Types[s.Subscription]
+	}
+	return nil
+}
+
+// IsPossibleType reports whether object type name is a possible
+// type of abstract or object type t.
+func (s *Schema) IsPossibleType(t *TypeDef, name string) bool {
+	switch t.Kind {
+	case ObjectKind:
+		return t.Name == name
+	case UnionKind:
+		for _, m := range t.Members {
+			if m == name {
+				return true
+			}
+		}
+	case InterfaceKind:
+		if o := s.Types[name]; o != nil {
+			for _, i := range o.Interfaces {
+				if i == t.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// TypeKind is the kind of a named type.
+type TypeKind int
+
+const (
+	ScalarKind TypeKind = iota
+	ObjectKind
+	InterfaceKind
+	UnionKind
+	EnumKind
+	InputObjectKind
+)
+
+func (k TypeKind) String() string {
+	return [...]string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT"}[k]
+}
+
+// TypeDef is a named type definition.
+type TypeDef struct {
+	Kind        TypeKind
+	Name        string
+	Description string
+	Fields      []*FieldDef      // For ObjectKind and InterfaceKind.
+	Interfaces  []string         // For ObjectKind and InterfaceKind.
+	Members     []string         // For UnionKind.
+	EnumValues  []*EnumValueDef  // For EnumKind.
+	InputFields []*InputValueDef // For InputObjectKind.
+	Directives  []*Directive
+	Builtin     bool // Whether type is a built-in scalar or introspection type.
+	Pos         Pos
+}
+
+// Field returns the field named name, or nil if none.
+func (t *TypeDef) Field(name string) *FieldDef {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField returns the input field named name, or nil if none.
+func (t *TypeDef) InputField(name string) *InputValueDef {
+	for _, f := range t.InputFields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// EnumValue returns the enum value named name, or nil if none.
+func (t *TypeDef) EnumValue(name string) *EnumValueDef {
+	for _, v := range t.EnumValues {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// IsLeaf reports whether t is a scalar or enum type.
+func (t *TypeDef) IsLeaf() bool {
+	return t.Kind == ScalarKind || t.Kind == EnumKind
+}
+
+// IsInput reports whether t can be used as an input type.
+func (t *TypeDef) IsInput() bool {
+	return t.Kind == ScalarKind || t.Kind == EnumKind || t.Kind == InputObjectKind
+}
+
+// FieldDef is a field definition of an object or interface type.
+type FieldDef struct {
+	Name        string
+	Description string
+	Arguments   []*InputValueDef
+	Type        *Type
+	Directives  []*Directive
+	Deprecation
+	Pos Pos
+}
+
+// Argument returns the argument named name, or nil if none.
+func (f *FieldDef) Argument(name string) *InputValueDef {
+	for _, a := range f.Arguments {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// InputValueDef is an argument or input field definition.
+type InputValueDef struct {
+	Name        string
+	Description string
+	Type        *Type
+	Default     *Value
+	Directives  []*Directive
+	Deprecation
+	Pos Pos
+}
+
+// EnumValueDef is an enum value definition.
+type EnumValueDef struct {
+	Name        string
+	Description string
+	Directives  []*Directive
+	Deprecation
+	Pos Pos
+}
+
+// Deprecation is the state of the @deprecated directive on a definition.
+type Deprecation struct {
+	Deprecated        bool
+	DeprecationReason string
+}
+
+// DirectiveDef is a directive definition.
+type DirectiveDef struct {
+	Name        string
+	Description string
+	Arguments   []*InputValueDef
+	Locations   []string
+	Repeatable  bool
+	Builtin     bool
+	Pos         Pos
+}
+
+// String returns a compact GraphQL representation of v.
+func (v *Value) String() string {
+	switch v.Kind {
+	case VariableValue:
+		return "$" + v.Raw
+	case StringValue:
+		return quote(v.Raw)
+	case ListValue:
+		var ss []string
+		for _, e := range v.List {
+			ss = append(ss, e.String())
+		}
+		return "[" + strings.Join(ss, ",") + "]"
+	case ObjectValue:
+		var ss []string
+		for _, f := range v.Fields {
+			ss = append(ss, f.Name+":"+f.Value.String())
+		}
+		return "{" + strings.Join(ss, ",") + "}"
+	default:
+		return v.Raw
+	}
+}
+
+// quote returns s as a GraphQL string literal.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
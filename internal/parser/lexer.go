@@ -0,0 +1,290 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenBlockString
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "EOF"
+	case tokenPunct:
+		return "punctuator"
+	case tokenName:
+		return "name"
+	case tokenInt:
+		return "int"
+	case tokenFloat:
+		return "float"
+	case tokenString, tokenBlockString:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// token is a lexical token of a GraphQL document.
+type token struct {
+	kind  tokenKind
+	value string // Punctuator, name, number, or unquoted string value.
+	pos   Pos
+	end   int // Byte offset just past the token.
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "EOF"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+// lexer splits a GraphQL document into tokens.
+// Whitespace, commas and comments are insignificant and skipped.
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int // Byte offset of the start of the current line.
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Offset: l.off, Line: l.line, Column: l.off - l.col + 1}
+}
+
+func (l *lexer) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...), Locations: []Pos{pos}}
+}
+
+// next returns the next significant token.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := l.pos()
+	if l.off >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos, end: l.off}, nil
+	}
+	c := l.src[l.off]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) != -1:
+		l.off++
+		return token{kind: tokenPunct, value: string(c), pos: pos, end: l.off}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.off:], "...") {
+			l.off += 3
+			return token{kind: tokenPunct, value: "...", pos: pos, end: l.off}, nil
+		}
+		return token{}, l.errorf(pos, "unexpected character '.'")
+	case c == '_' || isLetter(c):
+		start := l.off
+		for l.off < len(l.src) && (l.src[l.off] == '_' || isLetter(l.src[l.off]) || isDigit(l.src[l.off])) {
+			l.off++
+		}
+		return token{kind: tokenName, value: l.src[start:l.off], pos: pos, end: l.off}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+// skipIgnored skips whitespace, line terminators, commas, comments and BOM.
+func (l *lexer) skipIgnored() {
+	for l.off < len(l.src) {
+		switch c := l.src[l.off]; {
+		case c == ' ' || c == '\t' || c == ',' || c == '\r':
+			l.off++
+		case c == '\n':
+			l.off++
+			l.line++
+			l.col = l.off
+		case c == '#':
+			for l.off < len(l.src) && l.src[l.off] != '\n' {
+				l.off++
+			}
+		case strings.HasPrefix(l.src[l.off:], "\ufeff"):
+			l.off += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number(pos Pos) (token, error) {
+	start := l.off
+	kind := tokenInt
+	if l.src[l.off] == '-' {
+		l.off++
+	}
+	if !l.digits() {
+		return token{}, l.errorf(pos, "invalid number %q", l.src[start:l.off])
+	}
+	if l.off < len(l.src) && l.src[l.off] == '.' {
+		kind = tokenFloat
+		l.off++
+		if !l.digits() {
+			return token{}, l.errorf(pos, "invalid number %q", l.src[start:l.off])
+		}
+	}
+	if l.off < len(l.src) && (l.src[l.off] == 'e' || l.src[l.off] == 'E') {
+		kind = tokenFloat
+		l.off++
+		if l.off < len(l.src) && (l.src[l.off] == '+' || l.src[l.off] == '-') {
+			l.off++
+		}
+		if !l.digits() {
+			return token{}, l.errorf(pos, "invalid number %q", l.src[start:l.off])
+		}
+	}
+	return token{kind: kind, value: l.src[start:l.off], pos: pos, end: l.off}, nil
+}
+
+// digits consumes a run of digits, reporting whether there was at least one.
+func (l *lexer) digits() bool {
+	start := l.off
+	for l.off < len(l.src) && isDigit(l.src[l.off]) {
+		l.off++
+	}
+	return l.off > start
+}
+
+func (l *lexer) string(pos Pos) (token, error) {
+	l.off++ // Opening quote.
+	var b strings.Builder
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == '"':
+			l.off++
+			return token{kind: tokenString, value: b.String(), pos: pos, end: l.off}, nil
+		case c == '\n':
+			return token{}, l.errorf(pos, "unterminated string")
+		case c == '\\':
+			if l.off+1 >= len(l.src) {
+				return token{}, l.errorf(pos, "unterminated string")
+			}
+			esc := l.src[l.off+1]
+			l.off += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.off+4 > len(l.src) {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				var r rune
+				for _, h := range l.src[l.off : l.off+4] {
+					r <<= 4
+					switch {
+					case h >= '0' && h <= '9':
+						r |= h - '0'
+					case h >= 'a' && h <= 'f':
+						r |= h - 'a' + 10
+					case h >= 'A' && h <= 'F':
+						r |= h - 'A' + 10
+					default:
+						return token{}, l.errorf(pos, "invalid unicode escape")
+					}
+				}
+				l.off += 4
+				b.WriteRune(r)
+			default:
+				return token{}, l.errorf(pos, "invalid escape sequence \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+			l.off++
+		}
+	}
+	return token{}, l.errorf(pos, "unterminated string")
+}
+
+func (l *lexer) blockString(pos Pos) (token, error) {
+	l.off += 3 // Opening quotes.
+	var b strings.Builder
+	for l.off < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.off:], `"""`):
+			l.off += 3
+			return token{kind: tokenBlockString, value: blockStringValue(b.String()), pos: pos, end: l.off}, nil
+		case strings.HasPrefix(l.src[l.off:], `\"""`):
+			b.WriteString(`"""`)
+			l.off += 4
+		default:
+			if l.src[l.off] == '\n' {
+				l.line++
+				l.col = l.off + 1
+			}
+			b.WriteByte(l.src[l.off])
+			l.off++
+		}
+	}
+	return token{}, l.errorf(pos, "unterminated block string")
+}
+
+// blockStringValue removes common indentation and leading and trailing
+// blank lines from raw, as specified for block strings.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(raw, "\r\n", "\n", -1), "\n")
+	common := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == len(line) {
+			continue
+		}
+		if common == -1 || indent < common {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= common {
+				lines[i] = lines[i][common:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
@@ -0,0 +1,294 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// parser is a recursive descent parser of GraphQL documents.
+type parser struct {
+	lex  *lexer
+	tok  token // Current token.
+	prev int   // Byte offset just past the previous token.
+	err  error // First error encountered.
+}
+
+func newParser(src string) *parser {
+	p := &parser{lex: newLexer(src)}
+	p.advance()
+	return p
+}
+
+// advance reads the next token into p.tok.
+func (p *parser) advance() {
+	if p.err != nil {
+		return
+	}
+	p.prev = p.tok.end
+	tok, err := p.lex.next()
+	if err != nil {
+		p.err = err
+		p.tok = token{kind: tokenEOF}
+		return
+	}
+	p.tok = tok
+}
+
+func (p *parser) errorf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	p.err = &Error{Message: fmt.Sprintf(format, args...), Locations: []Pos{p.tok.pos}}
+	p.tok = token{kind: tokenEOF}
+}
+
+// peek reports whether the current token is punctuator or keyword s.
+func (p *parser) peek(s string) bool {
+	return (p.tok.kind == tokenPunct || p.tok.kind == tokenName) && p.tok.value == s
+}
+
+// skip consumes the current token if it is punctuator or keyword s.
+func (p *parser) skip(s string) bool {
+	if p.peek(s) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+// expect consumes punctuator or keyword s, or records an error.
+func (p *parser) expect(s string) {
+	if !p.skip(s) {
+		p.errorf("expected %q, found %v", s, p.tok)
+	}
+}
+
+// name consumes a name token and returns it.
+func (p *parser) name() string {
+	if p.tok.kind != tokenName {
+		p.errorf("expected name, found %v", p.tok)
+		return ""
+	}
+	s := p.tok.value
+	p.advance()
+	return s
+}
+
+// ParseDocument parses a GraphQL executable document.
+func ParseDocument(src string) (*Document, error) {
+	p := newParser(src)
+	doc := new(Document)
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"), p.peek("query"), p.peek("mutation"), p.peek("subscription"):
+			doc.Operations = append(doc.Operations, p.operation())
+		case p.peek("fragment"):
+			doc.Fragments = append(doc.Fragments, p.fragment())
+		default:
+			p.errorf("unexpected %v", p.tok)
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(doc.Operations) == 0 && len(doc.Fragments) == 0 {
+		return nil, &Error{Message: "document contains no definitions"}
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() *Operation {
+	op := &Operation{Type: "query", Pos: p.tok.pos}
+	if p.peek("{") {
+		op.SelectionSet = p.selectionSet()
+		return op
+	}
+	op.Type = p.name()
+	if p.tok.kind == tokenName {
+		op.Name = p.name()
+	}
+	if p.peek("(") {
+		op.VarDefsStart = p.tok.pos.Offset
+		p.advance()
+		for !p.skip(")") && p.err == nil {
+			op.VarDefs = append(op.VarDefs, p.varDef())
+		}
+		op.VarDefsEnd = p.prev
+	}
+	op.Directives = p.directives(false)
+	op.SelectionSet = p.selectionSet()
+	return op
+}
+
+func (p *parser) varDef() *VarDef {
+	v := &VarDef{Pos: p.tok.pos}
+	p.expect("$")
+	v.Name = p.name()
+	p.expect(":")
+	v.TypeStart = p.tok.pos.Offset
+	v.Type = p.typeRef()
+	v.TypeEnd = p.prev
+	if p.skip("=") {
+		v.Default = p.value(true)
+	}
+	v.Directives = p.directives(true)
+	v.Start, v.End = v.Pos.Offset, p.prev
+	return v
+}
+
+func (p *parser) fragment() *Fragment {
+	f := &Fragment{Pos: p.tok.pos}
+	p.expect("fragment")
+	if p.peek("on") {
+		p.errorf("unexpected %v", p.tok)
+	}
+	f.Name = p.name()
+	p.expect("on")
+	f.TypeCond = p.name()
+	f.Directives = p.directives(false)
+	f.SelectionSet = p.selectionSet()
+	return f
+}
+
+func (p *parser) selectionSet() []Selection {
+	pos := p.tok.pos
+	p.expect("{")
+	var sels []Selection
+	for !p.skip("}") && p.err == nil {
+		sels = append(sels, p.selection())
+	}
+	if len(sels) == 0 && p.err == nil {
+		p.err = &Error{Message: "selection set must not be empty", Locations: []Pos{pos}}
+	}
+	return sels
+}
+
+func (p *parser) selection() Selection {
+	pos := p.tok.pos
+	if p.skip("...") {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			return &FragmentSpread{Name: p.name(), Directives: p.directives(false), Pos: pos}
+		}
+		f := &InlineFragment{Pos: pos}
+		if p.skip("on") {
+			f.TypeCond = p.name()
+		}
+		f.Directives = p.directives(false)
+		f.SelectionSet = p.selectionSet()
+		return f
+	}
+	f := &Field{Pos: pos}
+	f.Name = p.name()
+	if p.skip(":") {
+		f.Alias = f.Name
+		f.Name = p.name()
+	}
+	f.Arguments = p.arguments(false)
+	f.Directives = p.directives(false)
+	if p.peek("{") {
+		f.SelectionSet = p.selectionSet()
+	}
+	f.End = p.prev
+	return f
+}
+
+func (p *parser) arguments(constant bool) []*Argument {
+	if !p.skip("(") {
+		return nil
+	}
+	var args []*Argument
+	for !p.skip(")") && p.err == nil {
+		a := &Argument{Pos: p.tok.pos}
+		a.Name = p.name()
+		p.expect(":")
+		a.Value = p.value(constant)
+		args = append(args, a)
+	}
+	return args
+}
+
+func (p *parser) directives(constant bool) []*Directive {
+	var ds []*Directive
+	for p.peek("@") && p.err == nil {
+		d := &Directive{Pos: p.tok.pos}
+		p.advance()
+		d.Name = p.name()
+		d.Arguments = p.arguments(constant)
+		d.End = p.prev
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+func (p *parser) value(constant bool) *Value {
+	v := &Value{Pos: p.tok.pos, Raw: p.tok.value}
+	switch p.tok.kind {
+	case tokenPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				p.errorf("unexpected variable in constant value")
+				return v
+			}
+			p.advance()
+			v.Kind = VariableValue
+			v.Raw = p.name()
+			return v
+		case "[":
+			p.advance()
+			v.Kind = ListValue
+			v.Raw = ""
+			for !p.skip("]") && p.err == nil {
+				v.List = append(v.List, p.value(constant))
+			}
+			return v
+		case "{":
+			p.advance()
+			v.Kind = ObjectValue
+			v.Raw = ""
+			for !p.skip("}") && p.err == nil {
+				f := &ObjectField{Pos: p.tok.pos}
+				f.Name = p.name()
+				p.expect(":")
+				f.Value = p.value(constant)
+				v.Fields = append(v.Fields, f)
+			}
+			return v
+		}
+		p.errorf("expected value, found %v", p.tok)
+		return v
+	case tokenInt:
+		v.Kind = IntValue
+	case tokenFloat:
+		v.Kind = FloatValue
+	case tokenString, tokenBlockString:
+		v.Kind = StringValue
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+	default:
+		p.errorf("expected value, found %v", p.tok)
+		return v
+	}
+	p.advance()
+	return v
+}
+
+func (p *parser) typeRef() *Type {
+	var t *Type
+	if p.skip("[") {
+		t = &Type{Elem: p.typeRef()}
+		p.expect("]")
+	} else {
+		t = &Type{Name: p.name()}
+	}
+	if p.skip("!") {
+		t.NonNull = true
+	}
+	return t
+}
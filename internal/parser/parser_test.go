@@ -0,0 +1,308 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+const starWarsSDL = `
+"The query root."
+type Query {
+	hero(episode: Episode): Character
+	human(id: ID!): Human
+	search(text: String!, first: Int = 10): [SearchResult!]!
+}
+
+type Mutation {
+	createReview(episode: Episode!, review: ReviewInput!): Review
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+interface Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+}
+
+type Human implements Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+	height(unit: LengthUnit = METER): Float
+	mass: Float @deprecated(reason: "Use weight.")
+}
+
+type Droid implements Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+	primaryFunction: String
+}
+
+type Starship {
+	name: String!
+}
+
+union SearchResult = Human | Droid | Starship
+
+enum LengthUnit { METER FOOT }
+
+input ReviewInput {
+	stars: Int!
+	commentary: String
+}
+
+type Review {
+	stars: Int!
+	commentary: String
+}
+`
+
+func TestParseSchema(t *testing.T) {
+	s, err := parser.ParseSchema(starWarsSDL, `extend type Starship { length: Float }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Query, "Query"; got != want {
+		t.Errorf("got Query: %q, want: %q", got, want)
+	}
+	if got, want := s.Mutation, "Mutation"; got != want {
+		t.Errorf("got Mutation: %q, want: %q", got, want)
+	}
+	if got, want := s.Types["Query"].Description, "The query root."; got != want {
+		t.Errorf("got description: %q, want: %q", got, want)
+	}
+	if f := s.Types["Starship"].Field("length"); f == nil {
+		t.Error("extension field Starship.length not found")
+	}
+	mass := s.Types["Human"].Field("mass")
+	if !mass.Deprecated || mass.DeprecationReason != "Use weight." {
+		t.Errorf("got mass deprecation: %+v", mass.Deprecation)
+	}
+	if got, want := s.Types["Query"].Field("search").Type.String(), "[SearchResult!]!"; got != want {
+		t.Errorf("got type: %q, want: %q", got, want)
+	}
+	if !s.IsPossibleType(s.Types["Character"], "Droid") {
+		t.Error("Droid is not a possible type of Character")
+	}
+	if !s.IsPossibleType(s.Types["SearchResult"], "Starship") {
+		t.Error("Starship is not a possible type of SearchResult")
+	}
+}
+
+func TestParseSchema_error(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`type Query { a: Foo }`, `1:14: undefined type "Foo"`},
+		{`type Query { a: Int } type Query { b: Int }`, `1:23: type "Query" is defined more than once`},
+		{`type Foo { a: Int }`, `schema has no query root type`},
+		{`type Query { a: Int`, `1:20: expected name, found EOF`},
+	}
+	for _, tc := range tests {
+		_, err := parser.ParseSchema(tc.in)
+		if err == nil {
+			t.Errorf("%s: got error: nil, want: %v", tc.in, tc.want)
+			continue
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("%s: got error: %v, want: %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	src := `
+		# A comment.
+		query Hero($ep: Episode = JEDI, $withFriends: Boolean!) {
+			hero(episode: $ep) {
+				name
+				friends @include(if: $withFriends) { name }
+				... on Human { height(unit: FOOT) }
+				...droid
+			}
+			s: search(text: "r2\n\u0064", first: -1) { __typename }
+		}
+		fragment droid on Droid { primaryFunction }
+	`
+	doc, err := parser.ParseDocument(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Operation("")
+	if op == nil || op.Name != "Hero" || op.Type != "query" {
+		t.Fatalf("got operation: %+v", op)
+	}
+	if got, want := len(op.VarDefs), 2; got != want {
+		t.Fatalf("got %d variable definitions, want %d", got, want)
+	}
+	if got, want := op.VarDefs[0].Default.String(), "JEDI"; got != want {
+		t.Errorf("got default: %q, want: %q", got, want)
+	}
+	if got, want := op.VarDefs[1].Type.String(), "Boolean!"; got != want {
+		t.Errorf("got type: %q, want: %q", got, want)
+	}
+	if got, want := src[op.VarDefs[0].Start:op.VarDefs[0].End], "$ep: Episode = JEDI"; got != want {
+		t.Errorf("got variable definition: %q, want: %q", got, want)
+	}
+	if got, want := src[op.VarDefsStart:op.VarDefsEnd], "($ep: Episode = JEDI, $withFriends: Boolean!)"; got != want {
+		t.Errorf("got variable definitions: %q, want: %q", got, want)
+	}
+	friends := op.SelectionSet[0].(*parser.Field).SelectionSet[1].(*parser.Field)
+	if d := friends.Directives[0]; src[d.Pos.Offset:d.End] != "@include(if: $withFriends)" {
+		t.Errorf("got directive: %q", src[d.Pos.Offset:d.End])
+	}
+	hero := op.SelectionSet[0].(*parser.Field)
+	if got, want := len(hero.SelectionSet), 4; got != want {
+		t.Fatalf("got %d selections, want %d", got, want)
+	}
+	if _, ok := hero.SelectionSet[3].(*parser.FragmentSpread); !ok {
+		t.Errorf("got %T, want *parser.FragmentSpread", hero.SelectionSet[3])
+	}
+	search := op.SelectionSet[1].(*parser.Field)
+	if got, want := search.ResponseKey(), "s"; got != want {
+		t.Errorf("got response key: %q, want: %q", got, want)
+	}
+	if got, want := search.Arguments[0].Value.Raw, "r2\nd"; got != want {
+		t.Errorf("got string value: %q, want: %q", got, want)
+	}
+	if got, want := search.Arguments[1].Value.Kind, parser.IntValue; got != want {
+		t.Errorf("got value kind: %v, want: %v", got, want)
+	}
+	if f := doc.Fragment("droid"); f == nil || f.TypeCond != "Droid" {
+		t.Errorf("got fragment: %+v", f)
+	}
+}
+
+func TestParseDocument_error(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{ hero { name }`, `1:16: expected name, found EOF`},
+		{`{ hero(episode: ) }`, `1:17: expected value, found ")"`},
+		{`query { }`, `1:7: selection set must not be empty`},
+		{`{ a(s: "unterminated) }`, `1:8: unterminated string`},
+		{``, `document contains no definitions`},
+	}
+	for _, tc := range tests {
+		_, err := parser.ParseDocument(tc.in)
+		if err == nil {
+			t.Errorf("%s: got error: nil, want: %v", tc.in, tc.want)
+			continue
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("%s: got error: %v, want: %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := parser.ParseSchema(starWarsSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{
+			in: `query($ep: Episode) { hero(episode: $ep) { name ... on Droid { primaryFunction } } }`,
+		},
+		{
+			in: `{ search(text: "x") { ... on Human { name } ... on Starship { name } } __typename }`,
+		},
+		{
+			in: `mutation($review: ReviewInput!) { createReview(episode: JEDI, review: $review) { stars } }`,
+		},
+		{
+			in: `{ __schema { types { name } } __type(name: "Human") { fields { name } } }`,
+		},
+		{
+			in:   `{ hero { name nickname } }`,
+			want: []string{`1:15: cannot query field "nickname" on type "Character"`},
+		},
+		{
+			in:   `{ hero(episode: MOVIE, first: 1) { name { first } } }`,
+			want: []string{`1:17: expected value of type "Episode", found MOVIE`, `1:24: unknown argument "first" on field "Query.hero"`, `1:36: field "name" must not have a selection since type "String!" has no subfields`},
+		},
+		{
+			in:   `{ human { id } hero }`,
+			want: []string{`1:3: field "Query.human" argument "id" of type "ID!" is required, but it was not provided`, `1:16: field "hero" of type "Character" must have a selection of subfields`},
+		},
+		{
+			in:   `query($id: ID!, $unused: Int) { human(id: $id) { height(unit: $unit) } }`,
+			want: []string{`1:17: variable "$unused" is never used`, `1:63: variable "$unit" is not defined`},
+		},
+		{
+			in:   `query($id: String!) { human(id: $id) { name } }`,
+			want: []string{`1:33: variable "$id" of type "String!" used in position expecting type "ID!"`},
+		},
+		{
+			in:   `{ hero { ...f ... on Starship { name } } } fragment f on Human { name } fragment g on Droid { name }`,
+			want: []string{`1:15: fragment on "Starship" can never be spread within type "Character"`, `1:73: fragment "g" is never used`},
+		},
+		{
+			in:   `{ hero @cached { name } }`,
+			want: []string{`1:8: unknown directive "@cached"`},
+		},
+		{
+			in:   `mutation { createReview(episode: JEDI, review: {commentary: "ok", rating: 1}) { stars } }`,
+			want: []string{`1:48: field "ReviewInput.stars" of required type "Int!" was not provided`, `1:67: field "rating" is not defined by type "ReviewInput"`},
+		},
+	}
+	for _, tc := range tests {
+		doc, err := parser.ParseDocument(tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		var got []string
+		for _, err := range parser.Validate(s, doc) {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s:\ngot errors:\n%s\nwant:\n%s", tc.in, strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+		}
+	}
+}
+
+func TestCheck_deprecated(t *testing.T) {
+	s, err := parser.ParseSchema(`
+		type Query {
+			human(id: ID!, legacyID: Int @deprecated): Human
+		}
+		type Human {
+			mass: Float @deprecated(reason: "Use weight.")
+			height(unit: LengthUnit): Float
+		}
+		enum LengthUnit { METER FOOT @deprecated(reason: "Use METER.") }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parser.ParseDocument(`{ human(id: 1, legacyID: 1) { mass height(unit: FOOT) } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, warnings := parser.Check(s, doc)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	want := []string{
+		`1:16: argument "legacyID" on field "Query.human" is deprecated: No longer supported`,
+		`1:31: field "Human.mass" is deprecated: Use weight.`,
+		`1:49: enum value "LengthUnit.FOOT" is deprecated: Use METER.`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// SDL returns the schema definition language document of s,
+// without built-in definitions, in order of definition.
+func (s *Schema) SDL() string {
+	var defs []string
+	if schema := s.schemaDef(); schema != "" {
+		defs = append(defs, schema)
+	}
+	for _, name := range s.directiveNames() {
+		defs = append(defs, s.Directives[name].SDL())
+	}
+	for _, name := range s.TypeNames {
+		if t := s.Types[name]; !t.Builtin {
+			defs = append(defs, t.SDL())
+		}
+	}
+	return strings.Join(defs, "\n\n") + "\n"
+}
+
+// schemaDef returns the schema definition of s, or "" if its root
+// operation types have their default names.
+func (s *Schema) schemaDef() string {
+	if s.Query == "Query" && (s.Mutation == "" || s.Mutation == "Mutation") &&
+		(s.Subscription == "" || s.Subscription == "Subscription") {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("schema {\n")
+	for _, root := range [...]struct{ op, name string }{
+		{"query", s.Query}, {"mutation", s.Mutation}, {"subscription", s.Subscription},
+	} {
+		if root.name != "" {
+			b.WriteString("  " + root.op + ": " + root.name + "\n")
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// directiveNames returns the names of the directives of s that aren't
+// built in, sorted.
+func (s *Schema) directiveNames() []string {
+	var names []string
+	for name, d := range s.Directives {
+		if !d.Builtin {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SDL returns the schema definition language definition of t.
+func (t *TypeDef) SDL() string {
+	var b strings.Builder
+	writeDescription(&b, t.Description, "")
+	switch t.Kind {
+	case ScalarKind:
+		b.WriteString("scalar " + t.Name)
+		writeDirectives(&b, t.Directives)
+	case ObjectKind, InterfaceKind:
+		if t.Kind == ObjectKind {
+			b.WriteString("type " + t.Name)
+		} else {
+			b.WriteString("interface " + t.Name)
+		}
+		if len(t.Interfaces) > 0 {
+			b.WriteString(" implements " + strings.Join(t.Interfaces, " & "))
+		}
+		writeDirectives(&b, t.Directives)
+		b.WriteString(" {\n")
+		for _, f := range t.Fields {
+			b.WriteString(f.sdl("  "))
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+	case UnionKind:
+		b.WriteString("union " + t.Name)
+		writeDirectives(&b, t.Directives)
+		if len(t.Members) > 0 {
+			b.WriteString(" = " + strings.Join(t.Members, " | "))
+		}
+	case EnumKind:
+		b.WriteString("enum " + t.Name)
+		writeDirectives(&b, t.Directives)
+		b.WriteString(" {\n")
+		for _, v := range t.EnumValues {
+			writeDescription(&b, v.Description, "  ")
+			b.WriteString("  " + v.Name)
+			writeDeprecation(&b, v.Deprecation)
+			writeDirectives(&b, v.Directives)
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+	case InputObjectKind:
+		b.WriteString("input " + t.Name)
+		writeDirectives(&b, t.Directives)
+		b.WriteString(" {\n")
+		for _, f := range t.InputFields {
+			b.WriteString(f.sdl("  "))
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+	}
+	return b.String()
+}
+
+// SDL returns the schema definition language definition of f,
+// as it appears in the definition of its type, without indentation.
+func (f *FieldDef) SDL() string {
+	return f.sdl("")
+}
+
+func (f *FieldDef) sdl(indent string) string {
+	var b strings.Builder
+	writeDescription(&b, f.Description, indent)
+	b.WriteString(indent + f.Name)
+	writeArguments(&b, f.Arguments, indent)
+	b.WriteString(": " + f.Type.String())
+	writeDeprecation(&b, f.Deprecation)
+	writeDirectives(&b, f.Directives)
+	return b.String()
+}
+
+// SDL returns the schema definition language definition of v,
+// as it appears in the definition of its type, without indentation.
+func (v *InputValueDef) SDL() string {
+	return v.sdl("")
+}
+
+func (v *InputValueDef) sdl(indent string) string {
+	var b strings.Builder
+	writeDescription(&b, v.Description, indent)
+	b.WriteString(indent + v.Name + ": " + v.Type.String())
+	if v.Default != nil {
+		b.WriteString(" = " + v.Default.String())
+	}
+	writeDeprecation(&b, v.Deprecation)
+	writeDirectives(&b, v.Directives)
+	return b.String()
+}
+
+// SDL returns the schema definition language definition of d.
+func (d *DirectiveDef) SDL() string {
+	var b strings.Builder
+	writeDescription(&b, d.Description, "")
+	b.WriteString("directive @" + d.Name)
+	writeArguments(&b, d.Arguments, "")
+	if d.Repeatable {
+		b.WriteString(" repeatable")
+	}
+	b.WriteString(" on " + strings.Join(d.Locations, " | "))
+	return b.String()
+}
+
+// writeArguments writes argument definitions args to b, on one line,
+// unless any has a description.
+func writeArguments(b *strings.Builder, args []*InputValueDef, indent string) {
+	if len(args) == 0 {
+		return
+	}
+	multiline := false
+	for _, a := range args {
+		if a.Description != "" {
+			multiline = true
+		}
+	}
+	if !multiline {
+		ss := make([]string, len(args))
+		for i, a := range args {
+			ss[i] = a.sdl("")
+		}
+		b.WriteString("(" + strings.Join(ss, ", ") + ")")
+		return
+	}
+	b.WriteString("(\n")
+	for _, a := range args {
+		b.WriteString(a.sdl(indent+"  ") + "\n")
+	}
+	b.WriteString(indent + ")")
+}
+
+// writeDescription writes description desc to b, as a block string
+// on its own lines, if not empty.
+func writeDescription(b *strings.Builder, desc, indent string) {
+	if desc == "" {
+		return
+	}
+	desc = strings.ReplaceAll(desc, `"""`, `\"""`)
+	if !strings.Contains(desc, "\n") {
+		b.WriteString(indent + `"""` + desc + `"""` + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(desc, "\n") {
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
+
+func writeDeprecation(b *strings.Builder, d Deprecation) {
+	if !d.Deprecated {
+		return
+	}
+	b.WriteString(" @deprecated")
+	if d.DeprecationReason != "" && d.DeprecationReason != "No longer supported" {
+		b.WriteString("(reason: " + quote(d.DeprecationReason) + ")")
+	}
+}
+
+// writeDirectives writes the applied directives ds to b, except
+// @deprecated, which is written from definitions' Deprecation.
+func writeDirectives(b *strings.Builder, ds []*Directive) {
+	for _, d := range ds {
+		if d.Name == "deprecated" {
+			continue
+		}
+		b.WriteString(" @" + d.Name)
+		if len(d.Arguments) > 0 {
+			ss := make([]string, len(d.Arguments))
+			for i, a := range d.Arguments {
+				ss[i] = a.Name + ": " + a.Value.String()
+			}
+			b.WriteString("(" + strings.Join(ss, ", ") + ")")
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// ParseSchema parses one or more GraphQL SDL documents into a schema.
+// Built-in scalars, directives and introspection types are always included.
+func ParseSchema(srcs ...string) (*Schema, error) {
+	s := NewSchema()
+	for _, src := range srcs {
+		if err := s.parse(src, false); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Complete(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSchema returns a schema containing only the built-in scalars,
+// directives and introspection types. Types can be added to it with
+// AddType, after which it must be completed with Complete.
+func NewSchema() *Schema {
+	s := &Schema{
+		Types:      make(map[string]*TypeDef),
+		Directives: make(map[string]*DirectiveDef),
+	}
+	if err := s.parse(builtinSDL, true); err != nil {
+		panic(fmt.Errorf("parser: invalid built-in SDL: %v", err))
+	}
+	return s
+}
+
+// AddType adds type definition t to s.
+func (s *Schema) AddType(t *TypeDef) error {
+	return s.addType(t, false)
+}
+
+// Complete defaults the root operation types of s to types named Query,
+// Mutation and Subscription if unset, and checks that all referenced
+// types are defined.
+func (s *Schema) Complete() error {
+	if s.Query == "" && s.Types["Query"] != nil {
+		s.Query = "Query"
+	}
+	if s.Mutation == "" && s.Types["Mutation"] != nil {
+		s.Mutation = "Mutation"
+	}
+	if s.Subscription == "" && s.Types["Subscription"] != nil {
+		s.Subscription = "Subscription"
+	}
+	if s.Query == "" {
+		return &Error{Message: "schema has no query root type"}
+	}
+	return s.check()
+}
+
+// ParseValue parses a constant GraphQL input value literal, such as
+// a default value in an introspection result.
+func ParseValue(src string) (*Value, error) {
+	p := newParser(src)
+	v := p.value(true)
+	if p.err == nil && p.tok.kind != tokenEOF {
+		p.errorf("unexpected %v", p.tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return v, nil
+}
+
+// builtinSDL defines the built-in scalars, directives and introspection types.
+const builtinSDL = `
+scalar Int
+scalar Float
+scalar String
+scalar Boolean
+scalar ID
+
+directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+directive @specifiedBy(url: String!) on SCALAR
+
+type __Schema {
+	description: String
+	types: [__Type!]!
+	queryType: __Type!
+	mutationType: __Type
+	subscriptionType: __Type
+	directives: [__Directive!]!
+}
+
+type __Type {
+	kind: __TypeKind!
+	name: String
+	description: String
+	fields(includeDeprecated: Boolean = false): [__Field!]
+	interfaces: [__Type!]
+	possibleTypes: [__Type!]
+	enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+	inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+	ofType: __Type
+	specifiedByURL: String
+}
+
+type __Field {
+	name: String!
+	description: String
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+	type: __Type!
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+type __InputValue {
+	name: String!
+	description: String
+	type: __Type!
+	defaultValue: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+type __EnumValue {
+	name: String!
+	description: String
+	isDeprecated: Boolean!
+	deprecationReason: String
+}
+
+enum __TypeKind {
+	SCALAR
+	OBJECT
+	INTERFACE
+	UNION
+	ENUM
+	INPUT_OBJECT
+	LIST
+	NON_NULL
+}
+
+type __Directive {
+	name: String!
+	description: String
+	isRepeatable: Boolean!
+	locations: [__DirectiveLocation!]!
+	args(includeDeprecated: Boolean = false): [__InputValue!]!
+}
+
+enum __DirectiveLocation {
+	QUERY
+	MUTATION
+	SUBSCRIPTION
+	FIELD
+	FRAGMENT_DEFINITION
+	FRAGMENT_SPREAD
+	INLINE_FRAGMENT
+	VARIABLE_DEFINITION
+	SCHEMA
+	SCALAR
+	OBJECT
+	FIELD_DEFINITION
+	ARGUMENT_DEFINITION
+	INTERFACE
+	UNION
+	ENUM
+	ENUM_VALUE
+	INPUT_OBJECT
+	INPUT_FIELD_DEFINITION
+}
+`
+
+// parse parses SDL document src into s.
+func (s *Schema) parse(src string, builtin bool) error {
+	p := newParser(src)
+	for p.tok.kind != tokenEOF {
+		desc := p.description()
+		pos := p.tok.pos
+		extend := p.skip("extend")
+		switch {
+		case p.skip("schema"):
+			p.directives(true)
+			p.expect("{")
+			for !p.skip("}") && p.err == nil {
+				op := p.name()
+				p.expect(":")
+				name := p.name()
+				switch op {
+				case "query":
+					s.Query = name
+				case "mutation":
+					s.Mutation = name
+				case "subscription":
+					s.Subscription = name
+				default:
+					p.errorf("unknown operation type %q", op)
+				}
+			}
+		case p.skip("directive"):
+			d := &DirectiveDef{Description: desc, Builtin: builtin, Pos: pos}
+			p.expect("@")
+			d.Name = p.name()
+			d.Arguments = p.inputValueDefs("(", ")")
+			d.Repeatable = p.skip("repeatable")
+			p.expect("on")
+			p.skip("|")
+			d.Locations = append(d.Locations, p.name())
+			for p.skip("|") {
+				d.Locations = append(d.Locations, p.name())
+			}
+			s.Directives[d.Name] = d
+		case p.peek("scalar"), p.peek("type"), p.peek("interface"), p.peek("union"), p.peek("enum"), p.peek("input"):
+			t := p.typeDef(desc, pos)
+			t.Builtin = builtin
+			if p.err != nil {
+				break
+			}
+			if err := s.addType(t, extend); err != nil {
+				return err
+			}
+		default:
+			p.errorf("unexpected %v", p.tok)
+		}
+	}
+	return p.err
+}
+
+// addType adds type t to s, or merges it into an existing
+// type definition if extend is true.
+func (s *Schema) addType(t *TypeDef, extend bool) error {
+	old, ok := s.Types[t.Name]
+	switch {
+	case !extend && ok:
+		return &Error{Message: fmt.Sprintf("type %q is defined more than once", t.Name), Locations: []Pos{t.Pos}}
+	case !extend:
+		s.Types[t.Name] = t
+		s.TypeNames = append(s.TypeNames, t.Name)
+		return nil
+	case !ok:
+		return &Error{Message: fmt.Sprintf("cannot extend undefined type %q", t.Name), Locations: []Pos{t.Pos}}
+	case old.Kind != t.Kind:
+		return &Error{Message: fmt.Sprintf("cannot extend %v %q as %v", old.Kind, t.Name, t.Kind), Locations: []Pos{t.Pos}}
+	}
+	old.Fields = append(old.Fields, t.Fields...)
+	old.Interfaces = append(old.Interfaces, t.Interfaces...)
+	old.Members = append(old.Members, t.Members...)
+	old.EnumValues = append(old.EnumValues, t.EnumValues...)
+	old.InputFields = append(old.InputFields, t.InputFields...)
+	old.Directives = append(old.Directives, t.Directives...)
+	return nil
+}
+
+// description parses an optional description string.
+func (p *parser) description() string {
+	if p.tok.kind == tokenString || p.tok.kind == tokenBlockString {
+		s := p.tok.value
+		p.advance()
+		return s
+	}
+	return ""
+}
+
+func (p *parser) typeDef(desc string, pos Pos) *TypeDef {
+	t := &TypeDef{Description: desc, Pos: pos}
+	switch kind := p.name(); kind {
+	case "scalar":
+		t.Kind = ScalarKind
+		t.Name = p.name()
+		t.Directives = p.directives(true)
+	case "type", "interface":
+		t.Kind = ObjectKind
+		if kind == "interface" {
+			t.Kind = InterfaceKind
+		}
+		t.Name = p.name()
+		if p.skip("implements") {
+			p.skip("&")
+			t.Interfaces = append(t.Interfaces, p.name())
+			for p.skip("&") {
+				t.Interfaces = append(t.Interfaces, p.name())
+			}
+		}
+		t.Directives = p.directives(true)
+		if p.skip("{") {
+			for !p.skip("}") && p.err == nil {
+				t.Fields = append(t.Fields, p.fieldDef())
+			}
+		}
+	case "union":
+		t.Kind = UnionKind
+		t.Name = p.name()
+		t.Directives = p.directives(true)
+		if p.skip("=") {
+			p.skip("|")
+			t.Members = append(t.Members, p.name())
+			for p.skip("|") {
+				t.Members = append(t.Members, p.name())
+			}
+		}
+	case "enum":
+		t.Kind = EnumKind
+		t.Name = p.name()
+		t.Directives = p.directives(true)
+		if p.skip("{") {
+			for !p.skip("}") && p.err == nil {
+				v := &EnumValueDef{Description: p.description(), Pos: p.tok.pos}
+				v.Name = p.name()
+				v.Directives = p.directives(true)
+				v.Deprecation = deprecation(v.Directives)
+				t.EnumValues = append(t.EnumValues, v)
+			}
+		}
+	case "input":
+		t.Kind = InputObjectKind
+		t.Name = p.name()
+		t.Directives = p.directives(true)
+		t.InputFields = p.inputValueDefs("{", "}")
+	}
+	return t
+}
+
+func (p *parser) fieldDef() *FieldDef {
+	f := &FieldDef{Description: p.description(), Pos: p.tok.pos}
+	f.Name = p.name()
+	f.Arguments = p.inputValueDefs("(", ")")
+	p.expect(":")
+	f.Type = p.typeRef()
+	f.Directives = p.directives(true)
+	f.Deprecation = deprecation(f.Directives)
+	return f
+}
+
+// inputValueDefs parses optional input value definitions
+// enclosed in open and close punctuators.
+func (p *parser) inputValueDefs(open, close string) []*InputValueDef {
+	if !p.skip(open) {
+		return nil
+	}
+	var defs []*InputValueDef
+	for !p.skip(close) && p.err == nil {
+		d := &InputValueDef{Description: p.description(), Pos: p.tok.pos}
+		d.Name = p.name()
+		p.expect(":")
+		d.Type = p.typeRef()
+		if p.skip("=") {
+			d.Default = p.value(true)
+		}
+		d.Directives = p.directives(true)
+		d.Deprecation = deprecation(d.Directives)
+		defs = append(defs, d)
+	}
+	return defs
+}
+
+// deprecation returns the deprecation state described by directives.
+func deprecation(directives []*Directive) Deprecation {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		reason := "No longer supported"
+		for _, a := range d.Arguments {
+			if a.Name == "reason" && a.Value.Kind == StringValue {
+				reason = a.Value.Raw
+			}
+		}
+		return Deprecation{Deprecated: true, DeprecationReason: reason}
+	}
+	return Deprecation{}
+}
+
+// check verifies that all type references in s are defined.
+func (s *Schema) check() error {
+	ref := func(name string, pos Pos) error {
+		if s.Types[name] == nil {
+			return &Error{Message: fmt.Sprintf("undefined type %q", name), Locations: []Pos{pos}}
+		}
+		return nil
+	}
+	for _, name := range []string{s.Query, s.Mutation, s.Subscription} {
+		if name != "" && s.Types[name] == nil {
+			return &Error{Message: fmt.Sprintf("undefined root type %q", name)}
+		}
+	}
+	for _, name := range s.TypeNames {
+		t := s.Types[name]
+		for _, f := range t.Fields {
+			if err := ref(f.Type.NamedType(), f.Pos); err != nil {
+				return err
+			}
+			for _, a := range f.Arguments {
+				if err := ref(a.Type.NamedType(), a.Pos); err != nil {
+					return err
+				}
+			}
+		}
+		for _, f := range t.InputFields {
+			if err := ref(f.Type.NamedType(), f.Pos); err != nil {
+				return err
+			}
+		}
+		for _, i := range t.Interfaces {
+			if err := ref(i, t.Pos); err != nil {
+				return err
+			}
+		}
+		for _, m := range t.Members {
+			if err := ref(m, t.Pos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate validates executable document doc against schema s,
+// returning all errors found, in document order.
+func Validate(s *Schema, doc *Document) []*Error {
+	errs, _ := Check(s, doc)
+	return errs
+}
+
+// Check is like Validate, but also returns warnings about
+// usages of deprecated fields, arguments and enum values.
+func Check(s *Schema, doc *Document) (errs, warnings []*Error) {
+	v := &validator{schema: s, doc: doc}
+	for _, op := range doc.Operations {
+		v.operation(op)
+	}
+	v.fragments()
+	sortByLocation(v.errs)
+	sortByLocation(v.warnings)
+	return v.errs, v.warnings
+}
+
+// VariableTypes returns the type expected at the first usage of each
+// variable defined by the operations of doc, which is assumed to be valid
+// except for the types of variable definitions.
+func VariableTypes(s *Schema, doc *Document) map[*VarDef]*Type {
+	v := &validator{schema: s, doc: doc, usages: make(map[*VarDef]*Type)}
+	for _, op := range doc.Operations {
+		v.operation(op)
+	}
+	return v.usages
+}
+
+func sortByLocation(errs []*Error) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Locations[0].Offset < errs[j].Locations[0].Offset
+	})
+}
+
+type validator struct {
+	schema   *Schema
+	doc      *Document
+	errs     []*Error
+	warnings []*Error
+
+	// State of the operation being validated.
+	varDefs  map[string]*VarDef
+	varsUsed map[string]bool
+	visiting map[string]bool   // Fragments being visited, to detect cycles.
+	usages   map[*VarDef]*Type // Types expected at first usage of variables, if non-nil.
+
+	fragmentsUsed map[string]bool
+}
+
+func (v *validator) errorf(pos Pos, format string, args ...interface{}) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Locations: []Pos{pos}})
+}
+
+func (v *validator) warnf(pos Pos, format string, args ...interface{}) {
+	v.warnings = append(v.warnings, &Error{Message: fmt.Sprintf(format, args...), Locations: []Pos{pos}})
+}
+
+func (v *validator) operation(op *Operation) {
+	if op.Name == "" && len(v.doc.Operations) > 1 {
+		v.errorf(op.Pos, "anonymous operation must be the only defined operation")
+	}
+	for _, other := range v.doc.Operations {
+		if other != op && other.Name != "" && other.Name == op.Name && other.Pos.Offset < op.Pos.Offset {
+			v.errorf(op.Pos, "there can be only one operation named %q", op.Name)
+		}
+	}
+	root := v.schema.RootType(op.Type)
+	if root == nil {
+		v.errorf(op.Pos, "schema does not support %s operations", op.Type)
+		return
+	}
+
+	v.varDefs = make(map[string]*VarDef)
+	v.varsUsed = make(map[string]bool)
+	v.visiting = make(map[string]bool)
+	for _, d := range op.VarDefs {
+		if v.varDefs[d.Name] != nil {
+			v.errorf(d.Pos, "there can be only one variable named \"$%s\"", d.Name)
+		}
+		v.varDefs[d.Name] = d
+		if t := v.schema.Types[d.Type.NamedType()]; t == nil {
+			v.errorf(d.Pos, "unknown type %q", d.Type.NamedType())
+		} else if !t.IsInput() {
+			v.errorf(d.Pos, "variable \"$%s\" cannot be non-input type %q", d.Name, d.Type)
+		}
+		if d.Default != nil {
+			v.value(d.Default, d.Type)
+		}
+	}
+	v.directives(op.Directives, op.Type)
+	v.selectionSet(op.SelectionSet, root)
+	for _, d := range op.VarDefs {
+		if !v.varsUsed[d.Name] {
+			v.errorf(d.Pos, "variable \"$%s\" is never used", d.Name)
+		}
+	}
+}
+
+func (v *validator) selectionSet(sels []Selection, parent *TypeDef) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *Field:
+			v.field(sel, parent)
+		case *InlineFragment:
+			v.directives(sel.Directives, "INLINE_FRAGMENT")
+			t := parent
+			if sel.TypeCond != "" {
+				t = v.typeCondition(sel.TypeCond, sel.Pos, parent)
+				if t == nil {
+					continue
+				}
+			}
+			v.selectionSet(sel.SelectionSet, t)
+		case *FragmentSpread:
+			v.directives(sel.Directives, "FRAGMENT_SPREAD")
+			f := v.doc.Fragment(sel.Name)
+			if f == nil {
+				v.errorf(sel.Pos, "unknown fragment %q", sel.Name)
+				continue
+			}
+			if v.fragmentsUsed == nil {
+				v.fragmentsUsed = make(map[string]bool)
+			}
+			v.fragmentsUsed[f.Name] = true
+			if v.visiting[f.Name] {
+				v.errorf(sel.Pos, "cannot spread fragment %q within itself", f.Name)
+				continue
+			}
+			t := v.typeCondition(f.TypeCond, sel.Pos, parent)
+			if t == nil {
+				continue
+			}
+			v.visiting[f.Name] = true
+			v.selectionSet(f.SelectionSet, t)
+			delete(v.visiting, f.Name)
+		}
+	}
+}
+
+// typeCondition returns the type named by type condition name,
+// or nil if it's unknown or can never apply within parent.
+func (v *validator) typeCondition(name string, pos Pos, parent *TypeDef) *TypeDef {
+	t := v.schema.Types[name]
+	switch {
+	case t == nil:
+		v.errorf(pos, "unknown type %q", name)
+		return nil
+	case t.Kind != ObjectKind && t.Kind != InterfaceKind && t.Kind != UnionKind:
+		v.errorf(pos, "fragment cannot condition on non composite type %q", name)
+		return nil
+	case !v.overlap(t, parent):
+		v.errorf(pos, "fragment on %q can never be spread within type %q", name, parent.Name)
+		return nil
+	}
+	return t
+}
+
+// overlap reports whether composite types a and b have a possible type in common.
+func (v *validator) overlap(a, b *TypeDef) bool {
+	if a == b {
+		return true
+	}
+	for _, name := range v.schema.TypeNames {
+		t := v.schema.Types[name]
+		if t.Kind == ObjectKind && v.schema.IsPossibleType(a, name) && v.schema.IsPossibleType(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) field(f *Field, parent *TypeDef) {
+	v.directives(f.Directives, "FIELD")
+	var def *FieldDef
+	switch {
+	case f.Name == "__typename":
+		def = &FieldDef{Name: f.Name, Type: &Type{Name: "String", NonNull: true}}
+	case f.Name == "__schema" && parent.Name == v.schema.Query:
+		def = &FieldDef{Name: f.Name, Type: &Type{Name: "__Schema", NonNull: true}}
+	case f.Name == "__type" && parent.Name == v.schema.Query:
+		def = &FieldDef{Name: f.Name, Type: &Type{Name: "__Type"}, Arguments: []*InputValueDef{{Name: "name", Type: &Type{Name: "String", NonNull: true}}}}
+	default:
+		def = parent.Field(f.Name)
+	}
+	if def == nil {
+		v.errorf(f.Pos, "cannot query field %q on type %q", f.Name, parent.Name)
+		return
+	}
+	if def.Deprecated {
+		v.warnf(f.Pos, "field %q is deprecated: %s", parent.Name+"."+def.Name, def.DeprecationReason)
+	}
+	v.arguments(f.Arguments, def.Arguments, f.Pos, fmt.Sprintf("field %q", parent.Name+"."+def.Name))
+
+	t := v.schema.Types[def.Type.NamedType()]
+	switch {
+	case t.IsLeaf() && len(f.SelectionSet) > 0:
+		v.errorf(f.Pos, "field %q must not have a selection since type %q has no subfields", f.Name, def.Type)
+	case !t.IsLeaf() && len(f.SelectionSet) == 0:
+		v.errorf(f.Pos, "field %q of type %q must have a selection of subfields", f.Name, def.Type)
+	case !t.IsLeaf():
+		v.selectionSet(f.SelectionSet, t)
+	}
+}
+
+func (v *validator) directives(ds []*Directive, location string) {
+	for _, d := range ds {
+		def := v.schema.Directives[d.Name]
+		if def == nil {
+			v.errorf(d.Pos, "unknown directive \"@%s\"", d.Name)
+			continue
+		}
+		if location != "" && !contains(def.Locations, directiveLocation(location)) {
+			v.errorf(d.Pos, "directive \"@%s\" may not be used on %s", d.Name, directiveLocation(location))
+		}
+		v.arguments(d.Arguments, def.Arguments, d.Pos, fmt.Sprintf("directive \"@%s\"", d.Name))
+	}
+}
+
+// directiveLocation maps operation types to directive locations.
+func directiveLocation(loc string) string {
+	switch loc {
+	case "query":
+		return "QUERY"
+	case "mutation":
+		return "MUTATION"
+	case "subscription":
+		return "SUBSCRIPTION"
+	}
+	return loc
+}
+
+func (v *validator) arguments(args []*Argument, defs []*InputValueDef, pos Pos, owner string) {
+	seen := make(map[string]bool)
+	for _, a := range args {
+		if seen[a.Name] {
+			v.errorf(a.Pos, "there can be only one argument named %q", a.Name)
+		}
+		seen[a.Name] = true
+		def := argumentDef(defs, a.Name)
+		if def == nil {
+			v.errorf(a.Pos, "unknown argument %q on %s", a.Name, owner)
+			continue
+		}
+		if def.Deprecated {
+			v.warnf(a.Pos, "argument %q on %s is deprecated: %s", a.Name, owner, def.DeprecationReason)
+		}
+		v.value(a.Value, def.Type)
+	}
+	for _, def := range defs {
+		if def.Type.NonNull && def.Default == nil && !seen[def.Name] {
+			v.errorf(pos, "%s argument %q of type %q is required, but it was not provided", owner, def.Name, def.Type)
+		}
+	}
+}
+
+func argumentDef(defs []*InputValueDef, name string) *InputValueDef {
+	for _, d := range defs {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// value validates input value literal val against type t.
+func (v *validator) value(val *Value, t *Type) {
+	if val.Kind == VariableValue {
+		v.varsUsed[val.Raw] = true
+		d := v.varDefs[val.Raw]
+		if d == nil {
+			v.errorf(val.Pos, "variable \"$%s\" is not defined", val.Raw)
+			return
+		}
+		if v.usages != nil && v.usages[d] == nil {
+			v.usages[d] = t
+		}
+		if !Compatible(d.Type, d.Default != nil, t) {
+			v.errorf(val.Pos, "variable \"$%s\" of type %q used in position expecting type %q", val.Raw, d.Type, t)
+		}
+		return
+	}
+	if val.Kind == NullValue {
+		if t.NonNull {
+			v.errorf(val.Pos, "expected value of type %q, found null", t)
+		}
+		return
+	}
+	if t.Elem != nil {
+		if val.Kind == ListValue {
+			for _, e := range val.List {
+				v.value(e, t.Elem)
+			}
+			return
+		}
+		v.value(val, t.Elem) // Input coercion accepts a single item for a list.
+		return
+	}
+	def := v.schema.Types[t.Name]
+	if def == nil {
+		return
+	}
+	ok := true
+	switch def.Kind {
+	case EnumKind:
+		ev := def.EnumValue(val.Raw)
+		ok = val.Kind == EnumValue && ev != nil
+		if ok && ev.Deprecated {
+			v.warnf(val.Pos, "enum value %q is deprecated: %s", def.Name+"."+ev.Name, ev.DeprecationReason)
+		}
+	case InputObjectKind:
+		if val.Kind != ObjectValue {
+			ok = false
+			break
+		}
+		seen := make(map[string]bool)
+		for _, f := range val.Fields {
+			seen[f.Name] = true
+			fd := def.InputField(f.Name)
+			if fd == nil {
+				v.errorf(f.Pos, "field %q is not defined by type %q", f.Name, def.Name)
+				continue
+			}
+			if fd.Deprecated {
+				v.warnf(f.Pos, "input field %q is deprecated: %s", def.Name+"."+fd.Name, fd.DeprecationReason)
+			}
+			v.value(f.Value, fd.Type)
+		}
+		for _, fd := range def.InputFields {
+			if fd.Type.NonNull && fd.Default == nil && !seen[fd.Name] {
+				v.errorf(val.Pos, "field %q of required type %q was not provided", def.Name+"."+fd.Name, fd.Type)
+			}
+		}
+	case ScalarKind:
+		switch def.Name {
+		case "Int":
+			ok = val.Kind == IntValue
+		case "Float":
+			ok = val.Kind == IntValue || val.Kind == FloatValue
+		case "String":
+			ok = val.Kind == StringValue
+		case "Boolean":
+			ok = val.Kind == BooleanValue
+		case "ID":
+			ok = val.Kind == StringValue || val.Kind == IntValue
+		}
+	}
+	if !ok {
+		v.errorf(val.Pos, "expected value of type %q, found %s", t, val)
+	}
+}
+
+// Compatible reports whether a variable of type varType, with a default
+// value if hasDefault, can be used where type locType is expected.
+func Compatible(varType *Type, hasDefault bool, locType *Type) bool {
+	if locType.NonNull && !varType.NonNull {
+		if !hasDefault {
+			return false
+		}
+		l := *locType
+		l.NonNull = false
+		locType = &l
+	}
+	if varType.NonNull && !locType.NonNull {
+		vt := *varType
+		vt.NonNull = false
+		return Compatible(&vt, false, locType)
+	}
+	if varType.NonNull != locType.NonNull {
+		return false
+	}
+	if varType.Elem != nil || locType.Elem != nil {
+		if varType.Elem == nil || locType.Elem == nil {
+			return false
+		}
+		return Compatible(varType.Elem, false, locType.Elem)
+	}
+	return varType.Name == locType.Name
+}
+
+// fragments validates fragment definitions of the document.
+func (v *validator) fragments() {
+	for i, f := range v.doc.Fragments {
+		for _, other := range v.doc.Fragments[:i] {
+			if other.Name == f.Name {
+				v.errorf(f.Pos, "there can be only one fragment named %q", f.Name)
+			}
+		}
+		if !v.fragmentsUsed[f.Name] {
+			v.errorf(f.Pos, "fragment %q is never used", f.Name)
+		}
+		if t := v.schema.Types[f.TypeCond]; t == nil {
+			v.errorf(f.Pos, "unknown type %q", f.TypeCond)
+		}
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
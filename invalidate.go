@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// WithInvalidation makes the client evict cached responses when it's
+// passed subscription events with HandleEvent, keeping the cache fresh
+// without relying on TTLs alone. For events whose data has a top-level
+// field named field, such as "userUpdated", entities returns the entities
+// the event invalidates, as "Typename:id" keys such as "User:4". If
+// entities is nil, the entities are the objects in the event data with
+// __typename and id fields.
+//
+// Cached responses are evicted if they contain any of the entities, which
+// are the objects in their data with __typename and id fields. Queries
+// should select both on the objects that may be invalidated. The entities
+// of responses are only indexed by clients with rules, and are forgotten
+// once the responses expire, or are evicted or replaced by the client.
+func WithInvalidation(field string, entities func(data json.RawMessage) []string) ClientOption {
+	return func(c *Client) {
+		if c.invalidations == nil {
+			c.invalidations = make(map[string]func(json.RawMessage) []string)
+		}
+		c.invalidations[field] = entities
+	}
+}
+
+// HandleEvent evicts the cached responses invalidated by a subscription
+// event with data, according to the rules set with WithInvalidation.
+// It's intended to be called with the data of each event received on
+// the subscriptions whose fields have rules.
+func (c *Client) HandleEvent(data json.RawMessage) (err error) {
+	defer recoverPanic(&err)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var entities []string
+	for field, v := range fields {
+		rule, ok := c.invalidations[field]
+		if !ok {
+			continue
+		}
+		if rule == nil {
+			entities = append(entities, dataEntities(v)...)
+		} else {
+			entities = append(entities, rule(v)...)
+		}
+	}
+	c.Invalidate(entities...)
+	return nil
+}
+
+// Invalidate evicts the cached responses that contain any of entities,
+// given as "Typename:id" keys such as "User:4", for clients created
+// WithInvalidation.
+func (c *Client) Invalidate(entities ...string) {
+	if c.cache == nil {
+		return
+	}
+	c.entityMu.Lock()
+	var keys []string
+	for _, e := range entities {
+		for key := range c.entityKeys[e] {
+			keys = append(keys, key)
+		}
+		delete(c.entityKeys, e)
+	}
+	c.entityMu.Unlock()
+	for _, key := range keys {
+		c.evict(key)
+	}
+}
+
+// indexedEntry is the entities in a cached response, for Invalidate.
+type indexedEntry struct {
+	entities []string
+	expires  time.Time // Or zero if the response doesn't expire.
+}
+
+// indexEntities records the entities in the data for key, cached for ttl,
+// if c has invalidation rules, replacing those recorded for key before.
+func (c *Client) indexEntities(key string, data []byte, ttl time.Duration) {
+	if len(c.invalidations) == 0 {
+		return
+	}
+	entities := dataEntities(data)
+	c.entityMu.Lock()
+	defer c.entityMu.Unlock()
+	c.unindexLocked(key)
+	now := c.clock.Now()
+	// Sweep expired entries once in a while, so they don't accumulate.
+	if c.entitySets++; c.entitySets >= len(c.keyEntities) {
+		c.entitySets = 0
+		for k, e := range c.keyEntities {
+			if !e.expires.IsZero() && !now.Before(e.expires) {
+				c.unindexLocked(k)
+			}
+		}
+	}
+	if len(entities) == 0 {
+		return
+	}
+	if c.entityKeys == nil {
+		c.entityKeys = make(map[string]map[string]struct{})
+		c.keyEntities = make(map[string]indexedEntry)
+	}
+	for _, e := range entities {
+		keys, ok := c.entityKeys[e]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.entityKeys[e] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	c.keyEntities[key] = indexedEntry{entities: entities, expires: expires}
+}
+
+// unindexEntities forgets the entities recorded for key.
+func (c *Client) unindexEntities(key string) {
+	if len(c.invalidations) == 0 {
+		return
+	}
+	c.entityMu.Lock()
+	defer c.entityMu.Unlock()
+	c.unindexLocked(key)
+}
+
+// unindexLocked is unindexEntities with c.entityMu held.
+func (c *Client) unindexLocked(key string) {
+	e, ok := c.keyEntities[key]
+	if !ok {
+		return
+	}
+	delete(c.keyEntities, key)
+	for _, entity := range e.entities {
+		keys := c.entityKeys[entity]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.entityKeys, entity)
+		}
+	}
+}
+
+// dataEntities returns the "Typename:id" keys of the objects in JSON data
+// with __typename and id fields, sorted and without duplicates.
+func dataEntities(data []byte) []string {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var visit func(v interface{})
+	visit = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if typename, ok := v["__typename"].(string); ok {
+				switch id := v["id"].(type) {
+				case string:
+					seen[typename+":"+id] = true
+				case json.Number:
+					seen[typename+":"+id.String()] = true
+				}
+			}
+			for _, e := range v {
+				visit(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				visit(e)
+			}
+		}
+	}
+	visit(v)
+	entities := make([]string, 0, len(seen))
+	for e := range seen {
+		entities = append(entities, e)
+	}
+	sort.Strings(entities)
+	return entities
+}
@@ -0,0 +1,115 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_HandleEvent(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"user": {"__typename": "User", "id": "4", "login": "gopher"}, "repo": {"__typename": "Repository", "id": 7}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Hour),
+		graphql.WithInvalidation("userUpdated", nil),
+		graphql.WithInvalidation("repoDeleted", func(data json.RawMessage) []string {
+			var id string
+			json.Unmarshal(data, &id)
+			return []string{"Repository:" + id}
+		}),
+	)
+
+	var q struct {
+		User struct {
+			Typename graphql.String `graphql:"__typename"`
+			ID       graphql.ID
+			Login    graphql.String
+		}
+		Repo struct {
+			Typename graphql.String `graphql:"__typename"`
+			ID       graphql.ID
+		}
+	}
+	run := func(wantRequests int32) {
+		t.Helper()
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := atomic.LoadInt32(&requests); got != wantRequests {
+			t.Errorf("got %d requests, want %d", got, wantRequests)
+		}
+	}
+	run(1)
+	run(1)
+
+	// Events for other entities or fields don't invalidate the response.
+	for _, event := range []string{
+		`{"userUpdated": {"__typename": "User", "id": "5"}}`,
+		`{"userCreated": {"__typename": "User", "id": "4"}}`,
+	} {
+		if err := client.HandleEvent(json.RawMessage(event)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(1)
+
+	if err := client.HandleEvent(json.RawMessage(`{"userUpdated": {"__typename": "User", "id": "4", "login": "gopher2"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	run(2)
+	if err := client.HandleEvent(json.RawMessage(`{"repoDeleted": "7"}`)); err != nil {
+		t.Fatal(err)
+	}
+	run(3)
+	client.Invalidate("User:4")
+	run(4)
+}
+
+func TestClient_InvalidateReplaced(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		id := 3 + atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"__typename": "User", "id": "`+strconv.Itoa(int(id))+`"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Hour),
+		graphql.WithInvalidation("userUpdated", nil),
+	)
+
+	var q struct {
+		Viewer struct {
+			Typename graphql.String `graphql:"__typename"`
+			ID       graphql.ID
+		}
+	}
+	run := func(ctx context.Context, wantRequests int32) {
+		t.Helper()
+		if err := client.Query(ctx, &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := atomic.LoadInt32(&requests); got != wantRequests {
+			t.Errorf("got %d requests, want %d", got, wantRequests)
+		}
+	}
+	run(context.Background(), 1)
+	// Refreshing the response replaces User:4 with User:5, so invalidating
+	// User:4 no longer evicts it.
+	run(graphql.WithCacheBypass(context.Background()), 2)
+	client.Invalidate("User:4")
+	run(context.Background(), 2)
+	client.Invalidate("User:5")
+	run(context.Background(), 3)
+}
@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+// lifecycle tracks the background work of a client, such as prefetches
+// and batches of loaders, so it's canceled and waited for by Close.
+type lifecycle struct {
+	ctx    context.Context // Canceled by Close.
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Close cancels the background work of c, such as prefetches and batches
+// of loaders, and waits for it to stop. It also closes the idle connections
+// of the transport the client configured for its options, if any. Operations
+// run afterwards still work, but background work started by them is canceled
+// immediately.
+func (c *Client) Close() error {
+	l := c.lifecycle
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	l.cancel()
+	l.wg.Wait()
+	if c.ownTransport != nil {
+		c.ownTransport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// goBackground runs f, background work of c, in a goroutine, with a
+// context that's canceled when ctx is done or c is closed. Values of
+// ctx are kept. Close waits for f to return.
+func (c *Client) goBackground(ctx context.Context, f func(ctx context.Context)) {
+	l := c.lifecycle
+	ctx, cancel := context.WithCancel(ctx)
+	l.mu.Lock()
+	tracked := !l.closed
+	if tracked {
+		l.wg.Add(1)
+	} else {
+		cancel()
+	}
+	l.mu.Unlock()
+
+	go func() {
+		if tracked {
+			defer l.wg.Done()
+		}
+		defer cancel()
+		stopped := make(chan struct{})
+		defer close(stopped)
+		go func() {
+			select {
+			case <-l.ctx.Done():
+				cancel()
+			case <-stopped:
+			}
+		}()
+		defer c.recoverBackground()
+		f(ctx)
+	}()
+}
+
+// recoverBackground logs a panic in background work, which has no
+// caller to return an error to, rather than crash the program.
+func (c *Client) recoverBackground() {
+	if v := recover(); v != nil {
+		c.logf("graphql: panic in background work: %v\n%s", v, debug.Stack())
+	}
+}
@@ -0,0 +1,90 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+// cancelableRoundTripper is a localRoundTripper that fails requests
+// canceled while they're served, as http.Transport does.
+type cancelableRoundTripper struct {
+	localRoundTripper
+}
+
+func (rt cancelableRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.localRoundTripper.RoundTrip(req)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return resp, err
+}
+
+// blockingHandler serves requests once they're canceled, reporting
+// the requests it receives on started.
+func blockingHandler(started chan<- struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-req.Context().Done()
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": null}`)
+	})
+}
+
+func TestClient_Close_prefetch(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	started := make(chan struct{}, 1)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: cancelableRoundTripper{localRoundTripper{handler: blockingHandler(started)}}}, graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	// The prefetch isn't canceled by its context, but by closing the client.
+	if _, err := client.Prefetch(context.Background(), graphql.NewQuery(&q, nil)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Background work started after Close is canceled immediately.
+	if _, err := client.Prefetch(context.Background(), graphql.NewQuery(&q, nil)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-started:
+		t.Error("prefetch sent after the client was closed")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestClient_Close_loader(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	started := make(chan struct{}, 1)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: cancelableRoundTripper{localRoundTripper{handler: blockingHandler(started)}}})
+
+	type user struct {
+		Login graphql.String
+	}
+	users := graphql.NewLoader[graphql.ID, user](client, "user(id: $key)")
+	errc := make(chan error, 1)
+	go func() {
+		_, err := users.Load(context.Background(), "4")
+		errc <- err
+	}()
+	<-started
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
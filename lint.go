@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// Lint checks the document of op against schema, and returns the issues
+// found: InvalidOperation issues for syntax errors, unknown fields,
+// arguments and fragments, unused fragments and variables, and variables
+// used without being defined, and DeprecatedUsage issues for uses of
+// deprecated fields, arguments and enum values. Variables in
+// op.Vars that the operation doesn't define are reported too, located
+// at the operation. It returns nil if the document is valid.
+//
+// Lint can be called in tests, or when the operation is constructed,
+// to find mistakes in hand-written documents before they're sent.
+func (op *Static) Lint(schema *Schema) []Issue {
+	doc, err := parser.ParseDocument(op.QueryStr)
+	if err != nil {
+		return []Issue{issue(InvalidOperation, op, err.(*parser.Error))}
+	}
+	errs, warnings := parser.Check(schema.s, doc)
+	var issues []Issue
+	for _, err := range errs {
+		issues = append(issues, issue(InvalidOperation, op, err))
+	}
+	for _, w := range warnings {
+		issues = append(issues, issue(DeprecatedUsage, op, w))
+	}
+	if def := doc.Operation(op.Name); def != nil {
+		defined := make(map[string]bool, len(def.VarDefs))
+		for _, d := range def.VarDefs {
+			defined[d.Name] = true
+		}
+		var missing []string
+		for name := range op.Vars {
+			if !defined[name] {
+				missing = append(missing, name)
+			}
+		}
+		sort.Strings(missing)
+		for _, name := range missing {
+			issues = append(issues, issue(InvalidOperation, op, &parser.Error{
+				Message:   fmt.Sprintf("variable \"$%s\" has a value but is not defined by the operation", name),
+				Locations: []parser.Pos{def.Pos},
+			}))
+		}
+	}
+	return issues
+}
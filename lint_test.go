@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestStatic_Lint(t *testing.T) {
+	schema, err := graphql.ParseSchema([]byte(`
+		type Query {
+			user(login: String!): User
+		}
+		type User {
+			login: String!
+			name: String
+			email: String @deprecated(reason: "Use emails.")
+		}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := &graphql.Static{
+		QueryStr: `query($login: String!) {
+	user(login: $login) { ...UserFields }
+}
+fragment UserFields on User { login name }`,
+		Vars: map[string]interface{}{"login": "octocat"},
+	}
+	if issues := op.Lint(schema); issues != nil {
+		t.Errorf("got issues %v, want none", issues)
+	}
+
+	op = &graphql.Static{
+		QueryStr: `query($unused: Int) {
+	user(login: $login, first: 1) { login nickname email }
+}
+fragment UserFields on User { login }`,
+		Vars: map[string]interface{}{"login": "octocat"},
+	}
+	var got []string
+	for _, i := range op.Lint(schema) {
+		if i.Op != op {
+			t.Errorf("issue %v: got Op %v, want %v", i, i.Op, op)
+		}
+		got = append(got, i.String())
+	}
+	want := []string{
+		`invalid operation: 1:7: variable "$unused" is never used`,
+		`invalid operation: 2:14: variable "$login" is not defined`,
+		`invalid operation: 2:22: unknown argument "first" on field "Query.user"`,
+		`invalid operation: 2:40: cannot query field "nickname" on type "User"`,
+		`invalid operation: 4:1: fragment "UserFields" is never used`,
+		`deprecated usage: 2:49: field "User.email" is deprecated: Use emails.`,
+		`invalid operation: 1:1: variable "$login" has a value but is not defined by the operation`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got issues:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestStatic_Lint_syntaxError(t *testing.T) {
+	schema, err := graphql.ParseSchema([]byte(`type Query { a: Int }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := (&graphql.Static{QueryStr: "{\n  a"}).Lint(schema)
+	if len(issues) != 1 || issues[0].Kind != graphql.InvalidOperation || issues[0].Line == 0 {
+		t.Errorf("got issues %v, want one InvalidOperation issue with a location", issues)
+	}
+}
@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Loader coalesces fetches of single objects by key, such as nodes by ID,
+// made within a short window by any number of goroutines, into one query
+// with an aliased field per key. It's a client-side DataLoader, for
+// services that resolve their own fields from a GraphQL upstream.
+//
+// Objects are decoded into values of type T, a query data structure
+// for the fetched field, and keys are sent as variables of type K.
+type Loader[K comparable, T any] struct {
+	client *Client
+	field  string
+
+	// Wait is how long a batch collects keys after the first Load of it,
+	// before it's sent. It's 2ms if zero.
+	Wait time.Duration
+
+	// MaxBatch is the maximum number of distinct keys in a batch;
+	// a batch is sent as soon as it's full. There's no maximum if zero.
+	MaxBatch int
+
+	mu    sync.Mutex
+	batch *loaderBatch[K, T]
+}
+
+// NewLoader returns a Loader that fetches objects with GraphQL field
+// field of the query type, which refers to the key as variable $key:
+//
+//	type user struct {
+//		Login graphql.String
+//	}
+//	users := graphql.NewLoader[graphql.ID, user](client, "user(id: $key)")
+//	u, err := users.Load(ctx, "4")
+//
+// Keys are declared with the GraphQL type of K, as for the variables
+// of a Query, such as "ID!" for ID.
+func NewLoader[K comparable, T any](c *Client, field string) *Loader[K, T] {
+	return &Loader[K, T]{client: c, field: field}
+}
+
+// loaderBatch is a batch of keys of a Loader, loaded by one query.
+type loaderBatch[K comparable, T any] struct {
+	ctx   context.Context // Context of the first Load of the batch, for its values.
+	keys  []K
+	index map[K]int
+	timer Timer
+	done  chan struct{} // Closed once results and errs are set.
+
+	results []*T
+	errs    []error
+}
+
+// Load returns the object with key, or nil if the server returned null
+// for it. It waits for the batch of key to be loaded, or for ctx to be
+// done. The batch is sent with the values of the context of its first
+// Load, but isn't canceled with it; it's canceled if the client is closed.
+//
+// GraphQL errors with a path in the field of key are returned only for
+// key, and other GraphQL errors are returned for all keys of the batch.
+func (l *Loader[K, T]) Load(ctx context.Context, key K) (*T, error) {
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch[K, T]{ctx: detachedContext{ctx}, index: make(map[K]int), done: make(chan struct{})}
+		wait := l.Wait
+		if wait == 0 {
+			wait = 2 * time.Millisecond
+		}
+		b.timer = l.client.clock.AfterFunc(wait, func() { l.send(b) })
+		l.batch = b
+	}
+	i, ok := b.index[key]
+	if !ok {
+		i = len(b.keys)
+		b.index[key] = i
+		b.keys = append(b.keys, key)
+	}
+	full := l.MaxBatch > 0 && len(b.keys) >= l.MaxBatch
+	if full {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+	if full && b.timer.Stop() {
+		l.send(b)
+	}
+
+	select {
+	case <-b.done:
+		return b.results[i], b.errs[i]
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// send sends batch b, as background work of the client.
+func (l *Loader[K, T]) send(b *loaderBatch[K, T]) {
+	l.client.goBackground(b.ctx, func(ctx context.Context) { l.dispatch(ctx, b) })
+}
+
+// dispatch sends batch b with ctx, and sets its results.
+func (l *Loader[K, T]) dispatch(ctx context.Context, b *loaderBatch[K, T]) {
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock() // No keys are added to b once it's no longer l.batch.
+	defer close(b.done)
+
+	b.results = make([]*T, len(b.keys))
+	b.errs = make([]error, len(b.keys))
+	fields := make([]reflect.StructField, len(b.keys))
+	vars := make(map[string]interface{}, len(b.keys))
+	for i, key := range b.keys {
+		n := strconv.Itoa(i)
+		fields[i] = reflect.StructField{
+			Name: "N" + n,
+			Type: reflect.TypeOf((*T)(nil)),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"n%s: %s"`, n, keyVariable.ReplaceAllString(l.field, "${1}"+n))),
+		}
+		vars["key"+n] = key
+	}
+	data := reflect.New(reflect.StructOf(fields))
+	out, err := l.client.do(ctx, &Query{Data: data.Interface(), Vars: vars})
+	for i := range b.keys {
+		if err != nil {
+			b.errs[i] = err
+			continue
+		}
+		b.results[i] = data.Elem().Field(i).Interface().(*T)
+	}
+	if err != nil {
+		return
+	}
+	for _, e := range out.Errors {
+		if len(e.Path) > 0 {
+			if alias, ok := e.Path[0].(string); ok && len(alias) > 1 && alias[0] == 'n' {
+				if i, err := strconv.Atoi(alias[1:]); err == nil && i < len(b.keys) {
+					b.errs[i] = appendError(b.errs[i], e)
+					continue
+				}
+			}
+		}
+		for i := range b.keys {
+			b.errs[i] = appendError(b.errs[i], e)
+		}
+	}
+}
+
+// keyVariable matches references to the $key variable in the field of a Loader.
+var keyVariable = regexp.MustCompile(`(\$key)\b`)
+
+// appendError returns err with GraphQL error e appended, as an ErrorList.
+func appendError(err error, e Error) error {
+	errs, _ := err.(ErrorList)
+	return append(errs, e)
+}
+
+// detachedContext is a context with the values of a parent context,
+// that's never done.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,113 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestLoader(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in struct {
+			Query     string
+			Variables map[string]string
+		}
+		if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+			panic(err)
+		}
+		mu.Lock()
+		queries = append(queries, in.Query)
+		mu.Unlock()
+		data := map[string]interface{}{}
+		var errs []map[string]interface{}
+		for k, id := range in.Variables {
+			alias := "n" + k[len("key"):]
+			switch id {
+			case "missing":
+				data[alias] = nil
+			case "broken":
+				data[alias] = nil
+				errs = append(errs, map[string]interface{}{"message": "cannot load " + id, "path": []string{alias}})
+			default:
+				data[alias] = map[string]string{"login": "user" + id}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type user struct {
+		Login graphql.String
+	}
+	users := graphql.NewLoader[graphql.ID, user](client, "user(id: $key)")
+	users.MaxBatch = 4
+
+	ids := []graphql.ID{"1", "2", "1", "3", "missing", "broken"}
+	got := make([]*user, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id graphql.ID) {
+			defer wg.Done()
+			got[i], errs[i] = users.Load(context.Background(), id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		switch id {
+		case "missing":
+			if got[i] != nil || errs[i] != nil {
+				t.Errorf("%s: got %v, %v, want nil, nil", id, got[i], errs[i])
+			}
+		case "broken":
+			if errs[i] == nil || errs[i].Error() != "cannot load broken" {
+				t.Errorf("%s: got error: %v, want: cannot load broken", id, errs[i])
+			}
+		default:
+			if errs[i] != nil {
+				t.Errorf("%s: got error: %v", id, errs[i])
+			} else if want := graphql.String("user" + id); got[i] == nil || got[i].Login != want {
+				t.Errorf("%s: got %v, want login %v", id, got[i], want)
+			}
+		}
+	}
+	// Five distinct keys don't fit in one batch.
+	if len(queries) < 2 {
+		t.Fatalf("got %d queries, want at least 2: %q", len(queries), queries)
+	}
+	for _, q := range queries {
+		if n := strings.Count(q, "user(id: $key"); n > 4 {
+			t.Errorf("got query for %d keys, want at most 4: %q", n, q)
+		}
+		if want := "query($key0:ID!"; !strings.HasPrefix(q, want) || !strings.Contains(q, "{n0: user(id: $key0){login}") {
+			t.Errorf("got query: %q, want aliased user fields", q)
+		}
+	}
+}
+
+func TestLoader_canceled(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"n0": {"login": "user1"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	users := graphql.NewLoader[graphql.ID, struct{ Login graphql.String }](client, "user(id: $key)")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := users.Load(ctx, "1"); err != context.Canceled {
+		t.Errorf("got error: %v, want: %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WithNegativeCache returns op decorated so that its responses with errors
+// are cached for ttl, by clients created WithCache, if every error has one
+// of codes as the "code" of its extensions, such as "NOT_FOUND". Runs of
+// the operation are then served the cached errors, and data, if any,
+// rather than asking the server again for entities known to be absent.
+// Other responses with errors aren't cached, as without the decoration.
+//
+// The TTL should be short, as entities may be created in the meantime.
+// If it isn't positive, responses aren't negatively cached, as they'd
+// never expire.
+// Negatively cached responses are evicted by Client.Invalidate too, if
+// their data has the entities invalidated.
+func WithNegativeCache(op Operation, ttl time.Duration, codes ...string) Operation {
+	return &negativeCached{Operation: op, ttl: ttl, codes: codes}
+}
+
+type negativeCached struct {
+	Operation
+	ttl   time.Duration
+	codes []string
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *negativeCached) Unwrap() Operation {
+	return op.Operation
+}
+
+// cacheable reports whether every error of errs has one of the codes
+// of op.
+func (op *negativeCached) cacheable(errs ErrorList) bool {
+next:
+	for _, e := range errs {
+		code, _ := e.Extensions["code"].(string)
+		for _, c := range op.codes {
+			if c == code {
+				continue next
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// negativeEntry is a response with errors, as negatively cached.
+type negativeEntry struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors ErrorList       `json:"errors"`
+}
+
+// storeNegative caches response out to op for key, if op is negatively
+// cached and the errors of out are cacheable.
+func (c *Client) storeNegative(op Operation, key string, out Response) {
+	neg, ok := asOperation[*negativeCached](op)
+	if key == "" || !ok || neg.ttl <= 0 || len(out.Errors) == 0 || !neg.cacheable(out.Errors) {
+		return
+	}
+	b, err := json.Marshal(negativeEntry{Data: out.Data, Errors: out.Errors})
+	if err != nil {
+		return
+	}
+	c.cache.Set(negativeKey(key), c.wrapEntry(op, b), neg.ttl)
+	if out.Data != nil {
+		c.indexEntities(negativeKey(key), out.Data, neg.ttl)
+	}
+}
+
+// cachedNegative returns the negatively cached response to op for key,
+// if any.
+func (c *Client) cachedNegative(op Operation, key string) (out Response, ok bool) {
+	if key == "" {
+		return out, false
+	}
+	if _, ok := asOperation[*negativeCached](op); !ok {
+		return out, false
+	}
+	b, ok := c.getEntry(op, negativeKey(key))
+	if !ok {
+		return out, false
+	}
+	var e negativeEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return out, false
+	}
+	return Response{Data: e.Data, Errors: e.Errors, cached: true}, true
+}
+
+// negativeKey returns the cache key of the negatively cached response
+// for key.
+func negativeKey(key string) string {
+	return key + ":negative"
+}
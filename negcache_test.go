@@ -0,0 +1,95 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestWithNegativeCache(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(body, `"id":"missing"`):
+			mustWrite(w, `{"data": {"user": null}, "errors": [{"message": "user not found", "path": ["user"], "extensions": {"code": "NOT_FOUND"}}]}`)
+		default:
+			mustWrite(w, `{"data": {"user": null}, "errors": [{"message": "internal error", "extensions": {"code": "INTERNAL"}}]}`)
+		}
+	})
+	clock := graphqltest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithClock(clock),
+		graphql.WithCache(graphql.NewMemoryCache(), time.Hour),
+	)
+
+	var q struct {
+		User *struct {
+			Login graphql.String
+		} `graphql:"user(id: $id)"`
+	}
+	ttl := time.Minute
+	run := func(id string, negative bool) error {
+		var op graphql.Operation = graphql.NewQuery(&q, map[string]interface{}{"id": graphql.ID(id)})
+		if negative {
+			op = graphql.WithNegativeCache(op, ttl, "NOT_FOUND")
+		}
+		return client.Run(context.Background(), op)
+	}
+
+	for i := 0; i < 3; i++ {
+		err := run("missing", true)
+		var errs graphql.ErrorList
+		if !errors.As(err, &errs) || errs[0].Message != "user not found" || errs[0].Extensions["code"] != "NOT_FOUND" {
+			t.Fatalf("got error %v, want user not found", err)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+
+	// Other errors, and operations that don't opt in, aren't cached.
+	for i := 0; i < 2; i++ {
+		if err := run("broken", true); err == nil {
+			t.Fatal("got no error, want internal error")
+		}
+		if err := run("missing", false); err == nil {
+			t.Fatal("got no error, want user not found")
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 5 {
+		t.Errorf("got %d requests, want 5", got)
+	}
+
+	// Negatively cached responses expire.
+	clock.Advance(2 * time.Minute)
+	if err := run("missing", true); err == nil {
+		t.Fatal("got no error, want user not found")
+	}
+	if got := atomic.LoadInt32(&requests); got != 6 {
+		t.Errorf("got %d requests, want 6", got)
+	}
+
+	// Responses aren't negatively cached without a positive TTL, as they'd
+	// never expire.
+	clock.Advance(2 * time.Minute)
+	ttl = 0
+	for i := 0; i < 2; i++ {
+		if err := run("missing", true); err == nil {
+			t.Fatal("got no error, want user not found")
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 8 {
+		t.Errorf("got %d requests, want 8", got)
+	}
+}
@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"time"
+)
+
+// OperationEvent describes an operation run by a client, for observers
+// added with WithObserver.
+type OperationEvent struct {
+	Name     string        // Operation name, if any.
+	Query    string        // Query document of the operation, as constructed.
+	Start    time.Time     // Time the operation started, measured with the client's Clock.
+	Duration time.Duration // Time the operation took, including retries.
+	Cached   bool          // Whether the response was served from the cache.
+
+	// Conns are statistics of the connections of the operation's HTTP
+	// requests. They're zero for responses served from the cache, and
+	// for clients with a custom Transport.
+	Conns ConnStats
+
+	// Err is the error of the operation, as returned by Run: an ErrorList
+	// for responses with GraphQL errors. It's nil if it succeeded.
+	Err error
+}
+
+// WithObserver adds observer, which is called with an event for each
+// operation the client runs once it's done, with the context it was run
+// with, such as to export metrics. Observers are called synchronously, so
+// they should be fast.
+func WithObserver(observer func(ctx context.Context, e OperationEvent)) ClientOption {
+	return func(c *Client) {
+		c.observers = append(c.observers, observer)
+	}
+}
+
+// observe calls the observers of c with the event of op, started at
+// start, which returned out and err, with its connections traced by t.
+func (c *Client) observe(ctx context.Context, op Operation, start time.Time, t *connTracer, out Response, err error) {
+	e := OperationEvent{
+		Query:    op.Query(),
+		Start:    start,
+		Duration: c.clock.Now().Sub(start),
+		Cached:   out.cached,
+		Conns:    t.collected(),
+		Err:      err,
+	}
+	if op, ok := asOperation[NamedOperation](op); ok {
+		e.Name = op.OperationName()
+	}
+	if e.Err == nil && len(out.Errors) > 0 {
+		e.Err = out.Errors
+	}
+	for _, observer := range c.observers {
+		observer(ctx, e)
+	}
+}
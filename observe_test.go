@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestWithObserver(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	clock := graphqltest.NewClock(time.Now())
+	var events []graphql.OperationEvent
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithClock(clock), graphql.WithCache(graphql.NewMemoryCache(), time.Minute), graphql.WithAutoOperationNames(),
+		graphql.WithObserver(func(ctx context.Context, e graphql.OperationEvent) {
+			events = append(events, e)
+		}))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	for i, e := range events {
+		if e.Name == "" || e.Query == "" || e.Err != nil || !e.Start.Equal(clock.Now()) {
+			t.Errorf("event %d: got %+v, want a named operation without error", i, e)
+		}
+		if want := i == 1; e.Cached != want {
+			t.Errorf("event %d: got cached %v, want %v", i, e.Cached, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// WithAutoOperationNames makes the client name anonymous *Query and
+// *Mutation operations before running them, so server-side logs and
+// metrics aren't full of anonymous operations.
+//
+// The name is derived from the Go type name of the operation's Data,
+// such as "ViewerQuery" for a *viewerQuery, or, if Data is of an unnamed
+// struct type, from the name of the function that called into the client,
+// such as "FetchIssues" for (*Service).fetchIssues.
+// Operations with a non-empty Name are left unchanged.
+func WithAutoOperationNames() ClientOption {
+	return func(c *Client) {
+		c.autoName = true
+	}
+}
+
+// WithOperationNameInBody makes the client send the names of operations
+// implementing NamedOperation as the operationName member of the request
+// body, which some gateways use for routing and observability. By default,
+// names of *Query and *Mutation operations are only embedded in the query
+// document.
+func WithOperationNameInBody() ClientOption {
+	return func(c *Client) {
+		c.bodyName = true
+	}
+}
+
+// nameOperation returns a copy of op named automatically, if op is
+// an anonymous *Query or *Mutation. Otherwise it returns op.
+func nameOperation(op Operation) Operation {
+	switch op := op.(type) {
+	case *Query:
+		if op.Name == "" {
+			named := *op
+			named.Name = operationName(op.Data)
+			return &named
+		}
+	case *Mutation:
+		if op.Name == "" {
+			named := *op
+			named.Name = operationName(op.Data)
+			return &named
+		}
+	}
+	return op
+}
+
+// operationName derives an operation name for query data structure v.
+func operationName(v interface{}) string {
+	if t := reflect.TypeOf(v); t != nil {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if name := sanitizeName(t.Name()); name != "" {
+			return name
+		}
+	}
+	return callerName()
+}
+
+// callerName returns a name derived from the first function on the
+// call stack outside of this package, or "" if there is none.
+func callerName() string {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/arvata-io/graphql.") {
+			return funcName(frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcName returns an operation name for fully qualified function name fn,
+// such as "FetchIssues" for "example.org/pkg.(*Service).fetchIssues.func1".
+func funcName(fn string) string {
+	if i := strings.LastIndex(fn, "/"); i != -1 {
+		fn = fn[i+1:]
+	}
+	parts := strings.Split(fn, ".")
+	for i := len(parts) - 1; i > 0; i-- { // parts[0] is the package name.
+		p := parts[i]
+		if strings.HasPrefix(p, "func") && strings.Trim(p[len("func"):], "0123456789") == "" {
+			continue // Closure.
+		}
+		if name := sanitizeName(p); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// sanitizeName returns s with characters not allowed in GraphQL names
+// removed and its first letter upper-cased.
+func sanitizeName(s string) string {
+	if i := strings.Index(s, "["); i != -1 {
+		s = s[:i] // Type parameters.
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, s)
+	s = strings.TrimLeft(s, "0123456789")
+	if s == "" {
+		return ""
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,117 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+type viewerQuery struct {
+	Viewer struct {
+		Login graphql.String
+	}
+}
+
+type issueService struct {
+	client *graphql.Client
+}
+
+func (s *issueService) fetchIssue(number int) error {
+	var q struct {
+		Repository struct {
+			Issue struct {
+				Title graphql.String
+			} `graphql:"issue(number: $number)"`
+		} `graphql:"repository(owner: \"octocat\", name: \"hello\")"`
+	}
+	run := func() error {
+		return s.client.Query(context.Background(), &q, map[string]interface{}{"number": graphql.Int(number)})
+	}
+	return run()
+}
+
+func TestClient_autoOperationNames(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		queries = append(queries, mustRead(req.Body))
+		mustWrite(w, `{"data": {}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithAutoOperationNames(),
+	)
+
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&issueService{client: client}).fetchIssue(1); err != nil {
+		t.Fatal(err)
+	}
+	named := &graphql.Mutation{Data: new(struct{ Ok graphql.Boolean }), Name: "Explicit"}
+	if err := client.Run(context.Background(), named); err != nil {
+		t.Fatal(err)
+	}
+	if named.Name != "Explicit" {
+		t.Errorf("operation Name modified to %q", named.Name)
+	}
+
+	want := []string{
+		`{"query":"query ViewerQuery{viewer{login}}"}` + "\n",
+		`{"query":"query FetchIssue($number:Int!){repository(owner: \"octocat\", name: \"hello\"){issue(number: $number){title}}}","variables":{"number":1}}` + "\n",
+		`{"query":"mutation Explicit{ok}"}` + "\n",
+	}
+	if len(queries) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(queries), len(want))
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("request %d:\ngot:  %s\nwant: %s", i, queries[i], want[i])
+		}
+	}
+}
+
+func TestClient_operationNameInBody(t *testing.T) {
+	var bodies []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		bodies = append(bodies, mustRead(req.Body))
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	httpClient := &http.Client{Transport: localRoundTripper{handler: mux}}
+
+	ops := []graphql.Operation{
+		&graphql.Query{Data: new(viewerQuery), Name: "Viewer"},
+		&graphql.Static{QueryStr: "query A{a} query B{viewer{login}}", Into: new(viewerQuery), Name: "B"},
+		&graphql.Query{Data: new(viewerQuery)},
+	}
+	for _, client := range []*graphql.Client{
+		graphql.NewClient("/graphql", httpClient),
+		graphql.NewClient("/graphql", httpClient, graphql.WithOperationNameInBody()),
+	} {
+		for _, op := range ops {
+			if err := client.Run(context.Background(), op); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	want := []string{
+		`{"query":"query Viewer{viewer{login}}"}`,
+		`{"query":"query A{a} query B{viewer{login}}"}`,
+		`{"query":"{viewer{login}}"}`,
+		`{"query":"query Viewer{viewer{login}}","operationName":"Viewer"}`,
+		`{"query":"query A{a} query B{viewer{login}}","operationName":"B"}`,
+		`{"query":"{viewer{login}}"}`,
+	}
+	if len(bodies) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(bodies), len(want))
+	}
+	for i := range want {
+		if bodies[i] != want[i]+"\n" {
+			t.Errorf("request %d:\ngot:  %s\nwant: %s", i, bodies[i], want[i])
+		}
+	}
+}
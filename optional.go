@@ -0,0 +1,312 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Optional is an optional GraphQL input value of type T, which
+// distinguishes between a value that is absent and one that is
+// explicitly null. Its zero value is absent.
+//
+// As a variable, an Optional is declared with the nullable type of T,
+// such as "String" for Optional[String]. Absent variables are left out
+// of the request, so the server uses their default values, while null
+// ones are sent as JSON null.
+//
+// As a field of an input object, an absent Optional is left out of the
+// object sent as a variable, whatever its json tag. Input objects with
+// Optional fields are sent as JSON objects of their fields, so their
+// MarshalJSON methods, if any, aren't used.
+type Optional[T any] struct {
+	value T
+	state optionalState
+}
+
+type optionalState uint8
+
+const (
+	absent optionalState = iota
+	null
+	present
+)
+
+// Some returns an Optional with value v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, state: present}
+}
+
+// Null returns an explicitly null Optional.
+func Null[T any]() Optional[T] {
+	return Optional[T]{state: null}
+}
+
+// Get returns the value of o, and whether it has one.
+func (o Optional[T]) Get() (v T, ok bool) {
+	return o.value, o.state == present
+}
+
+// IsNull reports whether o is explicitly null.
+func (o Optional[T]) IsNull() bool {
+	return o.state == null
+}
+
+// IsZero reports whether o is absent.
+func (o Optional[T]) IsZero() bool {
+	return o.state == absent
+}
+
+// MarshalJSON encodes the value of o, or null if it has none.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != present {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (Optional[T]) optionalType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (o Optional[T]) optionalValue() interface{} {
+	if o.state != present {
+		return nil
+	}
+	return o.value
+}
+
+// optional is implemented by Optional types.
+type optional interface {
+	IsZero() bool
+	optionalType() reflect.Type
+	optionalValue() interface{} // Value, or nil if absent or null.
+}
+
+// optionalType returns the value type of t, if t is an Optional type.
+func optionalType(t reflect.Type) (reflect.Type, bool) {
+	if !t.Implements(optionalInterface) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(optional).optionalType(), true
+}
+
+var optionalInterface = reflect.TypeOf((*optional)(nil)).Elem()
+
+// omitAbsent returns variables without absent Optional values, including
+// those in fields of input objects, which are converted to maps of their
+// fields.
+func omitAbsent(variables map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	for k, v := range variables {
+		o, isOptional := v.(optional)
+		absent := isOptional && o.IsZero()
+		if !absent && (v == nil || !hasOptional(reflect.TypeOf(v))) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(variables))
+			for k, v := range variables {
+				out[k] = v
+			}
+		}
+		if absent {
+			delete(out, k)
+		} else {
+			out[k] = withoutAbsent(reflect.ValueOf(v))
+		}
+	}
+	if out == nil {
+		return variables
+	}
+	return out
+}
+
+// optionalTypes caches whether types have Optional values, by reflect.Type.
+var optionalTypes sync.Map
+
+// hasOptional reports whether values of type t may have Optional values,
+// in their elements or fields, as encoded by encoding/json.
+func hasOptional(t reflect.Type) bool {
+	if h, ok := optionalTypes.Load(t); ok {
+		return h.(bool)
+	}
+	h := containsOptional(t, make(map[reflect.Type]bool))
+	optionalTypes.Store(t, h)
+	return h
+}
+
+func containsOptional(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(optionalInterface) {
+		return true
+	}
+	if t.Implements(jsonMarshaler) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsOptional(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" && containsOptional(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// withoutAbsent returns v, which has Optional values, without the absent
+// ones, with structs and maps converted to maps, and lists to slices.
+func withoutAbsent(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !hasOptional(v.Type()) {
+		return v.Interface()
+	}
+	if o, ok := v.Interface().(optional); ok {
+		return withoutAbsent(reflect.ValueOf(o.optionalValue()))
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return withoutAbsent(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = withoutAbsent(v.Index(i))
+		}
+		return list
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			if o, ok := iter.Value().Interface().(optional); ok && o.IsZero() {
+				continue
+			}
+			m[fmt.Sprint(iter.Key().Interface())] = withoutAbsent(iter.Value())
+		}
+		return m
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		addFields(m, v)
+		return m
+	}
+	return v.Interface()
+}
+
+// addFields adds the exported fields of struct v to m, by their JSON
+// names, following the rules of encoding/json, except for the absent
+// Optional ones. Fields of embedded structs are added unless m has their
+// names.
+func addFields(m map[string]interface{}, v reflect.Value) {
+	var embedded []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if f.Anonymous && name == "" {
+			ev := fv
+			for ev.Kind() == reflect.Ptr && !ev.IsNil() {
+				ev = ev.Elem()
+			}
+			if ev.Kind() == reflect.Ptr {
+				continue // Nil embedded struct pointers have no fields.
+			}
+			if ev.Kind() == reflect.Struct {
+				embedded = append(embedded, ev)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if o, ok := fv.Interface().(optional); ok && o.IsZero() {
+			continue
+		}
+		if hasOption(opts, "omitempty") && isEmptyValue(fv) || hasOption(opts, "omitzero") && isZeroValue(fv) {
+			continue
+		}
+		if hasOption(opts, "string") {
+			if b, err := json.Marshal(fv.Interface()); err == nil {
+				m[name] = string(b)
+				continue
+			}
+		}
+		m[name] = withoutAbsent(fv)
+	}
+	for _, ev := range embedded {
+		em := make(map[string]interface{})
+		addFields(em, ev)
+		for k, v := range em {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+	}
+}
+
+// hasOption reports whether comma-separated json tag options opts
+// have option.
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty, as by the omitempty option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isZeroValue reports whether v is zero, as by the omitzero option:
+// whether its IsZero method reports so, if it has one, or it's the
+// zero value of its type otherwise.
+func isZeroValue(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
@@ -0,0 +1,79 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestOptional_variables(t *testing.T) {
+	var body string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body = mustRead(req.Body)
+		mustWrite(w, `{"data": {"updateUser": {"name": "Gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var m struct {
+		UpdateUser struct {
+			Name graphql.String
+		} `graphql:"updateUser(name: $name, bio: $bio, location: $location)"`
+	}
+	err := client.Mutate(context.Background(), &m, map[string]interface{}{
+		"name":     graphql.Some(graphql.String("Gopher")),
+		"bio":      graphql.Null[graphql.String](),
+		"location": graphql.Optional[graphql.String]{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body, `{"query":"mutation($bio:String$location:String$name:String){updateUser(name: $name, bio: $bio, location: $location){name}}","variables":{"bio":null,"name":"Gopher"}}`+"\n"; got != want {
+		t.Errorf("got body:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestOptional_inputField(t *testing.T) {
+	var body string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body = mustRead(req.Body)
+		mustWrite(w, `{"data": {"updateUser": {"name": "Gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type UserInput struct {
+		Name     graphql.Optional[graphql.String] `json:"name"`
+		Bio      graphql.Optional[graphql.String] `json:"bio,omitzero"`
+		Location graphql.Optional[graphql.String]
+		Tags     []graphql.String `json:"tags,omitempty"`
+		Manager  *UserInput       `json:"manager,omitempty"`
+		Ignored  graphql.String   `json:"-"`
+	}
+	var m struct {
+		UpdateUser struct {
+			Name graphql.String
+		} `graphql:"updateUser(input: $input)"`
+	}
+	err := client.Mutate(context.Background(), &m, map[string]interface{}{
+		"input": UserInput{
+			Bio:      graphql.Null[graphql.String](),
+			Location: graphql.Some(graphql.String("Mars")),
+			Manager:  &UserInput{Name: graphql.Some(graphql.String("Boss"))},
+			Ignored:  "x",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body, `{"query":"mutation($input:UserInput!){updateUser(input: $input){name}}","variables":{"input":{"Location":"Mars","bio":null,"manager":{"name":"Boss"}}}}`+"\n"; got != want {
+		t.Errorf("got body:\n%v\nwant:\n%v", got, want)
+	}
+
+	o := graphql.Some(graphql.Int(1))
+	if v, ok := o.Get(); !ok || v != 1 || o.IsNull() || o.IsZero() {
+		t.Errorf("got Some(1): %v, %v, null: %v, zero: %v", v, ok, o.IsNull(), o.IsZero())
+	}
+}
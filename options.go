@@ -0,0 +1,42 @@
+package graphql
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// Logger receives diagnostic messages from a Client.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger sets the logger that receives diagnostic messages,
+// such as warnings about usage of deprecated fields.
+func WithLogger(l Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithSchema enables client-side validation of operations against schema.
+// Operations that fail validation aren't sent, and their Run returns
+// a *ValidationError. Usages of deprecated fields, arguments and enum
+// values are reported as warnings to the client's Logger, once per
+// distinct query document.
+//
+// Variables are coerced into the types the schema expects where they're
+// used: an Int variable may be used as a Float, a String as an enum value,
+// and a single value as a list. Values that can't be coerced, such as
+// strings that aren't values of the expected enum, make Run return
+// a *CoercionError without sending the operation.
+func WithSchema(schema *Schema) ClientOption {
+	return func(c *Client) {
+		c.schema = schema
+	}
+}
+
+// logf logs a diagnostic message, if c has a logger.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
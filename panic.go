@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned when user-supplied code run by the client, such
+// as a RequestHandler, a transport, a codec, a cache, or the UnmarshalJSON
+// or DecodeGraphQL method of a query data structure, panics. The panic is
+// converted to an error, so it fails the operation rather than crashing
+// the program.
+type PanicError struct {
+	Value interface{} // Value passed to panic.
+	Stack []byte      // Stack trace of the goroutine that panicked, as returned by debug.Stack.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("graphql: panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it's an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverPanic converts a panic in the function it's deferred by to a
+// *PanicError stored in *err.
+func recoverPanic(err *error) {
+	if v := recover(); v != nil {
+		if pe, ok := v.(*PanicError); ok {
+			*err = pe
+			return
+		}
+		*err = &PanicError{Value: v, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,127 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// panickyScalar is a custom scalar whose UnmarshalJSON panics.
+type panickyScalar struct{}
+
+func (*panickyScalar) UnmarshalJSON([]byte) error { panic("bad scalar") }
+
+// panickyCache is a graphql.Cache whose Set panics.
+type panickyCache struct{ *graphql.MemoryCache }
+
+func (panickyCache) Set(string, []byte, time.Duration) { panic("bad cache") }
+
+func viewerClient(opts ...graphql.ClientOption) *graphql.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "status": "busy"}}}`)
+	})
+	return graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, opts...)
+}
+
+func TestClient_Run_panicInRequestHandler(t *testing.T) {
+	client := viewerClient()
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	errBad := errors.New("bad handler")
+	err := client.Run(context.Background(), &graphql.Query{
+		Data:           &q,
+		RequestHandler: func(*http.Request) { panic(errBad) },
+	})
+	var pe *graphql.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got error %v, want a *graphql.PanicError", err)
+	}
+	if !errors.Is(err, errBad) {
+		t.Errorf("got error %v, want it to wrap %v", err, errBad)
+	}
+	if got, want := err.Error(), "graphql: panic: bad handler"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+	if !strings.Contains(string(pe.Stack), "panic_test.go") {
+		t.Errorf("got stack without the panicking function:\n%s", pe.Stack)
+	}
+}
+
+func TestClient_Query_panicInScalar(t *testing.T) {
+	client := viewerClient()
+	var q struct {
+		Viewer struct {
+			Login  graphql.String
+			Status panickyScalar
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	var pe *graphql.PanicError
+	if !errors.As(err, &pe) || pe.Value != "bad scalar" {
+		t.Errorf("got error %v, want a *graphql.PanicError of the scalar", err)
+	}
+}
+
+func TestClient_Query_panicInParallelDecode(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": `+batchedData(`{"name": "repo3"}`)+`}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		R0 repo          `graphql:"r0: repository(id: 0)"`
+		R1 repo          `graphql:"r1: repository(id: 1)"`
+		R2 repo          `graphql:"r2: repository(id: 2)"`
+		R3 panickyScalar `graphql:"r3: repository(id: 3)"`
+		R4 *repo         `graphql:"r4: repository(id: 4)"`
+	}
+	err := client.Query(context.Background(), &q, nil)
+	var pe *graphql.PanicError
+	if !errors.As(err, &pe) || pe.Value != "bad scalar" {
+		t.Errorf("got error %v, want a *graphql.PanicError of the scalar", err)
+	}
+}
+
+func TestClient_Query_panicInTransform(t *testing.T) {
+	client := viewerClient(graphql.WithResponseTransform(func(io.Reader) io.Reader { panic("bad transform") }))
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	var pe *graphql.PanicError
+	if !errors.As(err, &pe) || pe.Value != "bad transform" {
+		t.Errorf("got error %v, want a *graphql.PanicError of the transform", err)
+	}
+}
+
+func TestClient_Prefetch_panic(t *testing.T) {
+	var logs logRecorder
+	client := viewerClient(graphql.WithCache(panickyCache{graphql.NewMemoryCache()}, time.Minute), graphql.WithLogger(&logs))
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if _, err := client.Prefetch(context.Background(), graphql.NewQuery(&q, nil)); err != nil {
+		t.Fatal(err)
+	}
+	client.Close() // Waits for the prefetch.
+	if len(logs) != 1 || !strings.Contains(logs[0], "graphql: panic in background work: bad cache") {
+		t.Errorf("got logs %q", logs)
+	}
+}
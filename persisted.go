@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Persisted is an operation sent to the server by the ID of its document,
+// as a trusted document (also known as a persisted query), instead of
+// by its query. The server must know the document by that ID.
+type Persisted struct {
+	Operation
+	ID string // Document ID.
+}
+
+// DocumentID returns op.ID.
+func (op *Persisted) DocumentID() string {
+	return op.ID
+}
+
+// OperationName returns the operation name of op's underlying
+// operation, if it has one.
+func (op *Persisted) OperationName() string {
+	if named, ok := asOperation[NamedOperation](op.Operation); ok {
+		return named.OperationName()
+	}
+	return ""
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *Persisted) Unwrap() Operation {
+	return op.Operation
+}
+
+// Manifest is a persisted operations manifest, mapping document IDs
+// to the documents of trusted operations.
+type Manifest struct {
+	docs map[string]string // Document ID -> document.
+	ids  map[string]string // Document -> document ID.
+}
+
+// NewManifest returns a manifest of documents, keyed by document ID.
+func NewManifest(documents map[string]string) *Manifest {
+	m := &Manifest{
+		docs: make(map[string]string, len(documents)),
+		ids:  make(map[string]string, len(documents)),
+	}
+	for id, doc := range documents {
+		m.docs[id] = doc
+		m.ids[doc] = id
+	}
+	return m
+}
+
+// ParseManifest parses a manifest in the JSON format used by Relay,
+// an object mapping document IDs to documents:
+//
+//	{"4f2c…": "query Viewer{viewer{login}}"}
+func ParseManifest(data []byte) (*Manifest, error) {
+	var documents map[string]string
+	if err := json.Unmarshal(data, &documents); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	return NewManifest(documents), nil
+}
+
+// ID returns the document ID of document doc, if m contains it.
+func (m *Manifest) ID(doc string) (id string, ok bool) {
+	id, ok = m.ids[doc]
+	return id, ok
+}
+
+// Document returns the document with ID id, if m contains it.
+func (m *Manifest) Document(id string) (doc string, ok bool) {
+	doc, ok = m.docs[id]
+	return doc, ok
+}
+
+// WithManifest makes the client send operations whose query is
+// in manifest m by their document ID, instead of by their query.
+func WithManifest(m *Manifest) ClientOption {
+	return func(c *Client) {
+		c.manifest = m
+	}
+}
+
+// documentID returns the document ID to send op by, or "" if op
+// should be sent by its query.
+func (c *Client) documentID(op Operation, query string) string {
+	if op, ok := asOperation[interface{ DocumentID() string }](op); ok {
+		return op.DocumentID()
+	}
+	if c.manifest != nil {
+		id, _ := c.manifest.ID(query)
+		return id
+	}
+	return ""
+}
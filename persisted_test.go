@@ -0,0 +1,69 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_persisted(t *testing.T) {
+	var bodies []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		bodies = append(bodies, mustRead(req.Body))
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	manifest, err := graphql.ParseManifest([]byte(`{"viewer-1": "{viewer{login}}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithManifest(manifest),
+		graphql.WithOperationNameInBody(),
+	)
+
+	ops := []graphql.Operation{
+		graphql.NewQuery(new(viewerQuery), nil),
+		&graphql.Persisted{Operation: &graphql.Query{Data: new(viewerQuery), Name: "V"}, ID: "v-2"},
+		graphql.NewQuery(new(viewerQuery), map[string]interface{}{"unused": graphql.Int(1)}),
+	}
+	for _, op := range ops {
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+		if got := op.ResponsePtr().(*viewerQuery).Viewer.Login; got != "gopher" {
+			t.Errorf("got login: %q, want: %q", got, "gopher")
+		}
+	}
+	want := []string{
+		`{"documentId":"viewer-1"}`,
+		`{"documentId":"v-2","operationName":"V"}`,
+		`{"query":"query($unused:Int!){viewer{login}}","variables":{"unused":1}}`,
+	}
+	for i := range want {
+		if bodies[i] != want[i]+"\n" {
+			t.Errorf("request %d:\ngot:  %s\nwant: %s", i, bodies[i], want[i])
+		}
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	m, err := graphql.ParseManifest([]byte(`{"a": "{a}", "b": "{b}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := m.ID("{b}"); !ok || id != "b" {
+		t.Errorf("got ID: %q, %v, want: %q, true", id, ok, "b")
+	}
+	if doc, ok := m.Document("a"); !ok || doc != "{a}" {
+		t.Errorf("got document: %q, %v, want: %q, true", doc, ok, "{a}")
+	}
+	if _, ok := m.ID("{c}"); ok {
+		t.Error("got ok for unknown document")
+	}
+	if _, err := graphql.ParseManifest([]byte(`[]`)); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
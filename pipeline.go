@@ -0,0 +1,294 @@
+package graphql
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// ErrPipelineClosed is returned for operations run on a Pipeline that's
+// closed, or that ended before their responses were received.
+var ErrPipelineClosed = errors.New("graphql: pipeline closed")
+
+// Pipeline sends many operations on one HTTP request to an endpoint
+// accepting newline-delimited JSON (NDJSON) streams of requests, such as
+// batch endpoints of some gateways, for bulk workloads. Each line of the
+// request body is a GraphQL request with an "id" member, and each line of
+// the response body is the response to the request with the same "id",
+// in any order:
+//
+//	→ {"id":"1","query":"{viewer{login}}"}
+//	← {"id":"1","data":{"viewer":{"login":"gopher"}}}
+//
+// Operations are sent as soon as they're run, without waiting for the
+// responses to earlier ones, so the HTTP transport must support reading
+// the response while the request is written, as HTTP/2 does.
+//
+// Pipelines are opened with one HTTP request, so headers set per request,
+// such as by ModifyRequest methods of operations, aren't sent, and can't
+// be opened by clients with a custom Transport, a codec other than
+// JSONCodec, strict mode or a request transform, or run operations with
+// file uploads.
+//
+// A Pipeline is safe for concurrent use by multiple goroutines.
+type Pipeline struct {
+	client *Client
+
+	wmu sync.Mutex // Guards w.
+	w   *io.PipeWriter
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]chan<- Response
+	err     error // Error that ended the pipeline, if it has ended.
+
+	done chan struct{} // Closed once the pipeline has ended.
+}
+
+// OpenPipeline opens a Pipeline to endpoint, absolute or relative to the
+// client's URL, or to the client's URL if it's "". If the client's URL is
+// a template, it's resolved with the parameters carried by ctx, set with
+// WithURLParams. The pipeline ends when it's closed, when ctx is done, or
+// when the client is closed.
+func (c *Client) OpenPipeline(ctx context.Context, endpoint string) (*Pipeline, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	if err := c.checkPipeline(); err != nil {
+		return nil, err
+	}
+	server, err := expandURL(ctx, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	req, err := http.NewRequest(http.MethodPost, u.ResolveReference(ref).String(), r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Accept", "application/x-ndjson")
+	c.setDeadlineHeader(ctx, req)
+	c.loadCookies(req.URL)
+	if err := c.setCSRFHeader(ctx, req); err != nil {
+		return nil, err
+	}
+	p := &Pipeline{
+		client:  c,
+		w:       w,
+		pending: make(map[string]chan<- Response),
+		done:    make(chan struct{}),
+	}
+	c.goBackground(ctx, func(ctx context.Context) {
+		err := p.read(ctx, req.WithContext(ctx))
+		r.CloseWithError(ErrPipelineClosed)
+		p.end(err)
+	})
+	return p, nil
+}
+
+// checkPipeline checks that c has no options pipelines can't honor.
+func (c *Client) checkPipeline() error {
+	_, jsonCodec := c.codec.(JSONCodec)
+	var option string
+	switch {
+	case c.customTransport != nil:
+		option = "a custom transport"
+	case !jsonCodec:
+		option = fmt.Sprintf("codec %T", c.codec)
+	case c.strict:
+		option = "strict mode"
+	case c.requestTransform != nil:
+		option = "a request transform"
+	default:
+		return nil
+	}
+	return fmt.Errorf("graphql: cannot open pipeline with %s", option)
+}
+
+// read sends req, and dispatches the responses in its response body
+// to the operations waiting for them, until it ends.
+func (p *Pipeline) read(ctx context.Context, req *http.Request) error {
+	c := p.client
+	c.refreshDNS()
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if len(resp.Header["Set-Cookie"]) > 0 {
+		c.saveCookies(req.URL)
+	}
+	if err := decodeContentEncoding(resp); err != nil {
+		return err
+	}
+	if c.responseTransform != nil {
+		resp.Body = ioutil.NopCloser(c.responseTransform(resp.Body))
+	}
+	if c.statusPolicy(resp.StatusCode) != StatusGraphQL {
+		return c.statusError(resp)
+	}
+	meta := ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header}
+	s := bufio.NewScanner(resp.Body)
+	s.Buffer(nil, 64<<20)
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var line struct {
+			ID string `json:"id"`
+			Response
+		}
+		if err := json.Unmarshal(s.Bytes(), &line); err != nil {
+			return err
+		}
+		if string(line.Data) == "null" {
+			line.Data = nil
+		}
+		line.HTTP = meta
+		p.mu.Lock()
+		ch, ok := p.pending[line.ID]
+		delete(p.pending, line.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- line.Response
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return ErrPipelineClosed
+}
+
+// end ends p with err, failing the operations waiting for responses.
+func (p *Pipeline) end(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+	p.pending = nil
+	close(p.done)
+}
+
+// Run runs op on p, as Client.Run does. Responses aren't cached.
+func (p *Pipeline) Run(ctx context.Context, op Operation) error {
+	out, err := p.do(ctx, op)
+	if err != nil {
+		return err
+	}
+	if len(out.Errors) > 0 {
+		return out.Errors
+	}
+	return nil
+}
+
+func (p *Pipeline) do(ctx context.Context, op Operation) (out Response, err error) {
+	defer recoverPanic(&err)
+	c := p.client
+	if c.autoName {
+		op = nameOperation(op)
+	}
+	in, _, err := c.request(ctx, op)
+	if err != nil {
+		return out, err
+	}
+	if id := c.documentID(op, in.Query); id != "" {
+		in.Query, in.DocumentID = "", id
+	}
+	if err := c.checkAllowed(in); err != nil {
+		return out, err
+	}
+	if len(findUploads(in.Variables)) > 0 {
+		return out, errors.New("graphql: cannot send uploads on a pipeline")
+	}
+	in.Variables = c.stringifyInt64s(omitAbsent(in.Variables))
+
+	ch := make(chan Response, 1)
+	p.mu.Lock()
+	if p.pending == nil {
+		p.mu.Unlock()
+		return out, p.closedErr()
+	}
+	p.nextID++
+	id := strconv.FormatUint(p.nextID, 10)
+	p.pending[id] = ch
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		if p.pending != nil {
+			delete(p.pending, id)
+		}
+		p.mu.Unlock()
+	}()
+
+	line, err := json.Marshal(struct {
+		ID string `json:"id"`
+		Request
+	}{id, in})
+	if err != nil {
+		return out, err
+	}
+	p.wmu.Lock()
+	_, err = p.w.Write(append(line, '\n'))
+	p.wmu.Unlock()
+	if err != nil {
+		return out, p.closedErr()
+	}
+
+	select {
+	case out = <-ch:
+	case <-p.done:
+		select {
+		case out = <-ch:
+		default:
+			return out, p.closedErr()
+		}
+	case <-ctx.Done():
+		return out, ctx.Err()
+	}
+	if out.Data != nil {
+		if err := c.decodeData(out.Data, op.ResponsePtr()); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// closedErr returns the error that ended p, if any, or ErrPipelineClosed.
+func (p *Pipeline) closedErr() error {
+	<-p.done
+	if p.err != nil {
+		return p.err
+	}
+	return ErrPipelineClosed
+}
+
+// Close ends the request stream of p, and waits for the responses to
+// the operations run on it, and for the pipeline to end. It returns
+// the error the pipeline ended with, if any other than the end of the
+// response stream.
+func (p *Pipeline) Close() error {
+	p.wmu.Lock()
+	p.w.Close()
+	p.wmu.Unlock()
+	<-p.done
+	if errors.Is(p.err, ErrPipelineClosed) {
+		return nil
+	}
+	return p.err
+}
@@ -0,0 +1,164 @@
+package graphql_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+// newPipelineServer returns an HTTP/2 server of NDJSON streams of
+// operations, which responds to each request as soon as it's read,
+// echoing the login variable.
+func newPipelineServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got := req.Header.Get("Content-Type"); got != "application/x-ndjson" {
+			t.Errorf("got Content-Type %q, want application/x-ndjson", got)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.(http.Flusher).Flush()
+		s := bufio.NewScanner(req.Body)
+		for s.Scan() {
+			var in struct {
+				ID        string
+				Query     string
+				Variables map[string]interface{}
+			}
+			if err := json.Unmarshal(s.Bytes(), &in); err != nil {
+				t.Error(err)
+				return
+			}
+			login := in.Variables["login"]
+			if login == "missing" {
+				fmt.Fprintf(w, `{"id": %q, "data": {"user": null}, "errors": [{"message": "user not found"}]}`+"\n", in.ID)
+			} else {
+				fmt.Fprintf(w, `{"id": %q, "data": {"user": {"login": %q}}}`+"\n", in.ID, login)
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type pipelineUser struct {
+	User *struct {
+		Login graphql.String
+	} `graphql:"user(login: $login)"`
+}
+
+func TestPipeline(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	srv := newPipelineServer(t)
+	client := graphql.NewClient(srv.URL+"/graphql", srv.Client())
+	t.Cleanup(func() { client.Close() })
+
+	p, err := client.OpenPipeline(context.Background(), "/batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(login string) {
+			defer wg.Done()
+			var q pipelineUser
+			err := p.Run(context.Background(), graphql.NewQuery(&q, map[string]interface{}{"login": graphql.String(login)}))
+			if err != nil {
+				t.Errorf("%s: %v", login, err)
+				return
+			}
+			if q.User == nil || string(q.User.Login) != login {
+				t.Errorf("got user %v, want %s", q.User, login)
+			}
+		}(fmt.Sprint("user", i))
+	}
+	wg.Wait()
+
+	var q pipelineUser
+	err = p.Run(context.Background(), graphql.NewQuery(&q, map[string]interface{}{"login": graphql.String("missing")}))
+	var errs graphql.ErrorList
+	if !errors.As(err, &errs) || errs[0].Message != "user not found" {
+		t.Errorf("got error %v, want %q", err, "user not found")
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	err = p.Run(context.Background(), graphql.NewQuery(&q, map[string]interface{}{"login": graphql.String("late")}))
+	if err != graphql.ErrPipelineClosed {
+		t.Errorf("got error %v after Close, want %v", err, graphql.ErrPipelineClosed)
+	}
+}
+
+func TestPipelineStatusError(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "batching disabled", http.StatusNotFound)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	client := graphql.NewClient(srv.URL, srv.Client())
+	t.Cleanup(func() { client.Close() })
+
+	p, err := client.OpenPipeline(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var q pipelineUser
+	err = p.Run(context.Background(), graphql.NewQuery(&q, map[string]interface{}{"login": graphql.String("gopher")}))
+	var statusErr *graphql.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("got error %v, want a 404 *graphql.StatusError", err)
+	}
+	if err := p.Close(); !errors.As(err, &statusErr) || !strings.Contains(err.Error(), "404") {
+		t.Errorf("got Close error %v, want the status error", err)
+	}
+}
+
+func TestPipelineAllowList(t *testing.T) {
+	graphqltest.VerifyNoLeaks(t)
+	srv := newPipelineServer(t)
+	allowed := graphql.NewQuery(&pipelineUser{}, map[string]interface{}{"login": graphql.String("gopher")})
+	client := graphql.NewClient(srv.URL, srv.Client(),
+		graphql.WithAllowList(graphql.NewManifest(map[string]string{"user-1": allowed.Query()})))
+	t.Cleanup(func() { client.Close() })
+
+	p, err := client.OpenPipeline(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Run(context.Background(), allowed); err != nil {
+		t.Errorf("allowed operation: %v", err)
+	}
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var policyErr *graphql.PolicyError
+	if err := p.Run(context.Background(), graphql.NewQuery(&q, nil)); !errors.As(err, &policyErr) {
+		t.Errorf("got error %v for operation not in the allow-list, want a *graphql.PolicyError", err)
+	}
+}
+
+func TestPipelineUnsupportedOptions(t *testing.T) {
+	client := graphql.NewClient("https://example.com/graphql", nil, graphql.WithStrictHTTP())
+	defer client.Close()
+	if _, err := client.OpenPipeline(context.Background(), ""); err == nil || !strings.Contains(err.Error(), "strict mode") {
+		t.Errorf("got error %v, want one about strict mode", err)
+	}
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns how long to wait before retry attempt, from 1.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff returns a Backoff waiting initial before the first
+// retry, doubled before each next one, up to max. Waits are jittered, to
+// between half and all of them, so clients don't retry in lockstep.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if half := int64(d / 2); half > 0 {
+			d = time.Duration(half + rand.Int63n(half+1))
+		}
+		return d
+	}
+}
+
+// ConstantBackoff returns a Backoff waiting d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// DefaultPollBackoff is the Backoff of PollUntil if it's given none.
+var DefaultPollBackoff = ExponentialBackoff(time.Second, 30*time.Second)
+
+// PollUntil runs op repeatedly, such as a query of the status of an
+// asynchronous job, until isDone reports that it's done, waiting between
+// runs as backoff says, measured with the client's Clock. isDone is called
+// with op.ResponsePtr(), once op's response has been decoded into it.
+// It returns the first error of a run, or the error of ctx if it's done
+// first. If backoff is nil, DefaultPollBackoff is used. Runs bypass the
+// client's cache, as with the ForceNetwork fetch policy, so each sees the
+// current state.
+//
+//	var q struct {
+//		Job struct {
+//			Status String
+//		} `graphql:"job(id: $id)"`
+//	}
+//	err := client.PollUntil(ctx, graphql.NewQuery(&q, vars), func(interface{}) bool {
+//		return q.Job.Status == "DONE"
+//	}, nil)
+func (c *Client) PollUntil(ctx context.Context, op Operation, isDone func(data interface{}) bool, backoff Backoff) error {
+	if backoff == nil {
+		backoff = DefaultPollBackoff
+	}
+	polled := WithFetchPolicy(op, ForceNetwork)
+	for attempt := 1; ; attempt++ {
+		if err := c.Run(ctx, polled); err != nil {
+			return err
+		}
+		if isDone(op.ResponsePtr()) {
+			return nil
+		}
+		if err := c.sleep(ctx, backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d to elapse, measured with c's clock, or for ctx
+// to be done, returning its error.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	elapsed := make(chan struct{})
+	t := c.clock.AfterFunc(d, func() { close(elapsed) })
+	select {
+	case <-elapsed:
+		return nil
+	case <-ctx.Done():
+		t.Stop()
+		return ctx.Err()
+	}
+}
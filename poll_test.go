@@ -0,0 +1,88 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestPollUntil(t *testing.T) {
+	statuses := []string{"PENDING", "RUNNING", "DONE"}
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		status := statuses[requests]
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"job": {"status": "`+status+`"}}}`)
+	})
+	// Polls aren't served from the cache.
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Hour))
+
+	var q struct {
+		Job struct {
+			Status graphql.String
+		} `graphql:"job(id: 1)"`
+	}
+	op := graphql.NewQuery(&q, nil)
+	var polled []graphql.String
+	err := client.PollUntil(context.Background(), op, func(data interface{}) bool {
+		if data != &q {
+			t.Errorf("got data %v, want the query's", data)
+		}
+		polled = append(polled, q.Job.Status)
+		return q.Job.Status == "DONE"
+	}, graphql.ConstantBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polled) != 3 || polled[2] != "DONE" {
+		t.Errorf("got statuses %q, want %q", polled, statuses)
+	}
+}
+
+func TestPollUntilContextDone(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"job": {"status": "RUNNING"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		Job struct {
+			Status graphql.String
+		} `graphql:"job(id: 1)"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := client.PollUntil(ctx, graphql.NewQuery(&q, nil), func(interface{}) bool { return false }, graphql.ConstantBackoff(time.Millisecond))
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := graphql.ExponentialBackoff(100*time.Millisecond, time.Second)
+	for _, tc := range []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	} {
+		for i := 0; i < 10; i++ {
+			if d := backoff(tc.attempt); d < tc.max/2 || d > tc.max {
+				t.Errorf("attempt %d: got %v, want between %v and %v", tc.attempt, d, tc.max/2, tc.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// PoolKey identifies a client in a ClientPool.
+type PoolKey struct {
+	Tenant string
+	URL    string // GraphQL server URL.
+	Token  string // Credential used by the client, if any.
+}
+
+// ClientPool creates configured clients on demand and caches them by key,
+// for multi-tenant services that talk to many GraphQL servers, or to one
+// with many credentials. It's safe for concurrent use.
+type ClientPool struct {
+	newClient  func(PoolKey) (*Client, error)
+	maxClients int
+	idle       time.Duration
+	clock      Clock
+
+	mu       sync.Mutex
+	lru      *list.List // Of *poolEntry, most recently used first.
+	entries  map[PoolKey]*list.Element
+	creating map[PoolKey]*poolCreation // Key -> creation of its client, for clients being created.
+	evicted  []*Client                 // Clients removed, to be closed once mu is unlocked.
+}
+
+// poolCreation is the creation of a client by newClient, which callers
+// of Get for the same key wait for.
+type poolCreation struct {
+	done   chan struct{} // Closed once the client is created, or failed to be.
+	client *Client
+	err    error
+}
+
+type poolEntry struct {
+	key      PoolKey
+	client   *Client
+	lastUsed time.Time
+}
+
+// PoolOption configures a ClientPool.
+type PoolOption func(*ClientPool)
+
+// WithMaxClients limits the number of clients in a pool to n. When a client
+// is created beyond that, the least recently used client is evicted.
+func WithMaxClients(n int) PoolOption {
+	return func(p *ClientPool) {
+		p.maxClients = n
+	}
+}
+
+// WithIdleTimeout makes a pool evict clients that haven't been used for d.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *ClientPool) {
+		p.idle = d
+	}
+}
+
+// WithPoolClock sets the clock that idle timeouts of a pool are measured
+// with. The default is the system clock.
+func WithPoolClock(clock Clock) PoolOption {
+	return func(p *ClientPool) {
+		p.clock = clock
+	}
+}
+
+// NewClientPool returns a pool that creates clients with newClient.
+// Options shared by all clients, such as a logger or status policy,
+// should be applied by newClient.
+func NewClientPool(newClient func(PoolKey) (*Client, error), opts ...PoolOption) *ClientPool {
+	p := &ClientPool{
+		newClient: newClient,
+		clock:     systemClock{},
+		lru:       list.New(),
+		entries:   make(map[PoolKey]*list.Element),
+		creating:  make(map[PoolKey]*poolCreation),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Get returns the client for key, creating it if it's not in the pool.
+// Clients are created without blocking Gets for other keys, and once
+// for concurrent Gets for the same key. Clients evicted from the pool
+// are closed.
+func (p *ClientPool) Get(key PoolKey) (*Client, error) {
+	p.mu.Lock()
+	now := p.clock.Now()
+	p.evictIdle(now)
+	if e, ok := p.entries[key]; ok {
+		entry := e.Value.(*poolEntry)
+		entry.lastUsed = now
+		p.lru.MoveToFront(e)
+		p.unlock()
+		return entry.client, nil
+	}
+	if c, ok := p.creating[key]; ok {
+		p.unlock()
+		<-c.done
+		return c.client, c.err
+	}
+	c := &poolCreation{done: make(chan struct{})}
+	p.creating[key] = c
+	p.unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.creating, key)
+		if c.err == nil && c.client == nil {
+			// newClient panicked, or returned no client.
+			c.err = errors.New("graphql: pool client not created")
+		}
+		if c.err == nil {
+			p.entries[key] = p.lru.PushFront(&poolEntry{key: key, client: c.client, lastUsed: p.clock.Now()})
+			for p.maxClients > 0 && p.lru.Len() > p.maxClients {
+				p.remove(p.lru.Back())
+			}
+		}
+		close(c.done)
+		p.unlock()
+	}()
+	// Create the client without holding p.mu, as creating it may be slow,
+	// such as when it fetches a credential.
+	c.client, c.err = p.newClient(key)
+	return c.client, c.err
+}
+
+// Evict removes the client for key from the pool, if any, such as when
+// its credential is revoked, and closes it.
+func (p *ClientPool) Evict(key PoolKey) {
+	p.mu.Lock()
+	defer p.unlock()
+	if e, ok := p.entries[key]; ok {
+		p.remove(e)
+	}
+}
+
+// Len returns the number of clients in the pool.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.unlock()
+	p.evictIdle(p.clock.Now())
+	return p.lru.Len()
+}
+
+// evictIdle evicts clients idle for longer than p.idle. p.mu must be held.
+func (p *ClientPool) evictIdle(now time.Time) {
+	if p.idle <= 0 {
+		return
+	}
+	for e := p.lru.Back(); e != nil && now.Sub(e.Value.(*poolEntry).lastUsed) > p.idle; e = p.lru.Back() {
+		p.remove(e)
+	}
+}
+
+// Close removes all the clients from the pool, and closes them.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	defer p.unlock()
+	for e := p.lru.Back(); e != nil; e = p.lru.Back() {
+		p.remove(e)
+	}
+	return nil
+}
+
+// remove removes e from the pool, to close its client once p.mu is
+// unlocked. p.mu must be held.
+func (p *ClientPool) remove(e *list.Element) {
+	entry := p.lru.Remove(e).(*poolEntry)
+	delete(p.entries, entry.key)
+	p.evicted = append(p.evicted, entry.client)
+}
+
+// unlock unlocks p.mu, and closes the clients removed while it was held,
+// as closing them waits for their background work.
+func (p *ClientPool) unlock() {
+	evicted := p.evicted
+	p.evicted = nil
+	p.mu.Unlock()
+	for _, client := range evicted {
+		client.Close()
+	}
+}
@@ -0,0 +1,181 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClientPool(t *testing.T) {
+	var created []graphql.PoolKey
+	pool := graphql.NewClientPool(func(key graphql.PoolKey) (*graphql.Client, error) {
+		if key.URL == "" {
+			return nil, errors.New("no URL")
+		}
+		created = append(created, key)
+		return graphql.NewClient(key.URL, nil), nil
+	}, graphql.WithMaxClients(2))
+
+	a := graphql.PoolKey{Tenant: "a", URL: "https://a.example.com/graphql", Token: "ta"}
+	b := graphql.PoolKey{Tenant: "b", URL: "https://b.example.com/graphql", Token: "tb"}
+	c := graphql.PoolKey{Tenant: "c", URL: "https://c.example.com/graphql", Token: "tc"}
+
+	ca, err := pool.Get(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := pool.Get(a); again != ca {
+		t.Error("got a different client for the same key")
+	}
+	pool.Get(b)
+	pool.Get(a) // Make b the least recently used.
+	pool.Get(c) // Evicts b.
+	if got, want := pool.Len(), 2; got != want {
+		t.Errorf("got %d clients, want %d", got, want)
+	}
+	pool.Get(b)
+	if got, want := len(created), 4; got != want {
+		t.Errorf("created %d clients, want %d: %v", got, want, created)
+	}
+
+	pool.Evict(b)
+	if got, want := pool.Len(), 1; got != want {
+		t.Errorf("got %d clients after Evict, want %d", got, want)
+	}
+	if _, err := pool.Get(graphql.PoolKey{Tenant: "d"}); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestClientPool_idleTimeout(t *testing.T) {
+	pool := graphql.NewClientPool(func(key graphql.PoolKey) (*graphql.Client, error) {
+		return graphql.NewClient(key.URL, nil), nil
+	}, graphql.WithIdleTimeout(10*time.Millisecond))
+
+	pool.Get(graphql.PoolKey{URL: "https://example.com/graphql"})
+	if got, want := pool.Len(), 1; got != want {
+		t.Errorf("got %d clients, want %d", got, want)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got, want := pool.Len(), 0; got != want {
+		t.Errorf("got %d clients after idle timeout, want %d", got, want)
+	}
+}
+
+func TestClientPool_closesEvicted(t *testing.T) {
+	var mu sync.Mutex
+	open := 0
+	closed := make(chan struct{}, 10)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch state {
+		case http.StateNew:
+			open++
+		case http.StateClosed:
+			open--
+			closed <- struct{}{}
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	pool := graphql.NewClientPool(func(key graphql.PoolKey) (*graphql.Client, error) {
+		return graphql.NewClient(key.URL, &http.Client{}, graphql.WithKeepAlive(graphql.GatewayKeepAlive)), nil
+	}, graphql.WithMaxClients(1))
+	defer pool.Close()
+	query := func(key graphql.PoolKey) {
+		t.Helper()
+		client, err := pool.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var q struct {
+			Viewer struct{ Login graphql.String }
+		}
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitClosed := func() {
+		t.Helper()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("connection of evicted client not closed")
+		}
+	}
+
+	query(graphql.PoolKey{Tenant: "a", URL: srv.URL})
+	query(graphql.PoolKey{Tenant: "b", URL: srv.URL}) // Evicts a.
+	waitClosed()
+	pool.Close()
+	waitClosed()
+	mu.Lock()
+	defer mu.Unlock()
+	if open != 0 {
+		t.Errorf("got %d open connections after Close, want 0", open)
+	}
+}
+
+func TestClientPool_createsOutsideLock(t *testing.T) {
+	a := graphql.PoolKey{Tenant: "a", URL: "https://a.example.com/graphql"}
+	b := graphql.PoolKey{Tenant: "b", URL: "https://b.example.com/graphql"}
+	var mu sync.Mutex
+	created := make(map[graphql.PoolKey]int)
+	started, release := make(chan struct{}), make(chan struct{})
+	pool := graphql.NewClientPool(func(key graphql.PoolKey) (*graphql.Client, error) {
+		mu.Lock()
+		created[key]++
+		mu.Unlock()
+		if key == a {
+			close(started)
+			<-release // Creating a is slow.
+		}
+		return graphql.NewClient(key.URL, nil), nil
+	})
+	defer pool.Close()
+
+	clients := make([]*graphql.Client, 5)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := pool.Get(a)
+			if err != nil {
+				t.Error(err)
+			}
+			clients[i] = client
+		}(i)
+	}
+	// Clients for other keys are created while a's is.
+	<-started
+	if _, err := pool.Get(b); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	wg.Wait()
+	for _, client := range clients {
+		if client == nil || client != clients[0] {
+			t.Fatalf("got clients %v, want the same one", clients)
+		}
+	}
+	if got, _ := pool.Get(a); got != clients[0] {
+		t.Error("got a different client for a once created")
+	}
+	if created[a] != 1 || created[b] != 1 {
+		t.Errorf("got clients created %v, want one for each key", created)
+	}
+}
@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// prefetch is a query response being prefetched into the cache.
+type prefetch struct {
+	done    chan struct{} // Closed once the response is cached, or failed to be.
+	expires time.Time     // When the cached response expires, once cached, if it does.
+}
+
+// expired reports whether the response cached by p has expired at now.
+func (p *prefetch) expired(now time.Time) bool {
+	return !p.expires.IsZero() && !now.Before(p.expires)
+}
+
+// Prefetch runs query op in the background and stores its response in
+// the client's cache, so a later run of an identical query is served
+// from the cache without waiting for the server. If the later run starts
+// while the prefetch is still in flight, it waits for the prefetch rather
+// than sending the query again. The data structure of op isn't modified.
+//
+// The prefetch is canceled when ctx is done, when cancel is called,
+// or when the client is closed.
+// Calling cancel also evicts the prefetched response from the cache if
+// no run has used it, so results that are never consumed don't linger.
+// Once the prefetched response expires, the prefetch is forgotten, and
+// calling cancel has no effect on the cache.
+//
+// It returns an error if the client has no cache, set with WithCache,
+// or op isn't a query. Errors of the query itself aren't reported.
+func (c *Client) Prefetch(ctx context.Context, op Operation) (cancel func(), err error) {
+	defer recoverPanic(&err)
+	if c.cache == nil {
+		return nil, errors.New("graphql: cannot prefetch without a cache")
+	}
+	if c.autoName {
+		op = nameOperation(op)
+	}
+	in, endpoint, err := c.request(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	key := c.cacheKey(ctx, op, endpoint, in)
+	if key == "" {
+		return nil, errors.New("graphql: cannot prefetch an operation that isn't cached")
+	}
+	if id := c.documentID(op, in.Query); id != "" {
+		in.Query, in.DocumentID = "", id
+	}
+
+	ctx, cancelCtx := context.WithCancel(ctx)
+	p := &prefetch{done: make(chan struct{})}
+	c.prefetchMu.Lock()
+	if c.prefetches == nil {
+		c.prefetches = make(map[string]*prefetch)
+	}
+	// Sweep expired prefetches once in a while, so unused ones don't accumulate.
+	if c.prefetchSets++; c.prefetchSets >= len(c.prefetches) {
+		c.prefetchSets = 0
+		now := c.clock.Now()
+		for k, p := range c.prefetches {
+			if p.expired(now) {
+				delete(c.prefetches, k)
+			}
+		}
+	}
+	c.prefetches[key] = p
+	c.prefetchMu.Unlock()
+	c.goBackground(ctx, func(ctx context.Context) {
+		defer close(p.done)
+		out, err := c.roundTrip(ctx, endpoint, in, c.modifier(op), nil)
+		if err == nil {
+			c.store(op, key, out)
+		}
+		if err != nil || out.Data == nil || len(out.Errors) > 0 {
+			c.removePrefetch(key, p)
+			return
+		}
+		ttl, ok := c.entryTTL(op, out)
+		if !ok {
+			c.removePrefetch(key, p)
+			return
+		}
+		if ttl > 0 {
+			c.prefetchMu.Lock()
+			p.expires = c.clock.Now().Add(ttl)
+			c.prefetchMu.Unlock()
+		}
+	})
+	return func() {
+		cancelCtx()
+		if c.removePrefetch(key, p) {
+			c.evict(key)
+		}
+	}, nil
+}
+
+// pendingPrefetch returns the prefetch of the response for key,
+// if it hasn't been used, and hasn't expired.
+func (c *Client) pendingPrefetch(key string) *prefetch {
+	c.prefetchMu.Lock()
+	defer c.prefetchMu.Unlock()
+	p := c.prefetches[key]
+	if p != nil && p.expired(c.clock.Now()) {
+		delete(c.prefetches, key)
+		return nil
+	}
+	return p
+}
+
+// usePrefetch marks the prefetch of the response for key, if any, as used.
+func (c *Client) usePrefetch(key string) {
+	c.prefetchMu.Lock()
+	delete(c.prefetches, key)
+	c.prefetchMu.Unlock()
+}
+
+// removePrefetch removes prefetch p of the response for key, and reports
+// whether it was still unused.
+func (c *Client) removePrefetch(key string, p *prefetch) bool {
+	c.prefetchMu.Lock()
+	defer c.prefetchMu.Unlock()
+	if c.prefetches[key] != p {
+		return false
+	}
+	delete(c.prefetches, key)
+	return true
+}
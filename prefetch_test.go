@@ -0,0 +1,135 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+func TestClient_Prefetch(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithCache(graphql.NewMemoryCache(), time.Minute))
+
+	type query struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var prefetched query
+	cancel, err := client.Prefetch(context.Background(), graphql.NewQuery(&prefetched, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	// The query waits for the prefetch in flight rather than sending another request.
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+	var q query
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %v, want: %v", got, want)
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(1); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+	if prefetched.Viewer.Login != "" {
+		t.Error("prefetch modified the data structure of its operation")
+	}
+}
+
+func TestClient_Prefetch_cancel(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	cache := graphql.NewMemoryCache()
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithCache(cache, time.Minute))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	cancel, err := client.Prefetch(context.Background(), graphql.NewQuery(&q, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel() // The prefetched response was never used, so it's evicted.
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(2); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+
+	if _, err := client.Prefetch(context.Background(), graphql.NewMutation(&q, nil)); err == nil {
+		t.Error("got no error prefetching a mutation")
+	}
+	if _, err := graphql.NewClient("/graphql", nil).Prefetch(context.Background(), graphql.NewQuery(&q, nil)); err == nil {
+		t.Error("got no error prefetching without a cache")
+	}
+}
+
+func TestClient_Prefetch_expired(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	clock := graphqltest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithClock(clock),
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+	)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	cancel, err := client.Prefetch(context.Background(), graphql.NewQuery(&q, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// Once the prefetched response expires, the prefetch is forgotten, so
+	// canceling it doesn't evict the response cached by a later run.
+	clock.Advance(2 * time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(2); got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
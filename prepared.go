@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prepared is an operation prepared by Client.Prepare. Its query document
+// and variable types are fixed when it's prepared, so running it doesn't
+// need to reflect over the query data structure again. It's safe for
+// concurrent use by multiple goroutines.
+type Prepared struct {
+	client         *Client
+	query          string
+	name           string
+	data           reflect.Type      // Type of data structure, or nil if unknown.
+	vars           map[string]string // Variable name -> GraphQL type.
+	requestHandler RequestHandlerFunc
+}
+
+// Prepare prepares op for repeated use. The query document of op is
+// constructed once, and the names and GraphQL types of its variables
+// are taken from the variables of op. If the client validates operations
+// against a schema, op is validated by Prepare.
+//
+// The data structure and variable values of op are not retained;
+// they're given to every call of Prepared.Run instead.
+func (c *Client) Prepare(op Operation) (*Prepared, error) {
+	if c.autoName {
+		op = nameOperation(op)
+	}
+	p := &Prepared{
+		client: c,
+		query:  op.Query(),
+		vars:   make(map[string]string),
+	}
+	if op, ok := op.(NamedOperation); ok {
+		p.name = op.OperationName()
+	}
+	if data := op.ResponsePtr(); data != nil {
+		p.data = reflect.TypeOf(data)
+	}
+	for k, v := range op.Variables() {
+		if v == nil {
+			return nil, fmt.Errorf("cannot prepare variable %q with nil value of unknown type", k)
+		}
+		p.vars[k] = argumentType(reflect.TypeOf(v))
+	}
+	switch op := op.(type) {
+	case *Query:
+		p.requestHandler = op.RequestHandler
+	case *Mutation:
+		p.requestHandler = op.RequestHandler
+	case *Static:
+		p.requestHandler = op.RequestHandler
+	}
+	if c.schema != nil {
+		p.query = c.coercion(p.query).query
+		if err := c.validate(op, p.query); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Query returns the query document of p.
+func (p *Prepared) Query() string {
+	return p.query
+}
+
+// Run executes p with variables, populating the response into data,
+// which must be of the same type as the data structure of the prepared
+// operation. variables must have the same types as the variables of
+// the prepared operation, and may omit only nullable ones.
+func (p *Prepared) Run(ctx context.Context, data interface{}, variables map[string]interface{}) error {
+	if p.data != nil && reflect.TypeOf(data) != p.data {
+		return fmt.Errorf("prepared operation decodes into %v, not %T", p.data, data)
+	}
+	if err := p.checkVariables(variables); err != nil {
+		return err
+	}
+	return p.client.Run(ctx, &Static{
+		QueryStr:       p.query,
+		Into:           data,
+		Vars:           variables,
+		Name:           p.name,
+		RequestHandler: p.requestHandler,
+	})
+}
+
+// checkVariables checks that variables match the variables of p.
+func (p *Prepared) checkVariables(variables map[string]interface{}) error {
+	var errs []string
+	for k, v := range variables {
+		typ, ok := p.vars[k]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("unknown variable %q", k))
+		case v == nil:
+			if strings.HasSuffix(typ, "!") {
+				errs = append(errs, fmt.Sprintf("variable %q of required type %s is nil", k, typ))
+			}
+		default:
+			if got := argumentType(reflect.TypeOf(v)); got != typ {
+				errs = append(errs, fmt.Sprintf("variable %q has type %s, want %s", k, got, typ))
+			}
+		}
+	}
+	for k, typ := range p.vars {
+		if _, ok := variables[k]; !ok && strings.HasSuffix(typ, "!") {
+			errs = append(errs, fmt.Sprintf("missing variable %q of required type %s", k, typ))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("invalid variables for prepared operation: %s", strings.Join(errs, "; "))
+}
+
+// argumentType returns the minified GraphQL type for Go type t.
+func argumentType(t reflect.Type) string {
+	var buf bytes.Buffer
+	writeArgumentType(&buf, t, true)
+	return buf.String()
+}
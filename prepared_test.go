@@ -0,0 +1,92 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+type repoQuery struct {
+	Repository struct {
+		Name graphql.String
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+func TestClient_Prepare(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query($name:String!$owner:String!){repository(owner: $owner, name: $name){name}}","variables":{"name":"graphql","owner":"shurcooL"}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		mustWrite(w, `{"data": {"repository": {"name": "graphql"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	p, err := client.Prepare(graphql.NewQuery(new(repoQuery), map[string]interface{}{
+		"owner": graphql.String(""),
+		"name":  graphql.String(""),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var q repoQuery
+			err := p.Run(context.Background(), &q, map[string]interface{}{
+				"owner": graphql.String("shurcooL"),
+				"name":  graphql.String("graphql"),
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got, want := q.Repository.Name, graphql.String("graphql"); got != want {
+				t.Errorf("got name: %q, want: %q", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPrepared_Run_invalid(t *testing.T) {
+	client := graphql.NewClient("/graphql", nil)
+	p, err := client.Prepare(graphql.NewQuery(new(repoQuery), map[string]interface{}{
+		"owner": graphql.String(""),
+		"name":  graphql.String(""),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		data interface{}
+		vars map[string]interface{}
+		want string
+	}{
+		{
+			data: new(viewerQuery),
+			want: "prepared operation decodes into *graphql_test.repoQuery, not *graphql_test.viewerQuery",
+		},
+		{
+			data: new(repoQuery),
+			vars: map[string]interface{}{"owner": graphql.Int(1), "first": graphql.Int(1)},
+			want: `invalid variables for prepared operation: missing variable "name" of required type String!; unknown variable "first"; variable "owner" has type Int!, want String!`,
+		},
+	}
+	for _, tc := range tests {
+		err := p.Run(context.Background(), tc.data, tc.vars)
+		if err == nil {
+			t.Errorf("got error: nil, want: %v", tc.want)
+			continue
+		}
+		if got := err.Error(); got != tc.want {
+			t.Errorf("got error: %v, want: %v", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+// Package protodata decodes GraphQL response data into protobuf messages,
+// for services whose data model is defined by protobuf, so they don't
+// need a parallel set of query structs for every query. Response fields
+// are mapped onto message fields by their JSON names, as with protojson,
+// so GraphQL fields named like the proto fields, or aliased to their
+// names, are decoded into them:
+//
+//	var user pb.User
+//	err := client.Run(ctx, &graphql.Static{
+//		QueryStr: `query($id: ID!) { user(id: $id) ` + protodata.Selection(&user) + ` }`,
+//		Vars:     map[string]interface{}{"id": graphql.ID("42")},
+//		Into:     protodata.Into(&user, "user"),
+//	})
+//
+// The package doesn't depend on protobuf. It works with the message structs
+// generated by protoc-gen-go, through their protobuf struct tags. Values are
+// decoded as protojson decodes them: 64-bit integers from numbers or
+// strings, bytes from Base64, enums from numbers or names, and the
+// well-known Timestamp, Duration and wrapper messages from their JSON
+// forms. Fields of oneofs aren't decoded, as their wrapper types can't be
+// found by reflection.
+package protodata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Into returns a graphql.DataDecoder decoding the response data at path,
+// a sequence of response field names, into msg, a pointer to a message
+// struct. With no path, the data itself is decoded into msg. If the data
+// at path is null or absent, msg is left as is.
+func Into(msg interface{}, path ...string) graphql.DataDecoder {
+	return &decoder{msg: msg, path: path}
+}
+
+type decoder struct {
+	msg  interface{}
+	path []string
+}
+
+// DecodeGraphQL implements graphql.DataDecoder.
+func (d *decoder) DecodeGraphQL(data []byte) error {
+	v := reflect.ValueOf(d.msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("protodata: cannot decode into %T; want pointer to message struct", d.msg)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("protodata: %v", err)
+	}
+	for _, name := range d.path {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[name]
+	}
+	if value == nil {
+		return nil
+	}
+	return decodeValue(v.Elem(), value, field{}, strings.Join(d.path, "."))
+}
+
+// Selection returns the GraphQL selection set of the fields of msg, a
+// pointer to a message struct, by their JSON names, such as
+// "{id,name,address{street,city}}". Fields of messages recursively
+// containing themselves are selected once, and fields of oneofs aren't.
+func Selection(msg interface{}) string {
+	t := reflect.TypeOf(msg)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var b strings.Builder
+	writeSelection(&b, t, map[reflect.Type]bool{})
+	return b.String()
+}
+
+func writeSelection(b *strings.Builder, t reflect.Type, onPath map[reflect.Type]bool) {
+	onPath[t] = true
+	defer delete(onPath, t)
+	b.WriteByte('{')
+	n := 0
+	for _, f := range fields(t) {
+		ft := t.Field(f.index).Type
+		for ft.Kind() == reflect.Ptr || (ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8) {
+			ft = ft.Elem()
+		}
+		isMessage := ft.Kind() == reflect.Struct && !isWellKnown(ft)
+		if isMessage && onPath[ft] {
+			continue
+		}
+		if n > 0 {
+			b.WriteByte(',')
+		}
+		n++
+		b.WriteString(f.jsonName)
+		if isMessage {
+			writeSelection(b, ft, onPath)
+		}
+	}
+	b.WriteByte('}')
+}
+
+// field is a field of a message struct.
+type field struct {
+	index    int
+	name     string // Proto name.
+	jsonName string
+	enum     bool
+}
+
+// fieldCache caches the fields of message struct types.
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fields returns the fields of message struct type t, as given by their
+// protobuf struct tags.
+func fields(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	var fs []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("protobuf")
+		if !ok || sf.PkgPath != "" {
+			continue
+		}
+		f := field{index: i}
+		for _, opt := range strings.Split(tag, ",") {
+			switch {
+			case strings.HasPrefix(opt, "name="):
+				f.name = opt[len("name="):]
+			case strings.HasPrefix(opt, "json="):
+				f.jsonName = opt[len("json="):]
+			case strings.HasPrefix(opt, "enum="):
+				f.enum = true
+			}
+		}
+		if f.jsonName == "" {
+			f.jsonName = f.name
+		}
+		fs = append(fs, f)
+	}
+	fieldCache.Store(t, fs)
+	return fs
+}
+
+// decodeValue decodes JSON value into v, the value of message field f,
+// at path in the response data.
+func decodeValue(v reflect.Value, value interface{}, f field, path string) error {
+	if value == nil {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(v.Elem(), value, f, path)
+	case reflect.Struct:
+		if isWellKnown(v.Type()) {
+			return decodeWellKnown(v, value, path)
+		}
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return typeError(value, "object", path)
+		}
+		for _, f := range fields(v.Type()) {
+			value, ok := obj[f.jsonName]
+			if !ok {
+				value, ok = obj[f.name]
+			}
+			if !ok {
+				continue
+			}
+			if err := decodeValue(v.Field(f.index), value, f, joinPath(path, f.jsonName)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return decodeBytes(v, value, path)
+		}
+		arr, ok := value.([]interface{})
+		if !ok {
+			return typeError(value, "list", path)
+		}
+		s := reflect.MakeSlice(v.Type(), len(arr), len(arr))
+		for i, elem := range arr {
+			if err := decodeValue(s.Index(i), elem, f, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return typeError(value, "object", path)
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(obj))
+		for k, elem := range obj {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := decodeScalar(key, k, field{}, path); err != nil {
+				return err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(val, elem, field{}, joinPath(path, k)); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+		return nil
+	case reflect.Interface:
+		return nil // A oneof.
+	default:
+		return decodeScalar(v, value, f, path)
+	}
+}
+
+// decodeScalar decodes JSON value into scalar v, the value of field f.
+func decodeScalar(v reflect.Value, value interface{}, f field, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return typeError(value, "string", path)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		switch value {
+		case true, "true":
+			v.SetBool(true)
+		case false, "false":
+			v.SetBool(false)
+		default:
+			return typeError(value, "boolean", path)
+		}
+	case reflect.Int32, reflect.Int64:
+		if s, ok := value.(string); ok && f.enum {
+			if n, ok := enumValue(v.Type(), s); ok {
+				v.SetInt(int64(n))
+				return nil
+			}
+		}
+		n, err := strconv.ParseInt(numberString(value), 10, v.Type().Bits())
+		if err != nil {
+			return typeError(value, "integer", path)
+		}
+		v.SetInt(n)
+	case reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(numberString(value), 10, v.Type().Bits())
+		if err != nil {
+			return typeError(value, "unsigned integer", path)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var x float64
+		switch s := numberString(value); s {
+		case "NaN":
+			x = math.NaN()
+		case "Infinity":
+			x = math.Inf(1)
+		case "-Infinity":
+			x = math.Inf(-1)
+		default:
+			var err error
+			x, err = strconv.ParseFloat(s, v.Type().Bits())
+			if err != nil {
+				return typeError(value, "number", path)
+			}
+		}
+		v.SetFloat(x)
+	default:
+		return fmt.Errorf("protodata: %s: cannot decode into field of type %v", path, v.Type())
+	}
+	return nil
+}
+
+// numberString returns JSON value as the string of a number, if it's
+// a number or a string.
+func numberString(value interface{}) string {
+	switch value := value.(type) {
+	case json.Number:
+		return value.String()
+	case string:
+		return value
+	}
+	return ""
+}
+
+func decodeBytes(v reflect.Value, value interface{}, path string) error {
+	s, ok := value.(string)
+	if !ok {
+		return typeError(value, "Base64 string", path)
+	}
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("protodata: %s: invalid Base64: %v", path, err)
+	}
+	v.SetBytes(b)
+	return nil
+}
+
+// maxEnumValue is the largest enum value whose name is found by enumValue.
+const maxEnumValue = 255
+
+var enumCache sync.Map // reflect.Type -> map[string]int32
+
+// enumValue returns the value of enum type t named name. The names of
+// values from 0 to maxEnumValue are found with the String method
+// generated for enum types. A name matches a value named with a prefix,
+// such as "ADMIN" for "ROLE_ADMIN", if only one does.
+func enumValue(t reflect.Type, name string) (int32, bool) {
+	names, ok := enumCache.Load(t)
+	if !ok {
+		m := make(map[string]int32)
+		if t.Implements(reflect.TypeOf((*fmt.Stringer)(nil)).Elem()) {
+			for n := int64(0); n <= maxEnumValue; n++ {
+				v := reflect.New(t).Elem()
+				v.SetInt(n)
+				if s := v.Interface().(fmt.Stringer).String(); s != strconv.FormatInt(n, 10) {
+					m[s] = int32(n)
+				}
+			}
+		}
+		names, _ = enumCache.LoadOrStore(t, m)
+	}
+	m := names.(map[string]int32)
+	if n, ok := m[name]; ok {
+		return n, true
+	}
+	var (
+		found int32
+		count int
+	)
+	for s, n := range m {
+		if strings.HasSuffix(s, "_"+name) {
+			found = n
+			count++
+		}
+	}
+	return found, count == 1
+}
+
+// Package paths of the well-known types.
+const (
+	timestamppb = "google.golang.org/protobuf/types/known/timestamppb"
+	durationpb  = "google.golang.org/protobuf/types/known/durationpb"
+	wrapperspb  = "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// isWellKnown reports whether t is a well-known message type decoded from
+// its JSON form, rather than from an object of its fields.
+func isWellKnown(t reflect.Type) bool {
+	switch t.PkgPath() {
+	case timestamppb, durationpb, wrapperspb:
+		return true
+	}
+	return false
+}
+
+func decodeWellKnown(v reflect.Value, value interface{}, path string) error {
+	switch v.Type().PkgPath() {
+	case timestamppb:
+		s, ok := value.(string)
+		if !ok {
+			return typeError(value, "RFC 3339 timestamp", path)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("protodata: %s: invalid timestamp: %v", path, err)
+		}
+		v.FieldByName("Seconds").SetInt(t.Unix())
+		v.FieldByName("Nanos").SetInt(int64(t.Nanosecond()))
+	case durationpb:
+		s, ok := value.(string)
+		if !ok || !strings.HasSuffix(s, "s") {
+			return typeError(value, "duration in seconds", path)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("protodata: %s: invalid duration: %v", path, err)
+		}
+		v.FieldByName("Seconds").SetInt(int64(d / time.Second))
+		v.FieldByName("Nanos").SetInt(int64(d % time.Second))
+	case wrapperspb:
+		f, ok := v.Type().FieldByName("Value")
+		if !ok {
+			return fmt.Errorf("protodata: %s: cannot decode into %v", path, v.Type())
+		}
+		return decodeValue(v.FieldByIndex(f.Index), value, field{}, path)
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func typeError(value interface{}, want, path string) error {
+	var got string
+	switch value.(type) {
+	case map[string]interface{}:
+		got = "object"
+	case []interface{}:
+		got = "list"
+	case string:
+		got = "string"
+	case json.Number:
+		got = "number"
+	case bool:
+		got = "boolean"
+	}
+	if path == "" {
+		return fmt.Errorf("protodata: got %s, want %s", got, want)
+	}
+	return fmt.Errorf("protodata: %s: got %s, want %s", path, got, want)
+}
@@ -0,0 +1,142 @@
+package protodata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/protodata"
+)
+
+// The types below are shaped like those generated by protoc-gen-go for:
+//
+//	message User {
+//		string id = 1;
+//		string display_name = 2;
+//		int64 follower_count = 3;
+//		Role role = 4;
+//		repeated string tags = 5;
+//		bytes avatar = 6;
+//		Address address = 7;
+//		map<string, int32> scores = 8;
+//		repeated User friends = 9;
+//	}
+
+type Role int32
+
+const (
+	Role_ROLE_UNSPECIFIED Role = 0
+	Role_ROLE_ADMIN       Role = 1
+	Role_ROLE_MEMBER      Role = 2
+)
+
+func (x Role) String() string {
+	switch x {
+	case Role_ROLE_UNSPECIFIED:
+		return "ROLE_UNSPECIFIED"
+	case Role_ROLE_ADMIN:
+		return "ROLE_ADMIN"
+	case Role_ROLE_MEMBER:
+		return "ROLE_MEMBER"
+	}
+	return strconv.Itoa(int(x))
+}
+
+type User struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	Id            string           `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DisplayName   string           `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	FollowerCount int64            `protobuf:"varint,3,opt,name=follower_count,json=followerCount,proto3" json:"follower_count,omitempty"`
+	Role          Role             `protobuf:"varint,4,opt,name=role,proto3,enum=example.Role" json:"role,omitempty"`
+	Tags          []string         `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Avatar        []byte           `protobuf:"bytes,6,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Address       *Address         `protobuf:"bytes,7,opt,name=address,proto3" json:"address,omitempty"`
+	Scores        map[string]int32 `protobuf:"bytes,8,rep,name=scores,proto3" json:"scores,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Friends       []*User          `protobuf:"bytes,9,rep,name=friends,proto3" json:"friends,omitempty"`
+}
+
+type Address struct {
+	Street string `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	City   string `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+}
+
+func TestInto(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct{ Query string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		query = body.Query
+		w.Write([]byte(`{"data": {"user": {
+			"id": "1",
+			"displayName": "Gopher",
+			"followerCount": "9007199254740993",
+			"role": "ADMIN",
+			"tags": ["go", "graphql"],
+			"avatar": "aGk=",
+			"address": {"street": "1 Main St", "city": null},
+			"scores": {"go": 10},
+			"friends": [{"id": "2", "display_name": "Ferris", "role": 2}],
+			"__typename": "User"
+		}}}`))
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL, nil)
+	var user User
+	err := client.Run(context.Background(), &graphql.Static{
+		QueryStr: `query($id: ID!) { user(id: $id) ` + protodata.Selection(&user) + ` }`,
+		Vars:     map[string]interface{}{"id": graphql.ID("1")},
+		Into:     protodata.Into(&user, "user"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query($id: ID!) { user(id: $id) {id,displayName,followerCount,role,tags,avatar,address{street,city},scores} }`; query != want {
+		t.Errorf("got query:\n%s\nwant:\n%s", query, want)
+	}
+	want := User{
+		Id:            "1",
+		DisplayName:   "Gopher",
+		FollowerCount: 9007199254740993,
+		Role:          Role_ROLE_ADMIN,
+		Tags:          []string{"go", "graphql"},
+		Avatar:        []byte("hi"),
+		Address:       &Address{Street: "1 Main St"},
+		Scores:        map[string]int32{"go": 10},
+		Friends:       []*User{{Id: "2", DisplayName: "Ferris", Role: Role_ROLE_MEMBER}},
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("got user:\n%+v\nwant:\n%+v", user, want)
+	}
+}
+
+func TestInto_errors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want string
+	}{
+		{`{"user": {"followerCount": true}}`, "protodata: user.followerCount: got boolean, want integer"},
+		{`{"user": {"tags": "go"}}`, "protodata: user.tags: got string, want list"},
+		{`{"user": {"friends": [{"role": "OWNER"}]}}`, "protodata: user.friends[0].role: got string, want integer"},
+	} {
+		var user User
+		err := protodata.Into(&user, "user").DecodeGraphQL([]byte(tc.data))
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: got error: %v, want: %v", tc.data, err, tc.want)
+		}
+	}
+	var user User
+	if err := protodata.Into(user).DecodeGraphQL([]byte(`{}`)); err == nil {
+		t.Error("got nil error decoding into non-pointer")
+	}
+}
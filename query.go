@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/arvata-io/graphql/ident"
+	"github.com/arvata-io/graphql/internal/jsonutil"
 )
 
 type RequestHandlerFunc func(req *http.Request)
@@ -21,10 +23,33 @@ type Operation interface {
 	ModifyRequest(req *http.Request)
 }
 
+// NamedOperation is an Operation with an operation name. If the client
+// was created with WithOperationNameInBody, the name is sent to the server
+// as the request's operationName.
+type NamedOperation interface {
+	Operation
+	OperationName() string
+}
+
+// Directive is a directive of an operation, written as in GraphQL
+// documents, such as "@cached(ttl: 60)" or "@cascade". Several servers,
+// such as Hasura and Dgraph, configure how operations are executed with
+// directives of their definitions.
+type Directive string
+
 type Query struct {
 	Data interface{}
 	Vars map[string]interface{}
 
+	// Name is the operation name, embedded in the query document
+	// if non-empty, as in "query Name{...}".
+	Name string
+
+	// Directives are directives of the operation, embedded in the query
+	// document after its variable definitions, as in
+	// "query @cached(ttl: 60){...}".
+	Directives []Directive
+
 	RequestHandler RequestHandlerFunc
 }
 
@@ -36,7 +61,7 @@ func NewQuery(data interface{}, vars map[string]interface{}) *Query {
 }
 
 func (op *Query) Query() string {
-	return constructQuery(op.Data, op.Vars)
+	return constructQuery(op.Data, op.Vars, op.Name, op.Directives...)
 }
 
 func (op *Query) Variables() map[string]interface{} {
@@ -53,10 +78,23 @@ func (op *Query) ResponsePtr() interface{} {
 	return op.Data
 }
 
+func (op *Query) OperationName() string {
+	return op.Name
+}
+
 type Mutation struct {
 	Data interface{}
 	Vars map[string]interface{}
 
+	// Name is the operation name, embedded in the mutation document
+	// if non-empty, as in "mutation Name{...}".
+	Name string
+
+	// Directives are directives of the operation, embedded in the mutation
+	// document after its variable definitions, as in
+	// "mutation @transactional{...}".
+	Directives []Directive
+
 	RequestHandler RequestHandlerFunc
 }
 
@@ -68,7 +106,7 @@ func NewMutation(data interface{}, vars map[string]interface{}) *Mutation {
 }
 
 func (op *Mutation) Query() string {
-	return constructMutation(op.Data, op.Vars)
+	return constructMutation(op.Data, op.Vars, op.Name, op.Directives...)
 }
 
 func (op *Mutation) Variables() map[string]interface{} {
@@ -85,11 +123,20 @@ func (op *Mutation) ResponsePtr() interface{} {
 	return op.Data
 }
 
+func (op *Mutation) OperationName() string {
+	return op.Name
+}
+
 type Static struct {
 	QueryStr string
 	Into     interface{}
 	Vars     map[string]interface{}
 
+	// Name is the name of the operation in QueryStr to execute.
+	// It's only sent to the server as the request's operationName
+	// if the client was created with WithOperationNameInBody.
+	Name string
+
 	RequestHandler RequestHandlerFunc
 }
 
@@ -105,26 +152,67 @@ func (op *Static) ResponsePtr() interface{} {
 	return op.Into
 }
 
+func (op *Static) OperationName() string {
+	return op.Name
+}
+
 func (op *Static) ModifyRequest(req *http.Request) {
 	if op.RequestHandler != nil {
 		op.RequestHandler(req)
 	}
 }
 
-func constructQuery(v interface{}, variables map[string]interface{}) string {
-	query := query(v)
+func constructQuery(v interface{}, variables map[string]interface{}, name string, directives ...Directive) string {
+	var args string
 	if len(variables) > 0 {
-		return "query(" + queryArguments(variables) + ")" + query
+		args = queryArguments(variables)
 	}
-	return query
+	dirs := operationDirectives(directives)
+	return documents.get(docKey{reflect.TypeOf(v), "query", name, args, dirs}, func() string {
+		query := query(v)
+		if args != "" {
+			return operationHeader("query", name) + "(" + args + ")" + dirs + query
+		}
+		if name != "" || dirs != "" {
+			return operationHeader("query", name) + dirs + query
+		}
+		return query
+	})
 }
 
-func constructMutation(v interface{}, variables map[string]interface{}) string {
-	query := query(v)
+func constructMutation(v interface{}, variables map[string]interface{}, name string, directives ...Directive) string {
+	var args string
 	if len(variables) > 0 {
-		return "mutation(" + queryArguments(variables) + ")" + query
+		args = queryArguments(variables)
+	}
+	dirs := operationDirectives(directives)
+	return documents.get(docKey{reflect.TypeOf(v), "mutation", name, args, dirs}, func() string {
+		query := query(v)
+		if args != "" {
+			return operationHeader("mutation", name) + "(" + args + ")" + dirs + query
+		}
+		return operationHeader("mutation", name) + dirs + query
+	})
+}
+
+// operationDirectives returns directives as written in an operation
+// definition, each preceded by a space, as in " @cached(ttl: 60)".
+func operationDirectives(directives []Directive) string {
+	var b strings.Builder
+	for _, d := range directives {
+		b.WriteString(" ")
+		b.WriteString(strings.TrimSpace(string(d)))
+	}
+	return b.String()
+}
+
+// operationHeader returns the start of an operation definition of type
+// typ, such as "query" or "query Name" if name is non-empty.
+func operationHeader(typ, name string) string {
+	if name == "" {
+		return typ
 	}
-	return "mutation" + query
+	return typ + " " + name
 }
 
 // queryArguments constructs a minified arguments string for variables.
@@ -156,6 +244,11 @@ func queryArguments(variables map[string]interface{}) string {
 // value indicates whether t is a value (required) type or pointer (optional) type.
 // If value is true, then "!" is written at the end of t.
 func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
+	if elem, ok := optionalType(t); ok {
+		// Optional is an optional type too.
+		writeArgumentType(w, elem, false)
+		return
+	}
 	if t.Kind() == reflect.Ptr {
 		// Pointer is an optional type, so no "!" at the end of the pointer's underlying type.
 		writeArgumentType(w, t.Elem(), false)
@@ -171,8 +264,8 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 	default:
 		// Named type. E.g., "Int".
 		name := t.Name()
-		if name == "string" { // HACK: Workaround for https://github.com/shurcooL/githubv4/issues/12.
-			name = "ID"
+		if name == "string" {
+			name = "String"
 		}
 		io.WriteString(w, name)
 	}
@@ -183,50 +276,107 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 	}
 }
 
-// query uses writeQuery to recursively construct
-// a minified query string from the provided struct v.
+// query constructs a minified query string from the provided struct v.
 //
 // E.g., struct{Foo Int, BarBaz *Boolean} -> "{foo,barBaz}".
 func query(v interface{}) string {
+	var set selectionSet
+	if !set.addType(reflect.TypeOf(v)) {
+		return ""
+	}
 	var buf bytes.Buffer
-	writeQuery(&buf, reflect.TypeOf(v), false)
+	set.write(&buf)
 	return buf.String()
 }
 
-// writeQuery writes a minified query for t to w.
-// If inline is true, the struct fields of t are inlined into parent struct.
-func writeQuery(w io.Writer, t reflect.Type, inline bool) {
+// selectionSet is a selection set of a query being constructed. Fields
+// and inline fragments selected more than once, such as by a struct and
+// a struct embedded in it, are merged into one selection, with their
+// selection sets merged in turn, as GraphQL would merge them.
+type selectionSet struct {
+	selections []*selection
+}
+
+// selection is a field or inline fragment of a selectionSet.
+type selection struct {
+	head string        // Field or inline fragment, as in "user(login:$login)" or "... on User".
+	set  *selectionSet // Selection set of the field or fragment, or nil if it's a leaf.
+}
+
+// add returns the selection of s with head, adding it if s has none.
+// Selections are identified by their text, so fields with the same
+// response key, name, arguments and directives are merged.
+func (s *selectionSet) add(head string) *selection {
+	for _, sel := range s.selections {
+		if sel.head == head {
+			return sel
+		}
+	}
+	sel := &selection{head: head}
+	s.selections = append(s.selections, sel)
+	return sel
+}
+
+// addType adds the selections of the fields of type t to s. It reports
+// whether t has a selection set, that is, whether it isn't a scalar.
+func (s *selectionSet) addType(t reflect.Type) bool {
 	switch t.Kind() {
 	case reflect.Ptr, reflect.Slice:
-		writeQuery(w, t.Elem(), false)
+		return s.addType(t.Elem())
 	case reflect.Struct:
+		// Columnar is queried as a list of the objects of its columns.
+		if reflect.PtrTo(t).Implements(columnarType) {
+			return s.addType(reflect.New(t).Interface().(columnar).columnsType())
+		}
 		// If the type implements json.Unmarshaler, it's a scalar. Don't expand it.
 		if reflect.PtrTo(t).Implements(jsonUnmarshaler) {
-			return
-		}
-		if !inline {
-			io.WriteString(w, "{")
+			return false
 		}
 		for i := 0; i < t.NumField(); i++ {
-			if i != 0 {
-				io.WriteString(w, ",")
-			}
 			f := t.Field(i)
 			value, ok := f.Tag.Lookup("graphql")
-			inlineField := f.Anonymous && !ok
-			if !inlineField {
-				if ok {
-					io.WriteString(w, value)
-				} else {
-					io.WriteString(w, ident.ParseMixedCaps(f.Name).ToLowerCamelCase())
-				}
+			if ok && strings.TrimSpace(value) == jsonutil.FallbackTag {
+				// Unknown type fallback is populated from the response only.
+				continue
+			}
+			if f.Anonymous && !ok {
+				s.addType(f.Type)
+				continue
+			}
+			head := value
+			if !ok {
+				head = ident.ParseMixedCaps(f.Name).ToLowerCamelCase()
+			}
+			if flag, ok := f.Tag.Lookup("flag"); ok {
+				head += featureFlagDirective(flag)
+			}
+			sel := s.add(head)
+			set := sel.set
+			if set == nil {
+				set = new(selectionSet)
 			}
-			writeQuery(w, f.Type, inlineField)
+			if set.addType(f.Type) {
+				sel.set = set
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// write writes s to w, minified.
+func (s *selectionSet) write(w io.Writer) {
+	io.WriteString(w, "{")
+	for i, sel := range s.selections {
+		if i > 0 {
+			io.WriteString(w, ",")
 		}
-		if !inline {
-			io.WriteString(w, "}")
+		io.WriteString(w, sel.head)
+		if sel.set != nil {
+			sel.set.write(w)
 		}
 	}
+	io.WriteString(w, "}")
 }
 
 var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
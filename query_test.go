@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"encoding/json"
 	"net/url"
 	"testing"
 	"time"
@@ -227,9 +228,60 @@ func TestConstructQuery(t *testing.T) {
 			}{},
 			want: `{viewer{login,createdAt,id,databaseId}}`,
 		},
+		// Unknown type fallback fields should not be included in query.
+		{
+			inV: struct {
+				Node struct {
+					Other    json.RawMessage `graphql:"... on *"`
+					Typename string          `graphql:"__typename"`
+					Issue    struct {
+						Title string
+					} `graphql:"... on Issue"`
+				} `graphql:"node(id: \"1\")"`
+			}{},
+			want: `{node(id: "1"){__typename,... on Issue{title}}}`,
+		},
+		// Fields selected more than once are merged.
+		{
+			inV: func() interface{} {
+				type userFields struct {
+					Login  String
+					Avatar struct {
+						URL URI
+					} `graphql:"avatarUrl: avatar(size: 64)"`
+				}
+				type issueFields struct {
+					Title  String
+					Author userFields
+				}
+				return struct {
+					Issue struct {
+						issueFields
+						Title  String
+						Number Int
+						Author struct {
+							userFields
+							Login String
+							Name  String
+							Email String `graphql:"email" flag:"emails"`
+						}
+						Item struct {
+							Title String
+						} `graphql:"... on Issue"`
+						Other struct {
+							Number Int
+						} `graphql:"... on Issue"`
+						First struct {
+							Title String
+						} `graphql:"first: author"`
+					} `graphql:"issue(number: 1)"`
+				}{}
+			}(),
+			want: `{issue(number: 1){title,author{login,avatarUrl: avatar(size: 64){url},name,email @featureFlag(if: "emails")},number,... on Issue{title,number},first: author{title}}}`,
+		},
 	}
 	for _, tc := range tests {
-		got := constructQuery(tc.inV, tc.inVariables)
+		got := constructQuery(tc.inV, tc.inVariables, "")
 		if got != tc.want {
 			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
 		}
@@ -264,13 +316,52 @@ func TestConstructMutation(t *testing.T) {
 		},
 	}
 	for _, tc := range tests {
-		got := constructMutation(tc.inV, tc.inVariables)
+		got := constructMutation(tc.inV, tc.inVariables, "")
 		if got != tc.want {
 			t.Errorf("\ngot:  %q\nwant: %q\n", got, tc.want)
 		}
 	}
 }
 
+func TestConstructOperationDirectives(t *testing.T) {
+	type viewer struct {
+		Viewer struct {
+			Login String
+		}
+	}
+	vars := map[string]interface{}{"first": Int(10)}
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{
+			got:  constructQuery(viewer{}, nil, "", "@cached(ttl: 60)"),
+			want: `query @cached(ttl: 60){viewer{login}}`,
+		},
+		{
+			got:  constructQuery(viewer{}, nil, "Viewer", "@cached(ttl: 60)", " @cascade "),
+			want: `query Viewer @cached(ttl: 60) @cascade{viewer{login}}`,
+		},
+		{
+			got:  constructQuery(viewer{}, vars, "Viewer", "@cached"),
+			want: `query Viewer($first:Int!) @cached{viewer{login}}`,
+		},
+		{
+			got:  constructQuery(viewer{}, nil, ""),
+			want: `{viewer{login}}`,
+		},
+		{
+			got:  constructMutation(viewer{}, nil, "", "@transactional"),
+			want: `mutation @transactional{viewer{login}}`,
+		},
+	}
+	for _, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("\ngot:  %q\nwant: %q\n", tc.got, tc.want)
+		}
+	}
+}
+
 func TestQueryArguments(t *testing.T) {
 	tests := []struct {
 		in   map[string]interface{}
@@ -305,6 +396,10 @@ func TestQueryArguments(t *testing.T) {
 			in:   map[string]interface{}{"id": ID("someID")},
 			want: "$id:ID!",
 		},
+		{
+			in:   map[string]interface{}{"login": "gopher"},
+			want: "$login:String!",
+		},
 		{
 			in:   map[string]interface{}{"ids": []ID{"someID", "anotherID"}},
 			want: `$ids:[ID!]!`,
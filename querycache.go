@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"container/list"
+	"sync"
+)
+
+// queryCache is an LRU cache of values computed from query strings, such
+// as the results of validating them, that holds up to
+// DefaultDocumentCacheSize queries. The zero value is an empty cache.
+type queryCache[V any] struct {
+	mu      sync.Mutex
+	lru     *list.List // Of *queryEntry[V], the most recently used first.
+	entries map[string]*list.Element
+}
+
+type queryEntry[V any] struct {
+	query string
+	v     V
+}
+
+// load returns the value cached for query, if any.
+func (c *queryCache[V]) load(query string) (v V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[query]
+	if !ok {
+		return v, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*queryEntry[V]).v, true
+}
+
+// loadOrStore returns the value cached for query if there's one, and
+// otherwise caches v and returns it. stored reports whether v was cached.
+func (c *queryCache[V]) loadOrStore(query string, v V) (actual V, stored bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[query]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*queryEntry[V]).v, false
+	}
+	if c.entries == nil {
+		c.lru = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	c.entries[query] = c.lru.PushFront(&queryEntry[V]{query: query, v: v})
+	for c.lru.Len() > DefaultDocumentCacheSize {
+		qe := c.lru.Remove(c.lru.Back()).(*queryEntry[V])
+		delete(c.entries, qe.query)
+	}
+	return v, true
+}
+
+// len returns the number of queries cached.
+func (c *queryCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,37 @@
+package graphql
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQueryCache(t *testing.T) {
+	var c queryCache[int]
+	if _, ok := c.load("q0"); ok {
+		t.Fatal("got value from empty cache")
+	}
+	if v, stored := c.loadOrStore("q0", 0); v != 0 || !stored {
+		t.Errorf("got %v, stored %v, want 0, stored true", v, stored)
+	}
+	if v, stored := c.loadOrStore("q0", 1); v != 0 || stored {
+		t.Errorf("got %v, stored %v, want 0, stored false", v, stored)
+	}
+
+	// Queries beyond the limit evict the least recently used ones, which
+	// isn't q0 once it's loaded.
+	for i := 1; i <= DefaultDocumentCacheSize; i++ {
+		if i == DefaultDocumentCacheSize {
+			c.load("q0")
+		}
+		c.loadOrStore(fmt.Sprintf("q%d", i), i)
+	}
+	if got, want := c.len(), DefaultDocumentCacheSize; got != want {
+		t.Errorf("got %d queries, want %d", got, want)
+	}
+	if v, ok := c.load("q0"); !ok || v != 0 {
+		t.Errorf("got %v, %v for q0, want 0, true", v, ok)
+	}
+	if _, ok := c.load("q1"); ok {
+		t.Error("got q1, want it evicted")
+	}
+}
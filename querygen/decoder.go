@@ -0,0 +1,182 @@
+package querygen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Decoder reads the JSON tokens of GraphQL response data for generated
+// decoders. It's not intended to be used otherwise.
+type Decoder struct {
+	d      *json.Decoder
+	peeked json.Token
+	isPeek bool
+}
+
+// NewDecoder returns a Decoder reading data.
+func NewDecoder(data []byte) *Decoder {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return &Decoder{d: d}
+}
+
+func (d *Decoder) token() (json.Token, error) {
+	if d.isPeek {
+		d.isPeek = false
+		return d.peeked, nil
+	}
+	return d.d.Token()
+}
+
+// More reports whether there's another element in the current object or array.
+func (d *Decoder) More() bool {
+	return d.isPeek || d.d.More()
+}
+
+// Null reports whether the next value is null, consuming it if so.
+func (d *Decoder) Null() (bool, error) {
+	tok, err := d.token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return true, nil
+	}
+	d.peeked, d.isPeek = tok, true
+	return false, nil
+}
+
+// Open reads the opening delimiter of an object or array, or null.
+// It reports whether the value is null.
+func (d *Decoder) Open(delim json.Delim) (null bool, err error) {
+	tok, err := d.token()
+	if err != nil {
+		return false, err
+	}
+	switch tok {
+	case nil:
+		return true, nil
+	case delim:
+		return false, nil
+	}
+	return false, fmt.Errorf("querygen: got %v, want %v", tok, delim)
+}
+
+// Close reads the closing delimiter of an object or array.
+func (d *Decoder) Close(delim json.Delim) error {
+	tok, err := d.token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("querygen: got %v, want %v", tok, delim)
+	}
+	return nil
+}
+
+// Key reads the key of the next member of an object.
+func (d *Decoder) Key() (string, error) {
+	tok, err := d.token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("querygen: got %v, want object key", tok)
+	}
+	return key, nil
+}
+
+// String reads a string value, or null.
+func (d *Decoder) String() (s string, null bool, err error) {
+	tok, err := d.token()
+	if err != nil || tok == nil {
+		return "", tok == nil, err
+	}
+	s, ok := tok.(string)
+	if !ok {
+		return "", false, fmt.Errorf("querygen: got %v, want string", tok)
+	}
+	return s, false, nil
+}
+
+// Bool reads a boolean value, or null.
+func (d *Decoder) Bool() (b bool, null bool, err error) {
+	tok, err := d.token()
+	if err != nil || tok == nil {
+		return false, tok == nil, err
+	}
+	b, ok := tok.(bool)
+	if !ok {
+		return false, false, fmt.Errorf("querygen: got %v, want boolean", tok)
+	}
+	return b, false, nil
+}
+
+// Int reads an integer value, or null.
+func (d *Decoder) Int() (n int64, null bool, err error) {
+	num, null, err := d.number()
+	if err != nil || null {
+		return 0, null, err
+	}
+	n, err = num.Int64()
+	return n, false, err
+}
+
+// Float reads a number value, or null.
+func (d *Decoder) Float() (f float64, null bool, err error) {
+	num, null, err := d.number()
+	if err != nil || null {
+		return 0, null, err
+	}
+	f, err = num.Float64()
+	return f, false, err
+}
+
+func (d *Decoder) number() (json.Number, bool, error) {
+	tok, err := d.token()
+	if err != nil || tok == nil {
+		return "", tok == nil, err
+	}
+	n, ok := tok.(json.Number)
+	if !ok {
+		return "", false, fmt.Errorf("querygen: got %v, want number", tok)
+	}
+	return n, false, nil
+}
+
+// Raw reads the next value, and returns its JSON text.
+func (d *Decoder) Raw() (json.RawMessage, error) {
+	if d.isPeek {
+		if _, ok := d.peeked.(json.Delim); ok {
+			return nil, fmt.Errorf("querygen: cannot read %v as a raw value", d.peeked)
+		}
+		d.isPeek = false
+		return json.Marshal(d.peeked)
+	}
+	var raw json.RawMessage
+	err := d.d.Decode(&raw)
+	return raw, err
+}
+
+// Skip skips the next value.
+func (d *Decoder) Skip() error {
+	_, err := d.Raw()
+	return err
+}
+
+// Alloc sets *p to a new T, and returns it.
+func Alloc[T any](p **T) *T {
+	if *p == nil {
+		*p = new(T)
+	}
+	return *p
+}
+
+// Grow appends a zero element to *s, and returns a pointer to it.
+func Grow[S ~[]E, E any](s *S) *E {
+	var zero E
+	*s = append(*s, zero)
+	return &(*s)[len(*s)-1]
+}
@@ -0,0 +1,303 @@
+// Code generated by querygen. DO NOT EDIT.
+
+package querygen_test
+
+import (
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/querygen"
+)
+
+// issuesQueryQuery is the query of issuesQuery.
+const issuesQueryQuery = "query Issues($first:Int!$name:String!$owner:String!){repository(owner: $owner, name: $name){id,name,stars: stargazerCount,owner{__typename,... on User{login,bio},... on Organization{login,seats}},issues(first: $first){totalCount,nodes{number,title,closed,score,created: createdAt,closedAt,author{login},updatedAt}},topics}}"
+
+// DecodeGraphQL decodes GraphQL response data into v.
+func (v *issuesQuery) DecodeGraphQL(data []byte) error {
+	d := querygen.NewDecoder(data)
+	if null1, err := d.Open('{'); err != nil {
+		return err
+	} else if !null1 {
+		for d.More() {
+			key2, err := d.Key()
+			if err != nil {
+				return err
+			}
+			switch key2 {
+			case "repository":
+				if null3, err := d.Open('{'); err != nil {
+					return err
+				} else if !null3 {
+					for d.More() {
+						key4, err := d.Key()
+						if err != nil {
+							return err
+						}
+						switch key4 {
+						case "id":
+							raw5, err := d.Raw()
+							if err != nil {
+								return err
+							}
+							if err := (*v).Repository.ID.UnmarshalJSON(raw5); err != nil {
+								return err
+							}
+						case "name":
+							if v6, null7, err := d.String(); err != nil {
+								return err
+							} else if !null7 {
+								(*v).Repository.Name = graphql.String(v6)
+							}
+						case "stars":
+							if v8, null9, err := d.Int(); err != nil {
+								return err
+							} else if !null9 {
+								(*v).Repository.Stars = graphql.Int(v8)
+							}
+						case "owner":
+							if null10, err := d.Open('{'); err != nil {
+								return err
+							} else if !null10 {
+								for d.More() {
+									key11, err := d.Key()
+									if err != nil {
+										return err
+									}
+									switch key11 {
+									case "__typename":
+										if v12, null13, err := d.String(); err != nil {
+											return err
+										} else if !null13 {
+											(*v).Repository.Owner.Typename = graphql.String(v12)
+										}
+									case "login":
+										raw14, err := d.Raw()
+										if err != nil {
+											return err
+										}
+										{
+											d := querygen.NewDecoder(raw14)
+											if v15, null16, err := d.String(); err != nil {
+												return err
+											} else if !null16 {
+												(*v).Repository.Owner.User.Login = graphql.String(v15)
+											}
+										}
+										{
+											d := querygen.NewDecoder(raw14)
+											if v17, null18, err := d.String(); err != nil {
+												return err
+											} else if !null18 {
+												(*v).Repository.Owner.Organization.Login = graphql.String(v17)
+											}
+										}
+									case "bio":
+										if null19, err := d.Null(); err != nil {
+											return err
+										} else if null19 {
+											(*v).Repository.Owner.User.Bio = nil
+										} else {
+											p20 := querygen.Alloc(&(*v).Repository.Owner.User.Bio)
+											if v21, null22, err := d.String(); err != nil {
+												return err
+											} else if !null22 {
+												(*p20) = graphql.String(v21)
+											}
+										}
+									case "seats":
+										if v23, null24, err := d.Int(); err != nil {
+											return err
+										} else if !null24 {
+											(*v).Repository.Owner.Organization.Seats = int64(v23)
+										}
+									default:
+										if err := d.Skip(); err != nil {
+											return err
+										}
+									}
+								}
+								if err := d.Close('}'); err != nil {
+									return err
+								}
+							}
+						case "issues":
+							if null25, err := d.Open('{'); err != nil {
+								return err
+							} else if !null25 {
+								for d.More() {
+									key26, err := d.Key()
+									if err != nil {
+										return err
+									}
+									switch key26 {
+									case "totalCount":
+										if v27, null28, err := d.Int(); err != nil {
+											return err
+										} else if !null28 {
+											(*v).Repository.Issues.TotalCount = graphql.Int(v27)
+										}
+									case "nodes":
+										if null29, err := d.Open('['); err != nil {
+											return err
+										} else if null29 {
+											(*v).Repository.Issues.Nodes = nil
+										} else {
+											(*v).Repository.Issues.Nodes = (*v).Repository.Issues.Nodes[:0]
+											for d.More() {
+												e30 := querygen.Grow(&(*v).Repository.Issues.Nodes)
+												if null31, err := d.Open('{'); err != nil {
+													return err
+												} else if !null31 {
+													for d.More() {
+														key32, err := d.Key()
+														if err != nil {
+															return err
+														}
+														switch key32 {
+														case "number":
+															if v33, null34, err := d.Int(); err != nil {
+																return err
+															} else if !null34 {
+																(*e30).Number = graphql.Int(v33)
+															}
+														case "title":
+															if v35, null36, err := d.String(); err != nil {
+																return err
+															} else if !null36 {
+																(*e30).Title = graphql.String(v35)
+															}
+														case "closed":
+															if v37, null38, err := d.Bool(); err != nil {
+																return err
+															} else if !null38 {
+																(*e30).Closed = graphql.Boolean(v37)
+															}
+														case "score":
+															if v39, null40, err := d.Float(); err != nil {
+																return err
+															} else if !null40 {
+																(*e30).Score = graphql.Float(v39)
+															}
+														case "created":
+															raw41, err := d.Raw()
+															if err != nil {
+																return err
+															}
+															if err := (*e30).Created.UnmarshalJSON(raw41); err != nil {
+																return err
+															}
+														case "closedAt":
+															raw42, err := d.Raw()
+															if err != nil {
+																return err
+															}
+															if string(raw42) == "null" {
+																(*e30).ClosedAt = nil
+															} else {
+																if err := querygen.Alloc(&(*e30).ClosedAt).UnmarshalJSON(raw42); err != nil {
+																	return err
+																}
+															}
+														case "author":
+															if null43, err := d.Null(); err != nil {
+																return err
+															} else if null43 {
+																(*e30).Author = nil
+															} else {
+																p44 := querygen.Alloc(&(*e30).Author)
+																if null45, err := d.Open('{'); err != nil {
+																	return err
+																} else if !null45 {
+																	for d.More() {
+																		key46, err := d.Key()
+																		if err != nil {
+																			return err
+																		}
+																		switch key46 {
+																		case "login":
+																			if v47, null48, err := d.String(); err != nil {
+																				return err
+																			} else if !null48 {
+																				(*p44).Login = graphql.String(v47)
+																			}
+																		default:
+																			if err := d.Skip(); err != nil {
+																				return err
+																			}
+																		}
+																	}
+																	if err := d.Close('}'); err != nil {
+																		return err
+																	}
+																}
+															}
+														case "updatedAt":
+															if v49, null50, err := d.String(); err != nil {
+																return err
+															} else if !null50 {
+																(*e30).Timestamps.UpdatedAt = graphql.String(v49)
+															}
+														default:
+															if err := d.Skip(); err != nil {
+																return err
+															}
+														}
+													}
+													if err := d.Close('}'); err != nil {
+														return err
+													}
+												}
+											}
+											if err := d.Close(']'); err != nil {
+												return err
+											}
+										}
+									default:
+										if err := d.Skip(); err != nil {
+											return err
+										}
+									}
+								}
+								if err := d.Close('}'); err != nil {
+									return err
+								}
+							}
+						case "topics":
+							if null51, err := d.Open('['); err != nil {
+								return err
+							} else if null51 {
+								(*v).Repository.Topics = nil
+							} else {
+								(*v).Repository.Topics = (*v).Repository.Topics[:0]
+								for d.More() {
+									e52 := querygen.Grow(&(*v).Repository.Topics)
+									if v53, null54, err := d.String(); err != nil {
+										return err
+									} else if !null54 {
+										(*e52) = string(v53)
+									}
+								}
+								if err := d.Close(']'); err != nil {
+									return err
+								}
+							}
+						default:
+							if err := d.Skip(); err != nil {
+								return err
+							}
+						}
+					}
+					if err := d.Close('}'); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := d.Close('}'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+// Package querygen generates Go code for GraphQL query data structures:
+// constants with their queries, and DecodeGraphQL methods that decode
+// response data into them without reflection. Clients use the methods
+// in place of their reflection-based decoder, for hot paths such as
+// gateways running millions of operations a day.
+//
+// Code is generated by a program that calls Generate with values of the
+// query data structure types, typically run with go generate:
+//
+//	//go:build ignore
+//
+//	package main
+//
+//	func main() {
+//		f, _ := os.Create("queries_gen.go")
+//		err := querygen.Generate(f, "issues", querygen.Type{
+//			Value:     issues.IssuesQuery{},
+//			Variables: map[string]interface{}{"first": graphql.Int(0)},
+//		})
+//		...
+//	}
+//
+// The generated decoders decode fields the way the client does, except
+// for fields tagged as the fallback of unknown types, which are skipped.
+// The other exported identifiers of this package are used by generated code.
+package querygen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/ident"
+)
+
+// Type is a query data structure type to generate code for.
+type Type struct {
+	// Value is a value of the type, which must be a named struct type
+	// of the package code is generated for.
+	Value interface{}
+
+	// Variables are the variables of the operation, with values of
+	// their types, as for graphql.NewQuery.
+	Variables map[string]interface{}
+
+	Mutation bool   // Whether the operation is a mutation, rather than a query.
+	Name     string // Operation name, if any.
+}
+
+// Generate writes a Go source file of package pkg to w, with a constant
+// named after each type with the suffix "Query", holding its operation,
+// and a DecodeGraphQL method of the type.
+func Generate(w io.Writer, pkg string, types ...Type) error {
+	g := &generator{imports: map[string]string{
+		"github.com/arvata-io/graphql/querygen": "querygen",
+	}}
+	var body bytes.Buffer
+	for _, typ := range types {
+		t := reflect.TypeOf(typ.Value)
+		if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+			return fmt.Errorf("querygen: %T isn't a named struct type", typ.Value)
+		}
+		if g.pkgPath == "" {
+			g.pkgPath = t.PkgPath()
+		} else if t.PkgPath() != g.pkgPath {
+			return fmt.Errorf("querygen: %v isn't in package %s", t, g.pkgPath)
+		}
+		ptr := reflect.New(t).Interface()
+		var query string
+		if typ.Mutation {
+			query = (&graphql.Mutation{Data: ptr, Vars: typ.Variables, Name: typ.Name}).Query()
+		} else {
+			query = (&graphql.Query{Data: ptr, Vars: typ.Variables, Name: typ.Name}).Query()
+		}
+		fmt.Fprintf(&body, "\n// %sQuery is the %s of %s.\n", t.Name(), operationType(typ.Mutation), t.Name())
+		fmt.Fprintf(&body, "const %sQuery = %s\n", t.Name(), strconv.Quote(query))
+		fmt.Fprintf(&body, "\n// DecodeGraphQL decodes GraphQL response data into v.\n")
+		fmt.Fprintf(&body, "func (v *%s) DecodeGraphQL(data []byte) error {\n", t.Name())
+		fmt.Fprintf(&body, "d := querygen.NewDecoder(data)\n")
+		g.n = 0
+		if err := g.decode(&body, "(*v)", t); err != nil {
+			return err
+		}
+		fmt.Fprintf(&body, "return nil\n}\n")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by querygen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "%q\n", path)
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("querygen: formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func operationType(mutation bool) string {
+	if mutation {
+		return "mutation"
+	}
+	return "query"
+}
+
+type generator struct {
+	pkgPath string
+	imports map[string]string // Import path -> package name.
+	n       int               // Number of variables declared in the current method.
+}
+
+// name returns a new variable name with prefix.
+func (g *generator) name(prefix string) string {
+	g.n++
+	return prefix + strconv.Itoa(g.n)
+}
+
+// typeName returns the name of named type t in generated code.
+func (g *generator) typeName(t reflect.Type) string {
+	if t.PkgPath() == "" || t.PkgPath() == g.pkgPath {
+		return t.Name()
+	}
+	name := t.String()
+	pkg := name[:strings.IndexByte(name, '.')]
+	g.imports[t.PkgPath()] = pkg
+	return name
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// isUnmarshaler reports whether values of t decode themselves.
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(jsonUnmarshaler)
+}
+
+// check writes the check of the error returned by call.
+func check(w io.Writer, call string) {
+	fmt.Fprintf(w, "if err := %s; err != nil {\nreturn err\n}\n", call)
+}
+
+// decode writes code that decodes the next value into expr, of type t.
+func (g *generator) decode(w io.Writer, expr string, t reflect.Type) error {
+	switch {
+	case isUnmarshaler(t):
+		raw := g.name("raw")
+		fmt.Fprintf(w, "%s, err := d.Raw()\nif err != nil {\nreturn err\n}\n", raw)
+		check(w, fmt.Sprintf("%s.UnmarshalJSON(%s)", expr, raw))
+		return nil
+	case t.Kind() == reflect.Ptr:
+		elem := t.Elem()
+		if isUnmarshaler(elem) {
+			raw := g.name("raw")
+			fmt.Fprintf(w, "%s, err := d.Raw()\nif err != nil {\nreturn err\n}\n", raw)
+			fmt.Fprintf(w, "if string(%s) == \"null\" {\n%s = nil\n} else {\n", raw, expr)
+			check(w, fmt.Sprintf("querygen.Alloc(&%s).UnmarshalJSON(%s)", expr, raw))
+			fmt.Fprintf(w, "}\n")
+			return nil
+		}
+		null, p := g.name("null"), g.name("p")
+		fmt.Fprintf(w, "if %s, err := d.Null(); err != nil {\nreturn err\n} else if %s {\n%s = nil\n} else {\n", null, null, expr)
+		fmt.Fprintf(w, "%s := querygen.Alloc(&%s)\n", p, expr)
+		if err := g.decode(w, "(*"+p+")", elem); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "}\n")
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.decodeStruct(w, expr, t)
+	case reflect.Slice:
+		null, e := g.name("null"), g.name("e")
+		fmt.Fprintf(w, "if %s, err := d.Open('['); err != nil {\nreturn err\n} else if %s {\n%s = nil\n} else {\n", null, null, expr)
+		fmt.Fprintf(w, "%s = %s[:0]\nfor d.More() {\n%s := querygen.Grow(&%s)\n", expr, expr, e, expr)
+		if err := g.decode(w, "(*"+e+")", t.Elem()); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "}\n")
+		check(w, "d.Close(']')")
+		fmt.Fprintf(w, "}\n")
+		return nil
+	case reflect.String:
+		g.decodeScalar(w, expr, t, "String")
+		return nil
+	case reflect.Bool:
+		g.decodeScalar(w, expr, t, "Bool")
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		g.decodeScalar(w, expr, t, "Int")
+		return nil
+	case reflect.Float32, reflect.Float64:
+		g.decodeScalar(w, expr, t, "Float")
+		return nil
+	}
+	// Other types, such as maps and interfaces, are decoded by encoding/json.
+	raw := g.name("raw")
+	g.imports["encoding/json"] = "json"
+	fmt.Fprintf(w, "%s, err := d.Raw()\nif err != nil {\nreturn err\n}\n", raw)
+	check(w, fmt.Sprintf("json.Unmarshal(%s, &%s)", raw, expr))
+	return nil
+}
+
+// decodeScalar writes code that decodes a scalar into expr, of type t,
+// with method of Decoder.
+func (g *generator) decodeScalar(w io.Writer, expr string, t reflect.Type, method string) {
+	v, null := g.name("v"), g.name("null")
+	fmt.Fprintf(w, "if %s, %s, err := d.%s(); err != nil {\nreturn err\n} else if !%s {\n%s = %s(%s)\n}\n",
+		v, null, method, null, expr, g.typeName(t), v)
+}
+
+// field is a struct field decoded from a response key.
+type field struct {
+	expr   string
+	typ    reflect.Type
+	allocs []string // Inlined pointer fields to allocate before the field is decoded.
+}
+
+// decodeStruct writes code that decodes an object into expr, of struct type t.
+func (g *generator) decodeStruct(w io.Writer, expr string, t reflect.Type) error {
+	var keys []string
+	fields := make(map[string][]field)
+	if err := g.collectFields(expr, t, nil, &keys, fields); err != nil {
+		return err
+	}
+	null, key := g.name("null"), g.name("key")
+	fmt.Fprintf(w, "if %s, err := d.Open('{'); err != nil {\nreturn err\n} else if !%s {\n", null, null)
+	fmt.Fprintf(w, "for d.More() {\n%s, err := d.Key()\nif err != nil {\nreturn err\n}\nswitch %s {\n", key, key)
+	for _, k := range keys {
+		fmt.Fprintf(w, "case %q:\n", k)
+		fs := fields[k]
+		for _, f := range fs {
+			for _, a := range f.allocs {
+				fmt.Fprintf(w, "querygen.Alloc(&%s)\n", a)
+			}
+		}
+		if len(fs) == 1 {
+			if err := g.decode(w, fs[0].expr, fs[0].typ); err != nil {
+				return err
+			}
+			continue
+		}
+		// Fields of several fragments have the same key. Each is decoded from its value.
+		raw := g.name("raw")
+		fmt.Fprintf(w, "%s, err := d.Raw()\nif err != nil {\nreturn err\n}\n", raw)
+		for _, f := range fs {
+			fmt.Fprintf(w, "{\nd := querygen.NewDecoder(%s)\n", raw)
+			if err := g.decode(w, f.expr, f.typ); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "}\n")
+		}
+	}
+	fmt.Fprintf(w, "default:\n")
+	check(w, "d.Skip()")
+	fmt.Fprintf(w, "}\n}\n")
+	check(w, "d.Close('}')")
+	fmt.Fprintf(w, "}\n")
+	return nil
+}
+
+// collectFields collects the fields of struct type t at expr by response
+// key, including those of inline fragments and embedded structs.
+func (g *generator) collectFields(expr string, t reflect.Type, allocs []string, keys *[]string, fields map[string][]field) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, inline, ok := responseKey(f)
+		if !ok {
+			continue
+		}
+		fexpr := expr + "." + f.Name
+		if !inline {
+			if _, ok := fields[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			fields[key] = append(fields[key], field{expr: fexpr, typ: f.Type, allocs: allocs})
+			continue
+		}
+		ft, fallocs := f.Type, allocs
+		if ft.Kind() == reflect.Ptr {
+			fallocs = append(allocs[:len(allocs):len(allocs)], fexpr)
+			fexpr, ft = "(*"+fexpr+")", ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			return errors.New("querygen: inline field " + f.Name + " isn't a struct")
+		}
+		if err := g.collectFields(fexpr, ft, fallocs, keys, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// responseKey returns the key of struct field f in responses, or whether
+// it's inlined into its parent, as fragments and embedded structs are.
+// It reports false for fields that aren't decoded.
+func responseKey(f reflect.StructField) (key string, inline, ok bool) {
+	value, tagged := f.Tag.Lookup("graphql")
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "... on *" || f.PkgPath != "":
+		return "", false, false
+	case !tagged:
+		if f.Anonymous {
+			return "", true, true
+		}
+		return ident.ParseMixedCaps(f.Name).ToLowerCamelCase(), false, true
+	case strings.HasPrefix(value, "..."):
+		return "", true, true
+	}
+	if i := strings.IndexAny(value, "(@"); i != -1 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, ":"); i != -1 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), false, true
+}
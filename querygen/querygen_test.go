@@ -0,0 +1,109 @@
+package querygen_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/internal/jsonutil"
+	"github.com/arvata-io/graphql/querygen"
+)
+
+var update = flag.Bool("update", false, "update generated code of tests")
+
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	err := querygen.Generate(&buf, "querygen_test", querygen.Type{
+		Value: issuesQuery{},
+		Variables: map[string]interface{}{
+			"owner": graphql.String(""),
+			"name":  graphql.String(""),
+			"first": graphql.Int(0),
+		},
+		Name: "Issues",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const file = "generated_test.go"
+	if *update {
+		if err := ioutil.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("generated code differs from %s; run go test -update to update it:\n%s", file, buf.Bytes())
+	}
+}
+
+func TestGenerate_notNamedStruct(t *testing.T) {
+	var v struct {
+		Viewer struct{ Login graphql.String }
+	}
+	if err := querygen.Generate(ioutil.Discard, "p", querygen.Type{Value: v}); err == nil {
+		t.Error("got no error for an unnamed struct type")
+	}
+}
+
+const issuesData = `{
+	"repository": {
+		"id": "R_1",
+		"name": "graphql",
+		"stars": 1234,
+		"owner": {"__typename": "User", "login": "gopher", "bio": null, "seats": 3},
+		"issues": {
+			"totalCount": 2,
+			"nodes": [
+				{"number": 1, "title": "First \"issue\"", "closed": true, "score": 1.5, "created": "2020-01-01T00:00:00Z", "closedAt": "2020-01-02T00:00:00Z", "author": {"login": "a"}, "updatedAt": "today"},
+				{"number": 2, "title": "Second", "closed": false, "score": 2, "created": "2020-01-03T00:00:00Z", "closedAt": null, "author": null}
+			]
+		},
+		"topics": ["go", "graphql"]
+	}
+}`
+
+func TestDecodeGraphQL(t *testing.T) {
+	var got issuesQuery
+	if err := got.DecodeGraphQL([]byte(issuesData)); err != nil {
+		t.Fatal(err)
+	}
+	var want issuesQuery
+	if err := jsonutil.UnmarshalGraphQL([]byte(issuesData), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("generated decoder differs from jsonutil:\ngot:  %+v\nwant: %+v", got, want)
+	}
+	if got.Repository.Owner.User.Login != "gopher" || got.Repository.Owner.Organization.Login != "gopher" {
+		t.Errorf("got owner: %+v", got.Repository.Owner)
+	}
+}
+
+func BenchmarkDecodeGraphQL(b *testing.B) {
+	data := []byte(issuesData)
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var q issuesQuery
+			if err := q.DecodeGraphQL(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("jsonutil", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var q issuesQuery
+			if err := jsonutil.UnmarshalGraphQL(data, &q); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package querygen_test
+
+import (
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// issuesQuery is a query data structure covering the kinds of fields
+// generated decoders handle.
+type issuesQuery struct {
+	Repository struct {
+		ID    graphql.ID
+		Name  graphql.String
+		Stars graphql.Int `graphql:"stars: stargazerCount"`
+		Owner struct {
+			Typename graphql.String `graphql:"__typename"`
+			User     struct {
+				Login graphql.String
+				Bio   *graphql.String
+			} `graphql:"... on User"`
+			Organization struct {
+				Login graphql.String
+				Seats int64
+			} `graphql:"... on Organization"`
+		}
+		Issues struct {
+			TotalCount graphql.Int
+			Nodes      []issue
+		} `graphql:"issues(first: $first)"`
+		Topics []string
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+type issue struct {
+	Number   graphql.Int
+	Title    graphql.String
+	Closed   graphql.Boolean
+	Score    graphql.Float
+	Created  time.Time `graphql:"created: createdAt"`
+	ClosedAt *time.Time
+	Author   *struct {
+		Login graphql.String
+	}
+	Timestamps
+}
+
+type Timestamps struct {
+	UpdatedAt graphql.String
+}
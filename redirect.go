@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithRedirects makes the client follow redirects, which it doesn't by
+// default, to the host of the GraphQL server URL, as resolved for the
+// request if it's a template, and to hosts. Following
+// a redirect to an unexpected host can leak credentials in request headers,
+// so hosts should only list trusted hosts, such as "api.example.com".
+// A host of "*.example.com" matches all subdomains of example.com.
+func WithRedirects(hosts ...string) ClientOption {
+	return func(c *Client) {
+		c.redirectHosts = append([]string{""}, hosts...) // "" stands for the server's host.
+	}
+}
+
+// RedirectError is returned (wrapped in a *url.Error) when the server
+// redirects the client to a location it's not allowed to follow.
+type RedirectError struct {
+	Location *url.URL
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("graphql: not following redirect to %v", e.Location)
+}
+
+// checkRedirect is the CheckRedirect function of c's HTTP client.
+// next is the HTTP client's original CheckRedirect function, if any.
+func (c *Client) checkRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !redirectAllowed(c.redirectHosts, req.URL, via[0].URL) {
+			return &RedirectError{Location: req.URL}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+}
+
+// redirectAllowed reports whether a redirect to u may be followed, to
+// hosts, where "" stands for the host of server, the URL the redirected
+// request was first sent to.
+func redirectAllowed(hosts []string, u, server *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, h := range hosts {
+		if h == "" {
+			if strings.EqualFold(server.Hostname(), host) {
+				return true
+			}
+			continue
+		}
+		h = strings.ToLower(h)
+		if h == host || strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:]) {
+			return true
+		}
+	}
+	return false
+}
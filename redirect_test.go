@@ -0,0 +1,91 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_redirects(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Host + req.URL.Path {
+		case "api.example.com/graphql":
+			http.Redirect(w, req, "https://api.example.com/v2/graphql", http.StatusTemporaryRedirect)
+		case "api.example.com/v2/graphql", "eu.gateway.example.net/graphql":
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+		case "other.example.com/graphql":
+			http.Redirect(w, req, "https://eu.gateway.example.net/graphql", http.StatusTemporaryRedirect)
+		case "evil.example.com/graphql":
+			http.Redirect(w, req, "https://attacker.example.org/graphql", http.StatusTemporaryRedirect)
+		default:
+			t.Errorf("unexpected request to %v", req.URL)
+		}
+	})
+	httpClient := &http.Client{Transport: localRoundTripper{handler: handler}}
+
+	tests := []struct {
+		url     string
+		opts    []graphql.ClientOption
+		wantErr string
+	}{
+		{
+			url:     "https://api.example.com/graphql",
+			wantErr: `Post "https://api.example.com/v2/graphql": graphql: not following redirect to https://api.example.com/v2/graphql`,
+		},
+		{
+			url:  "https://api.example.com/graphql",
+			opts: []graphql.ClientOption{graphql.WithRedirects()},
+		},
+		{
+			url:  "https://other.example.com/graphql",
+			opts: []graphql.ClientOption{graphql.WithRedirects("*.example.net")},
+		},
+		{
+			url:     "https://evil.example.com/graphql",
+			opts:    []graphql.ClientOption{graphql.WithRedirects("*.example.net")},
+			wantErr: `Post "https://attacker.example.org/graphql": graphql: not following redirect to https://attacker.example.org/graphql`,
+		},
+	}
+	for _, tc := range tests {
+		client := graphql.NewClient(tc.url, httpClient, tc.opts...)
+		err := client.Query(context.Background(), new(viewerQuery), nil)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: got error: %v", tc.url, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("%s: got error: %v, want: %v", tc.url, err, tc.wantErr)
+		}
+		var rerr *graphql.RedirectError
+		if !errors.As(err, &rerr) {
+			t.Errorf("%s: got error of type %T, want wrapped *graphql.RedirectError", tc.url, err)
+		}
+	}
+	if httpClient.CheckRedirect != nil {
+		t.Error("NewClient modified CheckRedirect of given HTTP client")
+	}
+}
+
+func TestClient_redirectsURLTemplate(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Host + req.URL.Path {
+		case "eu.example.com/graphql":
+			http.Redirect(w, req, "https://eu.example.com/v2/graphql", http.StatusTemporaryRedirect)
+		case "eu.example.com/v2/graphql":
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+		default:
+			t.Errorf("unexpected request to %v", req.URL)
+		}
+	})
+	client := graphql.NewClient("https://{region}.example.com/graphql", &http.Client{Transport: localRoundTripper{handler: handler}},
+		graphql.WithRedirects())
+	ctx := graphql.WithURLParams(context.Background(), map[string]string{"region": "eu"})
+	if err := client.Query(ctx, new(viewerQuery), nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package registry integrates clients with schema registries, such as
+// GraphQL Hive and Apollo GraphOS, which publish the schemas of servers
+// and collect the usage of their operations. Both are opt-in: operations
+// are checked against the published schema by calling Check, typically at
+// startup, and usage is reported by clients created with the option
+// returned by Registry.ReportUsage.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Registry is a schema registry.
+type Registry struct {
+	// SchemaURL is the URL of the SDL of the published schema, such as
+	// the "sdl" artifact of a Hive CDN target.
+	SchemaURL string
+
+	// UsageURL is the URL usage reports are posted to.
+	UsageURL string
+
+	// Header is sent with requests to the registry, such as
+	// "X-Hive-CDN-Key" or "Authorization" credentials.
+	Header http.Header
+
+	// HTTPClient sends requests to the registry. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	usage map[string]*OperationUsage // Hash -> usage since the last report.
+}
+
+// Schema returns the SDL of the published schema.
+func (r *Registry) Schema(ctx context.Context) ([]byte, error) {
+	req, err := r.newRequest(ctx, http.MethodGet, r.SchemaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry: fetching schema: non-200 OK status code: %v", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Check checks operations ops against the published schema, as
+// graphql.CheckCompatibility does, and returns the issues found.
+func (r *Registry) Check(ctx context.Context, ops ...graphql.Operation) ([]graphql.Issue, error) {
+	sdl, err := r.Schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphql.CheckCompatibility(sdl, ops...), nil
+}
+
+// OperationUsage is the usage of an operation, in usage reports.
+type OperationUsage struct {
+	Hash   string `json:"hash"`           // Hex SHA-256 hash of the document.
+	Name   string `json:"name,omitempty"` // Operation name, if any.
+	Query  string `json:"query"`          // Document of the operation.
+	Count  int    `json:"count"`          // Times the operation was run.
+	Errors int    `json:"errors"`         // Times the operation failed.
+}
+
+// Report is a usage report, posted as JSON to the UsageURL of a registry:
+//
+//	{
+//		"start": "2024-05-04T10:00:00Z",
+//		"end": "2024-05-04T10:01:00Z",
+//		"operations": [
+//			{"hash": "9f86…", "name": "Viewer", "query": "query Viewer{viewer{login}}", "count": 12, "errors": 1}
+//		]
+//	}
+type Report struct {
+	Start      time.Time        `json:"start"`
+	End        time.Time        `json:"end"`
+	Operations []OperationUsage `json:"operations"` // In order of hash.
+}
+
+// ReportUsage returns a client option that makes the client record the
+// usage of its operations in r, to be reported by Flush or Run.
+func (r *Registry) ReportUsage() graphql.ClientOption {
+	return graphql.WithObserver(func(_ context.Context, e graphql.OperationEvent) {
+		r.record(e)
+	})
+}
+
+func (r *Registry) record(e graphql.OperationEvent) {
+	sum := sha256.Sum256([]byte(e.Query))
+	hash := hex.EncodeToString(sum[:])
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.usage == nil {
+		r.usage = make(map[string]*OperationUsage)
+	}
+	u, ok := r.usage[hash]
+	if !ok {
+		u = &OperationUsage{Hash: hash, Name: e.Name, Query: e.Query}
+		r.usage[hash] = u
+	}
+	u.Count++
+	if e.Err != nil {
+		u.Errors++
+	}
+}
+
+// Flush posts a report of the usage recorded since the last report
+// ended at since, if any, to the UsageURL of r. The usage is dropped,
+// whether the report is posted or not. It returns the end of the report.
+func (r *Registry) Flush(ctx context.Context, since time.Time) (time.Time, error) {
+	r.mu.Lock()
+	usage := r.usage
+	r.usage = nil
+	r.mu.Unlock()
+	report := Report{Start: since, End: time.Now(), Operations: make([]OperationUsage, 0, len(usage))}
+	if len(usage) == 0 {
+		return report.End, nil
+	}
+	for _, u := range usage {
+		report.Operations = append(report.Operations, *u)
+	}
+	sort.Slice(report.Operations, func(i, j int) bool { return report.Operations[i].Hash < report.Operations[j].Hash })
+	body, err := json.Marshal(report)
+	if err != nil {
+		return report.End, err
+	}
+	req, err := r.newRequest(ctx, http.MethodPost, r.UsageURL, bytes.NewReader(body))
+	if err != nil {
+		return report.End, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.httpClient().Do(req)
+	if err != nil {
+		return report.End, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return report.End, fmt.Errorf("registry: reporting usage: non-2xx status code: %v", resp.Status)
+	}
+	return report.End, nil
+}
+
+// Run reports usage every interval until ctx is done, then reports the
+// remaining usage, with a new context. Errors are passed to onError,
+// if not nil.
+func (r *Registry) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	since := time.Now()
+	report := func(ctx context.Context) {
+		end, err := r.Flush(ctx, since)
+		since = end
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			report(ctx)
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			report(ctx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (r *Registry) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+func (r *Registry) httpClient() *http.Client {
+	if r.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return r.HTTPClient
+}
@@ -0,0 +1,178 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/registry"
+)
+
+const sdl = `
+type Query {
+	viewer: User!
+}
+
+type User {
+	login: String!
+}
+`
+
+// newRegistry returns a registry served by a fake, which records the
+// usage reports posted to it in reports.
+func newRegistry(t *testing.T, reports *[]registry.Report) *registry.Registry {
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Hive-CDN-Key") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch req.URL.Path {
+		case "/sdl":
+			io.WriteString(w, sdl)
+		case "/usage":
+			var r registry.Report
+			if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			*reports = append(*reports, r)
+			mu.Unlock()
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &registry.Registry{
+		SchemaURL: srv.URL + "/sdl",
+		UsageURL:  srv.URL + "/usage",
+		Header:    http.Header{"X-Hive-CDN-Key": {"key"}},
+	}
+}
+
+func TestRegistryCheck(t *testing.T) {
+	r := newRegistry(t, nil)
+
+	var ok struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	var removed struct {
+		Viewer struct {
+			Name graphql.String
+		}
+	}
+	issues, err := r.Check(context.Background(), graphql.NewQuery(&ok, nil), graphql.NewQuery(&removed, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Kind != graphql.InvalidOperation || !strings.Contains(issues[0].Message, "name") {
+		t.Errorf("got issues %v, want one about field name", issues)
+	}
+
+	r.Header = nil
+	if _, err := r.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("got error %v, want a 401 status error", err)
+	}
+}
+
+func TestRegistryReportUsage(t *testing.T) {
+	var reports []registry.Report
+	r := newRegistry(t, &reports)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), "Fail") {
+			io.WriteString(w, `{"errors": [{"message": "boom"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, nil, r.ReportUsage())
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := client.Run(context.Background(), &graphql.Query{Data: &q, Name: "Viewer"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.Query{Data: &q, Name: "Fail"}); err == nil {
+		t.Fatal("got nil error, want one")
+	}
+
+	since := time.Now().Add(-time.Minute)
+	if _, err := r.Flush(context.Background(), since); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	got := map[string]registry.OperationUsage{}
+	for _, u := range reports[0].Operations {
+		if len(u.Hash) != 64 {
+			t.Errorf("got hash %q, want a hex SHA-256 hash", u.Hash)
+		}
+		got[u.Name] = u
+	}
+	if u := got["Viewer"]; u.Count != 3 || u.Errors != 0 || u.Query != "query Viewer{viewer{login}}" {
+		t.Errorf("got Viewer usage %+v, want 3 runs without errors", u)
+	}
+	if u := got["Fail"]; u.Count != 1 || u.Errors != 1 {
+		t.Errorf("got Fail usage %+v, want 1 run with an error", u)
+	}
+	if !reports[0].Start.Equal(since) {
+		t.Errorf("got start %v, want %v", reports[0].Start, since)
+	}
+
+	// Usage is reported once.
+	if _, err := r.Flush(context.Background(), since); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Errorf("got %d reports without usage, want none", len(reports)-1)
+	}
+}
+
+func TestRegistryRun(t *testing.T) {
+	var reports []registry.Report
+	r := newRegistry(t, &reports)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, nil, r.ReportUsage())
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx, time.Hour, func(err error) { t.Error(err) })
+	}()
+	cancel()
+	<-done
+	if len(reports) != 1 || len(reports[0].Operations) != 1 {
+		t.Errorf("got reports %+v, want one with the remaining usage", reports)
+	}
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Result is the result of executing a GraphQL operation whose data
+// is decoded into a value of type T.
+type Result[T any] struct {
+	Data       T
+	Errors     ErrorList      // GraphQL errors in the response, if any.
+	Extensions map[string]any // The "extensions" member of the response.
+	HTTP       ResponseMeta
+}
+
+// Err returns r.Errors if non-empty, or nil.
+func (r *Result[T]) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors
+}
+
+// QueryResult executes a single GraphQL query request, with a query derived
+// from T, which should be a struct type that corresponds to the GraphQL schema.
+//
+// GraphQL errors in the response are returned in the result's Errors,
+// not as err, which only reports failures to execute the request or to
+// decode its response. The result's HTTP metadata is set whenever
+// a response was received.
+func QueryResult[T any](ctx context.Context, c *Client, variables map[string]any) (*Result[T], error) {
+	r := new(Result[T])
+	return r, r.exec(ctx, c, &Query{Data: &r.Data, Vars: variables})
+}
+
+// MutateResult is like QueryResult, but executes a single GraphQL
+// mutation request, with a mutation derived from T.
+func MutateResult[T any](ctx context.Context, c *Client, variables map[string]any) (*Result[T], error) {
+	r := new(Result[T])
+	return r, r.exec(ctx, c, &Mutation{Data: &r.Data, Vars: variables})
+}
+
+// RunResult is like QueryResult, but executes op, which must decode
+// into a *T, such as a *Static with an Into of type *T.
+func RunResult[T any](ctx context.Context, c *Client, op Operation) (*Result[T], error) {
+	r := new(Result[T])
+	data, ok := op.ResponsePtr().(*T)
+	if !ok {
+		return r, fmt.Errorf("operation decodes into %T, not %T", op.ResponsePtr(), data)
+	}
+	err := r.exec(ctx, c, op)
+	r.Data = *data
+	return r, err
+}
+
+func (r *Result[T]) exec(ctx context.Context, c *Client, op Operation) error {
+	out, err := c.do(ctx, op)
+	r.Errors, r.Extensions, r.HTTP = out.Errors, out.Extensions, out.HTTP
+	return err
+}
@@ -0,0 +1,76 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestQueryResult(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		mustWrite(w, `{
+			"data": {"viewer": {"login": "gopher"}},
+			"errors": [{"message": "partial", "path": ["viewer", "email"], "extensions": {"code": "FORBIDDEN"}}],
+			"extensions": {"cost": 1}
+		}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	r, err := graphql.QueryResult[viewerQuery](context.Background(), client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Data.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+	if got, want := len(r.Errors), 1; got != want {
+		t.Fatalf("got %d errors, want %d", got, want)
+	}
+	if got, want := r.Errors[0].Extensions["code"], "FORBIDDEN"; got != want {
+		t.Errorf("got error code: %v, want: %v", got, want)
+	}
+	if got, want := len(r.Errors[0].Path), 2; got != want {
+		t.Errorf("got path length: %v, want: %v", got, want)
+	}
+	if r.Err() == nil {
+		t.Error("got Err: nil, want: non-nil")
+	}
+	if got, want := r.Extensions["cost"], 1.0; got != want {
+		t.Errorf("got cost extension: %v, want: %v", got, want)
+	}
+	if got, want := r.HTTP.StatusCode, http.StatusOK; got != want {
+		t.Errorf("got status code: %v, want: %v", got, want)
+	}
+	if got, want := r.HTTP.Header.Get("X-Request-Id"), "abc"; got != want {
+		t.Errorf("got X-Request-Id: %q, want: %q", got, want)
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	r, err := graphql.RunResult[viewerQuery](context.Background(), client, &graphql.Static{
+		QueryStr: "{viewer{login}}",
+		Into:     new(viewerQuery),
+	})
+	if err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+	if got, want := r.HTTP.StatusCode, http.StatusBadGateway; got != want {
+		t.Errorf("got status code: %v, want: %v", got, want)
+	}
+
+	_, err = graphql.RunResult[repoQuery](context.Background(), client, graphql.NewQuery(new(viewerQuery), nil))
+	if got, want := err.Error(), "operation decodes into *graphql_test.viewerQuery, not *graphql_test.repoQuery"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_RoundTrip(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if got, want := mustRead(req.Body), `{"query":"query Q($id:ID!){node(id:$id){id}}","variables":{"id":"1"},"operationName":"Q","extensions":{"trace":true}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"node": {"id": "1"}}, "errors": [{"message": "deprecated", "locations": [{"line": 1, "column": 2}]}]}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	resp, err := client.RoundTrip(context.Background(), graphql.Request{
+		Query:         "query Q($id:ID!){node(id:$id){id}}",
+		Variables:     map[string]interface{}{"id": "1"},
+		OperationName: "Q",
+		Extensions:    map[string]interface{}{"trace": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.HTTP.Header.Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("got Content-Type: %q, want: %q", got, want)
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"data":{"node":{"id":"1"}},"errors":[{"message":"deprecated","locations":[{"line":1,"column":2}]}]}`; got != want {
+		t.Errorf("got re-encoded response:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestClient_RoundTrip_nullData(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": null, "errors": [{"message": "boom"}]}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	resp, err := client.RoundTrip(context.Background(), graphql.Request{Query: "{a}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data != nil {
+		t.Errorf("got data: %s, want: nil", resp.Data)
+	}
+	if got, want := resp.Errors.Error(), "boom"; got != want {
+		t.Errorf("got error: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Routed is an operation sent to a different endpoint than the client's
+// GraphQL server URL, such as a preview API host or another gateway path.
+type Routed struct {
+	Operation
+	URL string // Endpoint URL, absolute or relative to the client's URL.
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *Routed) Unwrap() Operation {
+	return op.Operation
+}
+
+// asOperation returns the first operation implementing T in the chain
+// of op and operations it wraps, as reported by their Unwrap methods.
+func asOperation[T any](op Operation) (T, bool) {
+	for op != nil {
+		if t, ok := op.(T); ok {
+			return t, true
+		}
+		u, ok := op.(interface{ Unwrap() Operation })
+		if !ok {
+			break
+		}
+		op = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// endpoint returns the URL to send op, whose query document as sent
+// is query, to, with URL template parameters expanded.
+func (c *Client) endpoint(ctx context.Context, op Operation, query string) (string, error) {
+	base, err := expandURL(ctx, c.url, op.Variables())
+	if err != nil {
+		return "", err
+	}
+	var routeURL string
+	if r, ok := asOperation[*Routed](op); ok {
+		routeURL = r.URL
+	}
+	if routeURL == "" && len(c.routes) > 0 {
+		routeURL = c.route(op, query)
+	}
+	if routeURL == "" {
+		return base, nil
+	}
+	route, err := expandURL(ctx, routeURL, op.Variables())
+	if err != nil {
+		return "", err
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(route)
+	if err != nil {
+		return "", fmt.Errorf("invalid route URL: %v", err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_routed(t *testing.T) {
+	var urls []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		urls = append(urls, req.URL.String())
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("https://api.example.com/graphql", &http.Client{Transport: localRoundTripper{handler: handler}},
+		graphql.WithOperationNameInBody(),
+	)
+
+	ops := []graphql.Operation{
+		graphql.NewQuery(new(viewerQuery), nil),
+		&graphql.Routed{Operation: graphql.NewQuery(new(viewerQuery), nil), URL: "/preview/graphql"},
+		&graphql.Routed{Operation: graphql.NewQuery(new(viewerQuery), nil), URL: "https://preview.example.com/graphql"},
+		&graphql.Persisted{Operation: &graphql.Routed{Operation: &graphql.Query{Data: new(viewerQuery), Name: "V"}, URL: "v2"}, ID: "v"},
+	}
+	for _, op := range ops {
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"https://api.example.com/graphql",
+		"https://api.example.com/preview/graphql",
+		"https://preview.example.com/graphql",
+		"https://api.example.com/v2",
+	}
+	if len(urls) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(urls), len(want))
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("request %d: got URL: %v, want: %v", i, urls[i], want[i])
+		}
+	}
+}
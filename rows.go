@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Rows is an iterator over the nodes of a connection in the responses to
+// a query, as rows of their fields, returned by Client.Rows. It's used
+// like database/sql.Rows:
+//
+//	rows, err := client.Rows(ctx, `query($after: String) {
+//		repository(owner: "octocat", name: "Hello-World") {
+//			issues(first: 100, after: $after) {
+//				nodes { number title author { login } }
+//				pageInfo { endCursor hasNextPage }
+//			}
+//		}
+//	}`, map[string]interface{}{"after": (*graphql.String)(nil)}, "repository", "issues")
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//	for rows.Next() {
+//		var number int
+//		var title, author string
+//		if err := rows.Scan(&number, &title, &author); err != nil {
+//			return err
+//		}
+//	}
+//	return rows.Err()
+type Rows struct {
+	ctx    context.Context
+	client *Client
+	op     *Static
+	path   []string
+
+	nodes   []json.RawMessage // Nodes of the current page.
+	edges   bool              // Whether nodes are edges, with a node field.
+	i       int               // Index in nodes of the next row.
+	cursor  string            // End cursor of the current page, if there's a next page.
+	columns []string
+	row     map[string]json.RawMessage // Current row, by column.
+	err     error
+	closed  bool
+}
+
+// Rows runs query with vars, and returns an iterator over the nodes of the
+// connection at path, a sequence of response keys from the data. The
+// connection has a nodes field, or an edges field whose elements have a
+// node field. If it has a pageInfo field, having endCursor and hasNextPage
+// fields, and vars have an "after" variable, following pages are fetched
+// as rows are read, with the end cursor of the previous page as "after".
+// Only one page of nodes is held in memory at a time. vars aren't modified.
+func (c *Client) Rows(ctx context.Context, query string, vars map[string]interface{}, path ...string) (*Rows, error) {
+	r := &Rows{
+		ctx:    ctx,
+		client: c,
+		op:     &Static{QueryStr: query, Vars: copyVariables(vars)},
+		path:   path,
+	}
+	if err := r.fetch(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// fetch fetches the page of the variables of r.op.
+func (r *Rows) fetch() error {
+	var data rawData
+	r.op.Into = &data
+	if err := r.client.Run(r.ctx, r.op); err != nil {
+		return err
+	}
+	var conn struct {
+		Nodes    []json.RawMessage
+		Edges    []json.RawMessage
+		PageInfo *struct {
+			EndCursor   *string
+			HasNextPage bool
+		}
+	}
+	value := json.RawMessage(data)
+	for _, key := range r.path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return fmt.Errorf("graphql: rows: %q isn't an object", key)
+		}
+		value = obj[key]
+		if value == nil || string(value) == "null" {
+			return errors.New("graphql: rows: connection is null")
+		}
+	}
+	if err := json.Unmarshal(value, &conn); err != nil {
+		return fmt.Errorf("graphql: rows: invalid connection: %v", err)
+	}
+	r.nodes, r.edges, r.i, r.cursor = conn.Nodes, false, 0, ""
+	if conn.Nodes == nil && conn.Edges != nil {
+		r.nodes, r.edges = conn.Edges, true
+	}
+	if _, ok := r.op.Vars["after"]; ok && conn.PageInfo != nil && conn.PageInfo.HasNextPage {
+		if conn.PageInfo.EndCursor == nil || *conn.PageInfo.EndCursor == "" {
+			return errors.New("graphql: rows: connection has a next page but no endCursor")
+		}
+		r.cursor = *conn.PageInfo.EndCursor
+	}
+	return nil
+}
+
+// Next prepares the next row for reading with Scan, fetching the next page
+// of the connection if needed. It returns false when there are no more
+// rows, or an error occurred, reported by Err.
+func (r *Rows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+	for r.i == len(r.nodes) {
+		if r.cursor == "" {
+			return false
+		}
+		r.op.Vars["after"] = String(r.cursor)
+		if r.err = r.fetch(); r.err != nil {
+			return false
+		}
+	}
+	node := r.nodes[r.i]
+	r.i++
+	if r.edges {
+		var edge struct{ Node json.RawMessage }
+		if r.err = json.Unmarshal(node, &edge); r.err != nil {
+			return false
+		}
+		node = edge.Node
+	}
+	r.row = nil
+	if string(node) == "null" {
+		return true
+	}
+	keys, values, err := flattenObject(node)
+	if err != nil {
+		r.err = fmt.Errorf("graphql: rows: %v", err)
+		return false
+	}
+	if r.columns == nil {
+		r.columns = keys
+	}
+	r.row = make(map[string]json.RawMessage, len(keys))
+	for i, k := range keys {
+		r.row[k] = values[i]
+	}
+	return true
+}
+
+// Columns returns the columns of the rows: the paths of the fields of the
+// first node, such as "author.login", in the order they were selected.
+// Fields of objects that are null in the first node are one column.
+// It returns nil until the first row is read with Next.
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Scan copies the values of the columns of the current row into dest,
+// which has a pointer for each column, decoding them with encoding/json.
+// Null values, and values of columns the row doesn't have, are set to
+// the zero value. Lists and objects can be scanned into json.RawMessage.
+func (r *Rows) Scan(dest ...interface{}) error {
+	if r.closed {
+		return errors.New("graphql: rows are closed")
+	}
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("graphql: expected %d destination arguments in Scan, not %d", len(r.columns), len(dest))
+	}
+	for i, col := range r.columns {
+		v := reflect.ValueOf(dest[i])
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return fmt.Errorf("graphql: Scan destination %d of type %T isn't a non-nil pointer", i, dest[i])
+		}
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		value, ok := r.row[col]
+		if !ok || string(value) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(value, dest[i]); err != nil {
+			return fmt.Errorf("graphql: scanning column %s: %v", col, err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error that ended iteration with Next, if any.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close stops iteration, so no more pages are fetched. It's safe to call
+// more than once.
+func (r *Rows) Close() error {
+	r.closed, r.nodes, r.row = true, nil, nil
+	return nil
+}
+
+// rawData is a DataDecoder keeping response data as is.
+type rawData []byte
+
+func (d *rawData) DecodeGraphQL(data []byte) error {
+	*d = append((*d)[:0], data...)
+	return nil
+}
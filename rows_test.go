@@ -0,0 +1,83 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_Rows(t *testing.T) {
+	var afters []interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct{ Variables map[string]interface{} }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		afters = append(afters, body.Variables["after"])
+		switch body.Variables["after"] {
+		case nil:
+			mustWrite(w, `{"data": {"repository": {"issues": {
+				"nodes": [
+					{"number": 1, "title": "First", "author": {"login": "gopher"}},
+					{"number": 2, "title": null, "author": null}
+				],
+				"pageInfo": {"endCursor": "c2", "hasNextPage": true}
+			}}}}`)
+		case "c2":
+			mustWrite(w, `{"data": {"repository": {"issues": {
+				"nodes": [],
+				"pageInfo": {"endCursor": "c3", "hasNextPage": true}
+			}}}}`)
+		default:
+			mustWrite(w, `{"data": {"repository": {"issues": {
+				"nodes": [{"number": 3, "title": "Third", "author": {"login": "octocat"}}],
+				"pageInfo": {"endCursor": null, "hasNextPage": false}
+			}}}}`)
+		}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	vars := map[string]interface{}{"after": (*graphql.String)(nil)}
+	rows, err := client.Rows(context.Background(), `query($after: String) {
+		repository { issues(first: 2, after: $after) { nodes { number title author { login } } pageInfo { endCursor hasNextPage } } }
+	}`, vars, "repository", "issues")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type row struct {
+		Number int
+		Title  string
+		Author *string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.Number, &r.Title, &r.Author); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	gopher, octocat := "gopher", "octocat"
+	want := []row{{1, "First", &gopher}, {2, "", nil}, {3, "Third", &octocat}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got rows: %+v, want: %+v", got, want)
+	}
+	if want := []string{"number", "title", "author.login"}; !reflect.DeepEqual(rows.Columns(), want) {
+		t.Errorf("got columns: %q, want: %q", rows.Columns(), want)
+	}
+	if want := []interface{}{nil, "c2", "c3"}; !reflect.DeepEqual(afters, want) {
+		t.Errorf("got after variables: %v, want: %v", afters, want)
+	}
+	if vars["after"] != (*graphql.String)(nil) {
+		t.Errorf("variables modified: %v", vars)
+	}
+}
@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithMaxConcurrency limits the requests the client has in flight to n,
+// including those of prefetches and loaders. Requests beyond it wait for
+// others to complete, or for their context to be done. It's useful with
+// RunAll, and for servers that limit the requests of each client.
+func WithMaxConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.inFlight = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquire waits for a slot for a request in flight, if c limits them,
+// returning a function releasing it, or the error of ctx if it's done
+// first.
+func (c *Client) acquire(ctx context.Context) (release func(), err error) {
+	if c.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.inFlight <- struct{}{}:
+		return func() { <-c.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// RunAllStats are the results of operations run by RunAllTimed.
+type RunAllStats struct {
+	Errs      []error         // Errors of the operations, at their indexes; nil for those that succeeded.
+	Durations []time.Duration // Time each operation took, at its index.
+	Elapsed   time.Duration   // Time all the operations took.
+}
+
+// Err returns the first error of the operations, or nil if all succeeded.
+func (s RunAllStats) Err() error {
+	for _, err := range s.Errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunAll runs independent operations ops concurrently, as Run does, and
+// waits for them. It returns their errors, at their indexes, nil for
+// those that succeeded. The requests in flight are limited as set by
+// WithMaxConcurrency.
+func (c *Client) RunAll(ctx context.Context, ops ...Operation) []error {
+	return c.RunAllTimed(ctx, ops...).Errs
+}
+
+// RunAllTimed is like RunAll, but also returns the time the operations
+// took, measured with the client's Clock.
+func (c *Client) RunAllTimed(ctx context.Context, ops ...Operation) RunAllStats {
+	stats := RunAllStats{
+		Errs:      make([]error, len(ops)),
+		Durations: make([]time.Duration, len(ops)),
+	}
+	start := c.clock.Now()
+	var wg sync.WaitGroup
+	wg.Add(len(ops))
+	for i, op := range ops {
+		go func(i int, op Operation) {
+			defer wg.Done()
+			opStart := c.clock.Now()
+			stats.Errs[i] = c.Run(ctx, op)
+			stats.Durations[i] = c.clock.Now().Sub(opStart)
+		}(i, op)
+	}
+	wg.Wait()
+	stats.Elapsed = c.clock.Now().Sub(start)
+	return stats
+}
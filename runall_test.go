@@ -0,0 +1,108 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestRunAll(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(body, "missing") {
+			mustWrite(w, `{"data": {"user": null}, "errors": [{"message": "user not found"}]}`)
+			return
+		}
+		mustWrite(w, `{"data": {"user": {"name": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithMaxConcurrency(2))
+
+	type userQuery struct {
+		User *struct {
+			Name graphql.String
+		} `graphql:"user(login: $login)"`
+	}
+	logins := []string{"a", "b", "missing", "c", "d", "e"}
+	queries := make([]userQuery, len(logins))
+	ops := make([]graphql.Operation, len(logins))
+	for i, login := range logins {
+		ops[i] = graphql.NewQuery(&queries[i], map[string]interface{}{"login": graphql.String(login)})
+	}
+	stats := client.RunAllTimed(context.Background(), ops...)
+	for i, err := range stats.Errs {
+		if wantErr := logins[i] == "missing"; (err != nil) != wantErr {
+			t.Errorf("operation %d: got error %v, want error: %v", i, err, wantErr)
+		}
+		if logins[i] != "missing" && (queries[i].User == nil || queries[i].User.Name != "gopher") {
+			t.Errorf("operation %d: got user %v, want gopher", i, queries[i].User)
+		}
+		if stats.Durations[i] <= 0 || stats.Durations[i] > stats.Elapsed {
+			t.Errorf("operation %d: got duration %v, want between 0 and %v", i, stats.Durations[i], stats.Elapsed)
+		}
+	}
+	if err := stats.Err(); err == nil || err.Error() != "user not found" {
+		t.Errorf("got error %v, want %q", err, "user not found")
+	}
+	if maxInFlight != 2 {
+		t.Errorf("got %d requests in flight at most, want 2", maxInFlight)
+	}
+
+	if errs := client.RunAll(context.Background(), ops[:2]...); len(errs) != 2 || errs[0] != nil || errs[1] != nil {
+		t.Errorf("got errors %v, want none", errs)
+	}
+}
+
+func TestWithMaxConcurrencyContextDone(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithMaxConcurrency(1))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	done := make(chan error)
+	go func() { done <- client.Query(context.Background(), &q, nil) }()
+	<-started // The first query has the only slot.
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var q2 struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Query(ctx, &q2, nil); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,5 +1,12 @@
 package graphql
 
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
 // Note: These custom types are meant to be used in queries for now.
 // But the plan is to switch to using native Go types (string, int, bool, time.Time, etc.).
 // See https://github.com/shurcooL/githubv4/issues/9 for details.
@@ -23,7 +30,16 @@ type (
 	// intended to be human-readable. When expected as an input type,
 	// any string (such as "VXNlci0xMA==") or integer (such as 4) input
 	// value will be accepted as an ID.
-	ID interface{}
+	//
+	// Servers differ in whether they encode IDs as JSON strings or numbers,
+	// so ID decodes from both, keeping numbers in their decimal form.
+	//
+	// ID is a string type, so converting an integer to it, as in ID(4),
+	// makes a string of the character with that code point, "\x04", rather
+	// than "4". Use NewID to make an ID from an integer. Variables of
+	// Go's string type are declared as String!, not ID!, so pass ID
+	// arguments as IDs.
+	ID string
 
 	// Int represents non-fractional signed whole numeric values.
 	// Int can represent values between -(2^31) and 2^31 - 1.
@@ -35,17 +51,78 @@ type (
 	String string
 )
 
+// Enum is implemented by GraphQL enum types that want forward-compatible
+// decoding. Servers may add new enum values at any time; when a response
+// contains a value that is not one of EnumValues, SetUnknown is called with
+// the raw value instead of storing it as is. Implementations typically set
+// the receiver to a designated Unknown constant, and may retain raw.
+//
+// SetUnknown is expected to have a pointer receiver.
+type Enum interface {
+	// EnumValues returns the enum values known to the client.
+	EnumValues() []string
+
+	// SetUnknown sets the receiver for an enum value that isn't known.
+	SetUnknown(raw string)
+}
+
 // NewBoolean is a helper to make a new *Boolean.
 func NewBoolean(v Boolean) *Boolean { return &v }
 
 // NewFloat is a helper to make a new *Float.
 func NewFloat(v Float) *Float { return &v }
 
-// NewID is a helper to make a new *ID.
-func NewID(v ID) *ID { return &v }
+// NewID is a helper to make a new *ID from v, which may be a string,
+// an integer, or a value of a type with one of those underlying types.
+func NewID(v interface{}) *ID {
+	id := toID(v)
+	return &id
+}
 
 // NewInt is a helper to make a new *Int.
 func NewInt(v Int) *Int { return &v }
 
 // NewString is a helper to make a new *String.
 func NewString(v String) *String { return &v }
+
+// UnmarshalJSON decodes id from a JSON string or number.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, (*string)(id))
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("cannot decode ID from %s", b)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// Equal reports whether id is equal to v, which may be an ID, a string
+// or an integer. Numeric IDs are equal to their decimal strings.
+func (id ID) Equal(v interface{}) bool {
+	return id == toID(v)
+}
+
+// toID converts v to an ID.
+func toID(v interface{}) ID {
+	switch v := v.(type) {
+	case ID:
+		return v
+	case string:
+		return ID(v)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return ID(rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ID(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return ID(strconv.FormatUint(rv.Uint(), 10))
+	}
+	return ID(fmt.Sprint(v))
+}
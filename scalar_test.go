@@ -1,6 +1,8 @@
 package graphql_test
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
 	"github.com/arvata-io/graphql"
@@ -28,3 +30,33 @@ func TestNewScalars(t *testing.T) {
 		t.Error("NewString returned nil")
 	}
 }
+
+func TestID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"a": {"id": "MDQ6VXNlcjE="}, "b": {"id": 12345678901234567890}, "c": {"id": null}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type node struct{ ID graphql.ID }
+	var q struct{ A, B, C node }
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.A.ID, graphql.ID("MDQ6VXNlcjE="); got != want {
+		t.Errorf("got a.id: %q, want: %q", got, want)
+	}
+	if got, want := q.B.ID, graphql.ID("12345678901234567890"); got != want {
+		t.Errorf("got b.id: %q, want: %q", got, want)
+	}
+	if q.C.ID != "" {
+		t.Errorf("got c.id: %q, want empty", q.C.ID)
+	}
+
+	if id := *graphql.NewID(uint64(12345678901234567890)); !id.Equal(q.B.ID) || !id.Equal("12345678901234567890") {
+		t.Errorf("got NewID: %q, want equal to %q", id, q.B.ID)
+	}
+	if graphql.ID("1").Equal(2) {
+		t.Error("got ID 1 equal to 2")
+	}
+}
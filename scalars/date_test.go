@@ -0,0 +1,110 @@
+package scalars_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+	"github.com/arvata-io/graphql/scalars"
+)
+
+const schema = `
+scalar Date
+scalar Duration
+scalar Decimal
+scalar Point
+scalar Geometry
+
+type Query {
+	shift(day: Date!, length: Duration!): Shift
+	total(amounts: [Decimal!]!): Decimal!
+	place(at: Point!): Place
+}
+
+type Place {
+	location: Point!
+	center: Point!
+	area: Geometry!
+}
+
+type Shift {
+	day: Date!
+	length: Duration!
+	break: Duration
+}
+`
+
+// newClient returns a client of a server that echoes the arguments of
+// the shift field, with a break given in seconds, and sums the amounts
+// given to the total field, and returns a place at the point given
+// to the place field.
+func newClient(t *testing.T) *graphql.Client {
+	t.Helper()
+	s, err := graphqltest.NewServer(schema, graphqltest.Resolvers{
+		"Query.shift": graphqltest.Resolver(func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"day": args["day"], "length": args["length"], "break": 1800.5}, nil
+		}),
+		"Query.total": graphqltest.Resolver(func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+			sum := new(big.Rat)
+			for _, a := range args["amounts"].([]interface{}) {
+				r, _ := new(big.Rat).SetString(a.(string))
+				sum.Add(sum, r)
+			}
+			return sum.FloatString(2), nil
+		}),
+		"Query.place": graphqltest.Resolver(func(_ interface{}, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{
+				"location": args["at"],
+				"center":   "SRID=4326;POINT(13.405 52.52)",
+				"area": map[string]interface{}{
+					"type":        "Polygon",
+					"coordinates": [][][]float64{{{13, 52}, {14, 52}, {14, 53}, {13, 52}}},
+				},
+			}, nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return graphql.NewClient(ts.URL, ts.Client())
+}
+
+func TestDate(t *testing.T) {
+	client := newClient(t)
+	var q struct {
+		Shift struct {
+			Day scalars.Date
+		} `graphql:"shift(day: $day, length: $length)"`
+	}
+	day := scalars.DateOf(time.Date(2024, time.February, 29, 23, 0, 0, 0, time.UTC))
+	err := client.Query(context.Background(), &q, map[string]interface{}{
+		"day":    day,
+		"length": scalars.Duration{Duration: time.Hour},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Shift.Day != day {
+		t.Errorf("got day: %v, want: %v", q.Shift.Day, day)
+	}
+	if got, want := q.Shift.Day.String(), "2024-02-29"; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := day.In(time.UTC), time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseDate_error(t *testing.T) {
+	for _, s := range []string{"2024-02-30", "2024-2-3", "20240203"} {
+		if _, err := scalars.ParseDate(s); err == nil {
+			t.Errorf("ParseDate(%q): got error: nil, want: non-nil", s)
+		}
+	}
+}
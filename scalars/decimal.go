@@ -0,0 +1,140 @@
+package scalars
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, of custom scalars named Decimal,
+// for values such as amounts of money that float64 can't represent.
+// It's encoded as a JSON string, such as "10.50", and decoded from
+// a JSON string or number. Its zero value is 0.
+//
+// Decimal only holds and validates the number. Arithmetic is left to a
+// decimal implementation of choice: values are converted to and from
+// implementations such as decimal.Decimal of github.com/shopspring/decimal,
+// or big.Float and big.Rat of math/big, with DecimalOf and Decimal.Into.
+type Decimal struct {
+	s string // In canonical form, such as "-0.5" or "1200"; empty means "0".
+}
+
+// ParseDecimal parses a decimal number, such as "-12.50" or "1.2e3".
+// Trailing zeros of the fraction are preserved.
+func ParseDecimal(s string) (Decimal, error) {
+	c, ok := canonicalDecimal(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{s: c}, nil
+}
+
+// DecimalOf converts n, which must marshal to a decimal number,
+// to a Decimal. Implementations that do include decimal.Decimal of
+// github.com/shopspring/decimal, *big.Float and *big.Int.
+func DecimalOf(n encoding.TextMarshaler) (Decimal, error) {
+	b, err := n.MarshalText()
+	if err != nil {
+		return Decimal{}, err
+	}
+	return ParseDecimal(string(b))
+}
+
+// DecimalFromRat converts r to a Decimal rounded to scale digits
+// after the decimal point.
+func DecimalFromRat(r *big.Rat, scale int) Decimal {
+	d, _ := ParseDecimal(r.FloatString(scale))
+	return d
+}
+
+// Into sets n, such as a *decimal.Decimal of github.com/shopspring/decimal,
+// or a *big.Float or *big.Rat, to the value of d.
+func (d Decimal) Into(n encoding.TextUnmarshaler) error {
+	return n.UnmarshalText([]byte(d.String()))
+}
+
+// Rat returns d as a *big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(d.String())
+	return r
+}
+
+// String returns d in decimal notation, without an exponent.
+func (d Decimal) String() string {
+	if d.s == "" {
+		return "0"
+	}
+	return d.s
+}
+
+// MarshalJSON encodes d as a JSON string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes d from a JSON string or number.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// maxDecimalExponent bounds the exponents ParseDecimal accepts,
+// since the canonical form of a number has no exponent.
+const maxDecimalExponent = 1000
+
+// canonicalDecimal returns s in canonical form, and whether s is
+// a valid decimal number.
+func canonicalDecimal(s string) (string, bool) {
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	mant, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return "", false
+		}
+		mant, exp = s[:i], e
+	}
+	intPart, frac, _ := strings.Cut(mant, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", false
+	}
+	point := len(intPart) + exp
+	if point < 0 {
+		digits = strings.Repeat("0", -point) + digits
+		point = 0
+	}
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+	c := strings.TrimLeft(digits[:point], "0")
+	if c == "" {
+		c = "0"
+	}
+	if frac := digits[point:]; frac != "" {
+		c += "." + frac
+	}
+	if neg && strings.Trim(c, "0.") != "" {
+		c = "-" + c
+	}
+	return c, true
+}
@@ -0,0 +1,68 @@
+package scalars_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/arvata-io/graphql/scalars"
+)
+
+func TestDecimal(t *testing.T) {
+	client := newClient(t)
+	var q struct {
+		Total scalars.Decimal `graphql:"total(amounts: $amounts)"`
+	}
+	a, err := scalars.ParseDecimal("0.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := scalars.DecimalOf(big.NewFloat(0.2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Query(context.Background(), &q, map[string]interface{}{
+		"amounts": []scalars.Decimal{a, b, scalars.DecimalFromRat(big.NewRat(1, 3), 2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Total.String(), "0.63"; got != want {
+		t.Errorf("got total: %v, want: %v", got, want)
+	}
+
+	var f big.Float
+	if err := q.Total.Into(&f); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.Text('f', 2), "0.63"; got != want {
+		t.Errorf("got big.Float: %v, want: %v", got, want)
+	}
+	if got, want := q.Total.Rat(), big.NewRat(63, 100); got.Cmp(want) != 0 {
+		t.Errorf("got big.Rat: %v, want: %v", got, want)
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var ds []scalars.Decimal
+	err := json.Unmarshal([]byte(`["10.50", 12345678901234567890.123456789, "-1.5E-3", "-0.0", null]`), &ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `["10.50","12345678901234567890.123456789","-0.0015","0.0","0"]`; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseDecimal_error(t *testing.T) {
+	for _, s := range []string{"", "-", ".", "1.2.3", "1e", "1e99999", "0x10", "NaN", "1/3"} {
+		if _, err := scalars.ParseDecimal(s); err == nil {
+			t.Errorf("ParseDecimal(%q): got error: nil, want: non-nil", s)
+		}
+	}
+}
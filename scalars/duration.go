@@ -0,0 +1,156 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationFormat is the JSON encoding of a Duration.
+type DurationFormat int
+
+const (
+	// ISO8601 encodes durations as ISO 8601 duration strings, such as "PT1H30M".
+	ISO8601 DurationFormat = iota
+	// Seconds encodes durations as JSON numbers of seconds, such as 5400.
+	Seconds
+)
+
+// Duration is a length of time, of custom scalars named Duration.
+// It's encoded in Format, and decoded from either format, in which
+// case Format is set to the format decoded.
+type Duration struct {
+	time.Duration
+	Format DurationFormat
+}
+
+// MarshalJSON encodes d in d.Format.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	if d.Format == Seconds {
+		return []byte(strconv.FormatFloat(d.Seconds(), 'f', -1, 64)), nil
+	}
+	return json.Marshal(FormatISO8601(d.Duration))
+}
+
+// UnmarshalJSON decodes d from an ISO 8601 duration string
+// or a number of seconds.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		v, err := ParseISO8601(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration{Duration: v, Format: ISO8601}
+		return nil
+	}
+	secs, err := strconv.ParseFloat(string(b), 64)
+	if err != nil || math.Abs(secs) > math.MaxInt64/float64(time.Second) {
+		return fmt.Errorf("cannot decode duration from %s", b)
+	}
+	*d = Duration{Duration: time.Duration(math.Round(secs * float64(time.Second))), Format: Seconds}
+	return nil
+}
+
+// ParseISO8601 parses an ISO 8601 duration, such as "PT1H30M" or "-P1DT0.5S".
+// Days are taken to be 24 hours long. Years and months aren't supported,
+// since their length varies.
+func ParseISO8601(s string) (time.Duration, error) {
+	orig := s
+	invalid := func() (time.Duration, error) {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return invalid()
+	}
+	s = s[1:]
+	var d float64
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return invalid()
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i <= 0 {
+			return invalid()
+		}
+		n, err := strconv.ParseFloat(strings.Replace(s[:i], ",", ".", 1), 64)
+		if err != nil {
+			return invalid()
+		}
+		var unit time.Duration
+		switch {
+		case !inTime && s[i] == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && s[i] == 'D':
+			unit = 24 * time.Hour
+		case !inTime && (s[i] == 'Y' || s[i] == 'M'):
+			return 0, fmt.Errorf("unsupported ISO 8601 duration %q: years and months have no fixed length", orig)
+		case inTime && s[i] == 'H':
+			unit = time.Hour
+		case inTime && s[i] == 'M':
+			unit = time.Minute
+		case inTime && s[i] == 'S':
+			unit = time.Second
+		default:
+			return invalid()
+		}
+		d += n * float64(unit)
+		s = s[i+1:]
+	}
+	if d > math.MaxInt64 {
+		return 0, fmt.Errorf("ISO 8601 duration %q out of range", orig)
+	}
+	if neg {
+		d = -d
+	}
+	return time.Duration(math.Round(d)), nil
+}
+
+// FormatISO8601 formats d as an ISO 8601 duration in hours,
+// minutes and seconds, such as "PT1H30M".
+func FormatISO8601(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	u := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		u = -u
+	}
+	b.WriteString("PT")
+	if h := u / uint64(time.Hour); h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		u -= h * uint64(time.Hour)
+	}
+	if m := u / uint64(time.Minute); m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		u -= m * uint64(time.Minute)
+	}
+	if u > 0 {
+		sec, ns := u/uint64(time.Second), u%uint64(time.Second)
+		if ns == 0 {
+			fmt.Fprintf(&b, "%dS", sec)
+		} else {
+			fmt.Fprintf(&b, "%d.%sS", sec, strings.TrimRight(fmt.Sprintf("%09d", ns), "0"))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package scalars_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql/scalars"
+)
+
+func TestDuration(t *testing.T) {
+	client := newClient(t)
+	var q struct {
+		Shift struct {
+			Length scalars.Duration
+			Break  *scalars.Duration
+		} `graphql:"shift(day: $day, length: $length)"`
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{
+		"day":    scalars.Date{Year: 2024, Month: time.March, Day: 1},
+		"length": scalars.Duration{Duration: 8*time.Hour + 30*time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Shift.Length, (scalars.Duration{Duration: 8*time.Hour + 30*time.Minute}); got != want {
+		t.Errorf("got length: %v, want: %v", got, want)
+	}
+	if got, want := *q.Shift.Break, (scalars.Duration{Duration: 30*time.Minute + 500*time.Millisecond, Format: scalars.Seconds}); got != want {
+		t.Errorf("got break: %v, want: %v", got, want)
+	}
+
+	b, err := json.Marshal([]scalars.Duration{*q.Shift.Break, {Duration: -90 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `[1800.5,"-PT1M30S"]`; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseISO8601(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"PT0S", 0},
+		{"PT1H30M", 90 * time.Minute},
+		{"P1DT12H", 36 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"PT0.25S", 250 * time.Millisecond},
+		{"PT1,5S", 1500 * time.Millisecond},
+		{"-PT5M", -5 * time.Minute},
+	}
+	for _, tc := range tests {
+		got, err := scalars.ParseISO8601(tc.in)
+		if err != nil {
+			t.Errorf("ParseISO8601(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseISO8601(%q): got %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	for _, s := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "P1M", "PTS"} {
+		if _, err := scalars.ParseISO8601(s); err == nil {
+			t.Errorf("ParseISO8601(%q): got error: nil, want: non-nil", s)
+		}
+	}
+}
+
+func TestFormatISO8601(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{36 * time.Hour, "PT36H"},
+		{time.Hour + 1500*time.Millisecond, "PT1H1.5S"},
+		{-time.Minute, "-PT1M"},
+	}
+	for _, tc := range tests {
+		if got := scalars.FormatISO8601(tc.in); got != tc.want {
+			t.Errorf("FormatISO8601(%v): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
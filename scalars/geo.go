@@ -0,0 +1,200 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PointFormat is the JSON encoding of a Point.
+type PointFormat int
+
+const (
+	// LatLng encodes points as objects, such as {"lat": 52.52, "lng": 13.405}.
+	LatLng PointFormat = iota
+	// WKT encodes points as Well-Known Text strings, such as "POINT(13.405 52.52)".
+	WKT
+	// GeoJSON encodes points as GeoJSON geometries,
+	// such as {"type": "Point", "coordinates": [13.405, 52.52]}.
+	GeoJSON
+)
+
+// Point is a geographic location, of custom scalars named Point.
+// It's encoded in Format, and decoded from any format, in which case
+// Format is set to the format decoded. Objects with "latitude" and
+// "longitude" or "lon" keys are decoded as LatLng too.
+type Point struct {
+	Lat, Lng float64
+	Format   PointFormat
+}
+
+// MarshalJSON encodes p in p.Format.
+func (p Point) MarshalJSON() ([]byte, error) {
+	switch p.Format {
+	case WKT:
+		return json.Marshal(p.WKT())
+	case GeoJSON:
+		return json.Marshal(Geometry{Type: "Point", Coordinates: p.coordinates()})
+	}
+	return json.Marshal(struct {
+		Lat float64 `json:"lat"`
+		Lng float64 `json:"lng"`
+	}{p.Lat, p.Lng})
+}
+
+// UnmarshalJSON decodes p from any format.
+func (p *Point) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		v, err := ParseWKTPoint(s)
+		if err != nil {
+			return err
+		}
+		*p = v
+		return nil
+	}
+	var o struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+		Lat         *float64  `json:"lat"`
+		Latitude    *float64  `json:"latitude"`
+		Lng         *float64  `json:"lng"`
+		Lon         *float64  `json:"lon"`
+		Longitude   *float64  `json:"longitude"`
+	}
+	if err := json.Unmarshal(b, &o); err != nil {
+		return fmt.Errorf("cannot decode point from %s", b)
+	}
+	if o.Type != "" {
+		if o.Type != "Point" || len(o.Coordinates) < 2 {
+			return fmt.Errorf("cannot decode point from GeoJSON %s geometry", o.Type)
+		}
+		*p = Point{Lat: o.Coordinates[1], Lng: o.Coordinates[0], Format: GeoJSON}
+		return nil
+	}
+	lat, lng := firstFloat(o.Lat, o.Latitude), firstFloat(o.Lng, o.Lon, o.Longitude)
+	if lat == nil || lng == nil {
+		return fmt.Errorf("cannot decode point from %s", b)
+	}
+	*p = Point{Lat: *lat, Lng: *lng, Format: LatLng}
+	return nil
+}
+
+func firstFloat(fs ...*float64) *float64 {
+	for _, f := range fs {
+		if f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// WKT returns p as Well-Known Text, such as "POINT(13.405 52.52)".
+func (p Point) WKT() string {
+	return "POINT(" + formatFloat(p.Lng) + " " + formatFloat(p.Lat) + ")"
+}
+
+func (p Point) coordinates() json.RawMessage {
+	return json.RawMessage("[" + formatFloat(p.Lng) + "," + formatFloat(p.Lat) + "]")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// ParseWKTPoint parses a point in Well-Known Text, such as
+// "POINT(13.405 52.52)", optionally with an SRID prefix as
+// in Extended Well-Known Text, such as "SRID=4326;POINT(13.405 52.52)".
+func ParseWKTPoint(s string) (Point, error) {
+	invalid := func() (Point, error) {
+		return Point{}, fmt.Errorf("invalid WKT point %q", s)
+	}
+	t := strings.TrimSpace(s)
+	if i := strings.Index(t, ";"); i != -1 && strings.HasPrefix(strings.ToUpper(t), "SRID=") {
+		t = strings.TrimSpace(t[i+1:])
+	}
+	if !strings.HasPrefix(strings.ToUpper(t), "POINT") {
+		return invalid()
+	}
+	t = strings.TrimSpace(t[len("POINT"):])
+	if !strings.HasPrefix(t, "(") || !strings.HasSuffix(t, ")") {
+		return invalid()
+	}
+	coords := strings.Fields(t[1 : len(t)-1])
+	if len(coords) < 2 || len(coords) > 3 {
+		return invalid()
+	}
+	lng, err1 := strconv.ParseFloat(coords[0], 64)
+	lat, err2 := strconv.ParseFloat(coords[1], 64)
+	if err1 != nil || err2 != nil {
+		return invalid()
+	}
+	return Point{Lat: lat, Lng: lng, Format: WKT}, nil
+}
+
+// Geometry is a GeoJSON geometry, of custom scalars such as GeoJSON
+// or Geometry. Its coordinates are kept as raw JSON, to be decoded into
+// Go types matching its Type with DecodeCoordinates.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []Geometry      `json:"geometries,omitempty"` // Of a GeometryCollection.
+}
+
+// NewGeometry returns a geometry of type typ with coordinates,
+// such as a [][]float64 for a LineString.
+func NewGeometry(typ string, coordinates interface{}) (Geometry, error) {
+	b, err := json.Marshal(coordinates)
+	if err != nil {
+		return Geometry{}, err
+	}
+	return Geometry{Type: typ, Coordinates: b}, nil
+}
+
+// DecodeCoordinates decodes the coordinates of g into v, such as
+// a *[]float64 for a Point, or a *[][][]float64 for a Polygon.
+func (g Geometry) DecodeCoordinates(v interface{}) error {
+	if len(g.Coordinates) == 0 {
+		return fmt.Errorf("GeoJSON %s geometry has no coordinates", g.Type)
+	}
+	return json.Unmarshal(g.Coordinates, v)
+}
+
+// Point returns g as a Point, if it's a GeoJSON Point.
+func (g Geometry) Point() (Point, error) {
+	if g.Type != "Point" {
+		return Point{}, fmt.Errorf("GeoJSON %s geometry is not a Point", g.Type)
+	}
+	var c []float64
+	if err := g.DecodeCoordinates(&c); err != nil {
+		return Point{}, err
+	}
+	if len(c) < 2 {
+		return Point{}, fmt.Errorf("GeoJSON Point has %d coordinates", len(c))
+	}
+	return Point{Lat: c[1], Lng: c[0], Format: GeoJSON}, nil
+}
+
+// UnmarshalJSON decodes g from a GeoJSON geometry object.
+func (g *Geometry) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	type geometry Geometry // Without methods, to avoid recursion.
+	var v geometry
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Type == "" {
+		return fmt.Errorf("GeoJSON geometry has no type: %s", b)
+	}
+	*g = Geometry(v)
+	return nil
+}
@@ -0,0 +1,85 @@
+package scalars_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql/scalars"
+)
+
+func TestPoint(t *testing.T) {
+	client := newClient(t)
+	var q struct {
+		Place struct {
+			Location scalars.Point
+			Center   scalars.Point
+			Area     scalars.Geometry
+		} `graphql:"place(at: $at)"`
+	}
+	at := scalars.Point{Lat: 52.52, Lng: 13.405}
+	if err := client.Query(context.Background(), &q, map[string]interface{}{"at": at}); err != nil {
+		t.Fatal(err)
+	}
+	if q.Place.Location != at {
+		t.Errorf("got location: %+v, want: %+v", q.Place.Location, at)
+	}
+	if got, want := q.Place.Center, (scalars.Point{Lat: 52.52, Lng: 13.405, Format: scalars.WKT}); got != want {
+		t.Errorf("got center: %+v, want: %+v", got, want)
+	}
+
+	if q.Place.Area.Type != "Polygon" {
+		t.Errorf("got area type: %v, want: Polygon", q.Place.Area.Type)
+	}
+	var rings [][][2]float64
+	if err := q.Place.Area.DecodeCoordinates(&rings); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rings, [][][2]float64{{{13, 52}, {14, 52}, {14, 53}, {13, 52}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got area coordinates: %v, want: %v", got, want)
+	}
+	if _, err := q.Place.Area.Point(); err == nil {
+		t.Error("got Point of Polygon error: nil, want: non-nil")
+	}
+}
+
+func TestPoint_JSON(t *testing.T) {
+	var ps []scalars.Point
+	err := json.Unmarshal([]byte(`[
+		{"latitude": 1, "longitude": 2},
+		{"type": "Point", "coordinates": [4, 3]},
+		"point (6 5)"
+	]`), &ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `[{"lat":1,"lng":2},{"type":"Point","coordinates":[4,3]},"POINT(6 5)"]`; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	for _, s := range []string{`{"lat": 1}`, `{"type": "LineString", "coordinates": [[1, 2], [3, 4]]}`, `"POINT(1)"`, `"LINESTRING(1 2, 3 4)"`} {
+		var p scalars.Point
+		if err := json.Unmarshal([]byte(s), &p); err == nil {
+			t.Errorf("decoding %s: got error: nil, want: non-nil", s)
+		}
+	}
+}
+
+func TestNewGeometry(t *testing.T) {
+	g, err := scalars.NewGeometry("Point", []float64{13.405, 52.52})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := g.Point()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p, (scalars.Point{Lat: 52.52, Lng: 13.405, Format: scalars.GeoJSON}); got != want {
+		t.Errorf("got: %+v, want: %+v", got, want)
+	}
+}
@@ -0,0 +1,360 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// Schema is a GraphQL schema that operations can be validated against.
+type Schema struct {
+	s *parser.Schema
+
+	hashOnce sync.Once
+	sdlHash  string // Hash of the SDL of the schema, for versioning cache entries.
+}
+
+// ParseSchema parses a GraphQL schema from SDL document sdl.
+func ParseSchema(sdl []byte) (*Schema, error) {
+	s, err := parser.ParseSchema(string(sdl))
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{s: s}, nil
+}
+
+// IntrospectionQuery is the query used to fetch a server's schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+	__schema {
+		queryType { name }
+		mutationType { name }
+		subscriptionType { name }
+		types { ...FullType }
+		directives {
+			name
+			description
+			locations
+			isRepeatable
+			args { ...InputValue }
+		}
+	}
+}
+fragment FullType on __Type {
+	kind
+	name
+	description
+	fields(includeDeprecated: true) {
+		name
+		description
+		args(includeDeprecated: true) { ...InputValue }
+		type { ...TypeRef }
+		isDeprecated
+		deprecationReason
+	}
+	inputFields(includeDeprecated: true) { ...InputValue }
+	interfaces { ...TypeRef }
+	enumValues(includeDeprecated: true) {
+		name
+		description
+		isDeprecated
+		deprecationReason
+	}
+	possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue {
+	name
+	description
+	type { ...TypeRef }
+	defaultValue
+	isDeprecated
+	deprecationReason
+}
+fragment TypeRef on __Type {
+	kind
+	name
+	ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+// SchemaFromIntrospection builds a schema from the JSON result of
+// IntrospectionQuery. data may be either the full response body,
+// or its "data" member.
+func SchemaFromIntrospection(data []byte) (*Schema, error) {
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) > 0 {
+		data = resp.Data
+	}
+	var in introspection
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	if in.Schema.QueryType == nil {
+		return nil, fmt.Errorf("introspection result has no __schema")
+	}
+	return in.schema()
+}
+
+// Introspect fetches the schema of the GraphQL server c targets
+// by running IntrospectionQuery.
+func (c *Client) Introspect(ctx context.Context) (*Schema, error) {
+	var in introspection
+	err := c.Run(ctx, &Static{QueryStr: IntrospectionQuery, Into: &in})
+	if err != nil {
+		return nil, err
+	}
+	return in.schema()
+}
+
+// introspection is the result of IntrospectionQuery. It's decoded
+// both by encoding/json and by Client.Run.
+type introspection struct {
+	Schema struct {
+		QueryType        *struct{ Name string } `json:"queryType"`
+		MutationType     *struct{ Name string } `json:"mutationType"`
+		SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+		Types            []introspectionType    `json:"types"`
+		Directives       []struct {
+			Name         string                    `json:"name"`
+			Description  string                    `json:"description"`
+			Locations    []string                  `json:"locations"`
+			IsRepeatable bool                      `json:"isRepeatable"`
+			Args         []introspectionInputValue `json:"args"`
+		} `json:"directives"`
+	} `json:"__schema" graphql:"__schema"`
+}
+
+type introspectionType struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Fields      []struct {
+		Name              string                    `json:"name"`
+		Description       string                    `json:"description"`
+		Args              []introspectionInputValue `json:"args"`
+		Type              *introspectionTypeRef     `json:"type"`
+		IsDeprecated      bool                      `json:"isDeprecated"`
+		DeprecationReason string                    `json:"deprecationReason"`
+	} `json:"fields"`
+	InputFields []introspectionInputValue `json:"inputFields"`
+	Interfaces  []introspectionTypeRef    `json:"interfaces"`
+	EnumValues  []struct {
+		Name              string `json:"name"`
+		Description       string `json:"description"`
+		IsDeprecated      bool   `json:"isDeprecated"`
+		DeprecationReason string `json:"deprecationReason"`
+	} `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef `json:"possibleTypes"`
+}
+
+type introspectionInputValue struct {
+	Name              string                `json:"name"`
+	Description       string                `json:"description"`
+	Type              *introspectionTypeRef `json:"type"`
+	DefaultValue      *string               `json:"defaultValue"`
+	IsDeprecated      bool                  `json:"isDeprecated"`
+	DeprecationReason string                `json:"deprecationReason"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+var introspectionKinds = map[string]parser.TypeKind{
+	"SCALAR":       parser.ScalarKind,
+	"OBJECT":       parser.ObjectKind,
+	"INTERFACE":    parser.InterfaceKind,
+	"UNION":        parser.UnionKind,
+	"ENUM":         parser.EnumKind,
+	"INPUT_OBJECT": parser.InputObjectKind,
+}
+
+func (in *introspection) schema() (*Schema, error) {
+	s := parser.NewSchema()
+	for _, it := range in.Schema.Types {
+		if old := s.Types[it.Name]; old != nil && old.Builtin {
+			continue
+		}
+		kind, ok := introspectionKinds[it.Kind]
+		if !ok {
+			return nil, fmt.Errorf("type %q has invalid kind %q", it.Name, it.Kind)
+		}
+		t := &parser.TypeDef{Kind: kind, Name: it.Name, Description: it.Description}
+		for _, f := range it.Fields {
+			fd := &parser.FieldDef{
+				Name:        f.Name,
+				Description: f.Description,
+				Deprecation: parser.Deprecation{Deprecated: f.IsDeprecated, DeprecationReason: f.DeprecationReason},
+			}
+			var err error
+			if fd.Type, err = f.Type.typ(); err != nil {
+				return nil, err
+			}
+			if fd.Arguments, err = inputValueDefs(f.Args); err != nil {
+				return nil, err
+			}
+			t.Fields = append(t.Fields, fd)
+		}
+		var err error
+		if t.InputFields, err = inputValueDefs(it.InputFields); err != nil {
+			return nil, err
+		}
+		for _, i := range it.Interfaces {
+			t.Interfaces = append(t.Interfaces, i.Name)
+		}
+		if kind == parser.UnionKind {
+			for _, m := range it.PossibleTypes {
+				t.Members = append(t.Members, m.Name)
+			}
+		}
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, &parser.EnumValueDef{
+				Name:        v.Name,
+				Description: v.Description,
+				Deprecation: parser.Deprecation{Deprecated: v.IsDeprecated, DeprecationReason: v.DeprecationReason},
+			})
+		}
+		if err := s.AddType(t); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range in.Schema.Directives {
+		if old := s.Directives[d.Name]; old != nil && old.Builtin {
+			continue
+		}
+		args, err := inputValueDefs(d.Args)
+		if err != nil {
+			return nil, err
+		}
+		s.Directives[d.Name] = &parser.DirectiveDef{
+			Name:        d.Name,
+			Description: d.Description,
+			Arguments:   args,
+			Locations:   d.Locations,
+			Repeatable:  d.IsRepeatable,
+		}
+	}
+	if t := in.Schema.QueryType; t != nil {
+		s.Query = t.Name
+	}
+	if t := in.Schema.MutationType; t != nil {
+		s.Mutation = t.Name
+	}
+	if t := in.Schema.SubscriptionType; t != nil {
+		s.Subscription = t.Name
+	}
+	if err := s.Complete(); err != nil {
+		return nil, err
+	}
+	return &Schema{s: s}, nil
+}
+
+func inputValueDefs(in []introspectionInputValue) ([]*parser.InputValueDef, error) {
+	var defs []*parser.InputValueDef
+	for _, v := range in {
+		typ, err := v.Type.typ()
+		if err != nil {
+			return nil, err
+		}
+		def := &parser.InputValueDef{
+			Name:        v.Name,
+			Description: v.Description,
+			Type:        typ,
+			Deprecation: parser.Deprecation{Deprecated: v.IsDeprecated, DeprecationReason: v.DeprecationReason},
+		}
+		if v.DefaultValue != nil {
+			if def.Default, err = parser.ParseValue(*v.DefaultValue); err != nil {
+				return nil, fmt.Errorf("default value of %q: %v", v.Name, err)
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// typ converts introspected type reference r to a parser type.
+func (r *introspectionTypeRef) typ() (*parser.Type, error) {
+	if r == nil {
+		return nil, fmt.Errorf("missing type reference")
+	}
+	switch r.Kind {
+	case "NON_NULL":
+		t, err := r.OfType.typ()
+		if err != nil {
+			return nil, err
+		}
+		t.NonNull = true
+		return t, nil
+	case "LIST":
+		elem, err := r.OfType.typ()
+		if err != nil {
+			return nil, err
+		}
+		return &parser.Type{Elem: elem}, nil
+	}
+	return &parser.Type{Name: r.Name}, nil
+}
+
+// ValidationError is returned by Client.Run for operations that
+// don't validate against the schema set with WithSchema.
+type ValidationError struct {
+	Issues []Issue // All with Kind InvalidOperation.
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.String()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// validate validates query of op against c.schema. Results are
+// cached per query, for up to DefaultDocumentCacheSize queries, and
+// deprecation warnings are logged when a query's result is cached.
+func (c *Client) validate(op Operation, query string) error {
+	issues, ok := c.validated.load(query)
+	if !ok {
+		var warnings []*parser.Error
+		issues, warnings = c.check(query)
+		var stored bool
+		if issues, stored = c.validated.loadOrStore(query, issues); stored {
+			for _, w := range warnings {
+				c.logf("graphql: warning: %v", w)
+			}
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	e := &ValidationError{Issues: make([]Issue, len(issues))}
+	for i, issue := range issues {
+		issue.Op = op
+		e.Issues[i] = issue
+	}
+	return e
+}
+
+// check validates query against c.schema, returning the validation
+// errors as issues, and the deprecation warnings.
+func (c *Client) check(query string) ([]Issue, []*parser.Error) {
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return []Issue{issue(InvalidOperation, nil, err.(*parser.Error))}, nil
+	}
+	errs, warnings := parser.Check(c.schema.s, doc)
+	issues := []Issue{}
+	for _, err := range errs {
+		issues = append(issues, issue(InvalidOperation, nil, err))
+	}
+	return issues, warnings
+}
@@ -0,0 +1,129 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+const introspectionResult = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"mutationType": null,
+	"subscriptionType": null,
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "user", "args": [
+				{"name": "login", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}, "defaultValue": null}
+			], "type": {"kind": "OBJECT", "name": "User"}, "isDeprecated": false, "deprecationReason": null}
+		], "interfaces": []},
+		{"kind": "OBJECT", "name": "User", "fields": [
+			{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}, "isDeprecated": false},
+			{"name": "avatarURL", "args": [
+				{"name": "size", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "64"}
+			], "type": {"kind": "SCALAR", "name": "String"}, "isDeprecated": true, "deprecationReason": "Use avatarUrl."},
+			{"name": "repositories", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}}, "isDeprecated": false}
+		], "interfaces": []},
+		{"kind": "SCALAR", "name": "String"},
+		{"kind": "SCALAR", "name": "Int"},
+		{"kind": "OBJECT", "name": "__Schema", "fields": []}
+	],
+	"directives": [
+		{"name": "cached", "locations": ["FIELD"], "args": [{"name": "ttl", "type": {"kind": "SCALAR", "name": "Int"}}]}
+	]
+}}}`
+
+func TestClient_Run_withSchema(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"user": {"name": "Gopher", "avatarURL": "https://example.org/gopher.png"}}}`)
+	})
+	schema, err := graphql.SchemaFromIntrospection([]byte(introspectionResult))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logger logRecorder
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithSchema(schema),
+		graphql.WithLogger(&logger),
+	)
+
+	var q struct {
+		User struct {
+			Name      graphql.String
+			AvatarURL graphql.String `graphql:"avatarURL(size: 32) @cached(ttl: 60)"`
+		} `graphql:"user(login: \"gopher\")"`
+	}
+	for i := 0; i < 2; i++ {
+		err := client.Query(context.Background(), &q, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+	want := []string{`graphql: warning: 1:29: field "User.avatarURL" is deprecated: Use avatarUrl.`}
+	if fmt.Sprint(logger) != fmt.Sprint(want) {
+		t.Errorf("got log:\n%q\nwant:\n%q", logger, want)
+	}
+
+	var invalid struct {
+		User struct {
+			Email graphql.String
+		} `graphql:"user(login: \"gopher\")"`
+	}
+	err = client.Query(context.Background(), &invalid, nil)
+	if got, want := fmt.Sprint(err), `invalid operation: 1:24: cannot query field "email" on type "User"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if verr, ok := err.(*graphql.ValidationError); !ok || verr.Issues[0].Op == nil {
+		t.Errorf("got error %T, want *graphql.ValidationError with Op set", err)
+	}
+	if got, want := requests, 2; got != want {
+		t.Errorf("got %d requests after invalid query, want %d", got, want)
+	}
+}
+
+func TestClient_Introspect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, introspectionResult)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	schema, err := client.Introspect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = graphql.NewClient("/graphql", nil, graphql.WithSchema(schema))
+	var q struct {
+		User struct {
+			Repositories []graphql.String
+		} `graphql:"user(login: $login)"`
+	}
+	err = client.Query(context.Background(), &q, map[string]interface{}{"login": graphql.Int(1)})
+	if got, want := fmt.Sprint(err), `invalid operation: 1:32: variable "$login" of type "Int!" used in position expecting type "String!"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestSchemaFromIntrospection_error(t *testing.T) {
+	_, err := graphql.SchemaFromIntrospection([]byte(`{"data": {}}`))
+	if err == nil {
+		t.Fatal("got error: nil, want: non-nil")
+	}
+}
+
+// logRecorder is a graphql.Logger that records messages.
+type logRecorder []string
+
+func (l *logRecorder) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SDL returns the schema definition language document of s, such as
+// one built by Introspect, without built-in scalars, directives and
+// introspection types. Types are in order of definition.
+func (s *Schema) SDL() string {
+	return s.s.SDL()
+}
+
+// TypeSDL returns the schema definition language definition of the type
+// named name, if s has it.
+func (s *Schema) TypeSDL(name string) (sdl string, ok bool) {
+	t := s.s.Types[name]
+	if t == nil {
+		return "", false
+	}
+	return t.SDL(), true
+}
+
+// SearchTypes returns the names of the types of s containing pattern,
+// ignoring case, sorted. Built-in types are only included if pattern
+// is their full name.
+func (s *Schema) SearchTypes(pattern string) []string {
+	var names []string
+	for name, t := range s.s.Types {
+		if t.Builtin && !strings.EqualFold(name, pattern) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(name), strings.ToLower(pattern)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SearchFields returns the schema definition language definitions of the
+// fields of the type named typeName whose names contain pattern, ignoring
+// case, in order of definition. The fields of input object types are
+// their input fields.
+func (s *Schema) SearchFields(typeName, pattern string) ([]string, error) {
+	t := s.s.Types[typeName]
+	if t == nil {
+		return nil, fmt.Errorf("schema has no type %q", typeName)
+	}
+	pattern = strings.ToLower(pattern)
+	var defs []string
+	for _, f := range t.Fields {
+		if strings.Contains(strings.ToLower(f.Name), pattern) {
+			defs = append(defs, f.SDL())
+		}
+	}
+	for _, f := range t.InputFields {
+		if strings.Contains(strings.ToLower(f.Name), pattern) {
+			defs = append(defs, f.SDL())
+		}
+	}
+	return defs, nil
+}
@@ -0,0 +1,151 @@
+package graphql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+const exploreSDL = `schema {
+  query: Root
+}
+
+"""Caches a field."""
+directive @cached(ttl: Int = 60) repeatable on FIELD | QUERY
+
+directive @key(fields: String!) on OBJECT
+
+"""
+The root.
+
+Start here.
+"""
+type Root {
+  user(login: String!): User
+  users(
+    """Maximum number of users."""
+    first: Int = 10
+    filter: UserFilter
+  ): [User!]!
+}
+
+interface Node {
+  id: ID!
+}
+
+type User implements Node @key(fields: "id") {
+  id: ID!
+  name: String
+  avatarURL(size: Int = 64): String @deprecated(reason: "Use avatarUrl.")
+  role: Role
+}
+
+input UserFilter {
+  role: Role = ADMIN
+  nameContains: String
+}
+
+enum Role {
+  """Can do anything."""
+  ADMIN
+  MEMBER @deprecated
+}
+
+union SearchResult = User | Root
+
+scalar DateTime
+`
+
+func TestSchema_SDL(t *testing.T) {
+	schema, err := graphql.ParseSchema([]byte(exploreSDL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := exploreSDL
+	if got := schema.SDL(); got != want {
+		t.Errorf("got SDL:\n%s\nwant:\n%s", got, want)
+	}
+
+	// SDL of a schema parsed from SDL should be stable.
+	again, err := graphql.ParseSchema([]byte(schema.SDL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.SDL(); got != want {
+		t.Errorf("got SDL of reparsed schema:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSchema_SDL_introspection(t *testing.T) {
+	schema, err := graphql.SchemaFromIntrospection([]byte(introspectionResult))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `directive @cached(ttl: Int) on FIELD
+
+type Query {
+  user(login: String!): User
+}
+
+type User {
+  name: String
+  avatarURL(size: Int = 64): String @deprecated(reason: "Use avatarUrl.")
+  repositories: [String]!
+}
+`
+	if got := schema.SDL(); got != want {
+		t.Errorf("got SDL:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSchema_search(t *testing.T) {
+	schema, err := graphql.ParseSchema([]byte(exploreSDL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := schema.SearchTypes("user"), []string{"User", "UserFilter"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got types: %q, want: %q", got, want)
+	}
+	if got, want := schema.SearchTypes("string"), []string{"String"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got types: %q, want: %q", got, want)
+	}
+	if got := schema.SearchTypes("nope"); len(got) != 0 {
+		t.Errorf("got types: %q, want none", got)
+	}
+
+	got, err := schema.SearchFields("Root", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"user(login: String!): User",
+		"users(\n  \"\"\"Maximum number of users.\"\"\"\n  first: Int = 10\n  filter: UserFilter\n): [User!]!",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got fields: %q, want: %q", got, want)
+	}
+	if got, want := mustSearchFields(t, schema, "UserFilter", "ROLE"), []string{"role: Role = ADMIN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got input fields: %q, want: %q", got, want)
+	}
+	if _, err := schema.SearchFields("Nope", ""); err == nil {
+		t.Error("got nil error for unknown type")
+	}
+
+	if got, ok := schema.TypeSDL("SearchResult"); !ok || got != "union SearchResult = User | Root" {
+		t.Errorf("got type SDL: %q, %v", got, ok)
+	}
+	if _, ok := schema.TypeSDL("Nope"); ok {
+		t.Error("got SDL of unknown type")
+	}
+}
+
+func mustSearchFields(t *testing.T, schema *graphql.Schema, typeName, pattern string) []string {
+	t.Helper()
+	defs, err := schema.SearchFields(typeName, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return defs
+}
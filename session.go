@@ -0,0 +1,217 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// WithCookieJar makes the client store cookies set by the server in jar,
+// and send them with later requests, for servers using session cookies.
+func WithCookieJar(jar http.CookieJar) ClientOption {
+	return func(c *Client) {
+		c.session.jar = jar
+	}
+}
+
+// CookieStore persists the cookies of a client's session, so the session
+// can outlive the process.
+type CookieStore interface {
+	// Load returns the cookies stored for the GraphQL server.
+	Load() ([]*http.Cookie, error)
+	// Save stores the cookies currently sent to the GraphQL server.
+	Save(cookies []*http.Cookie) error
+}
+
+// WithCookieStore makes the client load its session cookies from store
+// when created, and save them to store whenever the server sets cookies.
+// If no cookie jar is set with WithCookieJar, an in-memory one is used.
+// If the client's URL is a template, the cookies are loaded for each host
+// it resolves to when the host is first sent a request, and those sent
+// to the host of the latest request are saved.
+func WithCookieStore(store CookieStore) ClientOption {
+	return func(c *Client) {
+		c.session.store = store
+	}
+}
+
+// CSRF configures how a client obtains a CSRF token and sends it to
+// a server that requires one, typically along with session cookies.
+type CSRF struct {
+	// URL is fetched with a GET request, before the first request that needs
+	// a token, to obtain one. It may be relative to the client's URL.
+	// If empty, the client's URL is fetched.
+	URL string
+
+	// Cookie is the name of the cookie holding the token. If empty,
+	// or if there's no such cookie, the token is taken from the response
+	// header named Header of the request to URL.
+	Cookie string
+
+	// Header is the name of the request header the token is sent in,
+	// such as "X-CSRF-Token".
+	Header string
+}
+
+// WithCSRF makes the client send a CSRF token in every request,
+// obtained as configured by csrf. If no cookie jar is set with
+// WithCookieJar, an in-memory one is used.
+func WithCSRF(csrf CSRF) ClientOption {
+	return func(c *Client) {
+		c.session.csrf = &csrf
+	}
+}
+
+// session is the session state of a client.
+type session struct {
+	jar   http.CookieJar
+	store CookieStore
+	csrf  *CSRF
+
+	mu    sync.Mutex
+	token string // CSRF token taken from a response header, if any.
+
+	loaded      []*http.Cookie // Cookies loaded from store, for a URL template.
+	loadMu      sync.Mutex
+	loadedHosts map[string]bool // Hosts of the URL template the loaded cookies are set for.
+}
+
+// configureSession applies the session options of c.
+// c.httpClient must not be shared.
+func (c *Client) configureSession() error {
+	s := &c.session
+	if s.jar == nil && (s.store != nil || s.csrf != nil) {
+		s.jar, _ = cookiejar.New(nil) // Never fails.
+	}
+	if s.jar == nil {
+		return nil
+	}
+	c.httpClient.Jar = s.jar
+	if s.store == nil {
+		return nil
+	}
+	cookies, err := s.store.Load()
+	if err != nil {
+		return fmt.Errorf("loading session cookies: %v", err)
+	}
+	if strings.Contains(c.url, "{") {
+		// The server's host is only known once the template is resolved.
+		s.loaded = cookies
+		return nil
+	}
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return err
+	}
+	s.jar.SetCookies(u, cookies)
+	return nil
+}
+
+// loadCookies sets the cookies loaded from the session's cookie store for
+// u, a URL the client's URL template resolved to, the first time its host
+// is sent a request.
+func (c *Client) loadCookies(u *url.URL) {
+	s := &c.session
+	if s.loaded == nil {
+		return
+	}
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	if s.loadedHosts[u.Host] {
+		return
+	}
+	if s.loadedHosts == nil {
+		s.loadedHosts = make(map[string]bool)
+	}
+	s.loadedHosts[u.Host] = true
+	s.jar.SetCookies(u, s.loaded)
+}
+
+// setCSRFHeader sets the CSRF token header of req, if configured,
+// obtaining a token first if the client has none.
+func (c *Client) setCSRFHeader(ctx context.Context, req *http.Request) error {
+	csrf := c.session.csrf
+	if csrf == nil {
+		return nil
+	}
+	token, err := c.csrfToken(ctx, req.URL)
+	if err != nil {
+		return fmt.Errorf("obtaining CSRF token: %v", err)
+	}
+	req.Header.Set(csrf.Header, token)
+	return nil
+}
+
+// csrfToken returns the current CSRF token for requests to u.
+func (c *Client) csrfToken(ctx context.Context, u *url.URL) (string, error) {
+	s := &c.session
+	if token, ok := c.csrfCookie(u); ok {
+		return token, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	fetchURL := u
+	if s.csrf.URL != "" {
+		ref, err := url.Parse(s.csrf.URL)
+		if err != nil {
+			return "", err
+		}
+		fetchURL = u.ResolveReference(ref)
+	}
+	req, err := http.NewRequest(http.MethodGet, fetchURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := ctxhttp.Do(ctx, c.httpClient, req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return "", fmt.Errorf("unexpected status code from %v: %v", fetchURL, resp.Status)
+	}
+	c.saveCookies(u)
+	if token, ok := c.csrfCookie(u); ok {
+		return token, nil
+	}
+	if s.token = resp.Header.Get(s.csrf.Header); s.token == "" {
+		return "", fmt.Errorf("no token in response from %v", fetchURL)
+	}
+	return s.token, nil
+}
+
+// csrfCookie returns the CSRF token from the cookie sent to u, if any.
+func (c *Client) csrfCookie(u *url.URL) (string, bool) {
+	s := &c.session
+	if s.csrf.Cookie == "" {
+		return "", false
+	}
+	for _, cookie := range s.jar.Cookies(u) {
+		if cookie.Name == s.csrf.Cookie {
+			return cookie.Value, true
+		}
+	}
+	return "", false
+}
+
+// saveCookies saves the cookies the client sends to the GraphQL server at
+// u to the session's cookie store, if any. Errors are logged.
+func (c *Client) saveCookies(u *url.URL) {
+	s := &c.session
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Save(s.jar.Cookies(u)); err != nil {
+		c.logf("graphql: saving session cookies: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// memoryCookieStore is a graphql.CookieStore that stores cookies in memory.
+type memoryCookieStore struct {
+	cookies []*http.Cookie
+}
+
+func (s *memoryCookieStore) Load() ([]*http.Cookie, error) { return s.cookies, nil }
+
+func (s *memoryCookieStore) Save(cookies []*http.Cookie) error {
+	s.cookies = cookies
+	return nil
+}
+
+func TestClient_session(t *testing.T) {
+	var fetches int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/csrf", func(w http.ResponseWriter, req *http.Request) {
+		fetches++
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: "t1", Path: "/"})
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		session, err := req.Cookie("session")
+		if err != nil || session.Value != "s1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		token, err := req.Cookie("csrftoken")
+		if err != nil || req.Header.Get("X-CSRF-Token") != token.Value {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	httpClient := &http.Client{Transport: localRoundTripper{handler: mux}}
+	store := new(memoryCookieStore)
+	csrf := graphql.CSRF{URL: "/csrf", Cookie: "csrftoken", Header: "X-CSRF-Token"}
+
+	client := graphql.NewClient("https://example.com/graphql", httpClient,
+		graphql.WithCookieStore(store),
+		graphql.WithCSRF(csrf),
+	)
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), new(viewerQuery), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := fetches, 1; got != want {
+		t.Errorf("got %d CSRF token fetches, want %d", got, want)
+	}
+	if got, want := len(store.cookies), 2; got != want {
+		t.Fatalf("got %d stored cookies, want %d", got, want)
+	}
+
+	// A new client resumes the stored session.
+	client = graphql.NewClient("https://example.com/graphql", httpClient,
+		graphql.WithCookieStore(store),
+		graphql.WithCSRF(csrf),
+	)
+	if err := client.Query(context.Background(), new(viewerQuery), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fetches, 1; got != want {
+		t.Errorf("got %d CSRF token fetches after resuming session, want %d", got, want)
+	}
+	if httpClient.Jar != nil {
+		t.Error("NewClient modified Jar of given HTTP client")
+	}
+}
+
+func TestClient_sessionURLTemplate(t *testing.T) {
+	var fetches int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/csrf", func(w http.ResponseWriter, req *http.Request) {
+		fetches++
+		http.SetCookie(w, &http.Cookie{Name: "csrftoken", Value: "t1", Path: "/"})
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if req.Host != "eu.example.com" {
+			t.Errorf("got request to host %q, want eu.example.com", req.Host)
+		}
+		token, err := req.Cookie("csrftoken")
+		if err != nil || req.Header.Get("X-CSRF-Token") != token.Value {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	httpClient := &http.Client{Transport: localRoundTripper{handler: mux}}
+	store := new(memoryCookieStore)
+	csrf := graphql.CSRF{URL: "/csrf", Cookie: "csrftoken", Header: "X-CSRF-Token"}
+	ctx := graphql.WithURLParams(context.Background(), map[string]string{"region": "eu"})
+
+	for i := 0; i < 2; i++ {
+		// Each client resumes the session stored by the previous one.
+		client := graphql.NewClient("https://{region}.example.com/graphql", httpClient,
+			graphql.WithCookieStore(store),
+			graphql.WithCSRF(csrf),
+		)
+		if err := client.Query(ctx, new(viewerQuery), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := fetches, 1; got != want {
+		t.Errorf("got %d CSRF token fetches, want %d", got, want)
+	}
+	if got, want := len(store.cookies), 1; got != want {
+		t.Errorf("got %d stored cookies, want %d", got, want)
+	}
+}
+
+func TestClient_csrfHeader(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			w.Header().Set("X-CSRF-Token", "t2")
+			return
+		}
+		if got, want := req.Header.Get("X-CSRF-Token"), "t2"; got != want {
+			t.Errorf("got CSRF token: %q, want: %q", got, want)
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("https://example.com/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithCSRF(graphql.CSRF{Header: "X-CSRF-Token"}),
+	)
+	if err := client.Query(context.Background(), new(viewerQuery), nil); err != nil {
+		t.Fatal(err)
+	}
+}
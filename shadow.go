@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// Shadow configures the mirroring of query traffic to a secondary
+// server, such as a GraphQL backend being migrated to, by WithShadow.
+type Shadow struct {
+	// Client is the client targeting the secondary server.
+	Client *Client
+
+	// Rate is the fraction of queries mirrored, from 0 to 1.
+	Rate float64
+
+	// Compare, if not nil, is called with the differences between the
+	// responses of the servers to each mirrored query, as computed by
+	// Compare, or with the error running it against the secondary server.
+	// Otherwise, the secondary server's responses are discarded.
+	Compare func(ctx context.Context, op Operation, diffs []Difference, err error)
+
+	// MaxInFlight is the maximum number of mirrored queries running at
+	// once, or DefaultShadowInFlight if 0. Queries sampled while that many
+	// are running aren't mirrored, so a slow secondary server doesn't pile
+	// up goroutines in the client.
+	MaxInFlight int
+}
+
+// DefaultShadowInFlight is the default maximum number of queries
+// mirrored at once by WithShadow.
+const DefaultShadowInFlight = 64
+
+// WithShadow mirrors a fraction of the queries the client runs
+// successfully to a secondary server, as configured by s, in the
+// background. Mutations and subscriptions aren't mirrored. Mirrored
+// queries don't delay the client's, and aren't canceled with their
+// contexts, but are canceled when the client is closed. Queries sampled
+// while s.MaxInFlight mirrored queries are running are dropped.
+func WithShadow(s Shadow) ClientOption {
+	return func(c *Client) {
+		n := s.MaxInFlight
+		if n <= 0 {
+			n = DefaultShadowInFlight
+		}
+		c.shadow = &s
+		c.mirrors = make(chan struct{}, n)
+	}
+}
+
+// mirror runs op against the secondary server of c.shadow in the
+// background, if op's document only has queries and is sampled, and
+// fewer than the maximum of mirrored queries are running, comparing its
+// response to out.
+func (c *Client) mirror(ctx context.Context, op Operation, out Response) {
+	s := c.shadow
+	if s.Rate <= 0 || (s.Rate < 1 && rand.Float64() >= s.Rate) {
+		return
+	}
+	if !c.queriesOnly(op.Query()) {
+		return
+	}
+	select {
+	case c.mirrors <- struct{}{}:
+		// Mirror op, unless too many queries are mirrored already.
+	default:
+		return
+	}
+	// Snapshot variables, since the caller may change them once op returns.
+	mirrored := &mirrored{Operation: op, vars: copyVariables(op.Variables())}
+	c.goBackground(detachedContext{ctx}, func(ctx context.Context) {
+		defer func() { <-c.mirrors }()
+		shadowed, err := s.Client.do(ctx, mirrored)
+		if s.Compare == nil {
+			return
+		}
+		var diffs []Difference
+		if err == nil {
+			diffs, err = diffCompared(out, shadowed)
+		}
+		s.Compare(ctx, op, diffs, err)
+	})
+}
+
+// queriesOnly reports whether document query only has query operations.
+func (c *Client) queriesOnly(query string) bool {
+	types := c.operationTypes(query)
+	for _, typ := range types {
+		if typ != "query" {
+			return false
+		}
+	}
+	return len(types) > 0
+}
+
+// selectsQuery reports whether the operation of document query selected
+// by name, or its only operation if name is empty, is a query.
+func (c *Client) selectsQuery(query, name string) bool {
+	types := c.operationTypes(query)
+	if name == "" && len(types) == 1 {
+		for _, typ := range types {
+			return typ == "query"
+		}
+	}
+	return types[name] == "query"
+}
+
+// operationTypes returns the types of the operations of document query
+// by name, or nil if it can't be parsed. Results are cached per query,
+// for up to DefaultDocumentCacheSize queries.
+func (c *Client) operationTypes(query string) map[string]string {
+	if types, ok := c.queryDocs.load(query); ok {
+		return types
+	}
+	var types map[string]string
+	if doc, err := parser.ParseDocument(query); err == nil {
+		types = make(map[string]string, len(doc.Operations))
+		for _, op := range doc.Operations {
+			types[op.Name] = op.Type
+		}
+	}
+	types, _ = c.queryDocs.loadOrStore(query, types)
+	return types
+}
+
+// mirrored is an operation mirrored to a secondary server, with a copy
+// of its variables, and whose response data isn't decoded.
+type mirrored struct {
+	Operation
+	vars map[string]interface{}
+}
+
+func (op *mirrored) Variables() map[string]interface{} {
+	return op.vars
+}
+
+func (op *mirrored) ResponsePtr() interface{} {
+	return discardData{}
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *mirrored) Unwrap() Operation {
+	return op.Operation
+}
@@ -0,0 +1,155 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithShadow(t *testing.T) {
+	newMux := func(resp string, requests *int32) *http.ServeMux {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(requests, 1)
+			mustRead(req.Body)
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(w, resp)
+		})
+		return mux
+	}
+	var primaryRequests, shadowRequests int32
+	secondary := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: newMux(`{"data": {"viewer": {"login": "gopher2"}}}`, &shadowRequests)}})
+	type comparison struct {
+		op    graphql.Operation
+		diffs []graphql.Difference
+		err   error
+	}
+	compared := make(chan comparison, 10)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: newMux(`{"data": {"viewer": {"login": "gopher"}}}`, &primaryRequests)}},
+		graphql.WithShadow(graphql.Shadow{
+			Client: secondary,
+			Rate:   1,
+			Compare: func(ctx context.Context, op graphql.Operation, diffs []graphql.Difference, err error) {
+				compared <- comparison{op, diffs, err}
+			},
+		}))
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	op := &graphql.Query{Data: &q}
+	if err := client.Run(ctx, op); err != nil {
+		t.Fatal(err)
+	}
+	cancel() // Mirrored queries outlive the contexts of the queries they mirror.
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+
+	select {
+	case c := <-compared:
+		if c.err != nil {
+			t.Fatal(c.err)
+		}
+		if c.op != op {
+			t.Errorf("got op: %v, want the mirrored query", c.op)
+		}
+		if len(c.diffs) != 1 || c.diffs[0].String() != `data.viewer.login: "gopher" != "gopher2"` {
+			t.Errorf("got differences: %v", c.diffs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query wasn't mirrored")
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login after mirroring: %q, want: %q", got, want)
+	}
+
+	// Mutations aren't mirrored.
+	var m struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Mutate(context.Background(), &m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := atomic.LoadInt32(&primaryRequests), int32(2); got != want {
+		t.Errorf("got %d primary requests, want %d", got, want)
+	}
+	if got, want := atomic.LoadInt32(&shadowRequests), int32(1); got != want {
+		t.Errorf("got %d shadow requests, want %d", got, want)
+	}
+}
+
+func TestWithShadow_rate(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		mustRead(req.Body)
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	hc := &http.Client{Transport: localRoundTripper{handler: mux}}
+	secondary := graphql.NewClient("/graphql", hc)
+	client := graphql.NewClient("/primary", &http.Client{Transport: localRoundTripper{handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})}}, graphql.WithShadow(graphql.Shadow{Client: secondary, Rate: 0}))
+
+	for i := 0; i < 10; i++ {
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Errorf("got %d shadow requests with rate 0, want 0", got)
+	}
+}
+
+func TestWithShadow_maxInFlight(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		mustRead(req.Body)
+		<-release
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	secondary := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+	client := graphql.NewClient("/primary", &http.Client{Transport: localRoundTripper{handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})}}, graphql.WithShadow(graphql.Shadow{Client: secondary, Rate: 1, MaxInFlight: 2}))
+
+	// Queries mirrored while two are stuck on the secondary server are dropped.
+	for i := 0; i < 10; i++ {
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+	client.Close()
+	if got, want := atomic.LoadInt32(&requests), int32(2); got != want {
+		t.Errorf("got %d shadow requests, want %d", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+// Package shopify provides helpers for the Shopify Admin GraphQL API,
+// such as running bulk operations.
+package shopify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// BulkOperationStatus is the status of a bulk operation.
+type BulkOperationStatus string
+
+// Statuses of bulk operations.
+const (
+	BulkOperationStatusCreated   BulkOperationStatus = "CREATED"
+	BulkOperationStatusRunning   BulkOperationStatus = "RUNNING"
+	BulkOperationStatusCompleted BulkOperationStatus = "COMPLETED"
+	BulkOperationStatusCanceling BulkOperationStatus = "CANCELING"
+	BulkOperationStatusCanceled  BulkOperationStatus = "CANCELED"
+	BulkOperationStatusFailed    BulkOperationStatus = "FAILED"
+	BulkOperationStatusExpired   BulkOperationStatus = "EXPIRED"
+)
+
+// Done reports whether s is a final status, that the operation
+// won't leave.
+func (s BulkOperationStatus) Done() bool {
+	switch s {
+	case BulkOperationStatusCompleted, BulkOperationStatusCanceled, BulkOperationStatusFailed, BulkOperationStatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// BulkOperation is a bulk operation, as of the last time it was polled.
+type BulkOperation struct {
+	ID             graphql.ID
+	Status         BulkOperationStatus
+	ErrorCode      *graphql.String // Error of a FAILED operation, such as "TIMEOUT".
+	ObjectCount    graphql.Int64   // Objects processed so far.
+	URL            *graphql.String `graphql:"url"`            // URL of the results of a COMPLETED operation, if any.
+	PartialDataURL *graphql.String `graphql:"partialDataUrl"` // URL of the partial results of a FAILED operation, if any.
+}
+
+// BulkError is returned for bulk operations that are rejected, or that
+// don't complete.
+type BulkError struct {
+	Operation  BulkOperation // Operation as last polled, if it was created.
+	UserErrors []UserError   // Reasons the operation was rejected, if it was.
+}
+
+func (e *BulkError) Error() string {
+	if len(e.UserErrors) > 0 {
+		msgs := make([]string, len(e.UserErrors))
+		for i, ue := range e.UserErrors {
+			msgs[i] = ue.Error()
+		}
+		return "shopify: bulk operation rejected: " + strings.Join(msgs, "; ")
+	}
+	msg := fmt.Sprintf("shopify: bulk operation %s is %s", e.Operation.ID, e.Operation.Status)
+	if e.Operation.ErrorCode != nil {
+		msg += ": " + string(*e.Operation.ErrorCode)
+	}
+	return msg
+}
+
+// UserError is an error of the input of a mutation.
+type UserError struct {
+	Field   []graphql.String // Path of the input field with the error, if any.
+	Message graphql.String
+}
+
+func (e UserError) Error() string {
+	if len(e.Field) == 0 {
+		return string(e.Message)
+	}
+	path := make([]string, len(e.Field))
+	for i, f := range e.Field {
+		path[i] = string(f)
+	}
+	return strings.Join(path, ".") + ": " + string(e.Message)
+}
+
+// Bulk runs bulk operations with a client of the Shopify Admin API: it
+// submits the bulk query, polls its status with exponential backoff until
+// it's done, then streams the JSONL file of its results:
+//
+//	bulk := &shopify.Bulk{Client: client}
+//	op, err := bulk.Run(ctx, `{ products { edges { node { id title } } } }`, func(line json.RawMessage) error {
+//		...
+//	})
+//
+// Objects nested in connections are lines of their own, with a
+// "__parentId" field of the ID of their parent.
+type Bulk struct {
+	Client *graphql.Client
+
+	// HTTPClient downloads the results. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// PollInterval is the time waited before polling the status first,
+	// doubled after each poll, up to MaxPollInterval. They default to
+	// DefaultPollInterval and DefaultMaxPollInterval.
+	PollInterval, MaxPollInterval time.Duration
+
+	// Progress, if not nil, is called with the operation each time
+	// its status is polled.
+	Progress func(BulkOperation)
+}
+
+// Defaults of Bulk.
+const (
+	DefaultPollInterval    = time.Second
+	DefaultMaxPollInterval = 30 * time.Second
+)
+
+// Run runs bulk query query, such as "{ products { edges { node { id } } } }",
+// calling each with each line of the results, in order. It returns the
+// completed operation, or a *BulkError if it's rejected or doesn't complete.
+// Results of operations without any, which have no URL, are empty.
+func (b *Bulk) Run(ctx context.Context, query string, each func(line json.RawMessage) error) (BulkOperation, error) {
+	op, err := b.Submit(ctx, query)
+	if err != nil {
+		return op, err
+	}
+	op, err = b.Wait(ctx, op.ID)
+	if err != nil {
+		return op, err
+	}
+	if op.URL == nil {
+		return op, nil
+	}
+	return op, b.Results(ctx, string(*op.URL), each)
+}
+
+// Submit submits bulk query query, returning the created operation,
+// or a *BulkError if it's rejected.
+func (b *Bulk) Submit(ctx context.Context, query string) (BulkOperation, error) {
+	var m struct {
+		BulkOperationRunQuery struct {
+			BulkOperation *BulkOperation
+			UserErrors    []UserError
+		} `graphql:"bulkOperationRunQuery(query: $query)"`
+	}
+	err := b.Client.Mutate(ctx, &m, map[string]interface{}{"query": graphql.String(query)})
+	if err != nil {
+		return BulkOperation{}, err
+	}
+	payload := m.BulkOperationRunQuery
+	if len(payload.UserErrors) > 0 || payload.BulkOperation == nil {
+		return BulkOperation{}, &BulkError{UserErrors: payload.UserErrors}
+	}
+	return *payload.BulkOperation, nil
+}
+
+// Poll returns the bulk operation with id.
+func (b *Bulk) Poll(ctx context.Context, id graphql.ID) (BulkOperation, error) {
+	var q struct {
+		Node struct {
+			BulkOperation BulkOperation `graphql:"... on BulkOperation"`
+		} `graphql:"node(id: $id)"`
+	}
+	err := b.Client.Query(ctx, &q, map[string]interface{}{"id": id})
+	return q.Node.BulkOperation, err
+}
+
+// Wait polls the bulk operation with id until it's done, returning it
+// once it's completed, or a *BulkError otherwise.
+func (b *Bulk) Wait(ctx context.Context, id graphql.ID) (BulkOperation, error) {
+	interval, max := b.PollInterval, b.MaxPollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	if max <= 0 {
+		max = DefaultMaxPollInterval
+	}
+	for {
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return BulkOperation{ID: id}, ctx.Err()
+		case <-t.C:
+		}
+		op, err := b.Poll(ctx, id)
+		if err != nil {
+			return op, err
+		}
+		if b.Progress != nil {
+			b.Progress(op)
+		}
+		if op.Status.Done() {
+			if op.Status != BulkOperationStatusCompleted {
+				return op, &BulkError{Operation: op}
+			}
+			return op, nil
+		}
+		if interval *= 2; interval > max {
+			interval = max
+		}
+	}
+}
+
+// Results downloads the JSONL file of results at url, calling each with
+// each of its lines, in order, until it returns an error.
+func (b *Bulk) Results(ctx context.Context, url string, each func(line json.RawMessage) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	hc := b.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("shopify: downloading bulk operation results: non-200 OK status code: %v", resp.Status)
+	}
+	s := bufio.NewScanner(resp.Body)
+	s.Buffer(nil, 64<<20) // Lines are objects, which may be large.
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		// Copy the line, as the scanner reuses its buffer.
+		if err := each(append(json.RawMessage(nil), line...)); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
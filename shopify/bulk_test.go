@@ -0,0 +1,154 @@
+package shopify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/shopify"
+)
+
+// shopifyServer is a fake Shopify Admin API running a bulk operation,
+// which goes through statuses, one per poll, then has results.
+type shopifyServer struct {
+	t        *testing.T
+	statuses []string
+	results  string
+
+	mu      sync.Mutex
+	queries []string // Bulk queries submitted.
+	polls   int
+}
+
+func (s *shopifyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/results.jsonl" {
+		io.WriteString(w, s.results)
+		return
+	}
+	var in graphql.Request
+	if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+		s.t.Error(err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(in.Query, "mutation"):
+		s.queries = append(s.queries, in.Variables["query"].(string))
+		io.WriteString(w, `{"data": {"bulkOperationRunQuery": {"bulkOperation": {"id": "gid://shopify/BulkOperation/1", "status": "CREATED", "errorCode": null, "objectCount": "0", "url": null, "partialDataUrl": null}, "userErrors": []}}}`)
+	default:
+		if id := in.Variables["id"]; id != "gid://shopify/BulkOperation/1" {
+			s.t.Errorf("got id %v, want gid://shopify/BulkOperation/1", id)
+		}
+		status := s.statuses[s.polls]
+		s.polls++
+		url := "null"
+		if status == "COMPLETED" {
+			url = `"http://` + req.Host + `/results.jsonl"`
+		}
+		io.WriteString(w, `{"data": {"node": {"id": "gid://shopify/BulkOperation/1", "status": "`+status+`", "errorCode": null, "objectCount": "`+strconv.Itoa(s.polls)+`", "url": `+url+`, "partialDataUrl": null}}}`)
+	}
+}
+
+func newBulk(t *testing.T, s *shopifyServer) *shopify.Bulk {
+	s.t = t
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return &shopify.Bulk{
+		Client:          graphql.NewClient(srv.URL, nil),
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 2 * time.Millisecond,
+	}
+}
+
+func TestBulkRun(t *testing.T) {
+	s := &shopifyServer{
+		statuses: []string{"RUNNING", "RUNNING", "COMPLETED"},
+		results: `{"id":"gid://shopify/Product/1","title":"Hat"}
+{"id":"gid://shopify/ProductVariant/2","__parentId":"gid://shopify/Product/1"}
+`,
+	}
+	bulk := newBulk(t, s)
+	var progress []shopify.BulkOperationStatus
+	bulk.Progress = func(op shopify.BulkOperation) {
+		progress = append(progress, op.Status)
+	}
+
+	var lines []string
+	op, err := bulk.Run(context.Background(), `{ products { edges { node { id title } } } }`, func(line json.RawMessage) error {
+		lines = append(lines, string(line))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Status != shopify.BulkOperationStatusCompleted || op.ObjectCount != 3 {
+		t.Errorf("got operation %+v, want COMPLETED with 3 objects", op)
+	}
+	if want := []string{`{ products { edges { node { id title } } } }`}; len(s.queries) != 1 || s.queries[0] != want[0] {
+		t.Errorf("got bulk queries %q, want %q", s.queries, want)
+	}
+	if got, want := strings.Join(lines, "\n"), strings.TrimSpace(s.results); got != want {
+		t.Errorf("got lines:\n%s\nwant:\n%s", got, want)
+	}
+	if want := "RUNNING RUNNING COMPLETED"; strings.Join(statusStrings(progress), " ") != want {
+		t.Errorf("got progress %v, want %s", progress, want)
+	}
+}
+
+func statusStrings(ss []shopify.BulkOperationStatus) []string {
+	strs := make([]string, len(ss))
+	for i, s := range ss {
+		strs[i] = string(s)
+	}
+	return strs
+}
+
+func TestBulkRunFailed(t *testing.T) {
+	bulk := newBulk(t, &shopifyServer{statuses: []string{"RUNNING", "FAILED"}})
+
+	_, err := bulk.Run(context.Background(), `{ orders { edges { node { id } } } }`, func(json.RawMessage) error {
+		t.Error("got results of failed operation")
+		return nil
+	})
+	var bulkErr *shopify.BulkError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("got error %v, want a *shopify.BulkError", err)
+	}
+	if bulkErr.Operation.Status != shopify.BulkOperationStatusFailed {
+		t.Errorf("got status %s, want FAILED", bulkErr.Operation.Status)
+	}
+}
+
+func TestBulkSubmitRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, `{"data": {"bulkOperationRunQuery": {"bulkOperation": null, "userErrors": [{"field": ["query"], "message": "Invalid bulk query"}]}}}`)
+	}))
+	defer srv.Close()
+	bulk := &shopify.Bulk{Client: graphql.NewClient(srv.URL, nil)}
+
+	_, err := bulk.Submit(context.Background(), `{ shop { name } }`)
+	if want := "shopify: bulk operation rejected: query: Invalid bulk query"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestBulkWaitCanceled(t *testing.T) {
+	bulk := newBulk(t, &shopifyServer{statuses: []string{"RUNNING"}})
+	bulk.PollInterval = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := bulk.Wait(ctx, "gid://shopify/BulkOperation/1"); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
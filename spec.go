@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Media types defined by the GraphQL over HTTP specification.
+const (
+	mediaTypeGraphQLResponse = "application/graphql-response+json"
+	mediaTypeJSON            = "application/json"
+)
+
+// WithStrictHTTP makes the client follow the GraphQL over HTTP specification
+// (https://graphql.github.io/graphql-over-http/draft/) strictly:
+//
+//   - Requests accept application/graphql-response+json, preferring it
+//     over application/json.
+//   - Responses must have one of those media types, with a charset of
+//     utf-8 if any.
+//   - Status codes are interpreted according to the response media type:
+//     a 4xx or 5xx status code with a well-formed GraphQL response
+//     carrying request errors results in those errors being returned,
+//     as with a 200 OK response.
+//
+// Responses that break the specification result in a *SpecViolationError.
+func WithStrictHTTP() ClientOption {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// SpecViolationError is returned by clients created with WithStrictHTTP
+// when a server's response doesn't conform to the GraphQL over HTTP
+// specification.
+type SpecViolationError struct {
+	StatusCode  int
+	ContentType string
+	Reason      string
+}
+
+func (e *SpecViolationError) Error() string {
+	return fmt.Sprintf("graphql: response violates GraphQL over HTTP specification: %s (status code: %d, content type: %q)", e.Reason, e.StatusCode, e.ContentType)
+}
+
+// setStrictHeaders sets the headers of req required in strict mode.
+func setStrictHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", mediaTypeJSON+"; charset=utf-8")
+	req.Header.Set("Accept", mediaTypeGraphQLResponse+", "+mediaTypeJSON+";q=0.9")
+}
+
+// readStrict reads GraphQL response resp in strict mode.
+func (c *Client) readStrict(resp *http.Response) (out Response, err error) {
+	meta := ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header}
+	contentType := resp.Header.Get("Content-Type")
+	violation := func(format string, args ...interface{}) (Response, error) {
+		return Response{HTTP: meta}, &SpecViolationError{
+			StatusCode:  resp.StatusCode,
+			ContentType: contentType,
+			Reason:      fmt.Sprintf(format, args...),
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return violation("invalid content type: %v", err)
+	}
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return violation("unsupported charset %q", charset)
+	}
+	ok2xx := resp.StatusCode >= 200 && resp.StatusCode < 300
+	switch mediaType {
+	case mediaTypeGraphQLResponse:
+	case mediaTypeJSON:
+		if resp.StatusCode != http.StatusOK {
+			// The server may be unrelated to GraphQL, such as a proxy
+			// reporting an error, so don't interpret the body.
+			return Response{HTTP: meta}, c.statusError(resp)
+		}
+	default:
+		if !ok2xx {
+			return Response{HTTP: meta}, c.statusError(resp)
+		}
+		return violation("unexpected media type %q", mediaType)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return violation("malformed response body: %v", err)
+	}
+	if _, ok := raw["data"]; !ok {
+		if _, ok := raw["errors"]; !ok {
+			return violation("response has neither data nor errors")
+		}
+	}
+	for k, v := range raw {
+		var err error
+		switch k {
+		case "data":
+			if string(v) != "null" {
+				out.Data = v
+			}
+		case "errors":
+			err = json.Unmarshal(v, &out.Errors)
+		case "extensions":
+			err = json.Unmarshal(v, &out.Extensions)
+		}
+		if err != nil {
+			return violation("malformed %q in response: %v", k, err)
+		}
+	}
+	switch {
+	case out.Data != nil && !ok2xx:
+		return violation("response with data has non-2xx status code")
+	case !ok2xx && len(out.Errors) == 0:
+		return violation("response with non-2xx status code has no errors")
+	}
+	out.HTTP = meta
+	return out, nil
+}
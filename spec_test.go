@@ -0,0 +1,112 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_strictHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     string
+		violation   bool
+	}{
+		{
+			name:        "graphql response",
+			status:      http.StatusOK,
+			contentType: "application/graphql-response+json; charset=utf-8",
+			body:        `{"data": {"viewer": {"login": "gopher"}}}`,
+		},
+		{
+			name:        "json",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"data": {"viewer": {"login": "gopher"}}}`,
+		},
+		{
+			name:        "request error",
+			status:      http.StatusBadRequest,
+			contentType: "application/graphql-response+json",
+			body:        `{"errors": [{"message": "cannot query field \"viewer\""}]}`,
+			wantErr:     `cannot query field "viewer"`,
+		},
+		{
+			name:        "json non-200",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"errors": [{"message": "bad request"}]}`,
+			wantErr:     `non-200 OK status code: 400 Bad Request body: "{\"errors\": [{\"message\": \"bad request\"}]}"`,
+		},
+		{
+			name:        "media type",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        `<html></html>`,
+			wantErr:     `graphql: response violates GraphQL over HTTP specification: unexpected media type "text/html" (status code: 200, content type: "text/html")`,
+			violation:   true,
+		},
+		{
+			name:        "charset",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=iso-8859-1",
+			body:        `{"data": {}}`,
+			wantErr:     `graphql: response violates GraphQL over HTTP specification: unsupported charset "iso-8859-1" (status code: 200, content type: "application/json; charset=iso-8859-1")`,
+			violation:   true,
+		},
+		{
+			name:        "data with error status",
+			status:      http.StatusInternalServerError,
+			contentType: "application/graphql-response+json",
+			body:        `{"data": {"viewer": null}, "errors": [{"message": "boom"}]}`,
+			wantErr:     `graphql: response violates GraphQL over HTTP specification: response with data has non-2xx status code (status code: 500, content type: "application/graphql-response+json")`,
+			violation:   true,
+		},
+		{
+			name:        "empty",
+			status:      http.StatusOK,
+			contentType: "application/graphql-response+json",
+			body:        `{}`,
+			wantErr:     `graphql: response violates GraphQL over HTTP specification: response has neither data nor errors (status code: 200, content type: "application/graphql-response+json")`,
+			violation:   true,
+		},
+	}
+	for _, tc := range tests {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			if got, want := req.Header.Get("Accept"), "application/graphql-response+json, application/json;q=0.9"; got != want {
+				t.Errorf("%s: got Accept: %q, want: %q", tc.name, got, want)
+			}
+			w.Header().Set("Content-Type", tc.contentType)
+			w.WriteHeader(tc.status)
+			mustWrite(w, tc.body)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithStrictHTTP())
+
+		var q viewerQuery
+		err := client.Query(context.Background(), &q, nil)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: got error: %v", tc.name, err)
+			} else if q.Viewer.Login != "gopher" {
+				t.Errorf("%s: got login: %q, want: %q", tc.name, q.Viewer.Login, "gopher")
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: got error: nil, want: %v", tc.name, tc.wantErr)
+			continue
+		}
+		if got := err.Error(); got != tc.wantErr {
+			t.Errorf("%s: got error: %v, want: %v", tc.name, got, tc.wantErr)
+		}
+		if _, ok := err.(*graphql.SpecViolationError); ok != tc.violation {
+			t.Errorf("%s: got error of type %T, want *graphql.SpecViolationError: %v", tc.name, err, tc.violation)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"context"
+	"time"
+)
+
+// StampedePolicy is how a client created WithCache handles runs of the
+// same query that miss the cache while the query is being fetched, for
+// WithStampedeProtection.
+type StampedePolicy struct {
+	// Wait is the longest time runs wait for the run fetching the query,
+	// before fetching it themselves. If zero, they wait until the run
+	// fetching it is done, or their context is.
+	Wait time.Duration
+
+	// StaleFor is how long the data of expired cache entries is kept,
+	// to be served to runs missing the cache while the query is fetched,
+	// rather than making them wait. If zero, stale data isn't served.
+	StaleFor time.Duration
+}
+
+// WithStampedeProtection protects the server from stampedes of identical
+// queries, for clients created WithCache: when many runs of a query miss
+// the cache at once, such as when a popular entry expires, only the first
+// fetches it, while the others wait for its response to be cached, or are
+// served stale data, per policy. Runs that wait for a fetch that fails,
+// or whose response isn't cached, such as because it has errors, fetch
+// the query themselves.
+func WithStampedeProtection(policy StampedePolicy) ClientOption {
+	return func(c *Client) {
+		c.stampede = &policy
+	}
+}
+
+// flight is a fetch of a query response to be cached.
+type flight struct {
+	done chan struct{} // Closed once the response is cached, or failed to be.
+}
+
+// joinFlight returns the data for key, cached by a run fetching it, or
+// stale, if any. Otherwise, if no run is fetching it, it registers the
+// caller as fetching it, and returns the function to call once done.
+// Both are nil if the caller should fetch it without registering, such
+// as when it timed out waiting for the run fetching it.
+func (c *Client) joinFlight(ctx context.Context, op Operation, key string) (data []byte, done func()) {
+	c.flightMu.Lock()
+	f := c.flights[key]
+	if f == nil {
+		f = &flight{done: make(chan struct{})}
+		if c.flights == nil {
+			c.flights = make(map[string]*flight)
+		}
+		c.flights[key] = f
+		c.flightMu.Unlock()
+		return nil, func() {
+			c.flightMu.Lock()
+			delete(c.flights, key)
+			c.flightMu.Unlock()
+			close(f.done)
+		}
+	}
+	c.flightMu.Unlock()
+
+	if c.stampede.StaleFor > 0 {
+		if data, ok := c.getEntry(op, staleKey(key)); ok {
+			return data, nil
+		}
+	}
+	var timeout chan struct{}
+	if c.stampede.Wait > 0 {
+		timeout = make(chan struct{})
+		timer := c.clock.AfterFunc(c.stampede.Wait, func() { close(timeout) })
+		defer timer.Stop()
+	}
+	select {
+	case <-f.done:
+		data, _ := c.getEntry(op, key)
+		return data, nil
+	case <-timeout:
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+// storeStale stores cache entry entry for key to be served stale, for
+// ttl past its expiry, if c serves stale data.
+func (c *Client) storeStale(key string, entry []byte, ttl time.Duration) {
+	if c.stampede != nil && c.stampede.StaleFor > 0 && ttl > 0 {
+		c.cache.Set(staleKey(key), entry, ttl+c.stampede.StaleFor)
+	}
+}
+
+// evict removes the cached data for key, including stale data.
+func (c *Client) evict(key string) {
+	c.cache.Delete(key)
+	c.unindexEntities(key)
+	if c.stampede != nil && c.stampede.StaleFor > 0 {
+		c.cache.Delete(staleKey(key))
+	}
+}
+
+// staleKey returns the cache key of the stale data for key.
+func staleKey(key string) string {
+	return key + ":stale"
+}
@@ -0,0 +1,136 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/graphqltest"
+)
+
+// blockingServer returns a handler answering queries with the login
+// "gopher" and the number of the request, blocking the requests for which
+// block returns true until release is closed.
+func blockingServer(requests *int32, block func(n int32) bool, release <-chan struct{}) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(requests, 1)
+		mustRead(req.Body)
+		if block(n) {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher", "databaseId": `+strconv.Itoa(int(n))+`}}}`)
+	})
+	return mux
+}
+
+type viewerIDQuery struct {
+	Viewer struct {
+		Login      graphql.String
+		DatabaseID graphql.Int
+	}
+}
+
+func TestWithStampedeProtection(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	handler := blockingServer(&requests, func(n int32) bool { return n == 1 }, release)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: handler}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithStampedeProtection(graphql.StampedePolicy{}),
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var q viewerIDQuery
+			if err := client.Query(context.Background(), &q, nil); err != nil {
+				errs <- err
+				return
+			}
+			if q.Viewer.DatabaseID != 1 {
+				t.Errorf("got database ID %d, want 1", q.Viewer.DatabaseID)
+			}
+		}()
+	}
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // Let the other runs miss the cache.
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestWithStampedeProtection_wait(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	defer close(release)
+	handler := blockingServer(&requests, func(n int32) bool { return n == 1 }, release)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: handler}},
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithStampedeProtection(graphql.StampedePolicy{Wait: 10 * time.Millisecond}),
+	)
+	go client.Query(context.Background(), new(viewerIDQuery), nil)
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The run waits for the blocked fetch, then fetches the query itself.
+	var q viewerIDQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.Viewer.DatabaseID != 2 {
+		t.Errorf("got database ID %d, want 2", q.Viewer.DatabaseID)
+	}
+}
+
+func TestWithStampedeProtection_stale(t *testing.T) {
+	clock := graphqltest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var requests int32
+	release := make(chan struct{})
+	defer close(release)
+	handler := blockingServer(&requests, func(n int32) bool { return n == 2 }, release)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: handler}},
+		graphql.WithClock(clock),
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+		graphql.WithStampedeProtection(graphql.StampedePolicy{StaleFor: time.Hour}),
+	)
+	var q viewerIDQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(2 * time.Minute)
+
+	// The second request blocks, so the third run is served stale data.
+	go client.Query(context.Background(), new(viewerIDQuery), nil)
+	for atomic.LoadInt32(&requests) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	q = viewerIDQuery{}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.Viewer.DatabaseID != 1 {
+		t.Errorf("got database ID %d, want stale 1", q.Viewer.DatabaseID)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
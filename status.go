@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusClass is how a client treats responses with some HTTP status code.
+type StatusClass int
+
+const (
+	// StatusFailure means the response is a hard failure,
+	// returned as a *StatusError.
+	StatusFailure StatusClass = iota
+	// StatusGraphQL means the response body is a GraphQL response,
+	// and is decoded as one.
+	StatusGraphQL
+	// StatusRetryable means the response is a failure, returned as
+	// a *StatusError, that may not recur if the request is retried.
+	StatusRetryable
+)
+
+// StatusPolicy classifies HTTP status codes of responses.
+type StatusPolicy func(code int) StatusClass
+
+// DefaultStatusPolicy is the status policy clients use unless
+// WithStatusPolicy is given. It treats 200 OK responses as GraphQL
+// responses; 429 Too Many Requests, 502 Bad Gateway, 503 Service
+// Unavailable and 504 Gateway Timeout responses as retryable;
+// and all others as hard failures.
+func DefaultStatusPolicy(code int) StatusClass {
+	switch code {
+	case http.StatusOK:
+		return StatusGraphQL
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return StatusRetryable
+	default:
+		return StatusFailure
+	}
+}
+
+// WithStatusPolicy sets the policy classifying the HTTP status codes of
+// responses, such as to decode 203 or 207 responses of some gateways as
+// GraphQL responses. It doesn't apply to clients created with WithStrictHTTP,
+// which interpret status codes as specified by GraphQL over HTTP.
+func WithStatusPolicy(p StatusPolicy) ClientOption {
+	return func(c *Client) {
+		c.statusPolicy = p
+	}
+}
+
+// StatusError is returned for responses whose HTTP status code
+// isn't classified as StatusGraphQL.
+type StatusError struct {
+	StatusCode int
+	Status     string // Such as "502 Bad Gateway".
+	Body       string // Quoted, truncated body or summary of it; see WithMaxErrorBody.
+	Retryable  bool   // Whether the status code is classified as StatusRetryable.
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("non-200 OK status code: %v body: %s", e.Status, e.Body)
+}
@@ -0,0 +1,58 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_statusPolicy(t *testing.T) {
+	policy := func(code int) graphql.StatusClass {
+		switch code {
+		case http.StatusOK, http.StatusMultiStatus:
+			return graphql.StatusGraphQL
+		case http.StatusConflict:
+			return graphql.StatusRetryable
+		}
+		return graphql.DefaultStatusPolicy(code)
+	}
+	tests := []struct {
+		status        int
+		opts          []graphql.ClientOption
+		wantErr       bool
+		wantRetryable bool
+	}{
+		{status: http.StatusMultiStatus, wantErr: true},
+		{status: http.StatusMultiStatus, opts: []graphql.ClientOption{graphql.WithStatusPolicy(policy)}},
+		{status: http.StatusServiceUnavailable, wantErr: true, wantRetryable: true},
+		{status: http.StatusConflict, wantErr: true},
+		{status: http.StatusConflict, opts: []graphql.ClientOption{graphql.WithStatusPolicy(policy)}, wantErr: true, wantRetryable: true},
+	}
+	for _, tc := range tests {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(tc.status)
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, tc.opts...)
+
+		err := client.Query(context.Background(), new(viewerQuery), nil)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("status %d: got error: %v, want error: %v", tc.status, err, tc.wantErr)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		serr, ok := err.(*graphql.StatusError)
+		if !ok {
+			t.Errorf("status %d: got error of type %T, want *graphql.StatusError", tc.status, err)
+			continue
+		}
+		if serr.StatusCode != tc.status || serr.Retryable != tc.wantRetryable {
+			t.Errorf("status %d: got StatusCode: %d, Retryable: %v, want Retryable: %v", tc.status, serr.StatusCode, serr.Retryable, tc.wantRetryable)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/arvata-io/graphql/internal/jsonutil"
+)
+
+// Stream is a query data structure field type for large String or
+// Base64-encoded scalar values, such as exports and reports. The value is
+// written to W while the response is read, rather than held in memory.
+// W must be set before the operation is run:
+//
+//	var q struct {
+//		Report graphql.Stream `graphql:"report(id: $id)"`
+//	}
+//	q.Report.W = f
+//
+// Values are streamed if the client uses JSONCodec and not WithStrictHTTP.
+// Otherwise, they're written to W once the whole response is read.
+type Stream struct {
+	W      io.Writer
+	Base64 bool  // Whether the value is decoded from standard Base64 encoding before it's written to W.
+	N      int64 // Number of bytes written to W, set once the value is written.
+}
+
+// StreamWriter returns the writer the value of s is written to.
+// It's called while decoding responses.
+func (s *Stream) StreamWriter() (io.WriteCloser, error) {
+	if s.W == nil {
+		return nil, errors.New("graphql.Stream has no writer")
+	}
+	s.N = 0
+	w := &countWriter{w: s.W, n: &s.N}
+	if s.Base64 {
+		return &base64Writer{w: w}, nil
+	}
+	return w, nil
+}
+
+// UnmarshalJSON writes JSON string b to s.W. It's used for responses
+// that aren't streamed.
+func (s *Stream) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	w, err := s.StreamWriter()
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, v); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// countWriter counts the bytes written to w in *n.
+type countWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	*w.n += int64(n)
+	return n, err
+}
+
+func (w *countWriter) Close() error { return nil }
+
+// base64Writer decodes standard Base64 encoding written to it,
+// ignoring line breaks, and writes the decoded bytes to w.
+type base64Writer struct {
+	w       io.WriteCloser
+	pending []byte // Encoded bytes not decoded yet, fewer than 4 after a Write.
+	buf     []byte
+}
+
+func (w *base64Writer) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if c != '\r' && c != '\n' {
+			w.pending = append(w.pending, c)
+		}
+	}
+	if err := w.flush(len(w.pending) / 4 * 4); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flush decodes and writes the first n pending bytes.
+func (w *base64Writer) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	if cap(w.buf) < base64.StdEncoding.DecodedLen(n) {
+		w.buf = make([]byte, base64.StdEncoding.DecodedLen(n))
+	}
+	m, err := base64.StdEncoding.Decode(w.buf[:cap(w.buf)], w.pending[:n])
+	if err != nil {
+		return err
+	}
+	w.pending = append(w.pending[:0], w.pending[n:]...)
+	_, err = w.w.Write(w.buf[:m])
+	return err
+}
+
+func (w *base64Writer) Close() error {
+	if len(w.pending) > 0 {
+		return base64.CorruptInputError(len(w.pending))
+	}
+	return w.w.Close()
+}
+
+var streamType = reflect.TypeOf(Stream{})
+
+// hasStream reports whether query data structure type t has Stream fields.
+func hasStream(t reflect.Type) bool {
+	return hasStreamVisit(t, make(map[reflect.Type]bool))
+}
+
+func hasStreamVisit(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t == nil || visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasStreamVisit(t.Elem(), visited)
+	case reflect.Struct:
+		if t == streamType {
+			return true
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if hasStreamVisit(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeStreaming decodes a JSON response from r into out, decoding
+// its data into the query data structure into while it's read.
+func decodeStreaming(r io.Reader, out *Response, into interface{}) error {
+	if d, ok := into.(responseDecoder); ok {
+		return d.decodeResponse(r, out)
+	}
+	errs, extensions, err := jsonutil.DecodeResponse(r, into)
+	if err != nil {
+		return err
+	}
+	if errs != nil {
+		if err := json.Unmarshal(errs, &out.Errors); err != nil {
+			return err
+		}
+	}
+	if extensions != nil {
+		if err := json.Unmarshal(extensions, &out.Extensions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
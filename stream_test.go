@@ -0,0 +1,90 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+// signalWriter is an io.Writer that closes started on its first write.
+type signalWriter struct {
+	bytes.Buffer
+	started chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	if w.Len() == 0 {
+		close(w.started)
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestStream(t *testing.T) {
+	content := strings.Repeat("0123456789abcdef", 64*1024)
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	half := len(encoded) / 2
+	w := &signalWriter{started: make(chan struct{})}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		mustWrite(rw, `{"data": {"export": {"name": "export.txt", "content": "`+encoded[:half])
+		rw.(http.Flusher).Flush()
+		<-w.started // The client must stream the first half before the rest is sent.
+		mustWrite(rw, encoded[half:]+`"}}, "errors": [{"message": "partial export"}], "extensions": {"cost": 1}}`)
+	}))
+	defer ts.Close()
+	client := graphql.NewClient(ts.URL, ts.Client())
+
+	var q struct {
+		Export struct {
+			Name    graphql.String
+			Content graphql.Stream
+		}
+	}
+	q.Export.Content = graphql.Stream{W: w, Base64: true}
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err.Error(), "partial export"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+	if got, want := q.Export.Name, graphql.String("export.txt"); got != want {
+		t.Errorf("got name: %v, want: %v", got, want)
+	}
+	if w.String() != content {
+		t.Errorf("got %d bytes of content, want %d", w.Len(), len(content))
+	}
+	if got, want := q.Export.Content.N, int64(len(content)); got != want {
+		t.Errorf("got N: %v, want: %v", got, want)
+	}
+}
+
+func TestStream_notStreamed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"report": "line 1\nline 2é"}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithStrictHTTP())
+
+	var buf bytes.Buffer
+	var q struct {
+		Report graphql.Stream
+	}
+	q.Report.W = &buf
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "line 1\nline 2é"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+
+	q.Report.W = nil
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil, want: non-nil for Stream without writer")
+	}
+}
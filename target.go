@@ -0,0 +1,267 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Target is a query data structure writing a list in response data to
+// an io.Writer, rather than decoding it, for ETL pipelines that never
+// need the data in structs. It's used as the data structure of a Static
+// operation:
+//
+//	target := graphql.NDJSONTarget(w, "repository", "issues", "nodes")
+//	err := client.Run(ctx, &graphql.Static{
+//		QueryStr: `{repository(owner: "octocat", name: "Hello-World"){issues(first: 100){nodes{number,title,author{login}}}}}`,
+//		Into:     target,
+//	})
+//
+// The objects of the list are written while the response is read, if the
+// client uses JSONCodec and not WithStrictHTTP, without holding the
+// response data in memory. As they're written as they're read, they
+// aren't taken back if the response turns out to be invalid, nor if the
+// operation is retried. Nor are they cached.
+type Target struct {
+	N int // Number of objects written.
+
+	w       *bufio.Writer
+	path    []string
+	format  ExportFormat
+	csv     *csv.Writer
+	columns []string // Columns of CSV records, once the header is written.
+}
+
+// NDJSONTarget returns a Target writing the objects of the list at path,
+// a sequence of response keys from the data, to w as NDJSON: each object
+// as JSON on its own line.
+func NDJSONTarget(w io.Writer, path ...string) *Target {
+	return &Target{w: bufio.NewWriter(w), path: path, format: NDJSON}
+}
+
+// CSVTarget returns a Target writing the objects of the list at path,
+// a sequence of response keys from the data, to w as CSV: a header row
+// of field paths, such as "author.login", followed by a row for each
+// object. The columns are the fields of the first object, so fields of
+// objects that are null in it are written as one column of JSON.
+// Strings are written unquoted, null as an empty field, and lists and
+// other values as JSON.
+func CSVTarget(w io.Writer, path ...string) *Target {
+	t := &Target{w: bufio.NewWriter(w), path: path, format: CSV}
+	t.csv = csv.NewWriter(t.w)
+	return t
+}
+
+// DecodeGraphQL implements DataDecoder, writing the list in data, such as
+// when the response isn't read by the target while it's read.
+func (t *Target) DecodeGraphQL(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := t.writeList(dec, t.path); err != nil {
+		return err
+	}
+	return t.flush()
+}
+
+// decodeResponse decodes a JSON response from r into out, writing the list
+// in its data while it's read.
+func (t *Target) decodeResponse(r io.Reader, out *Response) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("invalid GraphQL response: %v", err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "data":
+			err = t.writeList(dec, t.path)
+		case "errors":
+			err = dec.Decode(&out.Errors)
+		case "extensions":
+			err = dec.Decode(&out.Extensions)
+		default:
+			err = dec.Decode(new(json.RawMessage))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return t.flush()
+}
+
+// writeList writes the objects of the list at path in the next value of dec.
+func (t *Target) writeList(dec *json.Decoder, path []string) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if len(path) == 0 {
+		if tok != json.Delim('[') {
+			return fmt.Errorf("graphql: target: got %v, want list", tok)
+		}
+		for dec.More() {
+			var obj json.RawMessage
+			if err := dec.Decode(&obj); err != nil {
+				return err
+			}
+			if err := t.write(obj); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("graphql: target: got %v at %q, want object", tok, path[0])
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key == path[0] {
+			err = t.writeList(dec, path[1:])
+		} else {
+			err = dec.Decode(new(json.RawMessage))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// write writes JSON object obj.
+func (t *Target) write(obj json.RawMessage) error {
+	if t.format == NDJSON {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, obj); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := t.w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		t.N++
+		return nil
+	}
+	keys, values, err := flattenObject(obj)
+	if err != nil {
+		return err
+	}
+	if t.columns == nil {
+		t.columns = keys
+		if t.columns == nil {
+			t.columns = []string{}
+		}
+		if err := t.csv.Write(t.columns); err != nil {
+			return err
+		}
+	}
+	byKey := make(map[string]string, len(keys))
+	for i, k := range keys {
+		byKey[k] = csvField(values[i])
+	}
+	record := make([]string, len(t.columns))
+	for i, k := range t.columns {
+		record[i] = byKey[k]
+	}
+	if err := t.csv.Write(record); err != nil {
+		return err
+	}
+	t.N++
+	return nil
+}
+
+func (t *Target) flush() error {
+	if t.csv != nil {
+		t.csv.Flush()
+		if err := t.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return t.w.Flush()
+}
+
+// flattenObject returns the paths of the leaf values of JSON object obj,
+// such as "author.login", in order, and their values. A null object is
+// a leaf value.
+func flattenObject(obj json.RawMessage) (keys []string, values []json.RawMessage, err error) {
+	var walk func(prefix string, raw json.RawMessage) error
+	walk = func(prefix string, raw json.RawMessage) error {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			key := prefix + tok.(string)
+			if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '{' {
+				if err := walk(key+".", trimmed); err != nil {
+					return err
+				}
+				continue
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+		return nil
+	}
+	err = walk("", obj)
+	return keys, values, err
+}
+
+// csvField returns JSON value formatted as a CSV field, as Export does.
+func csvField(value json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return string(value)
+	}
+	b := buf.Bytes()
+	switch {
+	case string(b) == "null":
+		return ""
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err == nil {
+			return s
+		}
+	}
+	return string(b)
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("got %v, want %v", tok, delim)
+	}
+	return nil
+}
+
+// responseDecoder is implemented by query data structures that read
+// whole responses themselves.
+type responseDecoder interface {
+	decodeResponse(r io.Reader, out *Response) error
+}
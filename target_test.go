@@ -0,0 +1,86 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+const targetResponse = `{"data": {"repository": {"issues": {"nodes": [
+	{"number": 1, "title": "First, \"quoted\"", "author": {"login": "gopher"}, "labels": ["bug"]},
+	{"number": 2, "title": null, "author": null, "labels": []}
+]}}}}`
+
+func TestNDJSONTarget(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		mustWrite(w, targetResponse)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var buf bytes.Buffer
+	target := graphql.NDJSONTarget(&buf, "repository", "issues", "nodes")
+	err := client.Run(context.Background(), &graphql.Static{
+		QueryStr: `{repository{issues(first: 2){nodes{number,title,author{login},labels}}}}`,
+		Into:     target,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"number":1,"title":"First, \"quoted\"","author":{"login":"gopher"},"labels":["bug"]}
+{"number":2,"title":null,"author":null,"labels":[]}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if target.N != 2 {
+		t.Errorf("got N: %d, want 2", target.N)
+	}
+}
+
+func TestCSVTarget(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		mustWrite(w, targetResponse)
+	})
+	for _, client := range []*graphql.Client{
+		graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}),
+		graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithStrictHTTP()),
+	} {
+		var buf bytes.Buffer
+		err := client.Run(context.Background(), &graphql.Static{
+			QueryStr: `{repository{issues(first: 2){nodes{number,title,author{login},labels}}}}`,
+			Into:     graphql.CSVTarget(&buf, "repository", "issues", "nodes"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `number,title,author.login,labels
+1,"First, ""quoted""",gopher,"[""bug""]"
+2,,,[]
+`
+		if got := buf.String(); got != want {
+			t.Errorf("got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestTarget_null(t *testing.T) {
+	var buf bytes.Buffer
+	target := graphql.NDJSONTarget(&buf, "repository", "issues", "nodes")
+	if err := target.DecodeGraphQL([]byte(`{"repository": null}`)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 || target.N != 0 {
+		t.Errorf("got %d objects: %q", target.N, buf.String())
+	}
+	if err := target.DecodeGraphQL([]byte(`{"repository": {"issues": {"nodes": 1}}}`)); err == nil {
+		t.Error("got nil error for non-list")
+	}
+}
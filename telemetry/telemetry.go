@@ -0,0 +1,253 @@
+// Package telemetry exports the usage of GraphQL operations by clients:
+// the counts, latencies and error rates of each operation, aggregated and
+// posted periodically to an endpoint as JSON, for visibility into the
+// operations a fleet of clients runs.
+//
+// Reports are posted as JSON objects of Report, such as:
+//
+//	{
+//		"start": "2024-05-04T10:00:00Z",
+//		"end": "2024-05-04T10:01:00Z",
+//		"operations": [
+//			{
+//				"name": "Viewer",
+//				"hash": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+//				"count": 120,
+//				"errors": 3,
+//				"errorRate": 0.025,
+//				"cached": 40,
+//				"latency": {"min": 0.8, "mean": 21.3, "p50": 18.2, "p95": 55.1, "p99": 80.4, "max": 93.7}
+//			}
+//		]
+//	}
+//
+// Latencies are in milliseconds.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+// MaxSamples is the number of latencies of each operation kept between
+// reports to compute percentiles from. Beyond it, latencies are sampled.
+const MaxSamples = 1024
+
+// Exporter aggregates the usage of operations by clients, and posts
+// reports of it to URL.
+type Exporter struct {
+	URL    string      // URL reports are posted to.
+	Header http.Header // Headers sent with reports, such as credentials.
+
+	// HTTPClient posts the reports. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	start time.Time
+	ops   map[string]*operation // Hash -> usage since the last report.
+}
+
+// Report is a report of the usage of operations.
+type Report struct {
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	Operations []Operation `json:"operations"` // In order of name, then hash.
+}
+
+// Operation is the usage of an operation, in reports.
+type Operation struct {
+	Name      string  `json:"name,omitempty"` // Operation name, if any.
+	Hash      string  `json:"hash"`           // Hex SHA-256 hash of the document.
+	Count     int     `json:"count"`          // Times the operation was run.
+	Errors    int     `json:"errors"`         // Times the operation failed, including with GraphQL errors.
+	ErrorRate float64 `json:"errorRate"`      // Errors divided by Count.
+	Cached    int     `json:"cached"`         // Times the response was served from the cache.
+	Latency   Latency `json:"latency"`
+}
+
+// Latency is the distribution of the latencies of an operation,
+// in milliseconds.
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// operation aggregates the usage of an operation.
+type operation struct {
+	name                  string
+	hash                  string
+	count, errors, cached int
+	total, min, max       time.Duration
+	samples               []time.Duration
+}
+
+// Option returns a client option that makes the client record the usage
+// of its operations in e.
+func (e *Exporter) Option() graphql.ClientOption {
+	return graphql.WithObserver(func(_ context.Context, ev graphql.OperationEvent) {
+		e.Record(ev)
+	})
+}
+
+// Record records the usage of an operation described by ev.
+func (e *Exporter) Record(ev graphql.OperationEvent) {
+	sum := sha256.Sum256([]byte(ev.Query))
+	hash := hex.EncodeToString(sum[:])
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ops == nil {
+		e.ops = make(map[string]*operation)
+	}
+	if e.start.IsZero() {
+		e.start = time.Now()
+	}
+	op, ok := e.ops[hash]
+	if !ok {
+		op = &operation{name: ev.Name, hash: hash, min: ev.Duration}
+		e.ops[hash] = op
+	}
+	op.count++
+	if ev.Err != nil {
+		op.errors++
+	}
+	if ev.Cached {
+		op.cached++
+	}
+	op.total += ev.Duration
+	if ev.Duration < op.min {
+		op.min = ev.Duration
+	}
+	if ev.Duration > op.max {
+		op.max = ev.Duration
+	}
+	// Keep a uniform sample of the latencies (reservoir sampling).
+	if len(op.samples) < MaxSamples {
+		op.samples = append(op.samples, ev.Duration)
+	} else if i := rand.Intn(op.count); i < MaxSamples {
+		op.samples[i] = ev.Duration
+	}
+}
+
+// Snapshot returns a report of the usage recorded since the last one,
+// and starts a new one.
+func (e *Exporter) Snapshot() Report {
+	e.mu.Lock()
+	ops, start := e.ops, e.start
+	e.ops, e.start = nil, time.Time{}
+	e.mu.Unlock()
+
+	r := Report{Start: start, End: time.Now(), Operations: make([]Operation, 0, len(ops))}
+	for _, op := range ops {
+		r.Operations = append(r.Operations, op.report())
+	}
+	sort.Slice(r.Operations, func(i, j int) bool {
+		a, b := r.Operations[i], r.Operations[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Hash < b.Hash
+	})
+	return r
+}
+
+func (op *operation) report() Operation {
+	sort.Slice(op.samples, func(i, j int) bool { return op.samples[i] < op.samples[j] })
+	percentile := func(p float64) float64 {
+		return ms(op.samples[int(p*float64(len(op.samples)-1)+0.5)])
+	}
+	return Operation{
+		Name:      op.name,
+		Hash:      op.hash,
+		Count:     op.count,
+		Errors:    op.errors,
+		ErrorRate: float64(op.errors) / float64(op.count),
+		Cached:    op.cached,
+		Latency: Latency{
+			Min:  ms(op.min),
+			Mean: ms(op.total / time.Duration(op.count)),
+			P50:  percentile(0.50),
+			P95:  percentile(0.95),
+			P99:  percentile(0.99),
+			Max:  ms(op.max),
+		},
+	}
+}
+
+// ms returns d in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Flush posts a report of the usage recorded since the last one to the
+// URL of e, unless there's none. The usage is dropped, whether the report
+// is posted or not.
+func (e *Exporter) Flush(ctx context.Context) error {
+	r := e.Snapshot()
+	if len(r.Operations) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	hc := e.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry: posting report: non-2xx status code: %v", resp.Status)
+	}
+	return nil
+}
+
+// Run flushes e every interval until ctx is done, then flushes the
+// remaining usage, with a new context. Errors are passed to onError,
+// if not nil.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	flush := func(ctx context.Context) {
+		if err := e.Flush(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			flush(ctx)
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			flush(ctx)
+			cancel()
+			return
+		}
+	}
+}
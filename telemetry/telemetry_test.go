@@ -0,0 +1,99 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/telemetry"
+)
+
+func TestExporterSnapshot(t *testing.T) {
+	var e telemetry.Exporter
+	for i := 1; i <= 100; i++ {
+		ev := graphql.OperationEvent{Name: "Viewer", Query: "query Viewer{viewer{login}}", Duration: time.Duration(i) * time.Millisecond}
+		if i%10 == 0 {
+			ev.Err = errors.New("boom")
+		}
+		if i%4 == 0 {
+			ev.Cached = true
+		}
+		e.Record(ev)
+	}
+	e.Record(graphql.OperationEvent{Query: "{viewer{login}}", Duration: time.Millisecond})
+
+	r := e.Snapshot()
+	if len(r.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(r.Operations))
+	}
+	if r.Start.IsZero() || r.End.Before(r.Start) {
+		t.Errorf("got period %v to %v, want a valid one", r.Start, r.End)
+	}
+	anon, viewer := r.Operations[0], r.Operations[1]
+	if anon.Name != "" || anon.Count != 1 {
+		t.Errorf("got %+v, want one anonymous run", anon)
+	}
+	want := telemetry.Latency{Min: 1, Mean: 50.5, P50: 51, P95: 95, P99: 99, Max: 100}
+	if viewer.Name != "Viewer" || viewer.Count != 100 || viewer.Errors != 10 || viewer.ErrorRate != 0.1 || viewer.Cached != 25 || viewer.Latency != want {
+		t.Errorf("got %+v, want 100 runs, 10 errors, 25 cached, latency %+v", viewer, want)
+	}
+	if r := e.Snapshot(); len(r.Operations) != 0 {
+		t.Errorf("got %d operations in the next snapshot, want none", len(r.Operations))
+	}
+}
+
+func TestExporterFlush(t *testing.T) {
+	var got []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("got Authorization %q, want %q", req.Header.Get("Authorization"), "Bearer token")
+		}
+		var r map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Error(err)
+		}
+		got = append(got, r)
+	}))
+	defer srv.Close()
+	gql := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer gql.Close()
+
+	e := &telemetry.Exporter{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer token"}}}
+	client := graphql.NewClient(gql.URL, nil, e.Option())
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.Query{Data: &q, Name: "Viewer"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d reports, want 1", len(got))
+	}
+	ops := got[0]["operations"].([]interface{})
+	op := ops[0].(map[string]interface{})
+	for _, key := range []string{"name", "hash", "count", "errors", "errorRate", "cached", "latency"} {
+		if _, ok := op[key]; !ok {
+			t.Errorf("report operation has no %q: %v", key, op)
+		}
+	}
+	if op["name"] != "Viewer" || op["count"] != 1.0 {
+		t.Errorf("got operation %v, want 1 run of Viewer", op)
+	}
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// WithRequestTransform sets a transform applied to the bodies of requests
+// to the server after they're encoded, such as to wrap the GraphQL payload
+// in a signed or encrypted envelope, or in proprietary framing. The
+// transformed body is read fully before the request is sent. Headers
+// describing the transformed body, such as Content-Type, can be set by
+// the operation's RequestHandler or the HTTP client's transport.
+func WithRequestTransform(transform func(io.Reader) io.Reader) ClientOption {
+	return func(c *Client) {
+		c.requestTransform = transform
+	}
+}
+
+// WithResponseTransform sets a transform applied to the bodies of
+// responses from the server before they're decoded, such as to decrypt
+// an envelope, decompress a custom encoding, or unwrap NDJSON framing.
+// It also applies to the bodies included in errors for responses with
+// unexpected status codes.
+//
+// Errors from reading the transformed body are returned as errors
+// decoding the response.
+func WithResponseTransform(transform func(io.Reader) io.Reader) ClientOption {
+	return func(c *Client) {
+		c.responseTransform = transform
+	}
+}
+
+// decodeContentEncoding replaces the body of resp with its decoding, if it
+// has a gzip or deflate Content-Encoding. That's the case when the HTTP
+// client's transport has automatic decompression disabled, or when the
+// transport is a custom one that doesn't decompress.
+func decodeContentEncoding(resp *http.Response) error {
+	var (
+		r   io.Reader
+		err error
+	)
+	switch enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); enc {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = zlib.NewReader(resp.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%v response with invalid %s encoding: %v", resp.Status, resp.Header.Get("Content-Encoding"), err)
+	}
+	resp.Body = ioutil.NopCloser(r)
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
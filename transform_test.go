@@ -0,0 +1,120 @@
+package graphql_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_responseTransform(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, base64.StdEncoding.EncodeToString([]byte(`{"data": {"viewer": {"login": "gopher"}}}`)))
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithResponseTransform(func(r io.Reader) io.Reader {
+			return base64.NewDecoder(base64.StdEncoding, r)
+		}),
+	)
+
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+}
+
+func TestClient_responseTransform_errorBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		mustWrite(w, "ZGVuaWVk")
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithResponseTransform(func(r io.Reader) io.Reader {
+			var buf bytes.Buffer
+			io.Copy(&buf, base64.NewDecoder(base64.StdEncoding, r))
+			return &buf
+		}),
+	)
+
+	err := client.Query(context.Background(), new(viewerQuery), nil)
+	if got, want := err.Error(), `non-200 OK status code: 403 Forbidden body: "denied"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_requestTransform(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if got, want := mustRead(req.Body), `{"envelope":{"query":"{viewer{login}}"}`+"\n}"; got != want {
+			t.Errorf("got body: %q, want: %q", got, want)
+		}
+		if got, want := req.ContentLength, int64(len(`{"envelope":{"query":"{viewer{login}}"}`+"\n}")); got != want {
+			t.Errorf("got content length: %v, want: %v", got, want)
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithRequestTransform(func(r io.Reader) io.Reader {
+			return io.MultiReader(strings.NewReader(`{"envelope":`), r, strings.NewReader(`}`))
+		}),
+	)
+
+	if err := client.Query(context.Background(), new(viewerQuery), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_gzipResponse(t *testing.T) {
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		mustWrite(zw, s)
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	tests := []struct {
+		status  int
+		body    []byte
+		wantErr string
+	}{
+		{status: http.StatusOK, body: gzipped(`{"data": {"viewer": {"login": "gopher"}}}`)},
+		{status: http.StatusBadGateway, body: gzipped("upstream unavailable"), wantErr: `non-200 OK status code: 502 Bad Gateway body: "upstream unavailable"`},
+		{status: http.StatusOK, body: []byte("not gzip"), wantErr: `200 OK response with invalid gzip encoding: unexpected EOF`},
+	}
+	for _, tc := range tests {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(tc.status)
+			w.Write(tc.body)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+		var q viewerQuery
+		err := client.Query(context.Background(), &q, nil)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("got error: %v", err)
+			} else if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+				t.Errorf("got login: %q, want: %q", got, want)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("got error: %v, want: %v", err, tc.wantErr)
+		}
+	}
+}
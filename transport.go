@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WithProxy makes the client send requests through the proxy at proxyURL,
+// such as "http://proxy.example.com:3128" or "socks5://127.0.0.1:1080".
+// It requires the HTTP client's transport to be an *http.Transport,
+// or nil; the transport is copied, not modified.
+func WithProxy(proxyURL string) ClientOption {
+	return func(c *Client) {
+		c.proxy = proxyURL
+	}
+}
+
+// configureProxy sets the proxy of c's HTTP client's transport to c.proxy.
+// c.httpClient must not be shared.
+func (c *Client) configureProxy() error {
+	u, err := url.Parse(c.proxy)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %v", err)
+	}
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	t.Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// transport returns a copy of the *http.Transport of c's HTTP client,
+// installed in the HTTP client, to be configured by client options.
+// c.httpClient must not be shared.
+func (c *Client) transport() (*http.Transport, error) {
+	if c.ownTransport != nil {
+		return c.ownTransport, nil
+	}
+	rt := c.httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot configure HTTP client transport of type %T; want *http.Transport", rt)
+	}
+	c.ownTransport = t.Clone()
+	c.httpClient.Transport = c.ownTransport
+	return c.ownTransport, nil
+}
+
+// WithTLSConfig sets the TLS configuration used by the client's transport.
+// WithClientCertificate and WithRootCAs apply on top of it. It requires
+// the HTTP client's transport to be an *http.Transport, or nil;
+// the transport is copied, not modified.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tls.config = config
+	}
+}
+
+// WithClientCertificate makes the client present the certificate in PEM
+// encoded certFile, with the private key in keyFile, to servers requiring
+// mutual TLS authentication. It has the same transport requirement as
+// WithTLSConfig.
+func WithClientCertificate(certFile, keyFile string) ClientOption {
+	return func(c *Client) {
+		c.tls.certFile, c.tls.keyFile = certFile, keyFile
+	}
+}
+
+// WithRootCAs makes the client verify server certificates against the
+// certificate authorities in pool, instead of the system's. It has the
+// same transport requirement as WithTLSConfig.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return func(c *Client) {
+		c.tls.rootCAs = pool
+	}
+}
+
+// tlsOptions are the TLS options of a client.
+type tlsOptions struct {
+	config            *tls.Config
+	certFile, keyFile string
+	rootCAs           *x509.CertPool
+}
+
+func (o tlsOptions) isSet() bool {
+	return o.config != nil || o.certFile != "" || o.rootCAs != nil
+}
+
+// configureTLS sets the TLS configuration of c's HTTP client's transport
+// according to c.tls. c.httpClient must not be shared.
+func (c *Client) configureTLS() error {
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	var config *tls.Config
+	switch {
+	case c.tls.config != nil:
+		config = c.tls.config.Clone()
+	case t.TLSClientConfig != nil:
+		config = t.TLSClientConfig.Clone()
+	default:
+		config = new(tls.Config)
+	}
+	if c.tls.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.tls.certFile, c.tls.keyFile)
+		if err != nil {
+			return fmt.Errorf("loading client certificate: %v", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	if c.tls.rootCAs != nil {
+		config.RootCAs = c.tls.rootCAs
+	}
+	t.TLSClientConfig = config
+	return nil
+}
+
+// Timeouts are the timeouts of a client's HTTP requests. Zero values leave
+// the corresponding timeouts of the HTTP client and its transport as is.
+type Timeouts struct {
+	// Connect limits the time to establish TCP connections. Setting it,
+	// or WithIPPreference, replaces the transport's dialer.
+	Connect time.Duration
+
+	// TLSHandshake limits the time of TLS handshakes.
+	TLSHandshake time.Duration
+
+	// ResponseHeader limits the time to wait for the server's response
+	// headers after sending a request.
+	ResponseHeader time.Duration
+
+	// Total limits the time of requests from connecting until their
+	// response bodies are read, including redirects, so it must allow
+	// for the largest responses, such as those with Stream fields.
+	Total time.Duration
+}
+
+// DefaultTimeouts are timeouts suitable for production clients of most
+// servers, for use with WithTimeouts.
+var DefaultTimeouts = Timeouts{
+	Connect:        10 * time.Second,
+	TLSHandshake:   10 * time.Second,
+	ResponseHeader: 30 * time.Second,
+	Total:          60 * time.Second,
+}
+
+// WithTimeouts sets the timeouts of the client's HTTP requests, such as
+// DefaultTimeouts, as an alternative to configuring an http.Transport.
+// All but Total have the same transport requirement as WithProxy.
+// The deadlines of operations' contexts apply as well.
+func WithTimeouts(timeouts Timeouts) ClientOption {
+	return func(c *Client) {
+		c.timeouts = &timeouts
+	}
+}
+
+// configureTimeouts applies c.timeouts to c's HTTP client and its
+// transport. c.httpClient must not be shared.
+func (c *Client) configureTimeouts() error {
+	to := c.timeouts
+	if to.Total != 0 {
+		c.httpClient.Timeout = to.Total
+	}
+	if to.TLSHandshake == 0 && to.ResponseHeader == 0 {
+		return nil
+	}
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	if to.TLSHandshake != 0 {
+		t.TLSHandshakeTimeout = to.TLSHandshake
+	}
+	if to.ResponseHeader != 0 {
+		t.ResponseHeaderTimeout = to.ResponseHeader
+	}
+	return nil
+}
+
+// KeepAlive configures how a client keeps connections to servers alive
+// between requests. Zero values leave those of the transport as is.
+type KeepAlive struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept per
+	// host. Clients sending many concurrent requests to one server, as
+	// is typical of GraphQL, need it above http.DefaultMaxIdleConnsPerHost
+	// to reuse their connections rather than close and open them.
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long idle connections are kept. It should be
+	// shorter than the server's or load balancer's idle timeout, so
+	// requests aren't sent on connections being closed by the server.
+	IdleConnTimeout time.Duration
+}
+
+// GatewayKeepAlive keeps connections alive for clients of a GraphQL gateway
+// or server behind a load balancer, for use with WithKeepAlive.
+var GatewayKeepAlive = KeepAlive{
+	MaxIdleConnsPerHost: 64,
+	IdleConnTimeout:     50 * time.Second,
+}
+
+// WithKeepAlive sets how the client keeps connections alive, such as
+// with GatewayKeepAlive. How often connections are reused is reported
+// to observers in OperationEvent.Conns. It has the same transport
+// requirement as WithProxy.
+func WithKeepAlive(keepAlive KeepAlive) ClientOption {
+	return func(c *Client) {
+		c.keepAlive = &keepAlive
+	}
+}
+
+// configureKeepAlive applies c.keepAlive to c's HTTP client's transport.
+// c.httpClient must not be shared.
+func (c *Client) configureKeepAlive() error {
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	if n := c.keepAlive.MaxIdleConnsPerHost; n != 0 {
+		t.MaxIdleConnsPerHost = n
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+			t.MaxIdleConns = n // Otherwise, it limits idle connections per host too.
+		}
+	}
+	if d := c.keepAlive.IdleConnTimeout; d != 0 {
+		t.IdleConnTimeout = d
+	}
+	return nil
+}
+
+// configureDialer replaces the dialer of c's HTTP client's transport with
+// one applying the connect timeout and IP preference of c. c.httpClient
+// must not be shared.
+func (c *Client) configureDialer() error {
+	t, err := c.transport()
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second} // As of http.DefaultTransport.
+	if c.timeouts != nil && c.timeouts.Connect != 0 {
+		dialer.Timeout = c.timeouts.Connect
+	}
+	t.DialContext = dial(dialer, c.ipPreference)
+	return nil
+}
+
+// configureTransport applies the options configuring the transport of
+// c's HTTP client. c.httpClient must not be shared.
+func (c *Client) configureTransport() error {
+	if c.proxy != "" {
+		if err := c.configureProxy(); err != nil {
+			return err
+		}
+	}
+	if c.tls.isSet() {
+		if err := c.configureTLS(); err != nil {
+			return err
+		}
+	}
+	if c.timeouts != nil {
+		if err := c.configureTimeouts(); err != nil {
+			return err
+		}
+	}
+	if c.keepAlive != nil {
+		if err := c.configureKeepAlive(); err != nil {
+			return err
+		}
+	}
+	if (c.timeouts != nil && c.timeouts.Connect != 0) || c.ipPreference != HappyEyeballs {
+		if err := c.configureDialer(); err != nil {
+			return err
+		}
+	}
+	if c.dnsRefresh != nil {
+		if _, err := c.transport(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.URL.String(), "http://graphql.example.invalid/graphql"; got != want {
+			t.Errorf("got proxied URL: %v, want: %v", got, want)
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer proxy.Close()
+
+	client := graphql.NewClient("http://graphql.example.invalid/graphql", nil, graphql.WithProxy(proxy.URL))
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Viewer.Login, graphql.String("gopher"); got != want {
+		t.Errorf("got login: %q, want: %q", got, want)
+	}
+
+	client = graphql.NewClient("http://graphql.example.invalid/graphql", &http.Client{Transport: localRoundTripper{}}, graphql.WithProxy(proxy.URL))
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err.Error(), "cannot configure HTTP client transport of type graphql_test.localRoundTripper; want *http.Transport"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_mutualTLS(t *testing.T) {
+	certFile, keyFile, clientCert := writeClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got, want := req.TLS.PeerCertificates[0].Subject.CommonName, "graphql-client"; got != want {
+			t.Errorf("got client certificate for: %q, want: %q", got, want)
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ts.Certificate())
+
+	client := graphql.NewClient(ts.URL, nil,
+		graphql.WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+		graphql.WithClientCertificate(certFile, keyFile),
+		graphql.WithRootCAs(rootCAs),
+	)
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	client = graphql.NewClient(ts.URL, nil, graphql.WithRootCAs(rootCAs))
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil without client certificate, want: non-nil")
+	}
+
+	client = graphql.NewClient(ts.URL, nil, graphql.WithClientCertificate(keyFile, certFile))
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil with invalid client certificate files, want: non-nil")
+	}
+}
+
+// writeClientCertificate writes a self-signed client certificate and
+// its key to PEM files in a temporary directory.
+func writeClientCertificate(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "graphql-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestWithTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	client := graphql.NewClient(ts.URL, nil, graphql.WithTimeouts(graphql.Timeouts{ResponseHeader: 50 * time.Millisecond}))
+	var q viewerQuery
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil, want: response header timeout")
+	}
+
+	client = graphql.NewClient(ts.URL, nil, graphql.WithTimeouts(graphql.Timeouts{Total: 50 * time.Millisecond}))
+	if err := client.Query(context.Background(), &q, nil); err == nil {
+		t.Error("got error: nil, want: total timeout")
+	}
+
+	client = graphql.NewClient(ts.URL, &http.Client{Transport: localRoundTripper{}}, graphql.WithTimeouts(graphql.DefaultTimeouts))
+	err := client.Query(context.Background(), &q, nil)
+	if got, want := err.Error(), "cannot configure HTTP client transport of type graphql_test.localRoundTripper; want *http.Transport"; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
+
+func TestWithKeepAlive(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	}))
+	defer ts.Close()
+
+	var conns []graphql.ConnStats
+	client := graphql.NewClient(ts.URL, nil,
+		graphql.WithKeepAlive(graphql.GatewayKeepAlive),
+		graphql.WithObserver(func(ctx context.Context, e graphql.OperationEvent) {
+			conns = append(conns, e.Conns)
+		}),
+	)
+	var q viewerQuery
+	for i := 0; i < 3; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(conns) != 3 {
+		t.Fatalf("got %d events, want 3", len(conns))
+	}
+	for i, c := range conns {
+		if c.Requests != 1 || (c.Reused == 1) != (i > 0) {
+			t.Errorf("operation %d: got %d requests, %d reused", i, c.Requests, c.Reused)
+		}
+		if i > 0 && c.Connect != 0 {
+			t.Errorf("operation %d: got connect time %v on reused connection", i, c.Connect)
+		}
+	}
+	if conns[0].Connect == 0 {
+		t.Error("got zero connect time for new connection")
+	}
+}
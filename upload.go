@@ -0,0 +1,252 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Upload is a file to upload, as the value of a variable of the Upload
+// scalar. Requests with uploads are sent as multipart/form-data following
+// the GraphQL multipart request specification, streaming the files from
+// their Body without buffering them in memory.
+//
+// Uploads may be the values of variables, or elements of lists of them;
+// they aren't found in input objects. Request transforms set with
+// WithRequestTransform don't apply to requests with uploads.
+//
+// Operations decorated with WithRetry are only retried if the Body of
+// each of their uploads is an io.Seeker, such as an *os.File, which is
+// rewound to where it was before each attempt. Others are run once.
+//
+// Specification: https://github.com/jaydenseric/graphql-multipart-request-spec.
+type Upload struct {
+	Name        string    // File name.
+	ContentType string    // Media type; "application/octet-stream" if empty.
+	Body        io.Reader // File contents. It's read once, when the request is sent.
+
+	// Size is the size of Body in bytes, or 0 if unknown. If the sizes of
+	// all uploads of a request are known, it's sent with a Content-Length.
+	Size int64
+
+	// Progress, if non-nil, is called as Body is sent, with the number
+	// of bytes sent so far and Size, or -1 if Size is unknown.
+	// It's called from a goroutine other than the one running the operation.
+	Progress func(sent, total int64)
+}
+
+// MarshalJSON encodes u as null, since files are sent separately from
+// the operation.
+func (Upload) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// fileUpload is an upload found in the variables of a request.
+type fileUpload struct {
+	path   string // Object path of the variable, such as "variables.files.0".
+	upload *Upload
+}
+
+var uploadType = reflect.TypeOf(Upload{})
+
+// findUploads returns the uploads in variables, in order of path.
+func findUploads(variables map[string]interface{}) []fileUpload {
+	var uploads []fileUpload
+	var find func(path string, v reflect.Value)
+	find = func(path string, v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if !v.IsNil() {
+				find(path, v.Elem())
+			}
+		case reflect.Interface:
+			find(path, v.Elem())
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				find(path+"."+strconv.Itoa(i), v.Index(i))
+			}
+		case reflect.Struct:
+			if v.Type() == uploadType {
+				u := v.Interface().(Upload)
+				uploads = append(uploads, fileUpload{path: path, upload: &u})
+			}
+		}
+	}
+	for k, v := range variables {
+		find("variables."+k, reflect.ValueOf(v))
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].path < uploads[j].path })
+	return uploads
+}
+
+// uploadOffsets returns the offsets of the bodies of uploads, to rewind
+// them to with rewindUploads, and reports whether they're all io.Seekers.
+func uploadOffsets(uploads []fileUpload) ([]int64, bool) {
+	offsets := make([]int64, len(uploads))
+	for i, u := range uploads {
+		s, ok := u.upload.Body.(io.Seeker)
+		if !ok {
+			return nil, false
+		}
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, false
+		}
+		offsets[i] = offset
+	}
+	return offsets, true
+}
+
+// rewindUploads seeks the bodies of uploads to offsets.
+func rewindUploads(uploads []fileUpload, offsets []int64) error {
+	for i, u := range uploads {
+		if _, err := u.upload.Body.(io.Seeker).Seek(offsets[i], io.SeekStart); err != nil {
+			return fmt.Errorf("rewinding upload %q: %v", u.upload.Name, err)
+		}
+	}
+	return nil
+}
+
+// multipartBody is the body of a request with uploads. Its contents are
+// written by a goroutine started on the first Read, so no goroutine is
+// left behind if the request isn't sent.
+type multipartBody struct {
+	operations  []byte
+	uploads     []fileUpload
+	boundary    string
+	contentType string
+	length      int64 // Or -1 if unknown.
+
+	once sync.Once
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+}
+
+// newMultipartBody returns the body of a request with JSON-encoded
+// operations and uploads.
+func newMultipartBody(operations []byte, uploads []fileUpload) *multipartBody {
+	b := &multipartBody{operations: operations, uploads: uploads, length: -1}
+	cw := &countingWriter{}
+	mw := multipart.NewWriter(cw)
+	b.boundary, b.contentType = mw.Boundary(), mw.FormDataContentType()
+	if err := b.write(mw, false); err == nil {
+		b.length = cw.n
+		for _, u := range uploads {
+			if u.upload.Size <= 0 {
+				b.length = -1
+				break
+			}
+			b.length += u.upload.Size
+		}
+	}
+	b.pr, b.pw = io.Pipe()
+	return b
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			mw := multipart.NewWriter(b.pw)
+			mw.SetBoundary(b.boundary)
+			b.pw.CloseWithError(b.write(mw, true))
+		}()
+	})
+	return b.pr.Read(p)
+}
+
+func (b *multipartBody) Close() error {
+	return b.pr.Close()
+}
+
+// write writes the parts of b to mw, with the contents of files if files is set.
+func (b *multipartBody) write(mw *multipart.Writer, files bool) error {
+	if err := mw.WriteField("operations", string(b.operations)); err != nil {
+		return err
+	}
+	m := make(map[string][]string, len(b.uploads))
+	for i, u := range b.uploads {
+		m[strconv.Itoa(i)] = []string{u.path}
+	}
+	mapping, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := mw.WriteField("map", string(mapping)); err != nil {
+		return err
+	}
+	for i, u := range b.uploads {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%d"; filename="%s"`, i, escapeQuotes(u.upload.Name)))
+		ctype := u.upload.ContentType
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		h.Set("Content-Type", ctype)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if !files {
+			continue
+		}
+		if err := copyUpload(w, u.upload); err != nil {
+			return fmt.Errorf("uploading %s: %v", u.path, err)
+		}
+	}
+	return mw.Close()
+}
+
+// copyUpload copies the contents of u to w, reporting progress.
+func copyUpload(w io.Writer, u *Upload) error {
+	if u.Body == nil {
+		return nil
+	}
+	if u.Progress == nil {
+		_, err := io.Copy(w, u.Body)
+		return err
+	}
+	total := u.Size
+	if total <= 0 {
+		total = -1
+	}
+	var sent int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := u.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			sent += int64(n)
+			u.Progress(sent, total)
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,94 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_upload(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int64
+		wantLength bool
+	}{
+		{name: "known size", size: 11, wantLength: true},
+		{name: "unknown size", size: 0, wantLength: false},
+	}
+	for _, tc := range tests {
+		var parts []string
+		var contentLength int64
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			contentLength = req.ContentLength
+			_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mr := multipart.NewReader(req.Body, params["boundary"])
+			for {
+				p, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				parts = append(parts, strings.Join([]string{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), mustRead(p)}, "|"))
+			}
+			mustWrite(w, `{"data": {"upload": {"ok": true}}}`)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+		var progress []int64
+		var m struct {
+			Upload struct {
+				OK graphql.Boolean
+			} `graphql:"upload(file: $file, attachments: $attachments)"`
+		}
+		err := client.Mutate(context.Background(), &m, map[string]interface{}{
+			"file": graphql.Upload{
+				Name:        "hello.txt",
+				ContentType: "text/plain",
+				Body:        strings.NewReader("hello world"),
+				Size:        tc.size,
+				Progress:    func(sent, total int64) { progress = append(progress, sent, total) },
+			},
+			"attachments": []*graphql.Upload{
+				{Name: `a "b".bin`, Body: strings.NewReader("ab"), Size: 2},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			`operations|||{"query":"mutation($attachments:[Upload]!$file:Upload!){upload(file: $file, attachments: $attachments){ok}}","variables":{"attachments":[null],"file":null}}` + "\n",
+			`map|||{"0":["variables.attachments.0"],"1":["variables.file"]}`,
+			`0|a "b".bin|application/octet-stream|ab`,
+			`1|hello.txt|text/plain|hello world`,
+		}
+		if len(parts) != len(want) {
+			t.Fatalf("%s: got parts:\n%q\nwant:\n%q", tc.name, parts, want)
+		}
+		for i := range want {
+			if parts[i] != want[i] {
+				t.Errorf("%s: part %d:\ngot:  %s\nwant: %s", tc.name, i, parts[i], want[i])
+			}
+		}
+		if got := contentLength > 0; got != tc.wantLength {
+			t.Errorf("%s: got Content-Length: %d", tc.name, contentLength)
+		}
+		total := tc.size
+		if total == 0 {
+			total = -1
+		}
+		if len(progress) == 0 || progress[len(progress)-2] != 11 || progress[len(progress)-1] != total {
+			t.Errorf("%s: got progress: %v, want final 11 of %d", tc.name, progress, total)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type urlParamsKey struct{}
+
+// WithURLParams returns a copy of ctx carrying values for parameters in
+// URL templates. The GraphQL server URL of a client, and the URL of
+// a Routed operation, may be templates with parameters in braces, such as
+// "https://{region}.api.example.com/graphql?tenant={tenant}". Parameters
+// are resolved from values carried by the context of a request, or else
+// from the variables of the operation with the same names.
+func WithURLParams(ctx context.Context, params map[string]string) context.Context {
+	if old, ok := ctx.Value(urlParamsKey{}).(map[string]string); ok {
+		merged := make(map[string]string, len(old)+len(params))
+		for k, v := range old {
+			merged[k] = v
+		}
+		for k, v := range params {
+			merged[k] = v
+		}
+		params = merged
+	}
+	return context.WithValue(ctx, urlParamsKey{}, params)
+}
+
+// expandURL expands the parameters of URL template tmpl with values
+// from ctx or variables. Values are escaped for the part of the URL
+// they appear in.
+func expandURL(ctx context.Context, tmpl string, variables map[string]interface{}) (string, error) {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl, nil
+	}
+	params, _ := ctx.Value(urlParamsKey{}).(map[string]string)
+	var b strings.Builder
+	inQuery := false
+	for {
+		i := strings.IndexByte(tmpl, '{')
+		if i == -1 {
+			b.WriteString(tmpl)
+			return b.String(), nil
+		}
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j == -1 {
+			return "", fmt.Errorf("unterminated parameter in URL template %q", tmpl)
+		}
+		b.WriteString(tmpl[:i])
+		inQuery = inQuery || strings.ContainsRune(tmpl[:i], '?')
+		name := tmpl[i+1 : i+j]
+		value, ok := params[name]
+		if !ok {
+			value, ok = urlParam(variables[name])
+		}
+		if !ok {
+			return "", fmt.Errorf("no value for URL template parameter %q", name)
+		}
+		if inQuery {
+			b.WriteString(url.QueryEscape(value))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		tmpl = tmpl[i+j+1:]
+	}
+}
+
+// urlParam formats variable value v as a URL parameter value.
+// It reports false for nil values.
+func urlParam(v interface{}) (string, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", false
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", false
+	}
+	return fmt.Sprint(rv.Interface()), true
+}
@@ -0,0 +1,55 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_urlTemplate(t *testing.T) {
+	var urls []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		urls = append(urls, req.URL.String())
+		mustWrite(w, `{"data": {"repository": {"name": "graphql"}}}`)
+	})
+	client := graphql.NewClient("https://{region}.api.example.com/graphql?tenant={tenant}", &http.Client{Transport: localRoundTripper{handler: handler}})
+
+	ctx := graphql.WithURLParams(context.Background(), map[string]string{"region": "eu"})
+	ctx = graphql.WithURLParams(ctx, map[string]string{"tenant": "a&b"})
+	vars := map[string]interface{}{
+		"owner":  graphql.String("o"),
+		"name":   graphql.String("n"),
+		"region": graphql.String("us"),
+		"tenant": graphql.NewString("t1"),
+	}
+	ops := []struct {
+		ctx context.Context
+		op  graphql.Operation
+	}{
+		{ctx, graphql.NewQuery(new(repoQuery), vars)},
+		{context.Background(), graphql.NewQuery(new(repoQuery), vars)},
+		{ctx, &graphql.Routed{Operation: graphql.NewQuery(new(repoQuery), vars), URL: "/{owner}/graphql"}},
+	}
+	for _, tc := range ops {
+		if err := client.Run(tc.ctx, tc.op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"https://eu.api.example.com/graphql?tenant=a%26b",
+		"https://us.api.example.com/graphql?tenant=t1",
+		"https://eu.api.example.com/o/graphql",
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("request %d: got URL: %v, want: %v", i, urls[i], want[i])
+		}
+	}
+
+	err := client.Query(context.Background(), new(viewerQuery), nil)
+	if got, want := err.Error(), `no value for URL template parameter "region"`; got != want {
+		t.Errorf("got error: %v, want: %v", got, want)
+	}
+}
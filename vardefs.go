@@ -0,0 +1,240 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvata-io/graphql/internal/parser"
+)
+
+// VariableError is returned by Client.Run for operations that use a
+// variable they don't define, rather than sending them to be rejected
+// by the server. For a Query or Mutation, it means a variable referred
+// to in a graphql struct tag, such as `graphql:"node(id: $id)"`, is
+// missing from its variables.
+//
+// If the client was created with WithStrictVariables, it's also returned
+// for operations that define a variable they don't use, such as one in
+// the variables of a Query that no tag refers to.
+type VariableError struct {
+	Name      string // Name of the variable, without "$".
+	Operation string // Name of the operation, if any.
+	Field     string // Path of the field the variable is used by, such as "viewer.repository", if any.
+	Unused    bool   // Whether the variable is defined but not used, rather than used but not defined.
+}
+
+func (e *VariableError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graphql: variable \"$%s\"", e.Name)
+	if e.Unused {
+		b.WriteString(" is defined by operation")
+		if e.Operation != "" {
+			fmt.Fprintf(&b, " %q", e.Operation)
+		}
+		fmt.Fprintf(&b, " but not used; remove %q from its variables", e.Name)
+		return b.String()
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, " used by field %q", e.Field)
+	}
+	b.WriteString(" isn't defined by operation")
+	if e.Operation != "" {
+		fmt.Fprintf(&b, " %q", e.Operation)
+	}
+	fmt.Fprintf(&b, "; add %q to its variables", e.Name)
+	return b.String()
+}
+
+// WithStrictVariables makes the client return a *VariableError for
+// operations that define variables they don't use, rather than send
+// them, for servers that reject such operations, as the GraphQL
+// specification requires. Variables of a Query or Mutation are defined
+// by its variables, and used by the graphql struct tags of its data
+// structure, such as `graphql:"node(id: $id)"`. Operations run with a
+// schema, set with WithSchema, are checked regardless.
+func WithStrictVariables() ClientOption {
+	return func(c *Client) {
+		c.strictVars = true
+	}
+}
+
+// WithPruneUnusedVariables makes the client remove the variables that
+// operations don't use before sending them: both their values, and their
+// definitions in the document. It helps callers that reuse a generic
+// variables map across several operations. Variables of a Query or
+// Mutation are used by the graphql struct tags of its data structure,
+// such as `graphql:"node(id: $id)"`.
+func WithPruneUnusedVariables() ClientOption {
+	return func(c *Client) {
+		c.pruneVars = true
+	}
+}
+
+// varCheck is the result of checking a query for variables it uses
+// but doesn't define, and defines but doesn't use.
+type varCheck struct {
+	parsed    bool
+	undefined *VariableError  // First variable used but not defined, if any.
+	unused    *VariableError  // First variable defined but not used, if any.
+	used      map[string]bool // Variables used by any operation.
+	pruned    string          // Query without the definitions of variables that aren't used.
+}
+
+// varCheck returns the check of the variables of query. Results are
+// cached per query, for up to DefaultDocumentCacheSize queries, so that
+// documents built at run time, such as with literal values, don't grow
+// the cache without bound.
+func (c *Client) varCheck(query string) varCheck {
+	if check, ok := c.varChecks.load(query); ok {
+		return check
+	}
+	check, _ := c.varChecks.loadOrStore(query, checkDocumentVariables(query))
+	return check
+}
+
+// pruneVariables removes the variables request in doesn't use from its
+// variables and document. Queries that can't be parsed are left as is.
+func (c *Client) pruneVariables(in *Request) {
+	check := c.varCheck(in.Query)
+	if !check.parsed {
+		return
+	}
+	in.Query = check.pruned
+	for name := range in.Variables {
+		if !check.used[name] {
+			delete(in.Variables, name)
+		}
+	}
+}
+
+// checkVariables returns a *VariableError if an operation of query uses
+// a variable it doesn't define, or, if c has strict variables, defines a
+// variable it doesn't use. Queries that can't be parsed are left to the
+// server to report.
+func (c *Client) checkVariables(query string) error {
+	check := c.varCheck(query)
+	if check.undefined != nil {
+		return check.undefined
+	}
+	if c.strictVars && check.unused != nil {
+		return check.unused
+	}
+	return nil
+}
+
+// checkDocumentVariables checks the variables of the operations of query,
+// in document order.
+func checkDocumentVariables(query string) varCheck {
+	check := varCheck{used: make(map[string]bool)}
+	doc, err := parser.ParseDocument(query)
+	if err != nil {
+		return check
+	}
+	check.parsed = true
+	var unused [][2]int // Byte offsets of the definitions of unused variables.
+	for _, op := range doc.Operations {
+		defined := make(map[string]bool, len(op.VarDefs))
+		for _, d := range op.VarDefs {
+			defined[d.Name] = true
+		}
+		w := &varWalker{doc: doc, defined: defined, used: make(map[string]bool), visited: make(map[string]bool)}
+		w.directives(op.Directives, "")
+		w.selectionSet(op.SelectionSet, "")
+		if check.undefined == nil && w.undefined != nil {
+			w.undefined.Operation = op.Name
+			check.undefined = w.undefined
+		}
+		n := len(unused)
+		for _, d := range op.VarDefs {
+			if w.used[d.Name] {
+				continue
+			}
+			if check.unused == nil {
+				check.unused = &VariableError{Name: d.Name, Operation: op.Name, Unused: true}
+			}
+			unused = append(unused, [2]int{d.Start, d.End})
+		}
+		if len(op.VarDefs) > 0 && len(unused)-n == len(op.VarDefs) {
+			// Remove the parentheses too, as they can't be empty.
+			unused = append(unused[:n], [2]int{op.VarDefsStart, op.VarDefsEnd})
+		}
+		for name := range w.used {
+			check.used[name] = true
+		}
+	}
+	var b strings.Builder
+	last := 0
+	for _, r := range unused {
+		b.WriteString(query[last:r[0]])
+		last = r[1]
+	}
+	b.WriteString(query[last:])
+	check.pruned = b.String()
+	return check
+}
+
+// varWalker walks the selections of an operation, recording the
+// variables used, and the first use of one that isn't defined.
+type varWalker struct {
+	doc       *parser.Document
+	defined   map[string]bool
+	used      map[string]bool
+	visited   map[string]bool // Fragments walked.
+	undefined *VariableError
+}
+
+func (w *varWalker) selectionSet(sels []parser.Selection, path string) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *parser.Field:
+			p := sel.ResponseKey()
+			if path != "" {
+				p = path + "." + p
+			}
+			for _, a := range sel.Arguments {
+				w.value(a.Value, p)
+			}
+			w.directives(sel.Directives, p)
+			w.selectionSet(sel.SelectionSet, p)
+		case *parser.InlineFragment:
+			w.directives(sel.Directives, path)
+			w.selectionSet(sel.SelectionSet, path)
+		case *parser.FragmentSpread:
+			w.directives(sel.Directives, path)
+			if f := w.doc.Fragment(sel.Name); f != nil && !w.visited[sel.Name] {
+				w.visited[sel.Name] = true
+				w.directives(f.Directives, path)
+				w.selectionSet(f.SelectionSet, path)
+			}
+		}
+	}
+}
+
+func (w *varWalker) directives(ds []*parser.Directive, path string) {
+	for _, d := range ds {
+		for _, a := range d.Arguments {
+			w.value(a.Value, path)
+		}
+	}
+}
+
+func (w *varWalker) value(v *parser.Value, path string) {
+	if v == nil {
+		return
+	}
+	switch v.Kind {
+	case parser.VariableValue:
+		w.used[v.Raw] = true
+		if !w.defined[v.Raw] && w.undefined == nil {
+			w.undefined = &VariableError{Name: v.Raw, Field: path}
+		}
+	case parser.ListValue:
+		for _, e := range v.List {
+			w.value(e, path)
+		}
+	case parser.ObjectValue:
+		for _, f := range v.Fields {
+			w.value(f.Value, path)
+		}
+	}
+}
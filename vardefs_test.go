@@ -0,0 +1,174 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestClient_Run_undefinedVariable(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		Viewer struct {
+			Repository struct {
+				Name graphql.String
+			} `graphql:"repository(name: $name)"`
+			Bio graphql.String `graphql:"bio @include(if: $withBio)"`
+		}
+	}
+	tests := []struct {
+		name string
+		op   graphql.Operation
+		want string
+	}{
+		{
+			name: "missing from variables",
+			op:   graphql.NewQuery(&q, map[string]interface{}{"withBio": graphql.Boolean(true)}),
+			want: `graphql: variable "$name" used by field "viewer.repository" isn't defined by operation; add "name" to its variables`,
+		},
+		{
+			name: "directive",
+			op:   &graphql.Query{Data: &q, Vars: map[string]interface{}{"name": graphql.String("r")}, Name: "Viewer"},
+			want: `graphql: variable "$withBio" used by field "viewer.bio" isn't defined by operation "Viewer"; add "withBio" to its variables`,
+		},
+		{
+			name: "fragment",
+			op: &graphql.Static{
+				QueryStr: `query Q($first: Int) { ...F } fragment F on Query { issues(first: $first, filter: {labels: [$label]}) { totalCount } }`,
+				Into:     &q,
+			},
+			want: `graphql: variable "$label" used by field "issues" isn't defined by operation "Q"; add "label" to its variables`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Run(context.Background(), tt.op)
+			var ve *graphql.VariableError
+			if !errors.As(err, &ve) {
+				t.Fatalf("got error %v, want a *graphql.VariableError", err)
+			}
+			if got := err.Error(); got != tt.want {
+				t.Errorf("got error:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+	if requests != 0 {
+		t.Errorf("got %d requests, want none", requests)
+	}
+
+	// Variables defined by an operation may be omitted.
+	var v struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	op := &graphql.Static{QueryStr: `query($first: Int) { viewer { login } }`, Into: &v}
+	if err := client.Run(context.Background(), op); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestWithStrictVariables(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithStrictVariables())
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String `graphql:"login(format: $format)"`
+		}
+	}
+	vars := map[string]interface{}{
+		"format": graphql.String("short"),
+		"first":  graphql.Int(10),
+	}
+	err := client.Run(context.Background(), &graphql.Query{Data: &q, Vars: vars, Name: "Viewer"})
+	var ve *graphql.VariableError
+	if !errors.As(err, &ve) || !ve.Unused {
+		t.Fatalf("got error %v, want an unused *graphql.VariableError", err)
+	}
+	if got, want := err.Error(), `graphql: variable "$first" is defined by operation "Viewer" but not used; remove "first" from its variables`; got != want {
+		t.Errorf("got error:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Variables used only by fragments are used.
+	op := &graphql.Static{QueryStr: `query($id: ID!) { ...F } fragment F on Query { node(id: $id) { id } }`, Into: &q}
+	if err := client.Run(context.Background(), op); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestWithPruneUnusedVariables(t *testing.T) {
+	var got []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		got = append(got, mustRead(req.Body))
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}, graphql.WithPruneUnusedVariables())
+
+	// A generic variables map, shared by operations.
+	vars := map[string]interface{}{
+		"format": graphql.String("short"),
+		"first":  graphql.Int(10),
+	}
+	var q struct {
+		Viewer struct {
+			Login graphql.String `graphql:"login(format: $format)"`
+		}
+	}
+	if err := client.Query(context.Background(), &q, vars); err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	if err := client.Run(context.Background(), &graphql.Query{Data: &v, Vars: vars, Name: "Viewer"}); err != nil {
+		t.Fatal(err)
+	}
+	op := &graphql.Static{QueryStr: `query($id: ID, $first: Int) { viewer { login } }`, Into: &v, Vars: vars}
+	if err := client.Run(context.Background(), op); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"query":"query($format:String!){viewer{login(format: $format)}}","variables":{"format":"short"}}` + "\n",
+		`{"query":"query Viewer{viewer{login}}"}` + "\n",
+		`{"query":"query { viewer { login } }"}` + "\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got request body:\n%s\nwant:\n%s", got[i], want[i])
+		}
+	}
+	if len(vars) != 2 {
+		t.Errorf("variables of the caller were modified: %v", vars)
+	}
+}
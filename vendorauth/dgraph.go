@@ -0,0 +1,100 @@
+package vendorauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/arvata-io/graphql"
+)
+
+// Dgraph headers.
+const (
+	DgraphAPIKeyHeader      = "Dg-Auth"
+	DgraphAccessTokenHeader = "X-Dgraph-AccessToken"
+)
+
+// Dgraph is an http.RoundTripper authenticating requests to a Dgraph
+// GraphQL endpoint. Dgraph Cloud requires an API key; self-hosted servers
+// with access control lists require an access token, obtained with
+// DgraphLogin; and schemas with @auth rules read claims from a JWT in the
+// header named by their "# Dgraph.Authorization" comment.
+type Dgraph struct {
+	APIKey      string // Dgraph Cloud API key, sent in Dg-Auth, if any.
+	AccessToken string // Access JWT, sent in X-Dgraph-AccessToken, if any.
+
+	// AuthHeader is the header of the JWT for @auth rules, such as
+	// "X-My-App-Auth", as in the schema's "# Dgraph.Authorization" comment.
+	AuthHeader string
+	AuthToken  string // JWT for @auth rules, sent in AuthHeader, if any.
+
+	// Base is the round tripper sending the requests.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Dgraph) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := map[string]string{
+		DgraphAPIKeyHeader:      t.APIKey,
+		DgraphAccessTokenHeader: t.AccessToken,
+	}
+	if t.AuthHeader != "" {
+		headers[t.AuthHeader] = t.AuthToken
+	}
+	return base(t.Base).RoundTrip(withHeaders(req, headers))
+}
+
+// DgraphTokens are the tokens of a Dgraph login.
+type DgraphTokens struct {
+	AccessJWT  string // Access token, for Dgraph.AccessToken.
+	RefreshJWT string // Refresh token, for DgraphRefresh.
+}
+
+// DgraphLogin logs in to a Dgraph server with access control lists, as
+// user with password in namespace (0 by default), with admin, a client
+// of its /admin endpoint.
+func DgraphLogin(ctx context.Context, admin *graphql.Client, user, password string, namespace int) (DgraphTokens, error) {
+	var m struct {
+		Login dgraphLoginPayload `graphql:"login(userId: $userId, password: $password, namespace: $namespace)"`
+	}
+	err := admin.Mutate(ctx, &m, map[string]interface{}{
+		"userId":    graphql.String(user),
+		"password":  graphql.String(password),
+		"namespace": graphql.Int(namespace),
+	})
+	if err != nil {
+		return DgraphTokens{}, err
+	}
+	return m.Login.tokens()
+}
+
+// DgraphRefresh returns new tokens for refreshJWT, the refresh token of
+// a login, once its access token expires, with admin, a client of the
+// /admin endpoint of the Dgraph server.
+func DgraphRefresh(ctx context.Context, admin *graphql.Client, refreshJWT string) (DgraphTokens, error) {
+	var m struct {
+		Login dgraphLoginPayload `graphql:"login(refreshToken: $refreshToken)"`
+	}
+	err := admin.Mutate(ctx, &m, map[string]interface{}{
+		"refreshToken": graphql.String(refreshJWT),
+	})
+	if err != nil {
+		return DgraphTokens{}, err
+	}
+	return m.Login.tokens()
+}
+
+type dgraphLoginPayload struct {
+	Response struct {
+		AccessJWT  graphql.String `graphql:"accessJWT"`
+		RefreshJWT graphql.String `graphql:"refreshJWT"`
+	}
+}
+
+func (p dgraphLoginPayload) tokens() (DgraphTokens, error) {
+	if p.Response.AccessJWT == "" {
+		return DgraphTokens{}, errors.New("vendorauth: Dgraph login returned no access token")
+	}
+	return DgraphTokens{AccessJWT: string(p.Response.AccessJWT), RefreshJWT: string(p.Response.RefreshJWT)}, nil
+}
@@ -0,0 +1,99 @@
+package vendorauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arvata-io/graphql"
+	"github.com/arvata-io/graphql/vendorauth"
+)
+
+func TestDgraph(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header
+		io.WriteString(w, `{"data": {"me": {"name": "Gopher"}}}`)
+	}))
+	defer srv.Close()
+
+	httpClient := &http.Client{Transport: &vendorauth.Dgraph{
+		APIKey:      "key",
+		AccessToken: "access",
+		AuthHeader:  "X-My-App-Auth",
+		AuthToken:   "jwt",
+	}}
+	client := graphql.NewClient(srv.URL, httpClient)
+	var q struct {
+		Me struct {
+			Name graphql.String
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	for header, want := range map[string]string{
+		"Dg-Auth":              "key",
+		"X-Dgraph-AccessToken": "access",
+		"X-My-App-Auth":        "jwt",
+	} {
+		if v := got.Get(header); v != want {
+			t.Errorf("%s: got %q, want %q", header, v, want)
+		}
+	}
+}
+
+func TestDgraphLogin(t *testing.T) {
+	var gotBody struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&gotBody); err != nil {
+			t.Error(err)
+		}
+		io.WriteString(w, `{"data": {"login": {"response": {"accessJWT": "access", "refreshJWT": "refresh"}}}}`)
+	}))
+	defer srv.Close()
+	admin := graphql.NewClient(srv.URL, nil)
+
+	tokens, err := vendorauth.DgraphLogin(context.Background(), admin, "groot", "password", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (vendorauth.DgraphTokens{AccessJWT: "access", RefreshJWT: "refresh"}); tokens != want {
+		t.Errorf("got %+v, want %+v", tokens, want)
+	}
+	if want := `mutation($namespace:Int!$password:String!$userId:String!){login(userId: $userId, password: $password, namespace: $namespace){response{accessJWT,refreshJWT}}}`; gotBody.Query != want {
+		t.Errorf("got query %q, want %q", gotBody.Query, want)
+	}
+	if gotBody.Variables["userId"] != "groot" {
+		t.Errorf("got userId %v, want %q", gotBody.Variables["userId"], "groot")
+	}
+
+	tokens, err = vendorauth.DgraphRefresh(context.Background(), admin, "refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.AccessJWT != "access" {
+		t.Errorf("got access token %q, want %q", tokens.AccessJWT, "access")
+	}
+	if gotBody.Variables["refreshToken"] != "refresh" {
+		t.Errorf("got refreshToken %v, want %q", gotBody.Variables["refreshToken"], "refresh")
+	}
+}
+
+func TestDgraphLoginNoToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, `{"data": {"login": {"response": null}}}`)
+	}))
+	defer srv.Close()
+
+	_, err := vendorauth.DgraphLogin(context.Background(), graphql.NewClient(srv.URL, nil), "groot", "wrong", 0)
+	if err == nil {
+		t.Fatal("got nil error, want one")
+	}
+}
@@ -0,0 +1,45 @@
+package vendorauth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Hasura headers.
+const (
+	HasuraAdminSecretHeader = "X-Hasura-Admin-Secret"
+	HasuraRoleHeader        = "X-Hasura-Role"
+)
+
+// Hasura is an http.RoundTripper authenticating requests to a Hasura
+// GraphQL engine. With an admin secret, requests run as admin, or as Role
+// with session variables Session, as Hasura permits admins to. Without
+// one, Hasura authenticates requests by its webhook or JWT mode instead,
+// for which Role selects one of the roles the user is allowed.
+type Hasura struct {
+	AdminSecret string // Admin secret, sent in X-Hasura-Admin-Secret, if any.
+	Role        string // Role to run requests as, sent in X-Hasura-Role, if any.
+
+	// Session are session variables, such as "User-Id", sent in headers
+	// with their names prefixed by "X-Hasura-", unless they already are.
+	Session map[string]string
+
+	// Base is the round tripper sending the requests.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Hasura) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := map[string]string{
+		HasuraAdminSecretHeader: t.AdminSecret,
+		HasuraRoleHeader:        t.Role,
+	}
+	for name, v := range t.Session {
+		if !strings.HasPrefix(strings.ToLower(name), "x-hasura-") {
+			name = "X-Hasura-" + name
+		}
+		headers[name] = v
+	}
+	return base(t.Base).RoundTrip(withHeaders(req, headers))
+}
@@ -0,0 +1,70 @@
+package vendorauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arvata-io/graphql/vendorauth"
+)
+
+func TestHasura(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer srv.Close()
+
+	rt := &vendorauth.Hasura{
+		AdminSecret: "secret",
+		Role:        "user",
+		Session:     map[string]string{"User-Id": "42", "X-Hasura-Org-Id": "7"},
+	}
+	req, _ := http.NewRequest("POST", srv.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for header, want := range map[string]string{
+		"X-Hasura-Admin-Secret": "secret",
+		"X-Hasura-Role":         "user",
+		"X-Hasura-User-Id":      "42",
+		"X-Hasura-Org-Id":       "7",
+	} {
+		if v := got.Get(header); v != want {
+			t.Errorf("%s: got %q, want %q", header, v, want)
+		}
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("request modified: %v", req.Header)
+	}
+}
+
+func TestHasuraNoAdminSecret(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &vendorauth.Hasura{Role: "editor"}}
+	req, _ := http.NewRequest("POST", srv.URL, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if _, ok := got["X-Hasura-Admin-Secret"]; ok {
+		t.Error("got X-Hasura-Admin-Secret header, want none")
+	}
+	if v := got.Get("X-Hasura-Role"); v != "editor" {
+		t.Errorf("X-Hasura-Role: got %q, want %q", v, "editor")
+	}
+	if v := got.Get("Authorization"); v != "Bearer token" {
+		t.Errorf("Authorization: got %q, want %q", v, "Bearer token")
+	}
+}
@@ -0,0 +1,144 @@
+package vendorauth
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Signed is an http.RoundTripper signing requests with a timestamp, such
+// as by HMAC or AWS Signature Version 4, for servers rejecting requests
+// whose timestamps are too far from their own clocks.
+//
+// Signed detects the skew between the local clock and the server's from
+// the Date headers of its responses, and signs requests with timestamps
+// corrected by it. A request rejected because its signature expired is
+// signed again with the corrected timestamp and retried once.
+type Signed struct {
+	// Sign signs req, timestamped now, such as by setting its
+	// Authorization header. It may read the body of req, which is
+	// restored before req is sent.
+	Sign func(req *http.Request, now time.Time) error
+
+	// Expired reports whether resp rejects a request because its
+	// signature expired. The body of resp it's given holds the first
+	// 4 KiB of the response's, which is restored after, so it may read
+	// it. If nil, responses with status 401 or 403 whose bodies mention
+	// "expired" or "skew" are.
+	Expired func(resp *http.Response) bool
+
+	// Now returns the local time. If nil, time.Now is used.
+	Now func() time.Time
+
+	// Base is the round tripper sending the requests.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	mu   sync.Mutex
+	skew time.Duration
+}
+
+// Skew returns the skew detected between the server's clock and the local
+// clock, which is positive if the server's is ahead.
+func (t *Signed) Skew() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.skew
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Signed) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	skew := t.Skew()
+	resp, err := t.send(req, body, skew)
+	if err != nil {
+		return nil, err
+	}
+	if !t.expired(resp) || t.Skew() == skew {
+		return resp, nil
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return t.send(req, body, t.Skew())
+}
+
+// send sends a copy of req with body, signed with a timestamp corrected
+// by skew, and updates the skew from the Date header of the response.
+func (t *Signed) send(req *http.Request, body []byte, skew time.Duration) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if err := t.Sign(req, t.now().Add(skew)); err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := base(t.Base).RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.observe(resp)
+	return resp, nil
+}
+
+// observe updates the skew from the Date header of resp. As Date has
+// a resolution of seconds, skews under a second are ignored.
+func (t *Signed) observe(resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	skew := date.Sub(t.now().Truncate(time.Second))
+	if skew > -time.Second && skew < time.Second {
+		skew = 0
+	}
+	t.mu.Lock()
+	t.skew = skew
+	t.mu.Unlock()
+}
+
+// expired reports whether resp rejects a request because its signature
+// expired, peeking at the start of its body, which is restored.
+func (t *Signed) expired(resp *http.Response) bool {
+	if t.Expired == nil && resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	head, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	if t.Expired != nil {
+		peeked := *resp
+		peeked.Body = ioutil.NopCloser(bytes.NewReader(head))
+		return t.Expired(&peeked)
+	}
+	s := strings.ToLower(string(head))
+	return strings.Contains(s, "expired") || strings.Contains(s, "skew")
+}
+
+func (t *Signed) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// requestBody returns the body of req, read fully so it can be signed
+// and sent more than once, or nil if it has none.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return ioutil.ReadAll(req.Body)
+}
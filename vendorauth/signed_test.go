@@ -0,0 +1,122 @@
+package vendorauth_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql/vendorauth"
+)
+
+func TestSigned(t *testing.T) {
+	local := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	server := local.Add(10 * time.Minute)
+	var stamps, bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		stamps = append(stamps, req.Header.Get("X-Timestamp"))
+		w.Header().Set("Date", server.Format(http.TimeFormat))
+		stamp, _ := time.Parse(time.RFC3339, req.Header.Get("X-Timestamp"))
+		if d := server.Sub(stamp); d > time.Minute || d < -time.Minute {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Signature expired"}`))
+		}
+	}))
+	defer srv.Close()
+
+	rt := &vendorauth.Signed{
+		Sign: func(req *http.Request, now time.Time) error {
+			req.Header.Set("X-Timestamp", now.Format(time.RFC3339))
+			return nil
+		},
+		Now: func() time.Time { return local },
+	}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", srv.URL, strings.NewReader(`{"query":"{viewer{login}}"}`))
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("request %d: got status %v", i, resp.Status)
+		}
+	}
+	want := []string{"2024-01-01T12:00:00Z", "2024-01-01T12:10:00Z", "2024-01-01T12:10:00Z"}
+	if strings.Join(stamps, " ") != strings.Join(want, " ") {
+		t.Errorf("got timestamps: %q, want: %q", stamps, want)
+	}
+	for _, body := range bodies {
+		if body != `{"query":"{viewer{login}}"}` {
+			t.Errorf("got body: %q", body)
+		}
+	}
+	if got, want := rt.Skew(), 10*time.Minute; got != want {
+		t.Errorf("got skew: %v, want: %v", got, want)
+	}
+}
+
+func TestSignedRejected(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Signature expired"}`))
+	}))
+	defer srv.Close()
+
+	rt := &vendorauth.Signed{Sign: func(req *http.Request, now time.Time) error { return nil }}
+	req, _ := http.NewRequest("POST", srv.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || string(body) != `{"message": "Signature expired"}` {
+		t.Errorf("got response: %v %q", resp.Status, body)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 without skew", requests)
+	}
+}
+
+func TestSignedCustomExpired(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code": "REQUEST_TOO_OLD"}`))
+	}))
+	defer srv.Close()
+
+	var checked []string
+	rt := &vendorauth.Signed{
+		Sign: func(req *http.Request, now time.Time) error { return nil },
+		Expired: func(resp *http.Response) bool {
+			body, _ := ioutil.ReadAll(resp.Body)
+			checked = append(checked, string(body))
+			return strings.Contains(string(body), "REQUEST_TOO_OLD")
+		},
+	}
+	req, _ := http.NewRequest("POST", srv.URL, nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || string(body) != `{"code": "REQUEST_TOO_OLD"}` {
+		t.Errorf("got response: %v %q", resp.Status, body)
+	}
+	if len(checked) != 1 || checked[0] != `{"code": "REQUEST_TOO_OLD"}` {
+		t.Errorf("got bodies checked: %q", checked)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 without skew", requests)
+	}
+}
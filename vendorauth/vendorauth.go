@@ -0,0 +1,35 @@
+// Package vendorauth provides HTTP middleware that authenticates requests
+// to the GraphQL servers of common vendors, such as Hasura and Dgraph, with
+// the headers they expect, and that signs requests with timestamps
+// corrected for the skew of the server's clock. The middleware are
+// http.RoundTrippers, used in the HTTP client given to graphql.NewClient:
+//
+//	httpClient := &http.Client{Transport: &vendorauth.Hasura{
+//		AdminSecret: os.Getenv("HASURA_GRAPHQL_ADMIN_SECRET"),
+//		Role:        "user",
+//		Session:     map[string]string{"User-Id": "42"},
+//	}}
+//	client := graphql.NewClient("https://example.hasura.app/v1/graphql", httpClient)
+package vendorauth
+
+import "net/http"
+
+// base returns rt, or http.DefaultTransport if it's nil.
+func base(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// withHeaders returns a copy of req with headers set, as round trippers
+// mustn't modify requests. Headers with empty values are left unset.
+func withHeaders(req *http.Request, headers map[string]string) *http.Request {
+	req = req.Clone(req.Context())
+	for k, v := range headers {
+		if v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+	return req
+}
@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithAPIVersion makes the client select version of the server's API by
+// sending it in header with every request, such as "X-API-Version" or
+// GitHub's "X-GitHub-Api-Version". Schema previews selected by media
+// type can be sent in header "Accept", such as with version
+// "application/vnd.github.merge-info-preview+json". Operations wrapped
+// by WithVersion select another version. A RequestHandler can override it.
+func WithAPIVersion(header, version string) ClientOption {
+	return func(c *Client) {
+		c.versionHeader = http.CanonicalHeaderKey(header)
+		c.version = version
+	}
+}
+
+// WithVersion returns op decorated to select API version version instead
+// of the client's, as set with WithAPIVersion, such as to use a field
+// only available in a newer version.
+func WithVersion(op Operation, version string) Operation {
+	return &versioned{Operation: op, version: version}
+}
+
+type versioned struct {
+	Operation
+	version string
+}
+
+// Unwrap returns the operation wrapped by op.
+func (op *versioned) Unwrap() Operation {
+	return op.Operation
+}
+
+// VersionWarning is a warning about an operation selecting a deprecated
+// API version.
+type VersionWarning struct {
+	Op      Operation
+	Version string
+
+	// Message describes the deprecation, such as when the version stops
+	// being served, as given to WithDeprecatedVersions, or reported by
+	// the server.
+	Message string
+}
+
+// WithDeprecatedVersions marks API versions deprecated, mapping them to
+// messages describing their deprecation. warn is called whenever an
+// operation selects a deprecated version with WithAPIVersion or
+// WithVersion, and whenever the server reports the version an operation
+// selects is deprecated, with a Deprecation or Sunset response header.
+// If warn is nil, a warning is logged with the client's logger once
+// per version and message.
+func WithDeprecatedVersions(deprecated map[string]string, warn func(ctx context.Context, w VersionWarning)) ClientOption {
+	return func(c *Client) {
+		c.deprecations = deprecated
+		c.versionWarn = warn
+	}
+}
+
+// versionOf returns the API version op selects, or "" if none.
+func (c *Client) versionOf(op Operation) string {
+	if op, ok := asOperation[*versioned](op); ok {
+		return op.version
+	}
+	return c.version
+}
+
+// modifier returns the function modifying the HTTP requests of op,
+// which sets the API version header of c, if any, before calling
+// op.ModifyRequest, so a RequestHandler can override it.
+func (c *Client) modifier(op Operation) func(*http.Request) {
+	version := c.versionOf(op)
+	if c.versionHeader == "" || version == "" {
+		return op.ModifyRequest
+	}
+	return func(req *http.Request) {
+		req.Header.Set(c.versionHeader, version)
+		op.ModifyRequest(req)
+	}
+}
+
+// checkVersion warns about op selecting version if it's deprecated.
+func (c *Client) checkVersion(ctx context.Context, op Operation, version string) {
+	if msg, ok := c.deprecations[version]; ok {
+		c.warnVersion(ctx, VersionWarning{Op: op, Version: version, Message: msg})
+	}
+}
+
+// checkServerDeprecation warns about op selecting version if response
+// header h reports it's deprecated.
+func (c *Client) checkServerDeprecation(ctx context.Context, op Operation, version string, h http.Header) {
+	if h == nil {
+		return
+	}
+	var msg string
+	switch deprecation, sunset := h.Get("Deprecation"), h.Get("Sunset"); {
+	case deprecation != "" && sunset != "":
+		msg = "server reports deprecation " + deprecation + ", sunset " + sunset
+	case deprecation != "":
+		msg = "server reports deprecation " + deprecation
+	case sunset != "":
+		msg = "server reports sunset " + sunset
+	default:
+		return
+	}
+	c.warnVersion(ctx, VersionWarning{Op: op, Version: version, Message: msg})
+}
+
+func (c *Client) warnVersion(ctx context.Context, w VersionWarning) {
+	if c.versionWarn != nil {
+		c.versionWarn(ctx, w)
+		return
+	}
+	if _, warned := c.versionWarned.LoadOrStore(w.Version+"\x00"+w.Message, true); !warned {
+		c.logf("graphql: warning: API version %q is deprecated: %s", w.Version, w.Message)
+	}
+}
@@ -0,0 +1,125 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvata-io/graphql"
+)
+
+func TestWithAPIVersion(t *testing.T) {
+	var versions []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		versions = append(versions, req.Header.Get("X-Api-Version"))
+		mustRead(req.Body)
+		if req.Header.Get("X-Api-Version") == "2023-01" {
+			w.Header().Set("Deprecation", "@1688169599")
+			w.Header().Set("Sunset", "Sun, 30 Jun 2024 23:59:59 GMT")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	var warnings []graphql.VersionWarning
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithAPIVersion("X-API-Version", "2024-01"),
+		graphql.WithDeprecatedVersions(map[string]string{"2023-06": "removed in 2025"}, func(ctx context.Context, w graphql.VersionWarning) {
+			warnings = append(warnings, w)
+		}),
+	)
+
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	op := &graphql.Query{Data: &q}
+	for _, op := range []graphql.Operation{
+		op,
+		graphql.WithVersion(op, "2023-06"),
+		graphql.WithVersion(op, "2023-01"),
+		&graphql.Query{Data: &q, RequestHandler: func(req *http.Request) {
+			req.Header.Set("X-Api-Version", "2025-01")
+		}},
+	} {
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"2024-01", "2023-06", "2023-01", "2025-01"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("got versions: %q, want: %q", versions, want)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2: %+v", len(warnings), warnings)
+	}
+	if w := warnings[0]; w.Version != "2023-06" || w.Message != "removed in 2025" {
+		t.Errorf("got warning: %+v", w)
+	}
+	if w := warnings[1]; w.Version != "2023-01" || w.Message != "server reports deprecation @1688169599, sunset Sun, 30 Jun 2024 23:59:59 GMT" {
+		t.Errorf("got warning: %+v", w)
+	}
+}
+
+func TestWithDeprecatedVersions_log(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mustRead(req.Body)
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	var logger logRecorder
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithAPIVersion("X-API-Version", "2023-06"),
+		graphql.WithDeprecatedVersions(map[string]string{"2023-06": "removed in 2025"}, nil),
+		graphql.WithLogger(&logger),
+	)
+	for i := 0; i < 3; i++ {
+		var q struct {
+			Viewer struct {
+				Login graphql.String
+			}
+		}
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{`graphql: warning: API version "2023-06" is deprecated: removed in 2025`}; !reflect.DeepEqual([]string(logger), want) {
+		t.Errorf("got log:\n%q\nwant:\n%q", logger, want)
+	}
+}
+
+func TestWithAPIVersion_cache(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		mustRead(req.Body)
+		mustWrite(w, `{"data": {"viewer": {"login": "`+req.Header.Get("X-Api-Version")+`"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}},
+		graphql.WithAPIVersion("X-API-Version", "v1"),
+		graphql.WithCache(graphql.NewMemoryCache(), time.Minute),
+	)
+	var q struct {
+		Viewer struct {
+			Login graphql.String
+		}
+	}
+	for _, op := range []graphql.Operation{
+		&graphql.Query{Data: &q},
+		graphql.WithVersion(&graphql.Query{Data: &q}, "v2"),
+		&graphql.Query{Data: &q},
+	} {
+		if err := client.Run(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+	if got, want := q.Viewer.Login, graphql.String("v1"); got != want {
+		t.Errorf("got cached login: %q, want: %q", got, want)
+	}
+}